@@ -0,0 +1,123 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// adminPath is where the admin bridge answers on admin_port.
+const adminPath = "/sfdeploy"
+
+// adminEndpoint is the URL restarts and reloads are requested from: the
+// admin bridge (see adminbridge.go) at admin_host:admin_port, or admin_url
+// for an endpoint of your own that speaks the same protocol. It is empty
+// when neither is set. admin_host defaults to the host of the target.
+func adminEndpoint(config *Config) string {
+	if config.AdminURL != "" {
+		return config.AdminURL
+	}
+	if config.AdminPort <= 0 {
+		return ""
+	}
+	host := config.AdminHost
+	if host == "" {
+		host = healthHost(config)
+	}
+	return "http://" + net.JoinHostPort(host, strconv.Itoa(config.AdminPort)) + adminPath
+}
+
+// adminEndpointPort is the port of adminEndpoint, or 0.
+func adminEndpointPort(config *Config) int {
+	u, err := url.Parse(adminEndpoint(config))
+	if err != nil {
+		return 0
+	}
+	port, _ := strconv.Atoi(u.Port())
+	return port
+}
+
+// useAdminRestart reports whether restarts go through the admin API, in
+// which case the deploy phase leaves the server running.
+func useAdminRestart(config *Config) bool {
+	return adminEndpoint(config) != ""
+}
+
+// useAdminReload reports whether a deploy reloads the extensions through the
+// admin API instead of restarting the server.
+func useAdminReload(config *Config) bool {
+	return config.Reloadable && adminEndpoint(config) != ""
+}
+
+func adminRestart(config *Config) error {
+	return adminPost(config, url.Values{"action": {"restart"}})
+}
+
+func adminReload(config *Config) error {
+	return adminPost(config, url.Values{"action": {"reload"}, "extension": {config.ExtensionFolder}, "zone": {config.ZoneName}})
+}
+
+// adminPost sends a form-encoded action to the admin endpoint with the
+// admin_user and admin_password as basic auth. Any 2xx answer is success.
+func adminPost(config *Config, form url.Values) error {
+	req, err := http.NewRequest(http.MethodPost, adminEndpoint(config), strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if config.AdminUser != "" {
+		req.SetBasicAuth(config.AdminUser, config.AdminPassword)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// restartViaAdmin asks the admin API for a graceful restart. It returns false
+// when the request failed and the caller should fall back to a hard restart.
+func restartViaAdmin(config *Config) bool {
+	tprintf("🛰️ Requesting graceful restart via %s...\n", adminEndpoint(config))
+	if err := adminRestart(config); err != nil {
+		tprintf("⚠️ Admin API restart failed: %v\n", err)
+		tprintln("⚠️ Falling back to a hard restart")
+		return false
+	}
+
+	tprintln("✅ Restart requested through the admin API")
+	fmt.Println()
+	return true
+}
+
+// reloadViaAdmin asks the admin API to reload every extension in its zone,
+// leaving the rest of the server alone. It returns false when a reload
+// failed and the caller should restart the server instead.
+func reloadViaAdmin(config *Config) bool {
+	for _, ext := range extensionConfigs(config) {
+		zone := "its zone"
+		if ext.ZoneName != "" {
+			zone = "zone " + ext.ZoneName
+		}
+		tprintf("♻️ Reloading %s in %s via %s...\n", ext.ExtensionFolder, zone, adminEndpoint(&ext))
+		if err := adminReload(&ext); err != nil {
+			tprintf("⚠️ Admin API reload of %s failed: %v\n", ext.ExtensionFolder, err)
+			return false
+		}
+	}
+
+	tprintln("✅ Extension reloaded through the admin API, the server kept running")
+	fmt.Println()
+	return true
+}
@@ -0,0 +1,367 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const (
+	adminBridgeJar    = "sfdeploy-admin.jar"
+	adminBridgeConfig = "sfdeploy-admin.properties"
+	adminBridgeClass  = "sfdeploy.admin.AdminBridge"
+)
+
+// adminBridgeSource is the server side of the admin API. The AdminTool
+// talks to SmartFox over its binary client protocol, not HTTP, so sfdeploy
+// brings its own endpoint: a JDK HttpServer started from the init() of a
+// zone extension. It lives in extensions/__lib__, so it keeps running while
+// that extension is reloaded. It reads its address and credentials from
+// SFS2X/config/sfdeploy-admin.properties and answers form-encoded POSTs:
+//
+//	action=reload&extension=<folder>&zone=<zone>  reloads a zone extension
+//	action=restart                                 restarts SmartFox
+const adminBridgeSource = `package sfdeploy.admin;
+
+import com.smartfoxserver.v2.SmartFoxServer;
+import com.smartfoxserver.v2.entities.Zone;
+import com.smartfoxserver.v2.extensions.ISFSExtension;
+import com.sun.net.httpserver.HttpExchange;
+import com.sun.net.httpserver.HttpServer;
+import org.slf4j.Logger;
+import org.slf4j.LoggerFactory;
+
+import java.io.ByteArrayOutputStream;
+import java.io.FileInputStream;
+import java.io.IOException;
+import java.io.InputStream;
+import java.io.OutputStream;
+import java.lang.reflect.Method;
+import java.net.InetSocketAddress;
+import java.net.URLDecoder;
+import java.nio.charset.StandardCharsets;
+import java.security.MessageDigest;
+import java.util.ArrayList;
+import java.util.Base64;
+import java.util.HashMap;
+import java.util.List;
+import java.util.Map;
+import java.util.Properties;
+import java.util.concurrent.Executors;
+
+/**
+ * Lets sfdeploy reload extensions and restart the server over HTTP. Call
+ * AdminBridge.start() from the init() of a zone extension; it is started once
+ * per server and survives reloads of that extension. Generated by
+ * "sfdeploy admin install".
+ */
+public final class AdminBridge {
+    private static final String CONFIG = "config/` + adminBridgeConfig + `";
+    private static final Logger log = LoggerFactory.getLogger(AdminBridge.class);
+    private static HttpServer server;
+
+    private AdminBridge() {
+    }
+
+    public static synchronized void start() {
+        if (server != null) {
+            return;
+        }
+        Properties config = new Properties();
+        try (InputStream in = new FileInputStream(CONFIG)) {
+            config.load(in);
+        } catch (IOException e) {
+            log.warn("sfdeploy admin bridge not started, cannot read " + CONFIG + ": " + e.getMessage());
+            return;
+        }
+        String host = config.getProperty("host", "127.0.0.1").trim();
+        int port = Integer.parseInt(config.getProperty("port", "0").trim());
+        String user = config.getProperty("user", "");
+        String password = config.getProperty("password", "");
+        if (port <= 0 || user.isEmpty() || password.isEmpty()) {
+            log.warn("sfdeploy admin bridge not started, " + CONFIG + " needs port, user and password");
+            return;
+        }
+        String credentials = Base64.getEncoder().encodeToString((user + ":" + password).getBytes(StandardCharsets.UTF_8));
+        final byte[] expected = ("Basic " + credentials).getBytes(StandardCharsets.UTF_8);
+
+        try {
+            HttpServer http = HttpServer.create(new InetSocketAddress(host, port), 0);
+            http.createContext("` + adminPath + `", exchange -> handle(exchange, expected));
+            http.setExecutor(Executors.newSingleThreadExecutor(task -> {
+                Thread thread = new Thread(task, "sfdeploy-admin");
+                thread.setDaemon(true);
+                return thread;
+            }));
+            http.start();
+            server = http;
+            log.info("sfdeploy admin bridge listening on " + host + ":" + port);
+        } catch (IOException e) {
+            log.warn("sfdeploy admin bridge not started on " + host + ":" + port + ": " + e.getMessage());
+        }
+    }
+
+    private static void handle(HttpExchange exchange, byte[] expected) throws IOException {
+        try {
+            String auth = exchange.getRequestHeaders().getFirst("Authorization");
+            if (auth == null || !MessageDigest.isEqual(auth.getBytes(StandardCharsets.UTF_8), expected)) {
+                reply(exchange, 401, "unauthorized");
+                return;
+            }
+            if (!"POST".equals(exchange.getRequestMethod())) {
+                reply(exchange, 405, "POST only");
+                return;
+            }
+            Map<String, String> form = parseForm(readAll(exchange.getRequestBody()));
+            String action = form.getOrDefault("action", "");
+            if (action.equals("reload")) {
+                reload(exchange, form.getOrDefault("extension", ""), form.getOrDefault("zone", ""));
+            } else if (action.equals("restart")) {
+                restart(exchange);
+            } else {
+                reply(exchange, 400, "unknown action: " + action);
+            }
+        } finally {
+            exchange.close();
+        }
+    }
+
+    private static void reload(HttpExchange exchange, String extension, String zoneName) throws IOException {
+        SmartFoxServer sfs = SmartFoxServer.getInstance();
+        List<Zone> zones = new ArrayList<>();
+        if (!zoneName.isEmpty()) {
+            Zone zone = sfs.getZoneManager().getZoneByName(zoneName);
+            if (zone == null) {
+                reply(exchange, 404, "no zone " + zoneName);
+                return;
+            }
+            zones.add(zone);
+        } else {
+            zones.addAll(sfs.getZoneManager().getZoneList());
+        }
+
+        for (Zone zone : zones) {
+            ISFSExtension ext = zone.getExtension();
+            if (ext == null || !extension.equals(ext.getName())) {
+                continue;
+            }
+            try {
+                sfs.getExtensionManager().reloadExtension(ext);
+            } catch (RuntimeException e) {
+                log.warn("sfdeploy admin bridge could not reload " + extension, e);
+                reply(exchange, 500, "reload failed: " + e);
+                return;
+            }
+            log.info("sfdeploy admin bridge reloaded " + extension + " in zone " + zone.getName());
+            reply(exchange, 200, "reloaded " + extension + " in zone " + zone.getName());
+            return;
+        }
+        reply(exchange, 404, "no zone runs the extension " + extension);
+    }
+
+    private static void restart(HttpExchange exchange) throws IOException {
+        // Not every SmartFox version has restart(); 501 makes sfdeploy fall
+        // back to a hard restart
+        final Method restart;
+        try {
+            restart = SmartFoxServer.class.getMethod("restart");
+        } catch (NoSuchMethodException e) {
+            reply(exchange, 501, "this SmartFoxServer cannot restart itself");
+            return;
+        }
+        reply(exchange, 202, "restarting");
+
+        // The answer must reach sfdeploy before the server goes down
+        Thread thread = new Thread(() -> {
+            try {
+                Thread.sleep(500);
+                log.info("sfdeploy admin bridge restarting the server");
+                restart.invoke(SmartFoxServer.getInstance());
+            } catch (Exception e) {
+                log.warn("sfdeploy admin bridge could not restart the server", e);
+            }
+        }, "sfdeploy-restart");
+        thread.start();
+    }
+
+    private static String readAll(InputStream in) throws IOException {
+        ByteArrayOutputStream out = new ByteArrayOutputStream();
+        byte[] buffer = new byte[4096];
+        for (int n; (n = in.read(buffer)) > 0 && out.size() < 65536; ) {
+            out.write(buffer, 0, n);
+        }
+        return new String(out.toByteArray(), StandardCharsets.UTF_8);
+    }
+
+    private static Map<String, String> parseForm(String body) throws IOException {
+        Map<String, String> form = new HashMap<>();
+        for (String pair : body.split("&")) {
+            int eq = pair.indexOf('=');
+            if (eq > 0) {
+                form.put(URLDecoder.decode(pair.substring(0, eq), "UTF-8"), URLDecoder.decode(pair.substring(eq + 1), "UTF-8"));
+            }
+        }
+        return form;
+    }
+
+    private static void reply(HttpExchange exchange, int status, String message) throws IOException {
+        byte[] body = (message + "\n").getBytes(StandardCharsets.UTF_8);
+        exchange.getResponseHeaders().set("Content-Type", "text/plain; charset=utf-8");
+        exchange.sendResponseHeaders(status, body.length);
+        try (OutputStream out = exchange.getResponseBody()) {
+            out.write(body);
+        }
+    }
+}
+`
+
+// adminCommand implements "admin install".
+func adminCommand(config *Config) bool {
+	switch commandArg(0) {
+	case "install":
+		return installAdminBridge(config)
+	default:
+		tprintf("Unknown admin command: %s (expected install)\n", commandArg(0))
+		return false
+	}
+}
+
+// adminBindHost is the address the bridge listens on: loopback when sfdeploy
+// reaches a server on this machine through it, else every interface.
+func adminBindHost(config *Config) string {
+	if _, ok := targetShare(config); !ok && isLocalTarget(config) {
+		if ip := net.ParseIP(config.AdminHost); config.AdminHost == "" || config.AdminHost == "localhost" || (ip != nil && ip.IsLoopback()) {
+			return "127.0.0.1"
+		}
+	}
+	return "0.0.0.0"
+}
+
+// propertiesValue escapes a value for java.util.Properties.
+func propertiesValue(value string) string {
+	value = strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`).Replace(value)
+	if strings.HasPrefix(value, " ") {
+		value = `\` + value
+	}
+	return value
+}
+
+// installAdminBridge compiles the admin bridge against the server's
+// libraries and puts it into extensions/__lib__, with its settings in
+// SFS2X/config, on a local, share, SSH or Docker target.
+func installAdminBridge(config *Config) bool {
+	if config.AdminPort <= 0 || config.AdminUser == "" || config.AdminPassword == "" {
+		tprintln("❌ Set admin_port, admin_user and admin_password first; the bridge only answers authenticated requests")
+		return false
+	}
+	if config.AdminURL != "" {
+		tprintf("⚠️ Warning: admin_url is set, so sfdeploy keeps using %s instead of the bridge\n", config.AdminURL)
+	}
+
+	dir := filepath.Join(stateDir, "admin")
+	srcDir := filepath.Join(dir, "src")
+	classesDir := filepath.Join(dir, "classes")
+	os.RemoveAll(dir)
+	sourceFile := filepath.Join(srcDir, "sfdeploy", "admin", "AdminBridge.java")
+	if err := os.MkdirAll(filepath.Dir(sourceFile), 0755); err != nil {
+		tprintf("❌ Failed to create %s: %v\n", filepath.Dir(sourceFile), err)
+		return false
+	}
+	if err := os.MkdirAll(classesDir, 0755); err != nil {
+		tprintf("❌ Failed to create %s: %v\n", classesDir, err)
+		return false
+	}
+	if err := os.WriteFile(sourceFile, []byte(adminBridgeSource), 0644); err != nil {
+		tprintf("❌ Failed to write %s: %v\n", sourceFile, err)
+		return false
+	}
+
+	tprintln("🔨 Compiling the admin bridge...")
+	javacPath := filepath.Join(config.JavaPath, "javac")
+	if runtime.GOOS == "windows" {
+		javacPath += ".exe"
+	}
+	args := []string{"-cp", filepath.Join(absPath(serverLibDir(config)), "*"), "-d", absPath(classesDir), "-encoding", "UTF-8"}
+	if config.JavaRelease != "" {
+		args = append(args, "--release", config.JavaRelease)
+	}
+	if output, err := newCommand(javacPath, append(args, absPath(sourceFile))...).CombinedOutput(); err != nil {
+		tprintf("❌ Compiling the admin bridge failed: %s\n", strings.TrimSpace(string(output)))
+		return false
+	}
+	jarFile := filepath.Join(dir, adminBridgeJar)
+	if !runJar(config, classesDir, adminBridgeJar, "cf", absPath(jarFile), ".") {
+		return false
+	}
+
+	settings := fmt.Sprintf("# sfdeploy admin bridge, written by sfdeploy admin install\nhost=%s\nport=%d\nuser=%s\npassword=%s\n",
+		adminBindHost(config), config.AdminPort, propertiesValue(config.AdminUser), propertiesValue(config.AdminPassword))
+	settingsFile := filepath.Join(dir, adminBridgeConfig)
+	if err := os.WriteFile(settingsFile, []byte(settings), 0600); err != nil {
+		tprintf("❌ Failed to write %s: %v\n", settingsFile, err)
+		return false
+	}
+
+	if !copyAdminBridge(config, jarFile, settingsFile) {
+		return false
+	}
+
+	fmt.Println()
+	tprintf("✅ Installed the admin bridge, listening on %s:%d once started\n", adminBindHost(config), config.AdminPort)
+	tprintln("💡 Start it from the init() of your zone extension, then restart the server once:")
+	fmt.Printf("      %s.start();\n", adminBridgeClass)
+	return true
+}
+
+// copyAdminBridge puts the bridge JAR into extensions/__lib__ and its
+// settings into SFS2X/config of the target.
+func copyAdminBridge(config *Config, jarFile, settingsFile string) bool {
+	if remote, ok := parseRemoteTarget(config.TargetDir); ok {
+		commands := []string{
+			fmt.Sprintf("put %s %s", sftpQuote(jarFile), sftpQuote(path.Join(remote.extensionsDir(), "__lib__", adminBridgeJar))),
+			fmt.Sprintf("put %s %s", sftpQuote(settingsFile), sftpQuote(remote.path("SFS2X", "config", adminBridgeConfig))),
+			"chmod 600 " + sftpQuote(remote.path("SFS2X", "config", adminBridgeConfig)),
+		}
+		if output, err := remote.sftp(config, commands); err != nil {
+			tprintf("❌ SFTP upload failed: %s\n", strings.TrimSpace(string(output)))
+			return false
+		}
+		fmt.Printf("   + %s\n", remote.path("SFS2X", "extensions", "__lib__", adminBridgeJar))
+		fmt.Printf("   + %s\n", remote.path("SFS2X", "config", adminBridgeConfig))
+		return true
+	}
+	if d, ok := parseDockerTarget(config.TargetDir); ok {
+		for _, file := range [][2]string{
+			{jarFile, d.ref("SFS2X", "extensions", "__lib__", adminBridgeJar)},
+			{settingsFile, d.ref("SFS2X", "config", adminBridgeConfig)},
+		} {
+			if err := dockerCopy(file[0], file[1]); err != nil {
+				fmt.Printf("❌ %v\n", err)
+				return false
+			}
+			fmt.Printf("   + %s\n", file[1])
+		}
+		return true
+	}
+
+	for _, file := range [][2]string{
+		{jarFile, filepath.Join(config.TargetDir, "SFS2X", "extensions", "__lib__", adminBridgeJar)},
+		{settingsFile, filepath.Join(config.TargetDir, "SFS2X", "config", adminBridgeConfig)},
+	} {
+		if err := os.MkdirAll(filepath.Dir(file[1]), 0755); err != nil {
+			tprintf("❌ Failed to create %s: %v\n", filepath.Dir(file[1]), err)
+			return false
+		}
+		if err := copyFile(file[0], file[1]); err != nil {
+			tprintf("❌ Failed to copy %s: %v\n", filepath.Base(file[0]), err)
+			return false
+		}
+		fmt.Printf("   + %s\n", file[1])
+	}
+	os.Chmod(filepath.Join(config.TargetDir, "SFS2X", "config", adminBridgeConfig), 0600)
+	return true
+}
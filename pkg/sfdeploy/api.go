@@ -0,0 +1,185 @@
+package sfdeploy
+
+import "fmt"
+
+// Exported names for the types of Config fields, so a config can be built
+// in Go.
+type (
+	ExtensionConfig = extensionConfig
+	ResourceRoot    = resourceRoot
+	WorkspaceEntry  = workspaceEntry
+	NotifyConfig    = notifyConfig
+	SmokeConfig     = smokeConfig
+)
+
+// Builder compiles the project and creates its extension JARs.
+type Builder interface {
+	Build(config *Config) error
+}
+
+// Deployer copies the built JARs and resources to the servers.
+type Deployer interface {
+	Deploy(config *Config) error
+}
+
+// Restarter restarts the servers and checks that they came back.
+type Restarter interface {
+	Restart(config *Config) error
+}
+
+// PhaseError is returned when a phase fails. The reason has already been
+// printed; Code is the exit code the CLI uses for the failure.
+type PhaseError struct {
+	Phase string
+	Code  int
+}
+
+func (e *PhaseError) Error() string {
+	return fmt.Sprintf("%s failed (exit code %d)", e.Phase, e.Code)
+}
+
+// runStage runs phases and turns a failure into a PhaseError.
+func runStage(name string, config *Config, phases ...phase) error {
+	failureMu.Lock()
+	failureCode = exitOK
+	failureMu.Unlock()
+
+	if !runPhases(config, phases) {
+		return &PhaseError{Phase: name, Code: exitCode()}
+	}
+	return nil
+}
+
+// DefaultBuilder builds the project as "sfdeploy build" does, then runs its
+// unit tests unless SkipTests is set.
+type DefaultBuilder struct {
+	SkipTests bool
+}
+
+func (b DefaultBuilder) Build(config *Config) error {
+	phases := []phase{buildProject, customPhases("build")}
+	if !b.SkipTests {
+		phases = append(phases, runTests, customPhases("test"))
+	}
+	return runStage("build", config, phases...)
+}
+
+// DefaultDeployer deploys to target_dir or every server of targets, as
+// "sfdeploy deploy" does.
+type DefaultDeployer struct{}
+
+func (DefaultDeployer) Deploy(config *Config) error {
+	return runStage("deploy", config, lockedTarget(deployProject, customPhases("deploy")))
+}
+
+// DefaultRestarter restarts each server and runs the health check and smoke
+// test, as "sfdeploy restart" does.
+type DefaultRestarter struct{}
+
+func (DefaultRestarter) Restart(config *Config) error {
+	return runStage("restart", config, lockedTarget(restartServer, checkServerHealth, smokeTest, customPhases("restart")))
+}
+
+// Pipeline runs the stages of a hot deploy in order. A nil stage is skipped,
+// so a Pipeline without a Builder deploys the JARs of the last build.
+type Pipeline struct {
+	Builder   Builder
+	Deployer  Deployer
+	Restarter Restarter
+
+	// Cleanup removes the build artifacts from the source directory at the end
+	Cleanup bool
+}
+
+// NewPipeline returns the pipeline of "sfdeploy all".
+func NewPipeline() *Pipeline {
+	return &Pipeline{
+		Builder:   DefaultBuilder{},
+		Deployer:  DefaultDeployer{},
+		Restarter: DefaultRestarter{},
+		Cleanup:   true,
+	}
+}
+
+// Run runs the stages against a config from LoadConfig or Prepare. After a
+// deploy, the post-deploy hooks run once the servers are back.
+func (p *Pipeline) Run(config *Config) error {
+	if p.Builder != nil {
+		if err := p.Builder.Build(config); err != nil {
+			return err
+		}
+	}
+	if p.Deployer != nil {
+		if err := p.Deployer.Deploy(config); err != nil {
+			return err
+		}
+	}
+	if p.Restarter != nil {
+		if err := p.Restarter.Restart(config); err != nil {
+			return err
+		}
+	}
+	if p.Deployer != nil {
+		if err := runStage("post-deploy hooks", config, perTarget(postDeployHooks)); err != nil {
+			return err
+		}
+	}
+	if p.Cleanup {
+		return runStage("cleanup", config, cleanupProject)
+	}
+	return nil
+}
+
+// LoadConfig reads a config file the way the CLI does: the project config,
+// profile, SFDEPLOY_* variables and secrets are applied, then the
+// directories and the JDK are checked. An empty path uses the default file.
+func LoadConfig(path string) (*Config, error) {
+	if path != "" {
+		*flagConfig = path
+	}
+	config := &Config{}
+	if err := runStage("setup", config, setupDirectories, setupJava); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Prepare checks a config built in Go instead of read from a file, fills in
+// its defaults and finds the JDK.
+func Prepare(config *Config) error {
+	if err := resolveSecrets(config); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return &PhaseError{Phase: "setup", Code: exitConfig}
+	}
+	return runStage("setup", config, checkDirectories, setupJava)
+}
+
+// Options are the settings a library caller would otherwise pass as flags.
+type Options struct {
+	Profile  string
+	DryRun   bool
+	NoPrompt bool
+	Rebuild  bool
+	Verbose  bool
+	Debug    bool
+}
+
+// SetOptions applies o to every following call. The package keeps its state
+// in globals, so only one pipeline can run at a time.
+func SetOptions(o Options) {
+	*flagProfile = o.Profile
+	*flagDryRun = o.DryRun
+	*flagNoPrompt = o.NoPrompt
+	*flagRebuild = o.Rebuild
+	*flagVerbose = o.Verbose
+	*flagDebug = o.Debug
+
+	level := levelNormal
+	if o.Verbose {
+		level = levelVerbose
+	}
+	if o.Debug {
+		level = levelDebug
+	}
+	setRouted(level, false)
+}
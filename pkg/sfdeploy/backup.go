@@ -0,0 +1,239 @@
+package sfdeploy
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// backupSuffix is what follows the prefix in a backup name, the snapshot
+// timestamp: -20261014-150405.zip
+var backupSuffix = regexp.MustCompile(`^-\d{8}-\d{6}(-\d+)?\.zip$`)
+
+// backupPrefix names the zip files of one extension (and cluster target).
+func backupPrefix(config *Config) string {
+	if len(config.Targets) > 0 {
+		return config.ExtensionFolder + "-" + clusterKey(config.TargetDir)
+	}
+	return config.ExtensionFolder
+}
+
+// backupSnapshot zips a freshly taken snapshot into backup_dir, so every
+// overwrite of the live extension leaves a restorable archive behind.
+func backupSnapshot(config *Config, snapshotDir string) error {
+	if config.BackupDir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(config.BackupDir, 0755); err != nil {
+		return err
+	}
+
+	zipPath := filepath.Join(config.BackupDir, backupPrefix(config)+"-"+filepath.Base(snapshotDir)+".zip")
+	if err := zipDir(snapshotDir, zipPath); err != nil {
+		os.Remove(zipPath)
+		return err
+	}
+	tprintf("💾 Backup written: %s\n", zipPath)
+
+	return pruneBackups(config)
+}
+
+func zipDir(dir, zipPath string) error {
+	file, err := os.Create(zipPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		header.Method = zip.Deflate
+
+		w, err := archive.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		src, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		_, err = io.Copy(w, src)
+		return err
+	})
+	if err != nil {
+		archive.Close()
+		return err
+	}
+	return archive.Close()
+}
+
+func unzipTo(zipPath, dir string) error {
+	archive, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	for _, f := range archive.File {
+		dst := filepath.Join(dir, filepath.FromSlash(f.Name))
+		if !strings.HasPrefix(dst, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return fmt.Errorf("invalid path in backup: %s", f.Name)
+		}
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return err
+		}
+
+		src, err := f.Open()
+		if err != nil {
+			return err
+		}
+		out, err := os.Create(dst)
+		if err != nil {
+			src.Close()
+			return err
+		}
+		_, err = io.Copy(out, src)
+		src.Close()
+		out.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadBackups returns the zip backups of the extension, newest first.
+func loadBackups(config *Config) []string {
+	matches, _ := filepath.Glob(filepath.Join(config.BackupDir, backupPrefix(config)+"-*.zip"))
+
+	var backups []string
+	for _, match := range matches {
+		rest := strings.TrimPrefix(filepath.Base(match), backupPrefix(config))
+		if backupSuffix.MatchString(rest) {
+			backups = append(backups, match)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+	return backups
+}
+
+func pruneBackups(config *Config) error {
+	if config.BackupLimit <= 0 {
+		return nil
+	}
+	backups := loadBackups(config)
+	for i := config.BackupLimit; i < len(backups); i++ {
+		if err := os.Remove(backups[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func backupCommand(config *Config) bool {
+	if config.BackupDir == "" {
+		tprintln("backup_dir is not configured")
+		return false
+	}
+
+	switch commandArg(0) {
+	case "", "list":
+		return listBackups(config)
+	case "restore":
+		if commandArg(1) == "" {
+			tprintln("Usage: sfdeploy backup restore <n|file.zip>")
+			return false
+		}
+		return withDeployLock(func(config *Config) bool {
+			return restoreBackup(config, commandArg(1)) && restartServer(config) && checkServerHealth(config) && smokeTest(config)
+		})(config)
+	default:
+		tprintf("Unknown backup command: %s (expected list or restore)\n", commandArg(0))
+		return false
+	}
+}
+
+func listBackups(config *Config) bool {
+	backups := loadBackups(config)
+	if len(backups) == 0 {
+		tprintf("No backups for %s in %s\n", config.ExtensionFolder, config.BackupDir)
+		fmt.Println()
+		return true
+	}
+
+	tprintf("Backups for %s in %s (newest first):\n", config.ExtensionFolder, config.BackupDir)
+	for i, backup := range backups {
+		size := int64(0)
+		if info, err := os.Stat(backup); err == nil {
+			size = info.Size()
+		}
+		fmt.Printf("  #%-3d %s  %d KB\n", i+1, filepath.Base(backup), size/1024)
+	}
+	fmt.Println()
+
+	return true
+}
+
+// restoreBackup unpacks a backup, chosen by list number or file path, and
+// restores it like a history snapshot.
+func restoreBackup(config *Config, which string) bool {
+	zipPath := which
+	if n, err := strconv.Atoi(which); err == nil {
+		backups := loadBackups(config)
+		if n < 1 || n > len(backups) {
+			tprintf("❌ Backup #%d not found (have %d)\n", n, len(backups))
+			return false
+		}
+		zipPath = backups[n-1]
+	}
+
+	tprintf("⏪ Restoring Backup %s\n", filepath.Base(zipPath))
+
+	tmp, err := os.MkdirTemp("", "sfdeploy-backup-")
+	if err != nil {
+		tprintf("❌ Failed to create temporary directory: %v\n", err)
+		return false
+	}
+	defer os.RemoveAll(tmp)
+
+	if err := unzipTo(zipPath, tmp); err != nil {
+		tprintf("❌ Failed to unpack %s: %v\n", zipPath, err)
+		return false
+	}
+
+	var entry historyEntry
+	if data, err := os.ReadFile(filepath.Join(tmp, historyMetaFile)); err == nil {
+		json.Unmarshal(data, &entry)
+	}
+	if err := os.MkdirAll(filepath.Join(tmp, "extension"), 0755); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return false
+	}
+	entry.dir = tmp
+
+	return restoreEntry(config, entry)
+}
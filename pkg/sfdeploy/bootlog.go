@@ -0,0 +1,188 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const maxTraceLines = 60
+
+// restartMark is what a target looked like just before its restart: where
+// smartfox.log ended, so only lines written by the new boot are inspected,
+// and whether a server was running and with which PID, so the health check
+// can tell the new server from the old one.
+type restartMark struct {
+	offset int64
+	wasUp  bool
+	pid    string
+}
+
+// restartMarks holds the mark of each target, by target_dir.
+var (
+	restartMarks   = map[string]restartMark{}
+	restartMarksMu sync.Mutex
+)
+
+// logRecordStart matches the first line of a log4j record in the default
+// SFS2X layout ("14 Oct 2026 | 13:45:31,123 | INFO  | ..."). Other lines are
+// continuations such as stack traces.
+var logRecordStart = regexp.MustCompile(`^\d{1,2} \w{3} \d{4} \|`)
+
+func serverLogPath(config *Config) string {
+	if target, ok := parseShellTarget(config.TargetDir); ok {
+		return target.path("SFS2X", "logs", "smartfox.log")
+	}
+	return filepath.Join(config.TargetDir, "SFS2X", "logs", "smartfox.log")
+}
+
+// markRestart records the server log and process before the server
+// restarts.
+func markRestart(config *Config) {
+	var offset int64
+
+	if target, ok := parseShellTarget(config.TargetDir); ok {
+		output, err := target.run(config, "wc -c < "+shellQuote(serverLogPath(config))+" 2>/dev/null")
+		if err == nil {
+			if size, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64); err == nil {
+				offset = size
+			}
+		}
+	} else if info, err := os.Stat(serverLogPath(config)); err == nil {
+		offset = info.Size()
+	}
+
+	mark := restartMark{offset: offset, wasUp: tcpReachable(net.JoinHostPort(healthHost(config), strconv.Itoa(healthPort(config))))}
+	if mark.wasUp {
+		if process, ok := findServerProcess(config); ok {
+			mark.pid = process.PID
+		}
+	}
+
+	restartMarksMu.Lock()
+	restartMarks[config.TargetDir] = mark
+	restartMarksMu.Unlock()
+}
+
+// markServerStopped notes that the server of the restart was seen stopped,
+// so whatever answers on its port next is the new one.
+func markServerStopped(config *Config) {
+	restartMarksMu.Lock()
+	defer restartMarksMu.Unlock()
+	if mark, ok := restartMarks[config.TargetDir]; ok {
+		mark.wasUp = false
+		restartMarks[config.TargetDir] = mark
+	}
+}
+
+func restartMarkFor(config *Config) (restartMark, bool) {
+	restartMarksMu.Lock()
+	defer restartMarksMu.Unlock()
+	mark, ok := restartMarks[config.TargetDir]
+	return mark, ok
+}
+
+// readBootLog returns the log written since offset, restarting from the
+// beginning if the log was rotated in the meantime.
+func readBootLog(config *Config, offset int64) (string, error) {
+	if target, ok := parseShellTarget(config.TargetDir); ok {
+		script := fmt.Sprintf("f=%s; [ -f \"$f\" ] || exit 0; if [ $(wc -c < \"$f\") -lt %d ]; then cat \"$f\"; else tail -c +%d \"$f\"; fi",
+			shellQuote(serverLogPath(config)), offset, offset+1)
+		output, err := target.run(config, script)
+		return string(output), err
+	}
+
+	data, err := os.ReadFile(serverLogPath(config))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if int64(len(data)) < offset {
+		return string(data), nil
+	}
+	return string(data[offset:]), nil
+}
+
+func isBootReady(line string) bool {
+	return strings.Contains(line, "SmartFoxServer 2X") && strings.Contains(line, "READY")
+}
+
+// isBootError flags ERROR records and exceptions raised while loading
+// extensions.
+func isBootError(line string) bool {
+	if strings.Contains(line, "| ERROR") {
+		return true
+	}
+	return strings.Contains(line, "Exception") && strings.Contains(line, "Extension")
+}
+
+// bootErrors collects each error record together with its stack trace.
+func bootErrors(lines []string) [][]string {
+	var errors [][]string
+	for i := 0; i < len(lines); i++ {
+		if !isBootError(lines[i]) {
+			continue
+		}
+		trace := []string{lines[i]}
+		for i+1 < len(lines) && !logRecordStart.MatchString(lines[i+1]) && len(trace) < maxTraceLines {
+			i++
+			trace = append(trace, lines[i])
+		}
+		errors = append(errors, trace)
+	}
+	return errors
+}
+
+// followBootLog reads smartfox.log from offset until the READY line appears
+// or the deadline passes, and fails if the boot logged any errors. It gives
+// up early when serverUp reports the new server running but nothing was
+// logged, e.g. when logging is configured elsewhere. ready reports the READY
+// line.
+func followBootLog(config *Config, offset int64, deadline time.Time, serverUp func() bool) (ready, ok bool) {
+	tprintf("📜 Following %s...\n", serverLogPath(config))
+
+	var lines []string
+	waitUntil(deadline, func() bool {
+		text, err := readBootLog(config, offset)
+		if err != nil {
+			return false
+		}
+		// Only complete lines are used, the last one may still be written
+		complete := text[:strings.LastIndex(text, "\n")+1]
+		lines = strings.Split(strings.TrimRight(complete, "\r\n"), "\n")
+		for _, line := range lines {
+			if isBootReady(line) {
+				ready = true
+			}
+		}
+		return ready || (len(complete) == 0 && serverUp())
+	})
+
+	if errors := bootErrors(lines); len(errors) > 0 {
+		tprintf("❌ The server logged %d error(s) while booting:\n", len(errors))
+		for _, trace := range errors {
+			fmt.Println()
+			for _, line := range trace {
+				fmt.Printf("   %s\n", strings.TrimRight(line, "\r"))
+			}
+		}
+		fmt.Println()
+		return ready, false
+	}
+
+	if !ready {
+		tprintln("⚠️ Warning: The READY line did not appear in smartfox.log")
+		return false, true
+	}
+
+	tprintln("✅ SmartFoxServer reported READY")
+	return true, true
+}
@@ -0,0 +1,399 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+func buildProject(config *Config) bool {
+	tprintln("Phase 2: Building Project")
+
+	if !resolveDependencies(config) {
+		return false
+	}
+	checkServerCompatibility(config)
+
+	if !compileProject(config) {
+		return false
+	}
+	if config.ClientConstants != "" && !updateClientConstants(config) {
+		return false
+	}
+	return obfuscateJars(config)
+}
+
+// compileProject compiles the sources with the build tool of the project, or
+// javac, and packages the JARs.
+func compileProject(config *Config) bool {
+	if *flagDryRun {
+		return planBuild(config)
+	}
+
+	if isMavenProject(config.SourceDir) {
+		return buildMaven(config)
+	}
+
+	if isGradleProject(config.SourceDir) {
+		return buildGradle(config)
+	}
+
+	mode, err := packageMode(config)
+	if err != nil {
+		fmt.Println(err)
+		return false
+	}
+
+	roots := sourceRoots(config)
+	tprintln("Cleaning old class files...")
+	for _, root := range roots {
+		cleanClassFiles(root)
+	}
+
+	tprintln("Compiling Java files...")
+	javaFiles := projectJavaFiles(config)
+	kotlinFiles := projectKotlinFiles(config)
+	if len(javaFiles) == 0 && len(kotlinFiles) == 0 {
+		tprintln("No Java files found")
+		return false
+	}
+
+	tprintf("Found %d Java files\n", len(javaFiles))
+	if len(kotlinFiles) > 0 {
+		tprintf("Found %d Kotlin files\n", len(kotlinFiles))
+	}
+
+	classpath := buildClasspath(config)
+	warnProcessorPath(config)
+
+	plan, err := planCompile(config, sourceBase(config), javaFiles, classpath)
+	if err != nil {
+		tprintf("Failed to read sources: %v\n", err)
+		return false
+	}
+
+	cacheDir := classCacheDir(config)
+	if plan.Full {
+		tprintln("Full rebuild")
+		// The manifest describes the cache, so it goes with it until the build succeeds
+		os.Remove(buildManifestPath(config))
+		os.RemoveAll(cacheDir)
+	} else {
+		tprintf("Incremental build: %d changed or dependent, %d unchanged, %d removed\n",
+			len(plan.Compile), plan.Unchanged, len(plan.Removed))
+		if err := removeStaleClasses(config, plan); err != nil {
+			tprintf("Failed to update build manifest: %v\n", err)
+			return false
+		}
+	}
+
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		tprintf("Failed to create class cache: %v\n", err)
+		return false
+	}
+
+	env := buildEnvHash(config, classpath, plan.kotlin)
+	if restored := restoreCachedClasses(config, &plan, env); restored > 0 {
+		tprintf("Restored %d sources from the build cache, %d left to compile\n", restored, len(plan.Compile))
+	}
+
+	// Kotlin is recompiled whenever anything changed, since it may use the
+	// Java classes; a change to Kotlin sources makes the plan full
+	kotlinDir := kotlinClassesDir(config)
+	if len(kotlinFiles) > 0 && (plan.Full || len(plan.Compile) > 0 || len(plan.Removed) > 0 || !fileExists(kotlinDir)) {
+		if !compileKotlin(config, kotlinFiles, javaFiles, classpath) {
+			return false
+		}
+	} else if len(kotlinFiles) == 0 {
+		os.RemoveAll(kotlinDir)
+	}
+
+	if len(plan.Compile) > 0 {
+		// The classes compiled from the sources come before any JAR, so
+		// recompiled sources resolve against the current versions
+		absCacheDir, _ := filepath.Abs(cacheDir)
+		compileClasspath := absCacheDir
+		if len(kotlinFiles) > 0 {
+			compileClasspath += string(os.PathListSeparator) + absPath(kotlinDir)
+		}
+		compileClasspath += string(os.PathListSeparator) + classpath
+
+		javacPath := filepath.Join(config.JavaPath, "javac")
+		if runtime.GOOS == "windows" {
+			javacPath += ".exe"
+		}
+
+		// Generated sources go to their own folder so they never land in src
+		generatedDir := generatedSourcesDir(config)
+		if err := os.MkdirAll(generatedDir, 0755); err != nil {
+			tprintf("Failed to create %s: %v\n", generatedDir, err)
+			return false
+		}
+
+		// Previously compiled classes stay on the classpath so unchanged
+		// sources do not need to be passed to javac
+		args := []string{"-cp", compileClasspath, "-d", absCacheDir, "-s", absPath(generatedDir)}
+		args = append(args, javacOptions(config)...)
+
+		// Modules are compiled one after the other into the shared class
+		// cache, which is on the classpath of the modules after them
+		started := time.Now()
+		stopWatching := watchClassOutput(cacheDir, started, len(plan.Compile))
+		for _, group := range groupByRoot(config, plan.Compile) {
+			if len(roots) > 1 {
+				verbosef("   Compiling %d files of %s\n", len(group.files), filepath.Dir(group.dir))
+			}
+			cmd := newCommand(javacPath, append(slices.Clip(args), group.files...)...)
+			cmd.Dir = group.dir
+
+			output, err := cmd.CombinedOutput()
+			if err != nil {
+				stopWatching()
+				tprintf("Compilation failed: %s\n", string(output))
+				return false
+			}
+		}
+		stopWatching()
+		storeCompiledClasses(config, plan, env, started)
+	}
+
+	manifest := buildManifest{Classpath: classpath, Options: javacOptions(config), Kotlin: plan.kotlin, Sources: plan.sources}
+	if err := saveBuildManifest(config, manifest); err != nil {
+		tprintf("Warning: Could not save build manifest: %v\n", err)
+	}
+
+	if mode == packageJar {
+		tprintln("Compilation successful")
+		return packageClasses(config, roots)
+	}
+
+	srcDir := roots[0]
+
+	// The JARs are built from src, so place the compiled classes next to the sources
+	if err := copyDir(cacheDir, srcDir); err != nil {
+		tprintf("Failed to copy compiled classes: %v\n", err)
+		return false
+	}
+	if len(kotlinFiles) > 0 {
+		if err := copyDir(kotlinDir, srcDir); err != nil {
+			tprintf("Failed to copy compiled Kotlin classes: %v\n", err)
+			return false
+		}
+	}
+
+	tprintln("Compilation successful")
+
+	// Create SpookyCommon.jar from just the common folder
+	if config.CommonFile != "" && config.CommonFolder != "" {
+		tprintf("Creating %s...\n", config.CommonFile)
+		commonJarFile := filepath.Join(config.SourceDir, config.CommonFile)
+		commonDir := filepath.Join(srcDir, config.CommonFolder)
+
+		if !createJar(config, commonJarFile, commonDir, config.CommonFile) {
+			return false
+		}
+	}
+
+	// Create the extension JARs from the whole src folder or their packages
+	if !createExtensionJars(config, srcDir, false) {
+		return false
+	}
+	fmt.Println()
+
+	return true
+}
+
+// watchClassOutput shows javac's progress from the top-level classes it has
+// written so far, since javac reports nothing until it finishes.
+func watchClassOutput(dir string, started time.Time, sources int) func() {
+	p := startProgress("🔨 Compiling", int64(sources), countDetail("files"))
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(progressDelay)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+			written := 0
+			filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+				if err == nil && !info.IsDir() && strings.HasSuffix(path, ".class") &&
+					!strings.Contains(info.Name(), "$") && !info.ModTime().Before(started.Truncate(time.Second)) {
+					written++
+				}
+				return nil
+			})
+			p.set(int64(written))
+		}
+	}()
+	return func() {
+		close(stop)
+		<-done
+		p.finish()
+	}
+}
+
+// javacOptions returns the compiler options from the config, e.g.
+// --release 11 -encoding UTF-8 -processorpath lombok.jar -parameters.
+func javacOptions(config *Config) []string {
+	var options []string
+	if config.JavaRelease != "" {
+		options = append(options, "--release", config.JavaRelease)
+	}
+	if config.SourceEncoding != "" {
+		options = append(options, "-encoding", config.SourceEncoding)
+	}
+	options = append(options, processorOptions(config)...)
+	return append(options, config.JavacFlags...)
+}
+
+func cleanClassFiles(srcDir string) {
+	filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if strings.HasSuffix(strings.ToLower(info.Name()), ".class") {
+			os.Remove(path)
+		}
+		return nil
+	})
+}
+
+func findJavaFiles(config *Config, srcDir string) []string {
+	var javaFiles []string
+	ignore := sourceIgnore(config)
+	filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if ignore.skip(path, info.IsDir()) {
+			return skipWalk(info)
+		}
+		if strings.HasSuffix(strings.ToLower(info.Name()), ".java") {
+			javaFiles = append(javaFiles, path)
+		}
+		return nil
+	})
+	return javaFiles
+}
+
+func serverLibDir(config *Config) string {
+	if remote, ok := parseRemoteTarget(config.TargetDir); ok {
+		return remoteLibDir(config, remote)
+	}
+	if d, ok := parseDockerTarget(config.TargetDir); ok {
+		return dockerLibDir(config, d)
+	}
+	return filepath.Join(config.TargetDir, "SFS2X", "lib")
+}
+
+// classpathEntries lists the JAR locations scanned for compile dependencies,
+// in classpath order: SFS2X/lib, the shared and per-extension __lib__
+// folders, the project's own lib folder, downloaded dependencies, lib_jars and
+// any configured extra_libs. buildClasspath leaves out the JARs the project
+// deploys itself.
+func classpathEntries(config *Config) []string {
+	entries := []string{serverLibDir(config)}
+
+	if isLocalTarget(config) {
+		entries = append(entries,
+			libDir(config),
+			filepath.Join(extensionDir(config), "__lib__"),
+		)
+	}
+
+	entries = append(entries, filepath.Join(config.SourceDir, "lib"))
+	entries = append(entries, dependencyJars(config)...)
+
+	for _, lib := range config.LibJars {
+		if !filepath.IsAbs(lib) {
+			lib = filepath.Join(config.SourceDir, lib)
+		}
+		entries = append(entries, lib)
+	}
+
+	for _, lib := range config.ExtraLibs {
+		if !filepath.IsAbs(lib) {
+			lib = filepath.Join(config.SourceDir, lib)
+		}
+		entries = append(entries, lib)
+	}
+
+	return entries
+}
+
+// expandClasspathEntry resolves a directory to its JARs, a glob to its
+// matches, and leaves a plain file as is.
+func expandClasspathEntry(entry string) []string {
+	if strings.ContainsAny(entry, "*?[") {
+		matches, _ := filepath.Glob(entry)
+		return matches
+	}
+
+	info, err := os.Stat(entry)
+	if err != nil {
+		return nil
+	}
+	if info.IsDir() {
+		matches, _ := filepath.Glob(filepath.Join(entry, "*.jar"))
+		return matches
+	}
+	return []string{entry}
+}
+
+func buildClasspath(config *Config) string {
+	var classpathParts []string
+	seen := map[string]bool{}
+
+	// The CommonFile and extension JARs of the last deploy are older builds
+	// of the classes being compiled; on the classpath they would shadow them
+	own := map[string]bool{}
+	if isLocalTarget(config) {
+		if config.CommonFile != "" {
+			own[absPath(filepath.Join(libDir(config), config.CommonFile))] = true
+		}
+		for _, ext := range extensionConfigs(config) {
+			own[absPath(filepath.Join(extensionDir(&ext), ext.ExtensionFile))] = true
+		}
+	}
+
+	for _, entry := range classpathEntries(config) {
+		for _, jarFile := range expandClasspathEntry(entry) {
+			if abs, err := filepath.Abs(jarFile); err == nil {
+				jarFile = abs
+			}
+			if own[jarFile] {
+				verbosef("   Leaving %s off the classpath, it is built from these sources\n", jarFile)
+				continue
+			}
+			if !seen[jarFile] {
+				seen[jarFile] = true
+				classpathParts = append(classpathParts, jarFile)
+			}
+		}
+	}
+
+	for _, lib := range config.ExtraLibs {
+		if !filepath.IsAbs(lib) {
+			lib = filepath.Join(config.SourceDir, lib)
+		}
+		if len(expandClasspathEntry(lib)) == 0 {
+			tprintf("Warning: extra_libs entry matched no JAR files: %s\n", lib)
+		}
+	}
+
+	if len(classpathParts) == 0 {
+		tprintf("Warning: No JAR files found in %s\n", serverLibDir(config))
+		return "."
+	}
+
+	return strings.Join(classpathParts, string(os.PathListSeparator))
+}
@@ -0,0 +1,284 @@
+package sfdeploy
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// buildCacheDir holds the compiled classes of every source ever compiled,
+// keyed by content, so switching back to an earlier branch restores its
+// classes instead of recompiling them. It is shared by all projects.
+func buildCacheDir() string {
+	if dir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(dir, "sfdeploy", "classes")
+	}
+	return filepath.Join(stateDir, "class-cache")
+}
+
+// buildEnvHash fingerprints what a source is compiled against besides its
+// own content: the compiler options, the JAR contents on the classpath and
+// the Kotlin sources.
+func buildEnvHash(config *Config, classpath, kotlin string) string {
+	sum := sha256.New()
+	fmt.Fprintf(sum, "%s\x00%s\x00", strings.Join(javacOptions(config), "\x00"), kotlin)
+	for _, entry := range filepath.SplitList(classpath) {
+		hash := "missing"
+		if info, err := os.Stat(entry); err == nil && info.IsDir() {
+			hash = "dir"
+		} else if h, err := hashFile(entry); err == nil {
+			hash = h
+		}
+		fmt.Fprintf(sum, "%s %s\n", entry, hash)
+	}
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+func buildCacheKey(key string, record sourceRecord, env string) string {
+	sum := sha256.Sum256([]byte(key + "\x00" + record.Hash + "\x00" + env))
+	return hex.EncodeToString(sum[:])
+}
+
+func buildCacheEntry(cacheKey string) string {
+	return filepath.Join(buildCacheDir(), cacheKey[:2], cacheKey)
+}
+
+func packageDir(cacheDir string, record sourceRecord) string {
+	return filepath.Join(cacheDir, filepath.FromSlash(strings.ReplaceAll(record.Package, ".", "/")))
+}
+
+// restoreCachedClasses copies the cached classes of changed sources into the
+// class cache and drops them from the plan. Sources recompiled only because
+// they mention a changed class are left to javac, since their output depends
+// on the new version of that class. --rebuild bypasses the cache.
+func restoreCachedClasses(config *Config, plan *compilePlan, env string) int {
+	if *flagRebuild {
+		return 0
+	}
+	cacheDir := classCacheDir(config)
+
+	var compile, compileKeys []string
+	restored := 0
+	for i, key := range plan.compileKeys {
+		record := plan.sources[key]
+		old, known := plan.previous[key]
+		if !plan.Full && known && old.Hash == record.Hash {
+			compile = append(compile, plan.Compile[i])
+			compileKeys = append(compileKeys, key)
+			continue
+		}
+
+		entry := buildCacheEntry(buildCacheKey(key, record, env))
+		if !fileExists(entry) || copyDir(entry, packageDir(cacheDir, record)) != nil {
+			compile = append(compile, plan.Compile[i])
+			compileKeys = append(compileKeys, key)
+			continue
+		}
+		debugf("🐛 restored %s from %s\n", key, entry)
+		restored++
+	}
+
+	plan.Compile, plan.compileKeys = compile, compileKeys
+	return restored
+}
+
+// storeCompiledClasses adds the classes javac wrote since started to the
+// build cache. Each class is attributed to its source through the
+// SourceFile attribute, which also covers inner and secondary classes.
+func storeCompiledClasses(config *Config, plan compilePlan, env string, started time.Time) {
+	cacheDir := classCacheDir(config)
+	keys := map[string]string{}
+	for _, key := range plan.compileKeys {
+		record := plan.sources[key]
+		keys[filepath.Join(packageDir(cacheDir, record), filepath.Base(key))] = key
+	}
+
+	outputs := map[string][]string{}
+	filepath.Walk(cacheDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(path, ".class") || info.ModTime().Before(started.Truncate(2*time.Second)) {
+			return nil
+		}
+		source, err := classSourceFile(path)
+		if err != nil {
+			return nil
+		}
+		if key, ok := keys[filepath.Join(filepath.Dir(path), source)]; ok {
+			outputs[key] = append(outputs[key], path)
+		}
+		return nil
+	})
+
+	for key, files := range outputs {
+		entry := buildCacheEntry(buildCacheKey(key, plan.sources[key], env))
+		if fileExists(entry) {
+			continue
+		}
+		if err := writeCacheEntry(entry, files); err != nil {
+			debugf("🐛 could not cache %s: %v\n", key, err)
+		}
+	}
+}
+
+// writeCacheEntry fills a temporary folder first so an interrupted build
+// never leaves a partial entry behind.
+func writeCacheEntry(entry string, files []string) error {
+	tmp := entry + ".tmp"
+	os.RemoveAll(tmp)
+	if err := os.MkdirAll(tmp, 0755); err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := copyFile(file, filepath.Join(tmp, filepath.Base(file))); err != nil {
+			os.RemoveAll(tmp)
+			return err
+		}
+	}
+	return os.Rename(tmp, entry)
+}
+
+// classSourceFile reads the SourceFile attribute of a class file, e.g.
+// "Foo.java" for both Foo.class and Foo$Inner.class.
+func classSourceFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	r := &classReader{data: data}
+	if r.u4() != 0xCAFEBABE {
+		return "", errors.New("not a class file")
+	}
+	r.skip(4) // minor and major version
+
+	count := int(r.u2())
+	utf8 := make(map[int]string)
+	for i := 1; i < count && r.err == nil; i++ {
+		switch tag := r.u1(); tag {
+		case 1: // Utf8
+			utf8[i] = string(r.bytes(int(r.u2())))
+		case 3, 4, 9, 10, 11, 12, 17, 18: // Integer, Float, refs, NameAndType, dynamic
+			r.skip(4)
+		case 5, 6: // Long and Double take two slots
+			r.skip(8)
+			i++
+		case 7, 8, 16, 19, 20: // Class, String, MethodType, Module, Package
+			r.skip(2)
+		case 15: // MethodHandle
+			r.skip(3)
+		default:
+			return "", fmt.Errorf("unknown constant pool tag %d", tag)
+		}
+	}
+
+	r.skip(6) // access flags, this and super class
+	r.skip(2 * int(r.u2()))
+	for members := 0; members < 2; members++ { // fields, then methods
+		for n := int(r.u2()); n > 0 && r.err == nil; n-- {
+			r.skip(6)
+			r.skipAttributes()
+		}
+	}
+	for n := int(r.u2()); n > 0 && r.err == nil; n-- {
+		name := utf8[int(r.u2())]
+		length := int(r.u4())
+		if name == "SourceFile" {
+			if source, ok := utf8[int(r.u2())]; ok && r.err == nil {
+				return source, nil
+			}
+			break
+		}
+		r.skip(length)
+	}
+	if r.err != nil {
+		return "", r.err
+	}
+	return "", errors.New("no SourceFile attribute")
+}
+
+// classReader reads big-endian class file data, remembering the first
+// out-of-bounds read instead of panicking.
+type classReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *classReader) bytes(n int) []byte {
+	if r.err != nil || n < 0 || r.pos+n > len(r.data) {
+		r.err = errors.New("truncated class file")
+		return nil
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *classReader) skip(n int) { r.bytes(n) }
+
+func (r *classReader) u1() byte {
+	if b := r.bytes(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *classReader) u2() uint16 {
+	if b := r.bytes(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *classReader) u4() uint32 {
+	if b := r.bytes(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *classReader) skipAttributes() {
+	for n := int(r.u2()); n > 0 && r.err == nil; n-- {
+		r.skip(2)
+		r.skip(int(r.u4()))
+	}
+}
+
+// cacheCommand implements "cache" and "cache clean".
+func cacheCommand(config *Config) bool {
+	dir := buildCacheDir()
+	switch commandArg(0) {
+	case "", "info":
+		files, size := dirUsage(dir)
+		tprintf("📦 Build cache: %s\n", dir)
+		tprintf("   %d class files, %.1f MB\n", files, float64(size)/(1<<20))
+		return true
+	case "clean":
+		files, size := dirUsage(dir)
+		if err := os.RemoveAll(dir); err != nil {
+			tprintf("❌ Failed to remove %s: %v\n", dir, err)
+			return false
+		}
+		tprintf("🧹 Removed %d cached class files (%.1f MB) from %s\n", files, float64(size)/(1<<20), dir)
+		return true
+	default:
+		tprintf("Unknown cache command: %s (expected info or clean)\n", commandArg(0))
+		return false
+	}
+}
+
+func dirUsage(dir string) (int, int64) {
+	files, size := 0, int64(0)
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			files++
+			size += info.Size()
+		}
+		return nil
+	})
+	return files, size
+}
@@ -0,0 +1,258 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const defaultGradleTask = "jar"
+
+func isMavenProject(sourceDir string) bool {
+	return fileExists(filepath.Join(sourceDir, "pom.xml"))
+}
+
+func isGradleProject(sourceDir string) bool {
+	for _, name := range []string{"build.gradle", "build.gradle.kts", "gradlew"} {
+		if fileExists(filepath.Join(sourceDir, name)) {
+			return true
+		}
+	}
+	return false
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// wrapperCommand returns the absolute path of a project build wrapper
+// (mvnw, gradlew) if present, otherwise the global tool name.
+func wrapperCommand(sourceDir, wrapper, global string) string {
+	if runtime.GOOS == "windows" {
+		wrapper += map[string]string{"mvnw": ".cmd", "gradlew": ".bat"}[wrapper]
+	}
+	if path, err := filepath.Abs(filepath.Join(sourceDir, wrapper)); err == nil && fileExists(path) {
+		return path
+	}
+	return global
+}
+
+// mavenCommand prefers the project's Maven wrapper over a global mvn.
+func mavenCommand(sourceDir string) string {
+	return wrapperCommand(sourceDir, "mvnw", "mvn")
+}
+
+// gradleCommand prefers the project's Gradle wrapper over a global gradle.
+func gradleCommand(sourceDir string) string {
+	return wrapperCommand(sourceDir, "gradlew", "gradle")
+}
+
+func gradleArgs(config *Config) []string {
+	task := config.GradleTask
+	if task == "" {
+		task = defaultGradleTask
+	}
+	return []string{"--console=plain", task}
+}
+
+// buildOutputDir returns the configured build_output (relative to the
+// source directory) or the tool's default output directory.
+func buildOutputDir(config *Config, defaultDir string) string {
+	if config.BuildOutput == "" {
+		return filepath.Join(config.SourceDir, defaultDir)
+	}
+	if filepath.IsAbs(config.BuildOutput) {
+		return config.BuildOutput
+	}
+	return filepath.Join(config.SourceDir, config.BuildOutput)
+}
+
+func mavenArgs() []string {
+	if *flagSkipTests {
+		return []string{"-B", "-DskipTests", "package"}
+	}
+	return []string{"-B", "package"}
+}
+
+// javaHomeEnv points build tools at the JDK selected in setupJava.
+func javaHomeEnv(config *Config) []string {
+	return append(os.Environ(), "JAVA_HOME="+filepath.Dir(config.JavaPath))
+}
+
+func buildMaven(config *Config) bool {
+	mvn := mavenCommand(config.SourceDir)
+	tprintf("Maven project detected, running %s %s...\n", filepath.Base(mvn), strings.Join(mavenArgs(), " "))
+
+	cmd := newCommand(mvn, mavenArgs()...)
+	cmd.Dir = config.SourceDir
+	cmd.Env = javaHomeEnv(config)
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		tprintf("Maven build failed: %v\n", err)
+		fmt.Printf("%s\n", output)
+		return false
+	}
+
+	tprintln("Maven build successful")
+
+	return packageBuildOutput(config, buildOutputDir(config, "target"), filepath.Join(config.SourceDir, "target", "classes"))
+}
+
+func buildGradle(config *Config) bool {
+	gradle := gradleCommand(config.SourceDir)
+	tprintf("Gradle project detected, running %s %s...\n", filepath.Base(gradle), strings.Join(gradleArgs(config), " "))
+
+	cmd := newCommand(gradle, gradleArgs(config)...)
+	cmd.Dir = config.SourceDir
+	cmd.Env = javaHomeEnv(config)
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		tprintf("Gradle build failed: %v\n", err)
+		fmt.Printf("%s\n", output)
+		return false
+	}
+
+	tprintln("Gradle build successful")
+
+	return packageBuildOutput(config, buildOutputDir(config, filepath.Join("build", "libs")),
+		filepath.Join(config.SourceDir, "build", "classes", "java", "main"))
+}
+
+// packageBuildOutput copies the JAR produced by a build tool to the location
+// the deploy phase expects, and creates the common JAR from the compiled
+// classes when configured. outputDir may also be a JAR file, or a classes
+// directory which is then packaged into the extension JAR. With several
+// extensions, their JARs are built from the compiled classes instead.
+func packageBuildOutput(config *Config, outputDir, classesDir string) bool {
+	extensionJarFile := filepath.Join(config.SourceDir, config.ExtensionFile)
+
+	builtJar := ""
+	info, err := os.Stat(outputDir)
+	switch {
+	case len(config.Extensions) > 1:
+		// Each extension takes its packages from the compiled classes
+	case err != nil:
+		tprintf("Build output not found: %s\n", outputDir)
+		return false
+	case !info.IsDir():
+		builtJar = outputDir
+	default:
+		builtJar = findBuiltJar(outputDir)
+	}
+
+	if len(config.Extensions) > 1 {
+		if !hasClassFiles(classesDir) {
+			tprintf("No class files found in %s\n", classesDir)
+			return false
+		}
+		if !createExtensionJars(config, classesDir, false) {
+			return false
+		}
+	} else if builtJar != "" {
+		if err := copyFile(builtJar, extensionJarFile); err != nil {
+			tprintf("Failed to copy %s: %v\n", filepath.Base(builtJar), err)
+			return false
+		}
+		tprintf("%s created from %s\n", config.ExtensionFile, filepath.Base(builtJar))
+		if !signJar(config, extensionJarFile, config.ExtensionFile) {
+			return false
+		}
+	} else if hasClassFiles(outputDir) {
+		if !createExtensionJars(config, outputDir, false) {
+			return false
+		}
+		classesDir = outputDir
+	} else {
+		tprintf("No JAR or class files found in %s\n", outputDir)
+		return false
+	}
+
+	if config.CommonFile != "" && config.CommonFolder != "" {
+		commonDir := filepath.Join(classesDir, config.CommonFolder)
+		if _, err := os.Stat(commonDir); err != nil {
+			tprintf("Warning: %s not found, skipping %s\n", commonDir, config.CommonFile)
+		} else if !createJar(config, filepath.Join(config.SourceDir, config.CommonFile), commonDir, config.CommonFile) {
+			return false
+		}
+	}
+
+	fmt.Println()
+	return true
+}
+
+// createJar packages the contents of dir into jarFile with the JDK jar tool.
+func createJar(config *Config, jarFile, dir, name string) bool {
+	return runJar(config, dir, name, "cf", absPath(jarFile), ".") && signJar(config, jarFile, name)
+}
+
+func createJarWithManifest(config *Config, jarFile, dir, manifest, name string) bool {
+	return runJar(config, dir, name, "cfm", absPath(jarFile), absPath(manifest), ".") && signJar(config, jarFile, name)
+}
+
+// absPath is used for paths handed to tools that run inside another directory.
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+func runJar(config *Config, dir, name string, args ...string) bool {
+	jarPath := filepath.Join(config.JavaPath, "jar")
+	if runtime.GOOS == "windows" {
+		jarPath += ".exe"
+	}
+
+	cmd := newCommand(jarPath, args...)
+	cmd.Dir = dir
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		tprintf("JAR creation failed for %s: %s\n", name, string(output))
+		return false
+	}
+	tprintf("%s created successfully\n", name)
+	return true
+}
+
+func hasClassFiles(dir string) bool {
+	found := false
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && strings.HasSuffix(strings.ToLower(info.Name()), ".class") {
+			found = true
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return found
+}
+
+// findBuiltJar picks the newest JAR in dir, ignoring sources, javadoc, test
+// and pre-shade (original-*) artifacts.
+func findBuiltJar(dir string) string {
+	var newest string
+	var newestTime int64
+
+	jars, _ := filepath.Glob(filepath.Join(dir, "*.jar"))
+	for _, jar := range jars {
+		name := strings.ToLower(filepath.Base(jar))
+		if strings.HasPrefix(name, "original-") ||
+			strings.HasSuffix(name, "-sources.jar") ||
+			strings.HasSuffix(name, "-javadoc.jar") ||
+			strings.HasSuffix(name, "-tests.jar") {
+			continue
+		}
+
+		info, err := os.Stat(jar)
+		if err != nil {
+			continue
+		}
+		if newest == "" || info.ModTime().UnixNano() > newestTime {
+			newest, newestTime = jar, info.ModTime().UnixNano()
+		}
+	}
+
+	return newest
+}
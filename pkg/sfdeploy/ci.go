@@ -0,0 +1,221 @@
+package sfdeploy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// applyCIMode makes --ci imply the flags an unattended run needs.
+func applyCIMode() {
+	if *flagCI {
+		*flagNoPrompt = true
+		*flagNoPause = true
+	}
+}
+
+// groupWriter folds the console output of each phase into a collapsible
+// section using the GitHub Actions ::group:: workflow commands.
+type groupWriter struct {
+	out  io.Writer
+	line []byte
+	open bool
+}
+
+func (g *groupWriter) Write(p []byte) (int, error) {
+	g.line = append(g.line, p...)
+	for {
+		i := bytes.IndexByte(g.line, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		g.writeLine(string(g.line[:i+1]))
+		g.line = g.line[i+1:]
+	}
+}
+
+func (g *groupWriter) writeLine(line string) {
+	if m := phaseLine.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+		if g.open {
+			fmt.Fprintln(g.out, "::endgroup::")
+		}
+		fmt.Fprintf(g.out, "::group::%s", line)
+		g.open = true
+		return
+	}
+	io.WriteString(g.out, line)
+}
+
+func (g *groupWriter) Close() error {
+	if len(g.line) > 0 {
+		g.writeLine(string(g.line) + "\n")
+		g.line = nil
+	}
+	if g.open {
+		fmt.Fprintln(g.out, "::endgroup::")
+		g.open = false
+	}
+	return nil
+}
+
+// phaseReport is one phase in the --report summary. Phases run once per
+// cluster target appear once per target.
+type phaseReport struct {
+	Name     string  `json:"name"`
+	Duration float64 `json:"duration_seconds"`
+	Files    int     `json:"files_copied,omitempty"`
+	Bytes    int64   `json:"bytes_copied,omitempty"`
+	started  time.Time
+}
+
+// runReport is the JSON summary written to --report.
+type runReport struct {
+	Command     string        `json:"command"`
+	Result      string        `json:"result"`
+	ExitCode    int           `json:"exit_code"`
+	Started     string        `json:"started"`
+	Duration    float64       `json:"duration_seconds"`
+	Extension   string        `json:"extension,omitempty"`
+	Target      string        `json:"target,omitempty"`
+	Commit      string        `json:"commit,omitempty"`
+	FilesCopied int           `json:"files_copied"`
+	Phases      []phaseReport `json:"phases"`
+}
+
+// maxRecentLines is how much output the collector keeps for the log
+// excerpt of email notifications.
+const maxRecentLines = 500
+
+// loggedLine is one line of output and when it was printed.
+type loggedLine struct {
+	at   time.Time
+	text string
+}
+
+// reportCollector follows the full output to time each phase, count the
+// files it copied and keep the last lines.
+type reportCollector struct {
+	mu     sync.Mutex
+	line   []byte
+	phases []phaseReport
+	recent []loggedLine
+}
+
+var runReportCollector = &reportCollector{}
+
+func (c *reportCollector) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.line = append(c.line, p...)
+	for {
+		i := bytes.IndexByte(c.line, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		text := strings.TrimRightFunc(string(c.line[:i]), unicode.IsSpace)
+		c.observe(strings.TrimLeftFunc(strings.TrimSpace(text), isDecoration))
+		if text != "" {
+			c.recent = append(c.recent, loggedLine{at: time.Now(), text: text})
+			if len(c.recent) > maxRecentLines {
+				c.recent = c.recent[len(c.recent)-maxRecentLines:]
+			}
+		}
+		c.line = c.line[i+1:]
+	}
+}
+
+func (c *reportCollector) observe(message string) {
+	now := time.Now()
+	if m := phaseLine.FindStringSubmatch(message); m != nil {
+		c.finish(now)
+		c.phases = append(c.phases, phaseReport{Name: m[1], started: now})
+		return
+	}
+	if m := copySummary.FindStringSubmatch(message); m != nil && len(c.phases) > 0 {
+		n, _ := strconv.Atoi(m[1])
+		c.phases[len(c.phases)-1].Files += n
+		c.phases[len(c.phases)-1].Bytes += parseSize(m[2], m[3])
+	}
+}
+
+func (c *reportCollector) finish(now time.Time) {
+	if len(c.phases) > 0 {
+		last := &c.phases[len(c.phases)-1]
+		if last.Duration == 0 {
+			last.Duration = now.Sub(last.started).Round(time.Millisecond).Seconds()
+		}
+	}
+}
+
+// since returns the phases and the output lines of the run that started at
+// started, so a daemon running one job after another reports only the last.
+func (c *reportCollector) since(started time.Time) ([]phaseReport, []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.finish(time.Now())
+	var phases []phaseReport
+	for _, p := range c.phases {
+		if !p.started.Before(started) {
+			phases = append(phases, p)
+		}
+	}
+	var lines []string
+	for _, line := range c.recent {
+		if !line.at.Before(started) {
+			lines = append(lines, line.text)
+		}
+	}
+	return phases, lines
+}
+
+// writeReport writes the --report summary of a finished run.
+func writeReport(config *Config, name string, code int, started time.Time) {
+	if *flagReport == "" {
+		return
+	}
+
+	c := runReportCollector
+	c.mu.Lock()
+	c.finish(time.Now())
+	phases := append([]phaseReport{}, c.phases...)
+	c.mu.Unlock()
+
+	report := runReport{
+		Command:   name,
+		Result:    "success",
+		ExitCode:  code,
+		Started:   started.Format(time.RFC3339),
+		Duration:  time.Since(started).Round(time.Millisecond).Seconds(),
+		Extension: config.ExtensionFolder,
+		Target:    notifyTarget(config),
+		Phases:    phases,
+	}
+	if code != exitOK {
+		report.Result = "failure"
+	}
+	if config.SourceDir != "" {
+		report.Commit = gitCommit(config)
+	}
+	for _, p := range phases {
+		report.FilesCopied += p.Files
+	}
+	if report.Phases == nil {
+		report.Phases = []phaseReport{}
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err == nil {
+		err = os.WriteFile(*flagReport, append(data, '\n'), 0644)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️ Warning: Could not write report %s: %v\n", *flagReport, err)
+	}
+}
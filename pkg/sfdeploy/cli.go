@@ -0,0 +1,265 @@
+package sfdeploy
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+type phase func(config *Config) bool
+
+type command struct {
+	name        string
+	description string
+	phases      []phase
+}
+
+var commands = []command{
+	{"all", "Build, test, deploy, restart and clean up (default)",
+		[]phase{setupDirectories, setupJava, buildProject, customPhases("build"), runTests, customPhases("test"),
+			lockedTarget(deployProject, customPhases("deploy"), restartServer, checkServerHealth, smokeTest, customPhases("restart"), postDeployHooks), cleanupProject}},
+	{"build", "Compile sources and create the extension JARs",
+		[]phase{setupDirectories, setupJava, buildProject, customPhases("build")}},
+	{"test", "Build the project and run its unit tests",
+		[]phase{setupDirectories, setupJava, buildProject, customPhases("build"), runTests, customPhases("test")}},
+	{"deploy", "Copy built JARs and JSON files to the server",
+		[]phase{setupDirectories, lockedTarget(deployProject, customPhases("deploy"), postDeployHooks)}},
+	{"diff", "Build, then list the files and classes a deploy would add, change or remove",
+		[]phase{setupDirectories, setupJava, buildProject, perTarget(diffProject), cleanupProject}},
+	{"restart", "Restart SmartFox Server",
+		[]phase{setupDirectories, lockedTarget(restartServer, checkServerHealth, smokeTest, customPhases("restart"))}},
+	{"status", "Show whether the server is running, which ports answer and what is deployed",
+		[]phase{setupDirectories, perTarget(statusCommand)}},
+	{"clean", "Remove build artifacts from the source directory",
+		[]phase{setupDirectories, cleanupProject}},
+	{"rollback", "Restore the previous deployment and restart the server",
+		[]phase{setupDirectories, lockedTarget(eachExtension(rollbackDeployment), restartServer, checkServerHealth, smokeTest)}},
+	{"history", "List deploy history (history list) or restore an entry (history restore <n>)",
+		[]phase{setupDirectories, perTarget(eachExtension(historyCommand))}},
+	{"backup", "List zip backups (backup list) or restore one (backup restore <n|file>)",
+		[]phase{setupDirectories, perTarget(eachExtension(backupCommand))}},
+	{"init", "Create a new extension project (init [directory] [main class])",
+		[]phase{initCommand}},
+	{"generate", "Create a request handler and register it in the extension (generate handler <Name> [command]), or write client_constants (generate constants)",
+		[]phase{setupDirectories, generateCommand}},
+	{"config", "Check the config (config validate), change a key (config set <key> <value>), re-run setup (config edit) or share it (config export, config import)",
+		[]phase{configCommand}},
+	{"credentials", "Store a secret in the OS keychain (credentials set <name>), or list (credentials list) or delete them",
+		[]phase{credentialsCommand}},
+	{"admin", "Install the server-side bridge for graceful restarts and reloads (admin install)",
+		[]phase{setupDirectories, setupJava, adminCommand}},
+	{"cache", "Show the compiled class cache (cache info) or empty it (cache clean)",
+		[]phase{cacheCommand}},
+	{"stats", "Show the metrics of recent runs (stats [n]) and whether build or restart times are degrading",
+		[]phase{statsCommand}},
+	{"tui", "Full-screen UI to deploy, roll back and toggle watch mode",
+		[]phase{setupDirectories, setupJava, tuiCommand}},
+	{"watch", "Rebuild and redeploy whenever a .java file changes",
+		[]phase{setupDirectories, setupJava, watchProject}},
+	{"listen", "Run a webhook server that pulls and redeploys on every push to a branch",
+		[]phase{setupDirectories, setupJava, listenForPushes}},
+	{"serve", "Run a local REST API to trigger deploys and rollbacks and query status and history",
+		[]phase{setupDirectories, setupJava, serveAPI}},
+}
+
+// noRestartPhases is the all pipeline of --skip-restart.
+var noRestartPhases = []phase{setupDirectories, setupJava, buildProject, customPhases("build"), runTests, customPhases("test"),
+	lockedTarget(deployProject, customPhases("deploy"), postDeployHooks), cleanupProject}
+
+// pipelinePhases applies --hot, --skip-restart and --restart-only to the
+// phases of cmd. It returns nil when none of them is set.
+func pipelinePhases(cmd command) ([]phase, error) {
+	set := 0
+	for _, on := range []bool{*flagHot, *flagSkipRestart, *flagRestartOnly} {
+		if on {
+			set++
+		}
+	}
+	switch {
+	case set == 0:
+		return nil, nil
+	case set > 1:
+		return nil, fmt.Errorf("--hot, --skip-restart and --restart-only cannot be combined")
+	case *flagAll:
+		return nil, fmt.Errorf("--hot, --skip-restart and --restart-only work without --all")
+	}
+
+	switch {
+	case *flagHot:
+		if phases, ok := hotPhases(cmd.name); ok {
+			return phases, nil
+		}
+		return nil, fmt.Errorf("--hot works with the all and watch commands")
+	case *flagSkipRestart:
+		switch cmd.name {
+		case "all":
+			return noRestartPhases, nil
+		case "watch", "deploy":
+			return cmd.phases, nil
+		}
+		return nil, fmt.Errorf("--skip-restart works with the all, watch and deploy commands")
+	}
+	if cmd.name != "all" {
+		return nil, fmt.Errorf("--restart-only works with the all command")
+	}
+	restart, _ := findCommand("restart")
+	return restart.phases, nil
+}
+
+// Run executes a sfdeploy command line, without the program name, the way
+// the sfdeploy command does and returns its exit code. The output goes to
+// standard output.
+func Run(arguments []string) (code int) {
+	commandLine = arguments
+	commandFlags.Usage = printUsage
+	args, err := parseArgs(arguments)
+	if err == flag.ErrHelp {
+		return exitOK
+	}
+	if err != nil {
+		return exitUsage
+	}
+
+	name := "all"
+	if len(args) > 0 {
+		name, commandArgs = args[0], args[1:]
+	}
+
+	if name == "help" {
+		printUsage()
+		return exitOK
+	}
+	// Completion output is read by the shell, so it skips the banner too
+	if name == "completion" {
+		return runCompletion(commandArgs)
+	}
+	if name == "__complete" {
+		return runDynamicCompletion(commandArgs)
+	}
+	// A target child runs part of the command named after it
+	child := name == targetChildCommand
+	if child {
+		name = "all"
+		if len(commandArgs) > 0 {
+			name, commandArgs = commandArgs[0], commandArgs[1:]
+		}
+	}
+
+	stopOutput, err := startOutput()
+	if err != nil {
+		fmt.Println(err)
+		return exitUsage
+	}
+	config := Config{}
+	started := time.Now()
+	defer func() {
+		stopOutput()
+		writeReport(&config, name, code, started)
+		if !child && recordsMetrics(name) {
+			recordMetrics(&config, name, code == exitOK, started)
+		}
+	}()
+	printDebugEnv()
+
+	cmd, ok := findCommand(name)
+	if !ok {
+		tprintf("Unknown command: %s\n", name)
+		fmt.Println()
+		printUsage()
+		waitAndExit()
+		return exitUsage
+	}
+	if *flagAll {
+		phases, ok := workspacePhases(cmd.name)
+		if !ok || *flagSource != "" || *flagExtension != "" {
+			tprintln("--all works with the all, build, test and deploy commands, and without --source or --extension")
+			waitAndExit()
+			return exitUsage
+		}
+		cmd.phases = phases
+	}
+	if phases, err := pipelinePhases(cmd); err != nil {
+		fmt.Println(err)
+		waitAndExit()
+		return exitUsage
+	} else if phases != nil {
+		cmd.phases = phases
+	}
+	if *flagAt != "" {
+		at, err := scheduledTime(*flagAt, time.Now())
+		if err != nil || !slices.Contains(scheduledCommands, cmd.name) {
+			if err == nil {
+				err = fmt.Errorf("works with the %s commands", strings.Join(scheduledCommands, ", "))
+			}
+			tprintf("Invalid --at %q: %v\n", *flagAt, err)
+			waitAndExit()
+			return exitUsage
+		}
+		cmd.phases = schedulePhases(cmd.name, cmd.phases, at)
+	}
+
+	if child {
+		return runTargetChild(cmd)
+	}
+
+	tprintln("====  SpookyZone Hot Deploy CLI Tool ====")
+	fmt.Println()
+
+	runningPhases = cmd.phases
+	for i, run := range cmd.phases {
+		topLevelPhase.Store(int32(i + 1))
+		if !run(&config) {
+			recordFailure(run)
+			tprintf("❌ Command '%s' failed\n", cmd.name)
+			notifyResult(&config, cmd.name, false, started)
+			waitAndExit()
+			return exitCode()
+		}
+	}
+	notifyResult(&config, cmd.name, true, started)
+
+	if cmd.name == "all" {
+		tprintln("Hot deploy completed successfully!")
+	} else {
+		tprintf("Command '%s' completed successfully!\n", cmd.name)
+	}
+	waitAndExit()
+	return exitOK
+}
+
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+func printUsage() {
+	out := commandFlags.Output()
+	fmt.Fprintln(out, "Usage: sfdeploy [command] [flags]")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %-10s %s\n", cmd.name, cmd.description)
+	}
+	fmt.Fprintf(out, "  %-10s %s\n", "completion", "Print a shell completion script (bash, zsh, fish or powershell)")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Flags:")
+	commandFlags.PrintDefaults()
+}
+
+func waitAndExit() {
+	if *flagNoPrompt || *flagNoPause {
+		return
+	}
+
+	fmt.Println()
+	tprintln("Press Enter to exit...")
+	bufio.NewReader(os.Stdin).ReadLine()
+}
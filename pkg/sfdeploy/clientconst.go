@@ -0,0 +1,218 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// clientConstantsHeader starts every generated constants file; a file that
+// does not mention sfdeploy in its first line is never overwritten.
+const clientConstantsHeader = "// Generated by sfdeploy from the extension's addRequestHandler and send calls. Do not edit."
+
+var (
+	// The name given to addRequestHandler, and to send in handlers and
+	// extensions, as a string or a constant
+	registerPattern = regexp.MustCompile(`\baddRequestHandler\(\s*("[^"\\]*"|[\w.]+)\s*,`)
+	sendPattern     = regexp.MustCompile(`\bsend\(\s*("[^"\\]*"|[\w.]+)\s*,`)
+	// static final String BUY = "buy" in Java, const val BUY = "buy" in Kotlin
+	stringConstantPattern = regexp.MustCompile(`\b(?:static\s+final|final\s+static|const\s+val)\s+(?:String\s+)?(\w+)\s*(?::\s*String\s*)?=\s*"([^"\\]*)"`)
+)
+
+// clientNames are the command strings the game client needs: the requests
+// the extension handles and the names it sends back.
+type clientNames struct {
+	Requests []string
+	Sent     []string // responses and events that are not also requests
+}
+
+// scanClientNames collects the request and response names of the sources.
+// Names given as constants are looked up among the string constants of the
+// sources; ones that cannot be resolved are skipped.
+func scanClientNames(config *Config) clientNames {
+	files := append(projectJavaFiles(config), projectKotlinFiles(config)...)
+	sources := make([]string, 0, len(files))
+	constants := map[string]string{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		sources = append(sources, string(data))
+		for _, m := range stringConstantPattern.FindAllStringSubmatch(string(data), -1) {
+			constants[m[1]] = m[2]
+		}
+	}
+
+	resolve := func(arg string, names map[string]bool) {
+		if strings.HasPrefix(arg, `"`) {
+			names[strings.Trim(arg, `"`)] = true
+			return
+		}
+		// Commands.BUY is looked up as BUY
+		if value, ok := constants[arg[strings.LastIndex(arg, ".")+1:]]; ok {
+			names[value] = true
+		} else {
+			verbosef("   Skipping %s, not a string constant of the sources\n", arg)
+		}
+	}
+	requests, sent := map[string]bool{}, map[string]bool{}
+	for _, source := range sources {
+		for _, m := range registerPattern.FindAllStringSubmatch(source, -1) {
+			resolve(m[1], requests)
+		}
+		for _, m := range sendPattern.FindAllStringSubmatch(source, -1) {
+			resolve(m[1], sent)
+		}
+	}
+
+	var names clientNames
+	for name := range requests {
+		names.Requests = append(names.Requests, name)
+	}
+	for name := range sent {
+		if !requests[name] {
+			names.Sent = append(names.Sent, name)
+		}
+	}
+	sort.Strings(names.Requests)
+	sort.Strings(names.Sent)
+	return names
+}
+
+// renderClientConstants writes the names as a static class of string
+// constants in C# for Unity, named after the file, or as exported constants
+// in TypeScript and JavaScript.
+func renderClientConstants(path string, names clientNames) (string, error) {
+	ext := filepath.Ext(path)
+	var b strings.Builder
+	var line, indent string
+	switch ext {
+	case ".cs":
+		class := constantName(strings.TrimSuffix(filepath.Base(path), ext), false)
+		fmt.Fprintf(&b, "%s\npublic static class %s\n{\n", clientConstantsHeader, class)
+		line, indent = "    public const string %s = %q;\n", "    "
+	case ".ts", ".js":
+		fmt.Fprintf(&b, "%s\n", clientConstantsHeader)
+		line = "export const %s = %q;\n"
+	default:
+		return "", fmt.Errorf("unknown client language %q (expected .cs, .ts or .js)", ext)
+	}
+
+	used := map[string]string{}
+	for i, group := range []struct {
+		comment string
+		names   []string
+	}{
+		{"Requests the extension handles", names.Requests},
+		{"Responses and events the extension sends", names.Sent},
+	} {
+		if len(group.names) == 0 {
+			continue
+		}
+		if i > 0 || ext != ".cs" {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%s// %s\n", indent, group.comment)
+		for _, name := range group.names {
+			constant := constantName(name, ext != ".cs")
+			if other, ok := used[constant]; ok {
+				tprintf("⚠️ Warning: %q and %q both make the client constant %s, keeping %q\n", other, name, constant, other)
+				continue
+			}
+			used[constant] = name
+			fmt.Fprintf(&b, line, constant, name)
+		}
+	}
+	if ext == ".cs" {
+		b.WriteString("}\n")
+	}
+	return b.String(), nil
+}
+
+// updateClientConstants regenerates client_constants from the sources, so
+// the command strings of the game client match the server's. The file is
+// only written when its content changes, which keeps Unity from
+// reimporting it on every build.
+func updateClientConstants(config *Config) bool {
+	path := config.ClientConstants
+	names := scanClientNames(config)
+	content, err := renderClientConstants(path, names)
+	if err != nil {
+		tprintf("❌ client_constants: %v\n", err)
+		return false
+	}
+
+	old, err := os.ReadFile(path)
+	switch {
+	case err == nil && string(old) == content:
+		verbosef("Client constants are up to date: %s\n", path)
+		return true
+	case err == nil:
+		first, _, _ := strings.Cut(string(old), "\n")
+		if !strings.Contains(first, "sfdeploy") {
+			tprintf("❌ %s was not generated by sfdeploy; move it away or point client_constants at another file\n", path)
+			return false
+		}
+	case !os.IsNotExist(err):
+		tprintf("❌ Failed to read %s: %v\n", path, err)
+		return false
+	}
+
+	if *flagDryRun {
+		tprintf("[dry-run] Would update client constants %s\n", path)
+		return true
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		tprintf("❌ Failed to create %s: %v\n", filepath.Dir(path), err)
+		return false
+	}
+	if err := writeFileAtomic(path, []byte(content), 0644); err != nil {
+		tprintf("❌ Failed to write %s: %v\n", path, err)
+		return false
+	}
+	tprintf("📝 Updated client constants %s (%d requests, %d responses and events)\n", path, len(names.Requests), len(names.Sent))
+	return true
+}
+
+// constantName turns a request name such as buyItem or buy_item into BuyItem,
+// or BUY_ITEM when upper is set.
+func constantName(command string, upper bool) string {
+	var words []string
+	var word []rune
+	for i, r := range command {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			words, word = append(words, string(word)), nil
+		case unicode.IsUpper(r) && i > 0 && len(word) > 0 && !unicode.IsUpper(word[len(word)-1]):
+			words, word = append(words, string(word)), []rune{r}
+		default:
+			word = append(word, r)
+		}
+	}
+	words = append(words, string(word))
+
+	var parts []string
+	for _, w := range words {
+		if w == "" {
+			continue
+		}
+		if upper {
+			parts = append(parts, strings.ToUpper(w))
+		} else {
+			parts = append(parts, strings.ToUpper(w[:1])+w[1:])
+		}
+	}
+	name := strings.Join(parts, "")
+	if upper {
+		name = strings.Join(parts, "_")
+	}
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "Request" + name
+	}
+	return name
+}
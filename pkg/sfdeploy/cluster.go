@@ -0,0 +1,298 @@
+package sfdeploy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+type targetResult struct {
+	Target string
+	Status string // ok, failed or skipped
+}
+
+// clusterKey names a target in per-target state such as deploy history.
+func clusterKey(target string) string {
+	return strings.Trim(strings.NewReplacer("@", "_", ":", "_", "/", "_", `\`, "_").Replace(target), "_")
+}
+
+func runPhases(config *Config, phases []phase) bool {
+	for _, run := range phases {
+		if !run(config) {
+			recordFailure(run)
+			return false
+		}
+	}
+	return true
+}
+
+// targetChildCommand is the hidden command a parallel cluster run starts
+// once per target, see runTargetChildren.
+const targetChildCommand = "__target"
+
+// targetGroupID is the phase ID every perTarget group shares. It is set in
+// init because perTarget refers back to it.
+var targetGroupID uintptr
+
+func init() {
+	targetGroupID = phaseID(perTarget())
+}
+
+// runningPhases are the phases of the command Run executes, and
+// topLevelPhase is one more than the index of the one running, or 0 once a
+// perTarget group has taken it.
+var (
+	runningPhases []phase
+	topLevelPhase atomic.Int32
+)
+
+// targetChildRun is what a target child reads from stdin: the config the
+// parent set up and which of the command's phases to run against which
+// target.
+type targetChildRun struct {
+	Phase  int         `json:"phase"`
+	Target string      `json:"target"`
+	Config Config      `json:"config"`
+	State  configState `json:"state"`
+}
+
+// configState holds what the earlier phases filled into the unexported
+// Config fields, which JSON leaves out, so a target child builds and deploys
+// the same modules, extensions and projects as the parent.
+type configState struct {
+	Packages []string          `json:"packages,omitempty"`
+	Modules  []sourceModule    `json:"modules,omitempty"`
+	Projects []projectState    `json:"projects,omitempty"`
+	DotEnv   map[string]string `json:"dot_env,omitempty"`
+}
+
+type projectState struct {
+	Name   string      `json:"name"`
+	Config Config      `json:"config"`
+	State  configState `json:"state"`
+}
+
+func saveConfigState(config *Config) configState {
+	state := configState{Packages: config.packages, Modules: config.modules, DotEnv: config.dotEnv}
+	for _, project := range config.projects {
+		state.Projects = append(state.Projects, projectState{project.name, project.config, saveConfigState(&project.config)})
+	}
+	return state
+}
+
+func restoreConfigState(config *Config, state configState) {
+	config.packages, config.modules, config.dotEnv = state.Packages, state.Modules, state.DotEnv
+	config.projects = nil
+	for _, project := range state.Projects {
+		restoreConfigState(&project.Config, project.State)
+		config.projects = append(config.projects, workspaceProject{project.Name, project.Config})
+	}
+}
+
+// perTarget groups phases that act on the server. Without targets they run
+// once against target_dir; with targets they run for each server in turn
+// (or all at once with parallel), followed by a per-server report.
+func perTarget(phases ...phase) phase {
+	return func(config *Config) bool {
+		top := int(topLevelPhase.Swap(0)) - 1
+		if len(config.Targets) == 0 {
+			return runPhases(config, phases)
+		}
+
+		results := make([]targetResult, len(config.Targets))
+		for i, target := range config.Targets {
+			results[i] = targetResult{Target: target, Status: "skipped"}
+		}
+
+		run := func(i int) bool {
+			targetConfig := *config
+			targetConfig.TargetDir = config.Targets[i]
+
+			tprintf("🖥️ Target %d/%d: %s\n", i+1, len(config.Targets), config.Targets[i])
+			fmt.Println()
+
+			ok := runPhases(&targetConfig, phases)
+			if ok {
+				results[i].Status = "ok"
+			} else {
+				results[i].Status = "failed"
+			}
+			return ok
+		}
+
+		if config.Parallel && top >= 0 && phaseID(runningPhases[top]) == targetGroupID {
+			runTargetChildren(config, top, results)
+		} else {
+			if config.Parallel {
+				tprintln("⚠️ Running the targets one after another, parallel only applies to a group of the command itself")
+				fmt.Println()
+			}
+			for i := range config.Targets {
+				if !run(i) && config.StopOnFailure {
+					break
+				}
+			}
+		}
+
+		return reportTargets(results)
+	}
+}
+
+func reportTargets(results []targetResult) bool {
+	tprintln("📋 Cluster Summary")
+
+	allOK := true
+	for _, result := range results {
+		switch result.Status {
+		case "ok":
+			fmt.Printf("   ✅ %s\n", result.Target)
+		case "failed":
+			fmt.Printf("   ❌ %s\n", result.Target)
+			allOK = false
+		default:
+			tprintf("   ⏭️ %s (skipped)\n", result.Target)
+			allOK = false
+		}
+	}
+	fmt.Println()
+
+	return allOK
+}
+
+// runTargetChildren runs the phase at index phase of the running command
+// for every target at once, each in an sfdeploy child process of its own so
+// that no server state is shared. A target's output is shown in one piece
+// once it is done.
+func runTargetChildren(config *Config, phase int, results []targetResult) {
+	exe, err := os.Executable()
+	if err != nil {
+		tprintf("❌ Could not find the sfdeploy executable: %v\n", err)
+		recordExitCode(exitFailure)
+		for i := range results {
+			results[i].Status = "failed"
+		}
+		return
+	}
+
+	tprintf("🖥️ Running %d targets in parallel, the output of each follows when it is done\n", len(config.Targets))
+	fmt.Println()
+
+	// Output flags are fixed so the child's lines arrive as plain text, and
+	// the parent keeps the log, report and notifications
+	args := append([]string{targetChildCommand}, commandLine...)
+	args = append(args, "--no-pause", "--no-prompt", "--no-color", "--log-format=text", "--ci=false", "--report=")
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i, target := range config.Targets {
+		wg.Add(1)
+		go func(i int, target string) {
+			defer wg.Done()
+
+			run := targetChildRun{Phase: phase, Target: target, Config: *config, State: saveConfigState(config)}
+			payload, err := json.Marshal(run)
+			var out bytes.Buffer
+			code := exitFailure
+			if err == nil {
+				cmd := newCommand(exe, args...)
+				cmd.Stdin = bytes.NewReader(payload)
+				cmd.Stdout, cmd.Stderr = &out, &out
+				err = cmd.Run()
+				var exitErr *exec.ExitError
+				switch {
+				case err == nil:
+					code = exitOK
+				case errors.As(err, &exitErr):
+					code = exitErr.ExitCode()
+				}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			tprintf("🖥️ Target %d/%d: %s\n", i+1, len(config.Targets), target)
+			fmt.Println()
+			os.Stdout.Write(out.Bytes())
+			if err != nil && code == exitFailure {
+				tprintf("❌ Could not run sfdeploy for %s: %v\n", target, err)
+			}
+			if code == exitOK {
+				results[i].Status = "ok"
+			} else {
+				results[i].Status = "failed"
+				recordExitCode(code)
+			}
+		}(i, target)
+	}
+	wg.Wait()
+}
+
+// runTargetChild is the hidden __target command: it runs one phase of cmd
+// against the single target read from stdin, with the config the parent
+// already set up, and returns the exit code.
+func runTargetChild(cmd command) int {
+	var run targetChildRun
+	if err := json.NewDecoder(os.Stdin).Decode(&run); err != nil {
+		tprintf("❌ Could not read the target to run: %v\n", err)
+		return exitUsage
+	}
+	if run.Phase < 0 || run.Phase >= len(cmd.phases) || phaseID(cmd.phases[run.Phase]) != targetGroupID {
+		tprintf("❌ Phase %d of '%s' does not act on the targets\n", run.Phase, cmd.name)
+		return exitUsage
+	}
+
+	config := run.Config
+	restoreConfigState(&config, run.State)
+	config.TargetDir = run.Target
+	config.Targets = nil
+	if config.CopyWorkers > 0 {
+		copyWorkers = config.CopyWorkers
+	}
+	useLanguage(&config)
+
+	if !cmd.phases[run.Phase](&config) {
+		return exitCode()
+	}
+	return exitOK
+}
+
+// parallelTargetsError reports why the targets cannot run in parallel:
+// local and network share targets are restarted through the health port of
+// this machine and the processes found on it, so only servers reached over
+// SSH or in Docker can.
+func parallelTargetsError(config *Config) error {
+	if !config.Parallel {
+		return nil
+	}
+	var local []string
+	for _, target := range config.Targets {
+		if _, ok := parseShellTarget(target); !ok {
+			local = append(local, target)
+		}
+	}
+	if len(local) > 0 {
+		return fmt.Errorf("parallel needs every target to be reached over SSH or Docker, not %s", strings.Join(local, ", "))
+	}
+	return nil
+}
+
+// validateTargets checks every configured target before anything is built.
+func validateTargets(config *Config) bool {
+	if err := parallelTargetsError(config); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return false
+	}
+	for _, target := range config.Targets {
+		targetConfig := *config
+		targetConfig.TargetDir = target
+		if !validateTarget(&targetConfig) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,36 @@
+package sfdeploy
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// A target child must get the modules, extension packages, workspace
+// projects and .env variables the parent's earlier phases filled in.
+func TestTargetChildRunKeepsConfigState(t *testing.T) {
+	project := Config{SourceDir: "/src/lobby", ExtensionFolder: "Lobby"}
+	project.modules = []sourceModule{{Name: "core", Dir: "core"}, {Name: "game", Dir: "game", DependsOn: []string{"core"}}}
+	project.packages = []string{"com.lobby"}
+
+	config := Config{SourceDir: "/src/game", ExtensionFolder: "Game", Targets: []string{"a:/sfs", "b:/sfs"}}
+	config.modules = []sourceModule{{Name: "app", Dir: "."}}
+	config.packages = []string{"com.game", "com.shared"}
+	config.projects = []workspaceProject{{"lobby", project}}
+	config.dotEnv = map[string]string{"SFDEPLOY_HEALTH_TIMEOUT": "7"}
+
+	payload, err := json.Marshal(targetChildRun{Phase: 2, Target: "a:/sfs", Config: config, State: saveConfigState(&config)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var run targetChildRun
+	if err := json.Unmarshal(payload, &run); err != nil {
+		t.Fatal(err)
+	}
+	got := run.Config
+	restoreConfigState(&got, run.State)
+
+	if !reflect.DeepEqual(got, config) {
+		t.Errorf("the child's config = %+v, want %+v", got, config)
+	}
+}
@@ -0,0 +1,56 @@
+package sfdeploy
+
+import (
+	"os"
+	"strings"
+
+	"github.com/muesli/termenv"
+)
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiFaint  = "\x1b[2m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+)
+
+// colorEnabled reports whether console output is colored: text output to a
+// terminal, unless --no-color or NO_COLOR (https://no-color.org) is set.
+func colorEnabled(f *os.File) bool {
+	if *flagNoColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return (*flagLogFormat == "" || *flagLogFormat == "text") && isTerminal(f)
+}
+
+// enableColor prepares the console for ANSI colors, which Windows consoles
+// only show once virtual terminal processing is on. The returned func
+// restores the console mode.
+func enableColor(f *os.File) (func(), bool) {
+	restore, err := termenv.EnableVirtualTerminalProcessing(termenv.NewOutput(f))
+	if err != nil {
+		return nil, false
+	}
+	return func() { restore() }, true
+}
+
+// lineColor picks the color of an output line from how it starts: errors
+// red, warnings yellow, successes green and phase headlines bold.
+func lineColor(line string) string {
+	line = strings.TrimSpace(line)
+	switch {
+	case line == "":
+		return ""
+	case strings.HasPrefix(line, "❌"):
+		return ansiRed
+	case strings.HasPrefix(line, "⚠️"), strings.HasPrefix(line, "Warning:"):
+		return ansiYellow
+	case strings.HasPrefix(line, "✅"), strings.HasSuffix(line, "completed successfully!"):
+		return ansiGreen
+	case phaseLine.MatchString(line):
+		return ansiBold
+	}
+	return ""
+}
@@ -0,0 +1,400 @@
+package sfdeploy
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// completionShells are the shells "completion" writes scripts for.
+var completionShells = []string{"bash", "zsh", "fish", "powershell"}
+
+// subcommandWords are the words completed after commands that take one.
+var subcommandWords = map[string][]string{
+	"history":     {"list", "restore"},
+	"backup":      {"list", "restore"},
+	"admin":       {"install"},
+	"cache":       {"info", "clean"},
+	"config":      {"validate", "set", "edit", "export", "import"},
+	"generate":    {"handler", "constants"},
+	"credentials": {"set", "delete", "list"},
+	"completion":  completionShells,
+}
+
+// flagValues says what to complete as the value of a flag: "file", "dir",
+// "profile" or a list of words. Other flags take free text.
+var flagValues = map[string]string{
+	"config":     "file",
+	"report":     "file",
+	"source":     "dir",
+	"target":     "dir",
+	"java":       "dir",
+	"profile":    "profile",
+	"log-format": "text json",
+}
+
+type completionFlag struct {
+	name   string
+	usage  string
+	isBool bool
+	values string
+}
+
+func completionFlags() []completionFlag {
+	var flags []completionFlag
+	commandFlags.VisitAll(func(f *flag.Flag) {
+		b, ok := f.Value.(interface{ IsBoolFlag() bool })
+		flags = append(flags, completionFlag{f.Name, f.Usage, ok && b.IsBoolFlag(), flagValues[f.Name]})
+	})
+	return flags
+}
+
+type completionCommand struct {
+	name        string
+	description string
+}
+
+func completionCommands() []completionCommand {
+	var list []completionCommand
+	for _, cmd := range commands {
+		list = append(list, completionCommand{cmd.name, cmd.description})
+	}
+	return append(list,
+		completionCommand{"completion", "Print a shell completion script (bash, zsh, fish or powershell)"},
+		completionCommand{"help", "Show commands and flags"})
+}
+
+// runCompletion implements "completion <shell>". It runs before output is
+// routed so the script is printed without the banner.
+func runCompletion(args []string) int {
+	shell := ""
+	if len(args) > 0 {
+		shell = args[0]
+	}
+
+	var script string
+	switch shell {
+	case "bash":
+		script = bashCompletion()
+	case "zsh":
+		script = zshCompletion()
+	case "fish":
+		script = fishCompletion()
+	case "powershell", "pwsh":
+		script = powershellCompletion()
+	default:
+		fmt.Fprintf(os.Stderr, "Usage: sfdeploy completion %s\n", strings.Join(completionShells, "|"))
+		return exitUsage
+	}
+	fmt.Print(script)
+	return exitOK
+}
+
+// runDynamicCompletion implements the hidden "__complete profiles" command
+// the scripts call to list the profiles of the config file in use.
+func runDynamicCompletion(args []string) int {
+	if len(args) == 0 || args[0] != "profiles" {
+		return exitUsage
+	}
+	config, ok := loadConfig()
+	if !ok {
+		return exitConfig
+	}
+	for _, name := range profileNames(&config) {
+		fmt.Println(name)
+	}
+	return exitOK
+}
+
+func commandNames() string {
+	var names []string
+	for _, cmd := range completionCommands() {
+		names = append(names, cmd.name)
+	}
+	return strings.Join(names, " ")
+}
+
+func sortedSubcommands() []string {
+	var names []string
+	for name := range subcommandWords {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func bashCompletion() string {
+	var b strings.Builder
+	var flagNames, valueFlags []string
+	for _, f := range completionFlags() {
+		flagNames = append(flagNames, "--"+f.name)
+		if !f.isBool {
+			valueFlags = append(valueFlags, "--"+f.name, "-"+f.name)
+		}
+	}
+
+	b.WriteString(`# bash completion for sfdeploy
+# Load with: source <(sfdeploy completion bash)
+
+__sfdeploy_value_flags=" ` + strings.Join(valueFlags, " ") + ` "
+
+_sfdeploy() {
+    local cur prev
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    prev="${COMP_WORDS[COMP_CWORD-1]}"
+
+    local words=() config=() i w
+    for ((i = 1; i < COMP_CWORD; i++)); do
+        w="${COMP_WORDS[i]}"
+        case "$w" in
+            --config|-config) config=(--config "${COMP_WORDS[i+1]}") ;;
+            --config=*|-config=*) config=(--config "${w#*=}") ;;
+        esac
+        if [[ "$w" == -* ]]; then
+            [[ "$w" != *=* && "$__sfdeploy_value_flags" == *" $w "* ]] && ((i++))
+            continue
+        fi
+        words+=("$w")
+    done
+
+    case "$prev" in
+`)
+	for _, f := range completionFlags() {
+		if f.isBool {
+			continue
+		}
+		fmt.Fprintf(&b, "        --%s|-%s)\n", f.name, f.name)
+		switch f.values {
+		case "file":
+			b.WriteString("            COMPREPLY=($(compgen -f -- \"$cur\")); return ;;\n")
+		case "dir":
+			b.WriteString("            COMPREPLY=($(compgen -d -- \"$cur\")); return ;;\n")
+		case "profile":
+			b.WriteString("            COMPREPLY=($(compgen -W \"$(sfdeploy __complete profiles \"${config[@]}\" 2>/dev/null)\" -- \"$cur\")); return ;;\n")
+		case "":
+			b.WriteString("            return ;;\n")
+		default:
+			fmt.Fprintf(&b, "            COMPREPLY=($(compgen -W \"%s\" -- \"$cur\")); return ;;\n", f.values)
+		}
+	}
+	b.WriteString(`    esac
+
+    if [[ "$cur" == -* ]]; then
+        COMPREPLY=($(compgen -W "` + strings.Join(flagNames, " ") + `" -- "$cur"))
+        return
+    fi
+
+    case "${#words[@]}" in
+        0) COMPREPLY=($(compgen -W "` + commandNames() + `" -- "$cur")) ;;
+        1)
+            case "${words[0]}" in
+`)
+	for _, name := range sortedSubcommands() {
+		fmt.Fprintf(&b, "                %s) COMPREPLY=($(compgen -W \"%s\" -- \"$cur\")) ;;\n", name, strings.Join(subcommandWords[name], " "))
+	}
+	b.WriteString(`            esac
+            ;;
+    esac
+}
+
+complete -o default -F _sfdeploy sfdeploy
+`)
+	return b.String()
+}
+
+// zshEscape escapes the characters _arguments and _describe give a meaning.
+func zshEscape(s string) string {
+	return strings.NewReplacer(`'`, `'\''`, `[`, `\[`, `]`, `\]`, `:`, `\:`).Replace(s)
+}
+
+func zshCompletion() string {
+	var b strings.Builder
+	b.WriteString(`#compdef sfdeploy
+# zsh completion for sfdeploy
+# Load with: source <(sfdeploy completion zsh)
+
+_sfdeploy() {
+    local curcontext="$curcontext" state line config
+    typeset -A opt_args
+
+    _arguments -C \
+`)
+	for _, f := range completionFlags() {
+		usage := zshEscape(f.usage)
+		if f.isBool {
+			fmt.Fprintf(&b, "        '--%s[%s]' \\\n", f.name, usage)
+			continue
+		}
+		action := " "
+		switch f.values {
+		case "file":
+			action = "_files"
+		case "dir":
+			action = "_files -/"
+		case "profile":
+			action = "->profiles"
+		case "":
+		default:
+			action = "(" + f.values + ")"
+		}
+		fmt.Fprintf(&b, "        '--%s=[%s]:%s:%s' \\\n", f.name, usage, f.name, action)
+	}
+	b.WriteString(`        '1:command:->commands' \
+        '*::argument:->arguments'
+
+    case "$state" in
+        profiles)
+            config=()
+            [[ -n "${opt_args[--config]}" ]] && config=(--config "${opt_args[--config]}")
+            local -a profiles
+            profiles=(${(f)"$(sfdeploy __complete profiles "${config[@]}" 2>/dev/null)"})
+            _describe 'profile' profiles
+            ;;
+        commands)
+            local -a commands
+            commands=(
+`)
+	for _, cmd := range completionCommands() {
+		fmt.Fprintf(&b, "                '%s:%s'\n", cmd.name, zshEscape(cmd.description))
+	}
+	b.WriteString(`            )
+            _describe 'command' commands
+            ;;
+        arguments)
+            case "${line[1]}" in
+`)
+	for _, name := range sortedSubcommands() {
+		fmt.Fprintf(&b, "                %s) (( CURRENT == 2 )) && compadd %s ;;\n", name, strings.Join(subcommandWords[name], " "))
+	}
+	b.WriteString(`            esac
+            ;;
+    esac
+}
+
+compdef _sfdeploy sfdeploy
+`)
+	return b.String()
+}
+
+func fishEscape(s string) string {
+	return strings.ReplaceAll(s, `'`, `\'`)
+}
+
+func fishCompletion() string {
+	var b strings.Builder
+	b.WriteString(`# fish completion for sfdeploy
+# Load with: sfdeploy completion fish | source
+
+function __sfdeploy_config
+    set -l tokens (commandline -opc)
+    for i in (seq (count $tokens))
+        switch $tokens[$i]
+            case --config -config
+                set -l next (math $i + 1)
+                if test $next -le (count $tokens)
+                    echo --config $tokens[$next]
+                end
+            case '--config=*' '-config=*'
+                echo --config (string split -m1 = -- $tokens[$i])[2]
+        end
+    end
+end
+
+complete -c sfdeploy -f
+`)
+	for _, cmd := range completionCommands() {
+		fmt.Fprintf(&b, "complete -c sfdeploy -n __fish_use_subcommand -a %s -d '%s'\n", cmd.name, fishEscape(cmd.description))
+	}
+	for _, name := range sortedSubcommands() {
+		fmt.Fprintf(&b, "complete -c sfdeploy -n '__fish_seen_subcommand_from %s' -a '%s'\n", name, strings.Join(subcommandWords[name], " "))
+	}
+	for _, f := range completionFlags() {
+		line := fmt.Sprintf("complete -c sfdeploy -l %s -d '%s'", f.name, fishEscape(f.usage))
+		switch {
+		case f.isBool:
+		case f.values == "file":
+			line += " -r -F"
+		case f.values == "dir":
+			line += " -x -a '(__fish_complete_directories)'"
+		case f.values == "profile":
+			line += " -x -a '(sfdeploy __complete profiles (__sfdeploy_config) 2>/dev/null)'"
+		case f.values == "":
+			line += " -x"
+		default:
+			line += fmt.Sprintf(" -x -a '%s'", f.values)
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
+
+func powershellCompletion() string {
+	var b strings.Builder
+	b.WriteString(`# PowerShell completion for sfdeploy
+# Load with: sfdeploy completion powershell | Out-String | Invoke-Expression
+
+Register-ArgumentCompleter -Native -CommandName sfdeploy, sfdeploy.exe -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+
+    $commands = [ordered]@{
+`)
+	for _, cmd := range completionCommands() {
+		fmt.Fprintf(&b, "        '%s' = '%s'\n", cmd.name, strings.ReplaceAll(cmd.description, "'", "''"))
+	}
+	b.WriteString("    }\n    $subcommands = @{\n")
+	for _, name := range sortedSubcommands() {
+		fmt.Fprintf(&b, "        '%s' = @('%s')\n", name, strings.Join(subcommandWords[name], "', '"))
+	}
+	b.WriteString("    }\n    $flags = [ordered]@{\n")
+	for _, f := range completionFlags() {
+		fmt.Fprintf(&b, "        '--%s' = '%s'\n", f.name, strings.ReplaceAll(f.usage, "'", "''"))
+	}
+	var valueFlags []string
+	for _, f := range completionFlags() {
+		if !f.isBool {
+			valueFlags = append(valueFlags, "'--"+f.name+"'", "'-"+f.name+"'")
+		}
+	}
+	b.WriteString(`    }
+    $valueFlags = @(` + strings.Join(valueFlags, ", ") + `)
+
+    $elements = @($commandAst.CommandElements | Select-Object -Skip 1 | ForEach-Object { $_.ToString() })
+    if ($wordToComplete -ne '' -and $elements.Count -gt 0) {
+        $elements = @($elements | Select-Object -SkipLast 1)
+    }
+    $prev = if ($elements.Count -gt 0) { $elements[-1] } else { '' }
+
+    $config = @()
+    $words = @()
+    for ($i = 0; $i -lt $elements.Count; $i++) {
+        $w = $elements[$i]
+        if ($w -in '--config', '-config' -and $i + 1 -lt $elements.Count) { $config = @('--config', $elements[$i + 1]) }
+        if ($w -like '-*') {
+            if ($w -notlike '*=*' -and $w -in $valueFlags) { $i++ }
+            continue
+        }
+        $words += $w
+    }
+
+    $candidates = switch -Regex ($prev) {
+        '^--?profile$' { @(& sfdeploy __complete profiles @config 2>$null); break }
+        '^--?log-format$' { @('text', 'json'); break }
+        '^--?(config|report|source|target|java)$' { return }
+        default {
+            if ($wordToComplete -like '-*') { @($flags.Keys) }
+            elseif ($words.Count -eq 0) { @($commands.Keys) }
+            elseif ($words.Count -eq 1 -and $subcommands.Contains($words[0])) { $subcommands[$words[0]] }
+            else { return }
+        }
+    }
+
+    $candidates | Where-Object { $_ -like "$wordToComplete*" } | ForEach-Object {
+        $tip = if ($commands.Contains($_)) { $commands[$_] } elseif ($flags.Contains($_)) { $flags[$_] } else { $_ }
+        [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $tip)
+    }
+}
+`)
+	return b.String()
+}
@@ -0,0 +1,513 @@
+package sfdeploy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+type Config struct {
+	JavaPath        string            `json:"java_path"`
+	JavaVersion     string            `json:"java_version"`
+	JDKDownload     bool              `json:"jdk_download"`
+	KotlincPath     string            `json:"kotlinc_path"`
+	KotlinVersion   string            `json:"kotlin_version"`
+	SourceDir       string            `json:"source_dir"`
+	Modules         []sourceModule    `json:"modules"`
+	TargetDir       string            `json:"target_dir"`
+	ExtensionFolder string            `json:"extension_folder"`
+	ExtensionFile   string            `json:"extension_file"`
+	CommonFile      string            `json:"common_file"`
+	CommonFolder    string            `json:"common_folder"`
+	JsonSourceDir   string            `json:"json_source_dir"`
+	DeployJsonFiles []string          `json:"deploy_json_files"`
+	DeployFiles     []string          `json:"deploy_files"`
+	ResourceDirs    []resourceRoot    `json:"resource_dirs"`
+	JarFiles        []string          `json:"jar_files"`
+	Extensions      []extensionConfig `json:"extensions"`
+	Workspace       []workspaceEntry  `json:"workspace"`
+	HistoryLimit    int               `json:"history_limit"`
+	BackupDir       string            `json:"backup_dir"`
+	BackupLimit     int               `json:"backup_limit"`
+	SSHOptions      []string          `json:"ssh_options"`
+	JavaRelease     string            `json:"java_release"`
+	SourceEncoding  string            `json:"source_encoding"`
+	JavacFlags      []string          `json:"javac_flags"`
+	ProcessorPath   []string          `json:"processor_path"`
+	Processors      []string          `json:"processors"`
+	ProcessorOpts   map[string]string `json:"processor_options"`
+	ExtraLibs       []string          `json:"extra_libs"`
+	Dependencies    []string          `json:"dependencies"`
+	MavenRepository string            `json:"maven_repository"`
+	GradleTask      string            `json:"gradle_task"`
+	BuildOutput     string            `json:"build_output"`
+	Package         string            `json:"package"`
+	LibJars         []string          `json:"lib_jars"`
+	AdminURL        string            `json:"admin_url"`
+	AdminHost       string            `json:"admin_host"`
+	AdminPort       int               `json:"admin_port"`
+	AdminUser       string            `json:"admin_user"`
+	AdminPassword   string            `json:"admin_password"`
+	HealthPort      int               `json:"health_port"`
+	HealthHTTPPort  int               `json:"health_http_port"`
+	ServerPorts     []int             `json:"server_ports"`
+	HealthTimeout   int               `json:"health_timeout"`
+	DockerSignal    string            `json:"docker_signal"`
+	RestartCommand  string            `json:"restart_command"`
+	Targets         []string          `json:"targets"`
+	Parallel        bool              `json:"parallel"`
+	StopOnFailure   bool              `json:"stop_on_failure"`
+	ZoneName        string            `json:"zone_name"`
+	ZoneFile        string            `json:"zone_file"`
+	ZoneMainClass   string            `json:"zone_main_class"`
+	ZoneReloadMode  string            `json:"zone_reload_mode"`
+	Reloadable      bool              `json:"reloadable"`
+	ZoneSettings    map[string]string `json:"zone_settings"`
+	JsonTemplates   bool              `json:"json_templates"`
+	TemplateVars    map[string]string `json:"template_vars"`
+	PreDeployHooks  []string          `json:"pre_deploy_hooks"`
+	PostDeployHooks []string          `json:"post_deploy_hooks"`
+	CustomPhases    []customPhase     `json:"custom_phases"`
+	Notifications   notifyConfig      `json:"notifications"`
+	LogFile         string            `json:"log_file"`
+	LogMaxSize      int               `json:"log_max_size"`
+	LogMaxFiles     int               `json:"log_max_files"`
+	LogEachRun      bool              `json:"log_each_run"`
+	ListenAddr      string            `json:"listen_addr"`
+	ListenBranch    string            `json:"listen_branch"`
+	WebhookSecret   string            `json:"webhook_secret"`
+	ServeAddr       string            `json:"serve_addr"`
+	ServeToken      string            `json:"serve_token"`
+	GRPCAddr        string            `json:"grpc_addr"`
+	TestDir         string            `json:"test_dir"`
+	TestClasspath   []string          `json:"test_classpath"`
+	SmokeTest       smokeConfig       `json:"smoke_test"`
+	LockRetry       int               `json:"lock_retry"`
+	NetworkRetry    int               `json:"network_retry"`
+	CopyWorkers     int               `json:"copy_workers"`
+	RestartTimeout  int               `json:"restart_timeout"`
+	ShutdownWait    int               `json:"shutdown_wait"`
+	ShutdownGrace   int               `json:"shutdown_grace"`
+	KillWait        int               `json:"kill_wait"`
+	StartupWait     int               `json:"startup_wait"`
+	JVMOptions      jvmConfig         `json:"jvm_options"`
+	WindowsService  string            `json:"windows_service"`
+	SystemdUnit     string            `json:"systemd_unit"`
+	SystemdSudo     bool              `json:"systemd_sudo"`
+	CredentialStore string            `json:"credential_store"`
+	Language        string            `json:"language"`
+	ClientConstants string            `json:"client_constants"`
+	Signing         signConfig        `json:"signing"`
+	ProGuard        proguardConfig    `json:"proguard"`
+
+	Profiles map[string]json.RawMessage `json:"profiles,omitempty"`
+
+	// packages limits the extension JAR to these Java packages in the
+	// per-extension configs of extensionConfigs
+	packages []string
+
+	// modules are the source modules in build order, filled in by
+	// checkModules
+	modules []sourceModule
+
+	// projects are the resolved configs of the workspace projects in build
+	// order, filled in by setupWorkspace
+	projects []workspaceProject
+	// dotEnv are the variables of the project's .env, filled in by
+	// loadDotEnv
+	dotEnv map[string]string
+}
+
+const configFile = "sfdeploy_config.json"
+
+// projectConfigFile holds team-shared settings inside the source project.
+const projectConfigFile = ".sfdeploy.json"
+
+// configCandidates are tried in order when --config is not given.
+var configCandidates = []string{
+	configFile,
+	"sfdeploy.yaml",
+	"sfdeploy.yml",
+	"sfdeploy.toml",
+}
+
+func findConfigFile() string {
+	if *flagConfig != "" {
+		return *flagConfig
+	}
+
+	for _, name := range configCandidates {
+		if _, err := os.Stat(name); err == nil {
+			return name
+		}
+	}
+	return configFile
+}
+
+func loadConfig() (Config, bool) {
+	config, err := readConfigFile()
+	return config, err == nil
+}
+
+// readConfigFile parses the config file in use, reporting why it could not.
+func readConfigFile() (Config, error) {
+	var config Config
+
+	data, err := readConfigData()
+	if err != nil {
+		return config, err
+	}
+
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		return config, err
+	}
+
+	return config, nil
+}
+
+// readConfigData returns the config file in use as JSON.
+func readConfigData() ([]byte, error) {
+	data, err := os.ReadFile(findConfigFile())
+	if err != nil {
+		return nil, err
+	}
+	return configToJSON(findConfigFile(), data)
+}
+
+// configToJSON converts YAML and TOML config files to JSON so every format
+// shares the same struct tags and profile handling.
+func configToJSON(path string, data []byte) ([]byte, error) {
+	var generic map[string]interface{}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &generic); err != nil {
+			return nil, err
+		}
+	case ".toml":
+		if err := toml.Unmarshal(data, &generic); err != nil {
+			return nil, err
+		}
+	default:
+		return data, nil
+	}
+
+	return json.Marshal(generic)
+}
+
+// applyProfile overlays the named profile onto config. Only the keys present
+// in the profile are replaced, so a profile can be as small as a target_dir.
+func applyProfile(config *Config, name string) bool {
+	raw, ok := config.Profiles[name]
+	if !ok {
+		tprintf("Profile not found: %s\n", name)
+		if len(config.Profiles) > 0 {
+			tprintf("Available profiles: %s\n", strings.Join(profileNames(config), ", "))
+		}
+		return false
+	}
+
+	if err := json.Unmarshal(raw, config); err != nil {
+		tprintf("Invalid profile %s: %v\n", name, err)
+		return false
+	}
+
+	return true
+}
+
+func profileNames(config *Config) []string {
+	var names []string
+	for name := range config.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func setupDirectories(config *Config) bool {
+	tprintln("Phase 1: Directory Setup")
+
+	if !reportConfigFile() {
+		return false
+	}
+	savedConfig, exists := loadConfig()
+	if !exists && !hasFlagOverrides() && !hasEnvOverrides() {
+		tprintf("Config file not found: %s\n", findConfigFile())
+		tprintln("Run 'sfdeploy config edit' to create one")
+		return false
+	}
+
+	*config = savedConfig
+	if !applyOverrides(config) {
+		return false
+	}
+	openLogFile(config)
+	useLanguage(config)
+	if !reportMissingRequired(config) {
+		return false
+	}
+	return checkDirectories(config)
+}
+
+// checkDirectories validates the source, target and extensions of a config
+// whose overrides are applied and fills in the defaults derived from them.
+func checkDirectories(config *Config) bool {
+	if config.CopyWorkers > 0 {
+		copyWorkers = config.CopyWorkers
+	}
+
+	if len(config.Modules) == 0 && !validateSourceDir(config.SourceDir) {
+		tprintln("Source directory is invalid")
+		return false
+	}
+	if !checkModules(config) {
+		return false
+	}
+
+	if !setupTargets(config) {
+		return false
+	}
+
+	if !selectExtensions(config) {
+		return false
+	}
+	if config.ExtensionFile == "" {
+		config.ExtensionFile = config.ExtensionFolder + ".jar"
+	}
+	if err := checkCustomPhases(config); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return false
+	}
+
+	tprintf("Source: %s\n", config.SourceDir)
+	printTargets(config)
+	if len(config.Extensions) > 1 {
+		var names []string
+		for _, ext := range config.Extensions {
+			names = append(names, ext.Folder)
+		}
+		tprintf("Extensions: %s\n", strings.Join(names, ", "))
+	} else {
+		tprintf("Extension: %s\n", config.ExtensionFolder)
+	}
+	fmt.Println()
+	return true
+}
+
+func setupTargets(config *Config) bool {
+	if len(config.Targets) > 0 {
+		// The first server provides the libraries to compile against
+		if config.TargetDir == "" {
+			config.TargetDir = config.Targets[0]
+		}
+		return validateTargets(config)
+	}
+	return validateTarget(config)
+}
+
+func printTargets(config *Config) {
+	if len(config.Targets) > 0 {
+		tprintf("Targets: %s\n", strings.Join(config.Targets, ", "))
+	} else {
+		tprintf("Target: %s\n", config.TargetDir)
+	}
+}
+
+// applyOverrides layers the project config, profile, SFDEPLOY_* variables
+// (also from .env) and flags over the config file, then fills in secret
+// references.
+func applyOverrides(config *Config) bool {
+	if !mergeProjectConfig(config) {
+		return false
+	}
+
+	if *flagProfile != "" {
+		if !applyProfile(config, *flagProfile) {
+			return false
+		}
+		tprintf("Profile: %s\n", *flagProfile)
+	}
+	if !loadDotEnv(config) {
+		return false
+	}
+
+	applied, err := applyEnvOverrides(config)
+	if err != nil {
+		tprintf("Invalid environment override: %v\n", err)
+		return false
+	}
+	if len(applied) > 0 {
+		tprintf("Environment overrides: %s\n", strings.Join(applied, ", "))
+	}
+
+	applyFlagOverrides(config)
+
+	if err := resolveSecrets(config); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return false
+	}
+	return true
+}
+
+// mergeProjectConfig loads .sfdeploy.json from the source directory and
+// layers the machine config over it, so team-shared settings live with the
+// code while every key set locally still wins.
+func mergeProjectConfig(config *Config) bool {
+	// The profile, environment or flags may point at another source directory
+	probe := *config
+	if raw, ok := config.Profiles[*flagProfile]; ok {
+		json.Unmarshal(raw, &probe)
+	}
+	applyEnvOverrides(&probe)
+	applyFlagOverrides(&probe)
+	if probe.SourceDir == "" {
+		return true
+	}
+
+	path := filepath.Join(probe.SourceDir, projectConfigFile)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return true
+	}
+
+	var merged Config
+	if err == nil {
+		err = json.Unmarshal(data, &merged)
+	}
+	if err != nil {
+		tprintf("Invalid project config %s: %v\n", path, err)
+		return false
+	}
+	// Paths in the project config are relative to the project itself
+	if merged.JsonSourceDir != "" && !filepath.IsAbs(merged.JsonSourceDir) {
+		merged.JsonSourceDir = filepath.Join(probe.SourceDir, merged.JsonSourceDir)
+	}
+	if merged.ClientConstants != "" && !filepath.IsAbs(merged.ClientConstants) {
+		merged.ClientConstants = filepath.Join(probe.SourceDir, merged.ClientConstants)
+	}
+	for i, root := range merged.ResourceDirs {
+		if !filepath.IsAbs(root.Source) {
+			merged.ResourceDirs[i].Source = filepath.Join(probe.SourceDir, root.Source)
+		}
+	}
+
+	if local, err := readConfigData(); err == nil {
+		if err := json.Unmarshal(local, &merged); err != nil {
+			tprintf("Invalid config file %s: %v\n", findConfigFile(), err)
+			return false
+		}
+	}
+	*config = merged
+	tprintf("Project config: %s\n", path)
+	return true
+}
+
+func validateTarget(config *Config) bool {
+	if remote, ok := parseRemoteTarget(config.TargetDir); ok {
+		if !validateRemoteTargetDir(config, remote) {
+			tprintf("Remote target directory is invalid or unreachable: %s\n", remote)
+			return false
+		}
+	} else if d, ok := parseDockerTarget(config.TargetDir); ok {
+		if !validateDockerTarget(config, d) {
+			tprintf("Docker target is invalid or the container is not running: %s\n", d)
+			return false
+		}
+	} else if share, ok := targetShare(config); ok && !checkShare(config, share) {
+		return false
+	} else if !validateTargetDir(config.TargetDir) {
+		tprintf("Target directory is invalid: %s\n", config.TargetDir)
+		return false
+	}
+	return true
+}
+
+func setupJava(config *Config) bool {
+	if _, err := parseJavaVersion(javaVersionSpec(config)); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return false
+	}
+
+	if *flagJava != "" {
+		config.JavaPath = *flagJava
+	} else if config.JavaPath == "" || !hasJavac(config.JavaPath) || !isWantedJava(config, javaTool(config, "javac")) {
+		config.JavaPath = findJavaPath(config)
+	}
+	if config.JavaPath == "" {
+		tprintf("Java %s not found\n", javaVersionSpec(config))
+		return false
+	}
+
+	major, err := javacMajor(javaTool(config, "javac"))
+	if err != nil {
+		tprintf("⚠️ Warning: Could not determine the JDK version: %v\n", err)
+		tprintf("Java: %s\n", config.JavaPath)
+	} else {
+		if !javaVersionMatches(config, major) {
+			tprintf("⚠️ Warning: Java %d does not match java_version %s\n", major, javaVersionSpec(config))
+		}
+		tprintf("Java %d: %s\n", major, config.JavaPath)
+	}
+	fmt.Println()
+	return true
+}
+
+func validateSourceDir(dir string) bool {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return false
+	}
+
+	for _, srcDir := range sourceRoots(&Config{SourceDir: dir}) {
+		if hasJavaFiles(srcDir) {
+			return true
+		}
+	}
+	return false
+}
+
+func validateTargetDir(dir string) bool {
+	sfsDir := filepath.Join(dir, "SFS2X")
+	if _, err := os.Stat(sfsDir); os.IsNotExist(err) {
+		return false
+	}
+
+	if findLauncher(sfsDir) == "" {
+		return false
+	}
+
+	libDir := filepath.Join(sfsDir, "lib")
+	sfs2xJar := filepath.Join(libDir, "sfs2x.jar")
+	sfs2xCoreJar := filepath.Join(libDir, "sfs2x-core.jar")
+
+	if _, err := os.Stat(sfs2xJar); os.IsNotExist(err) {
+		tprintf("Warning: sfs2x.jar not found at %s\n", sfs2xJar)
+	}
+
+	if _, err := os.Stat(sfs2xCoreJar); os.IsNotExist(err) {
+		tprintf("Warning: sfs2x-core.jar not found at %s\n", sfs2xCoreJar)
+	}
+
+	return true
+}
+
+func hasJavaFiles(dir string) bool {
+	found := false
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if strings.HasSuffix(strings.ToLower(info.Name()), ".java") {
+			found = true
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return found
+}
@@ -0,0 +1,461 @@
+package sfdeploy
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	bundleFile    = "sfdeploy-bundle.json"
+	bundleVersion = 1
+)
+
+// bundlePathKeys are the keys, at any depth, that hold paths or lists of
+// paths. Absolute ones only exist on the exporting machine.
+var bundlePathKeys = map[string]bool{
+	"source_dir": true, "target_dir": true, "targets": true, "json_source_dir": true, "backup_dir": true,
+	"log_file": true, "client_constants": true, "build_output": true, "keystore": true, "jar": true,
+	"dir": true, "source": true, "extra_libs": true, "lib_jars": true, "processor_path": true,
+	"test_classpath": true, "rules": true,
+}
+
+// bundleDroppedKeys are detected on every machine, so they are not exported.
+var bundleDroppedKeys = map[string]bool{"java_path": true, "kotlinc_path": true}
+
+// windowsAbsPath matches C:\ and C:/ paths whatever the OS the bundle is
+// exported on.
+var windowsAbsPath = regexp.MustCompile(`^[A-Za-z]:[\\/]`)
+
+// configBundle is what "config export" writes: the config file with every
+// machine path and plain secret replaced by a ${name} placeholder, and the
+// parameters "config import" asks for.
+type configBundle struct {
+	Version    int               `json:"sfdeploy_bundle"`
+	Config     json.RawMessage   `json:"config"`
+	Parameters []bundleParameter `json:"parameters,omitempty"`
+}
+
+type bundleParameter struct {
+	Name   string `json:"name"` // the key path, e.g. profiles.staging.target_dir
+	Secret bool   `json:"secret,omitempty"`
+}
+
+// isSecretKey reports whether a key holds a secret. Webhook URLs carry the
+// token that lets anyone post to the channel.
+func isSecretKey(key string) bool {
+	return strings.Contains(key, "password") || strings.Contains(key, "secret") ||
+		strings.Contains(key, "token") || key == "storepass" || key == "keypass" || key == "webhook_url"
+}
+
+// secretQueryNames are parts of query parameter names that carry a
+// credential, as in ?access_token= or &sig=.
+var secretQueryNames = []string{"token", "key", "secret", "password", "pass", "sig", "auth", "credential"}
+
+// isSecretURL reports whether value is a URL with a password in its
+// userinfo or a credential in its query, whatever key holds it.
+func isSecretURL(value string) bool {
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return false
+	}
+	if _, ok := u.User.Password(); ok {
+		return true
+	}
+	for name := range u.Query() {
+		name = strings.ToLower(name)
+		for _, part := range secretQueryNames {
+			if strings.Contains(name, part) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isPlainSecret reports whether value, found at key, is a secret written
+// out in the config rather than a secret: reference.
+func isPlainSecret(key, value string) bool {
+	if value == "" || strings.HasPrefix(value, secretPrefix) {
+		return false
+	}
+	return isSecretKey(key) || isSecretURL(value)
+}
+
+// collectSecrets gathers the plain secrets below value, found at the key
+// path name, by their key path.
+func collectSecrets(name, key string, value any, found map[string]string) {
+	switch v := value.(type) {
+	case map[string]any:
+		for k, item := range v {
+			collectSecrets(name+"."+k, k, item, found)
+		}
+	case []any:
+		for i, item := range v {
+			collectSecrets(name+"."+strconv.Itoa(i), key, item, found)
+		}
+	case string:
+		if isPlainSecret(key, v) {
+			found[name] = v
+		}
+	}
+}
+
+// leakedSecrets returns the key paths of the secrets whose value still
+// appears in the exported bundle, also when it was copied under another key
+// or into a longer string such as a hook command.
+func leakedSecrets(secrets map[string]string, bundle []byte) []string {
+	var leaked []string
+	for name, value := range secrets {
+		quoted, _ := json.Marshal(value)
+		needle := quoted
+		// Short values would match by accident inside longer strings
+		if len(value) >= 8 {
+			needle = quoted[1 : len(quoted)-1]
+		}
+		if bytes.Contains(bundle, needle) {
+			leaked = append(leaked, name)
+		}
+	}
+	sort.Strings(leaked)
+	return leaked
+}
+
+// isMachinePath reports whether a path only makes sense on this machine:
+// an absolute local path. Relative paths, remote and Docker targets and
+// network shares are the same for the whole team.
+func isMachinePath(path string) bool {
+	if _, ok := parseShellTarget(path); ok {
+		return false
+	}
+	if _, ok := parseUNC(path); ok {
+		return false
+	}
+	return strings.HasPrefix(path, "/") || strings.HasPrefix(path, "~") || windowsAbsPath.MatchString(path)
+}
+
+// parameterize replaces the machine paths and plain secrets below value,
+// found at the key path name, with placeholders, collecting the parameters.
+func parameterize(name, key string, value any, params *[]bundleParameter) any {
+	placeholder := func(name string, secret bool) string {
+		*params = append(*params, bundleParameter{Name: name, Secret: secret})
+		return "${" + name + "}"
+	}
+	join := func(key string) string {
+		if name == "" {
+			return key
+		}
+		return name + "." + key
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if bundleDroppedKeys[k] {
+				delete(v, k)
+				continue
+			}
+			v[k] = parameterize(join(k), k, v[k], params)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = parameterize(join(strconv.Itoa(i)), key, item, params)
+		}
+	case string:
+		switch {
+		case v == "" || strings.HasPrefix(v, secretPrefix):
+		case isPlainSecret(key, v):
+			return placeholder(name, true)
+		case bundlePathKeys[key] && isMachinePath(v):
+			return placeholder(name, false)
+		}
+	}
+	return value
+}
+
+// configExport implements "config export [file]".
+func configExport() bool {
+	data, err := readConfigData()
+	if err != nil {
+		tprintf("❌ Cannot read %s: %v\n", findConfigFile(), err)
+		return false
+	}
+	keys, values, err := orderedObject(data)
+	if err != nil {
+		tprintf("❌ Cannot read %s: %v\n", findConfigFile(), err)
+		return false
+	}
+
+	var params []bundleParameter
+	secrets := map[string]string{}
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	written := 0
+	for _, key := range keys {
+		if bundleDroppedKeys[key] {
+			tprintf("   - %s (detected on each machine)\n", key)
+			continue
+		}
+		var value any
+		json.Unmarshal(values[key], &value)
+		collectSecrets(key, key, value, secrets)
+		raw, _ := json.Marshal(parameterize(key, key, value, &params))
+		if written > 0 {
+			buf.WriteString(",")
+		}
+		name, _ := json.Marshal(key)
+		fmt.Fprintf(&buf, "%s:%s", name, raw)
+		written++
+	}
+	buf.WriteString("}")
+
+	bundle, _ := json.MarshalIndent(configBundle{Version: bundleVersion, Config: buf.Bytes(), Parameters: params}, "", "  ")
+	path := commandArg(1)
+	if path == "" {
+		path = bundleFile
+	}
+	if leaked := leakedSecrets(secrets, bundle); len(leaked) > 0 {
+		for _, name := range leaked {
+			tprintf("❌ The value of %s would be exported in plain text\n", name)
+		}
+		tprintf("❌ Not writing %s; store it with 'sfdeploy credentials set <name>', refer to it as secret:<name> and export again\n", path)
+		return false
+	}
+	if err := writeFileAtomic(path, append(bundle, '\n'), 0644); err != nil {
+		tprintf("❌ Failed to write %s: %v\n", path, err)
+		return false
+	}
+	for _, param := range params {
+		kind := "path"
+		if param.Secret {
+			kind = "secret"
+		}
+		tprintf("   ? %s (%s, asked for on import)\n", param.Name, kind)
+	}
+	tprintf("✅ Exported %d setting(s) of %s to %s\n", written, findConfigFile(), path)
+	tprintf("💡 Share it with your team; they run: sfdeploy config import %s\n", path)
+	return true
+}
+
+// orderedObject splits a JSON object into its keys, in file order, and their
+// values.
+func orderedObject(data []byte) ([]string, map[string]json.RawMessage, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return nil, nil, fmt.Errorf("not a JSON object")
+	}
+	var keys []string
+	values := map[string]json.RawMessage{}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		key := t.(string)
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, nil, err
+		}
+		if _, ok := values[key]; !ok {
+			keys = append(keys, key)
+		}
+		values[key] = value
+	}
+	return keys, values, nil
+}
+
+// askBundleParameter asks for the value of a placeholder on this machine.
+// Source and server directories offer the recent ones and the SmartFox
+// Server found here.
+func askBundleParameter(reader *bufio.Reader, param bundleParameter) string {
+	// targets.0 is a target_dir too
+	parts := strings.Split(param.Name, ".")
+	key := parts[len(parts)-1]
+	if _, err := strconv.Atoi(key); err == nil && len(parts) > 1 {
+		key = parts[len(parts)-2]
+	}
+	if key == "targets" {
+		key = "target_dir"
+	}
+	label := fmt.Sprintf(tr("Path for %s"), param.Name)
+	if param.Secret {
+		label = fmt.Sprintf(tr("Value of %s"), param.Name)
+	}
+	for _, field := range wizardFields {
+		if field.key == param.Name {
+			label = tr(field.label)
+		}
+	}
+
+	switch {
+	case param.Secret && isTerminal(os.Stdin):
+		value, _ := readHidden(fmt.Sprintf(tr("%s (hidden, Enter to leave out): "), label))
+		return strings.TrimSpace(value)
+	case param.Secret:
+		return promptLine(reader, label, "")
+	case key == "source_dir":
+		def := ""
+		if validateSourceDir(".") {
+			def = "."
+		}
+		return promptRecentDir(reader, key, label, def)
+	case key == "target_dir":
+		return promptRecentDir(reader, key, label, findSmartFoxServer())
+	}
+	return promptPath(reader, label, "")
+}
+
+// fillPlaceholders puts the answers into the placeholders below value. A
+// parameter left empty removes its key or list item; ${...} strings that are
+// not a bundle parameter are config values and stay as they are. Whole numbers become
+// ints again, so YAML and TOML configs get 30 rather than 30.0.
+func fillPlaceholders(value any, answers map[string]string) (any, bool) {
+	switch v := value.(type) {
+	case float64:
+		if v == float64(int64(v)) {
+			return int64(v), true
+		}
+	case map[string]any:
+		for k, item := range v {
+			if filled, ok := fillPlaceholders(item, answers); ok {
+				v[k] = filled
+			} else {
+				delete(v, k)
+			}
+		}
+	case []any:
+		var kept []any
+		for _, item := range v {
+			if filled, ok := fillPlaceholders(item, answers); ok {
+				kept = append(kept, filled)
+			}
+		}
+		return kept, true
+	case string:
+		if name, ok := strings.CutPrefix(v, "${"); ok && strings.HasSuffix(name, "}") {
+			if answer, declared := answers[strings.TrimSuffix(name, "}")]; declared {
+				return answer, answer != ""
+			}
+		}
+	}
+	return value, true
+}
+
+// storeBundleSecrets puts the secret answers into the credential store the
+// imported config will use and answers a secret: reference in their place,
+// so they never reach the config file.
+func storeBundleSecrets(params []bundleParameter, values map[string]json.RawMessage, answers map[string]string) bool {
+	store, _ := readConfigFile()
+	if raw, ok := values["credential_store"]; ok {
+		json.Unmarshal(raw, &store.CredentialStore)
+	}
+	for _, param := range params {
+		value := answers[param.Name]
+		if !param.Secret || value == "" {
+			continue
+		}
+		where, err := setSecret(&store, param.Name, value)
+		if err != nil {
+			tprintf("❌ Failed to store %s: %v\n", param.Name, err)
+			return false
+		}
+		tprintf("🔐 Stored %s in the %s\n", param.Name, storeDescription(where))
+		answers[param.Name] = secretPrefix + param.Name
+	}
+	return true
+}
+
+// configImport implements "config import [file]": it asks for the
+// parameters of a bundle and writes its settings into the config file,
+// creating it if needed. Keys the config has already are replaced, others
+// are kept.
+func configImport() bool {
+	path := commandArg(1)
+	if path == "" {
+		path = bundleFile
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		tprintf("❌ Cannot read %s: %v\n", path, err)
+		return false
+	}
+	var bundle configBundle
+	if err := json.Unmarshal(data, &bundle); err != nil || bundle.Version == 0 {
+		tprintf("❌ %s is not an sfdeploy bundle (made by sfdeploy config export)\n", path)
+		return false
+	}
+	if bundle.Version > bundleVersion {
+		tprintf("❌ %s was exported by a newer sfdeploy (bundle version %d); update sfdeploy first\n", path, bundle.Version)
+		return false
+	}
+	keys, values, err := orderedObject(bundle.Config)
+	if err != nil {
+		tprintf("❌ Invalid config in %s: %v\n", path, err)
+		return false
+	}
+
+	target := findConfigFile()
+	tprintf("📦 Importing %s into %s\n", path, target)
+	// Every parameter has an answer, empty when it is left out
+	answers := map[string]string{}
+	for _, param := range bundle.Parameters {
+		answers[param.Name] = ""
+	}
+	var skipped []string
+	if len(bundle.Parameters) > 0 && !*flagNoPrompt {
+		tprintln("Enter the paths on this machine (press Enter to leave a setting out)")
+		fmt.Println()
+		reader := bufio.NewReader(os.Stdin)
+		for _, param := range bundle.Parameters {
+			answers[param.Name] = askBundleParameter(reader, param)
+		}
+		fmt.Println()
+	}
+	for _, param := range bundle.Parameters {
+		if answers[param.Name] == "" {
+			skipped = append(skipped, param.Name)
+		}
+	}
+	if !storeBundleSecrets(bundle.Parameters, values, answers) {
+		return false
+	}
+
+	dirs := map[string]string{}
+	imported := 0
+	for _, key := range keys {
+		var value any
+		json.Unmarshal(values[key], &value)
+		filled, ok := fillPlaceholders(value, answers)
+		if !ok {
+			continue
+		}
+		if s, isString := filled.(string); isString && recentDirKeys[key] != "" {
+			dirs[key] = s
+		}
+		if err := setConfigKey(target, []string{key}, filled); err != nil {
+			tprintf("❌ Failed to set %s: %v\n", key, err)
+			return false
+		}
+		imported++
+	}
+	rememberDirs(dirs)
+
+	tprintf("✅ Imported %d setting(s) into %s\n", imported, target)
+	if len(skipped) > 0 {
+		tprintf("💡 Left out: %s; set them with sfdeploy config set <key> <value> or SFDEPLOY_* variables\n", strings.Join(skipped, ", "))
+	}
+	tprintln("💡 Check the result with: sfdeploy config validate")
+	return true
+}
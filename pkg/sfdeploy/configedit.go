@@ -0,0 +1,515 @@
+package sfdeploy
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// wizardFields are the keys "config edit" asks for, in order.
+var wizardFields = []struct {
+	key   string
+	label string
+}{
+	{"source_dir", "Source directory (contains src/)"},
+	{"target_dir", "SmartFox Server directory (contains SFS2X/)"},
+	{"extension_folder", "Extension folder name"},
+	{"zone_name", "Zone to register the extension in (optional)"},
+	{"zone_main_class", "Extension main class"},
+	{"json_source_dir", "Folder with the JSON files to deploy"},
+	{"deploy_json_files", "JSON files to deploy, without .json (comma separated)"},
+	{"java_version", "Java version"},
+}
+
+// configKeys turns a dotted key into its path in the config file, nested
+// under the selected profile when --profile is given.
+func configKeys(key string) []string {
+	keys := strings.Split(key, ".")
+	if *flagProfile != "" {
+		keys = append([]string{"profiles", *flagProfile}, keys...)
+	}
+	return keys
+}
+
+// configFieldType finds the Go type behind a dotted key, so values given on
+// the command line are stored with the type the config expects.
+func configFieldType(keys []string) (reflect.Type, error) {
+	if len(keys) > 2 && keys[0] == "profiles" {
+		keys = keys[2:]
+	}
+	t := reflect.TypeOf(Config{})
+	for _, key := range keys {
+		switch t.Kind() {
+		case reflect.Struct:
+			field, ok := fieldByTag(t, key)
+			if !ok {
+				return nil, fmt.Errorf("unknown config key: %s", strings.Join(keys, "."))
+			}
+			t = field.Type
+		case reflect.Map:
+			t = t.Elem()
+		default:
+			return nil, fmt.Errorf("%s has no keys", strings.Join(keys, "."))
+		}
+	}
+	if t == reflect.TypeOf(json.RawMessage{}) {
+		return nil, fmt.Errorf("set the keys of a profile one by one, e.g. --profile %s target_dir", keys[len(keys)-1])
+	}
+	return t, nil
+}
+
+func fieldByTag(t reflect.Type, tag string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		if name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ","); name == tag {
+			return t.Field(i), true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// parseConfigValue converts command-line text to the type of the key. Lists
+// take several arguments or one comma separated argument; maps and objects
+// take JSON.
+func parseConfigValue(t reflect.Type, args []string) (any, error) {
+	raw := strings.Join(args, " ")
+	switch {
+	case t.Kind() == reflect.String:
+		return raw, nil
+	case t.Kind() == reflect.Bool:
+		return strconv.ParseBool(raw)
+	case t.Kind() == reflect.Int:
+		return strconv.Atoi(raw)
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.String && !strings.HasPrefix(raw, "["):
+		if len(args) == 1 {
+			args = strings.Split(raw, ",")
+		}
+		list := []string{}
+		for _, item := range args {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		return list, nil
+	default:
+		var value any
+		if err := json.Unmarshal([]byte(raw), &value); err != nil {
+			return nil, fmt.Errorf("expected a JSON value: %v", err)
+		}
+		return value, nil
+	}
+}
+
+// setConfigKey changes one key of the config file in place, keeping the
+// order of the other keys and, in YAML, their comments. The file is locked
+// from reading it until the new version has replaced it.
+func setConfigKey(path string, keys []string, value any) error {
+	unlock, err := lockConfigFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		data, err = setYAMLKey(data, keys, value)
+	case ".toml":
+		data, err = setTOMLKey(data, keys, value)
+	default:
+		data, err = setJSONKey(data, keys, value)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Make sure the file still loads before replacing it
+	converted, err := configToJSON(path, data)
+	if err == nil {
+		err = json.Unmarshal(converted, &Config{})
+	}
+	if err != nil {
+		return fmt.Errorf("the new value does not fit the config: %v", err)
+	}
+	return writeFileAtomic(path, data, 0644)
+}
+
+func marshalJSONValue(value any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSpace(buf.Bytes()), nil
+}
+
+func setJSONKey(data []byte, keys []string, value any) ([]byte, error) {
+	var keyOrder []string
+	members := map[string]json.RawMessage{}
+
+	if len(bytes.TrimSpace(data)) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+			return nil, errors.New("the config is not a JSON object")
+		}
+		for dec.More() {
+			t, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key := t.(string)
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return nil, err
+			}
+			if _, ok := members[key]; !ok {
+				keyOrder = append(keyOrder, key)
+			}
+			members[key] = raw
+		}
+	}
+
+	var encoded []byte
+	var err error
+	if len(keys) == 1 {
+		encoded, err = marshalJSONValue(value)
+	} else {
+		encoded, err = setJSONKey(members[keys[0]], keys[1:], value)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", keys[0], err)
+	}
+	if _, ok := members[keys[0]]; !ok {
+		keyOrder = append(keyOrder, keys[0])
+	}
+	members[keys[0]] = encoded
+
+	var compact bytes.Buffer
+	compact.WriteByte('{')
+	for i, key := range keyOrder {
+		if i > 0 {
+			compact.WriteByte(',')
+		}
+		name, _ := marshalJSONValue(key)
+		compact.Write(name)
+		compact.WriteByte(':')
+		compact.Write(members[key])
+	}
+	compact.WriteByte('}')
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, compact.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+func setYAMLKey(data []byte, keys []string, value any) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+
+	node := doc.Content[0]
+	for i, key := range keys {
+		if node.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("%s is not a mapping", strings.Join(keys[:i], "."))
+		}
+		var child *yaml.Node
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			if node.Content[j].Value == key {
+				child = node.Content[j+1]
+			}
+		}
+		if child == nil {
+			child = &yaml.Node{Kind: yaml.MappingNode}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, child)
+		}
+		if i == len(keys)-1 {
+			var encoded yaml.Node
+			if err := encoded.Encode(value); err != nil {
+				return nil, err
+			}
+			encoded.HeadComment, encoded.LineComment = child.HeadComment, child.LineComment
+			*child = encoded
+		}
+		node = child
+	}
+
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), enc.Close()
+}
+
+// setTOMLKey rewrites the whole file, since the TOML library keeps neither
+// key order nor comments.
+func setTOMLKey(data []byte, keys []string, value any) ([]byte, error) {
+	generic := map[string]any{}
+	if err := toml.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+
+	table := generic
+	for i, key := range keys[:len(keys)-1] {
+		next, ok := table[key].(map[string]any)
+		if !ok {
+			if _, exists := table[key]; exists {
+				return nil, fmt.Errorf("%s is not a table", strings.Join(keys[:i+1], "."))
+			}
+			next = map[string]any{}
+			table[key] = next
+		}
+		table = next
+	}
+	table[keys[len(keys)-1]] = value
+
+	var out bytes.Buffer
+	if err := toml.NewEncoder(&out).Encode(generic); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// configSet implements "config set <key> <value>".
+func configSet() bool {
+	if len(commandArgs) < 3 {
+		tprintln("Usage: sfdeploy config set <key> <value> (e.g. config set extension_folder MyExtension)")
+		return false
+	}
+	key := commandArgs[1]
+	keys := configKeys(key)
+
+	t, err := configFieldType(keys)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return false
+	}
+	value, err := parseConfigValue(t, commandArgs[2:])
+	if err != nil {
+		tprintf("❌ Invalid value for %s: %v\n", key, err)
+		return false
+	}
+
+	path := findConfigFile()
+	if err := setConfigKey(path, keys, value); err != nil {
+		tprintf("❌ Failed to update %s: %v\n", path, err)
+		return false
+	}
+	shown, _ := marshalJSONValue(value)
+	if *flagProfile != "" {
+		tprintf("✅ Set %s = %s in profile %s of %s\n", key, shown, *flagProfile, path)
+	} else {
+		tprintf("✅ Set %s = %s in %s\n", key, shown, path)
+	}
+	return true
+}
+
+// configEdit implements "config edit": it asks for the main settings with
+// the current values as defaults and writes those that changed, creating
+// the config file if there is none.
+func configEdit() bool {
+	if *flagNoPrompt {
+		tprintln("❌ config edit needs prompts; use config set instead")
+		return false
+	}
+
+	path := findConfigFile()
+	current, err := readConfigFile()
+	exists := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		tprintf("❌ Cannot edit %s: %v\n", path, err)
+		return false
+	}
+	if *flagProfile != "" && exists {
+		if _, ok := current.Profiles[*flagProfile]; ok && !applyProfile(&current, *flagProfile) {
+			return false
+		}
+	}
+
+	if exists {
+		tprintf("✏️ Editing %s (press Enter to keep a value)\n", path)
+	} else {
+		tprintf("✏️ Creating %s\n", path)
+	}
+	if *flagProfile != "" {
+		tprintf("Profile: %s\n", *flagProfile)
+	}
+	fmt.Println()
+
+	reader := bufio.NewReader(os.Stdin)
+	answers := map[string]string{}
+	dirs := map[string]string{}
+	values := reflect.ValueOf(current)
+	var sourceDir, zoneName, mainClass string
+	for _, field := range wizardFields {
+		structField, _ := fieldByTag(values.Type(), field.key)
+		old := formatConfigValue(values.FieldByIndex(structField.Index))
+		def := old
+		switch {
+		case def == "" && field.key == "target_dir":
+			def = findSmartFoxServer()
+		case field.key == "extension_folder" && sourceDir != "" && (def == "" || current.ZoneMainClass == ""):
+			mainClass = pickExtensionClass(reader, sourceDir)
+			if def == "" && mainClass != "" {
+				def = extensionFolderFor(mainClass)
+			} else if def == "" {
+				def = filepath.Base(sourceDir)
+			}
+		case field.key == "zone_main_class" && zoneName == "":
+			// The main class is only written into a zone definition
+			continue
+		case def == "" && field.key == "zone_main_class":
+			def = mainClass
+		}
+
+		for {
+			var answer string
+			switch field.key {
+			case "source_dir", "target_dir":
+				answer = promptRecentDir(reader, field.key, tr(field.label), def)
+			case "json_source_dir":
+				answer = promptPath(reader, tr(field.label), def)
+			default:
+				answer = promptLine(reader, tr(field.label), def)
+			}
+			if confirmWizardAnswer(reader, field.key, answer) {
+				answers[field.key] = answer
+				break
+			}
+		}
+		switch field.key {
+		case "source_dir":
+			sourceDir = answers[field.key]
+			dirs[field.key] = sourceDir
+		case "target_dir":
+			dirs[field.key] = answers[field.key]
+		case "zone_name":
+			zoneName = answers[field.key]
+		}
+		if answers[field.key] == old && (exists || old == "") {
+			delete(answers, field.key)
+		}
+	}
+
+	if len(answers) == 0 {
+		tprintln("No changes")
+		rememberDirs(dirs)
+		return true
+	}
+	for _, field := range wizardFields {
+		answer, ok := answers[field.key]
+		if !ok {
+			continue
+		}
+		keys := configKeys(field.key)
+		t, _ := configFieldType(keys)
+		value, err := parseConfigValue(t, []string{answer})
+		if err == nil {
+			err = setConfigKey(path, keys, value)
+		}
+		if err != nil {
+			tprintf("❌ Failed to set %s: %v\n", field.key, err)
+			return false
+		}
+	}
+	tprintf("✅ Saved %d setting(s) to %s\n", len(answers), path)
+	rememberDirs(dirs)
+	return true
+}
+
+func promptLine(reader *bufio.Reader, label, def string) string {
+	if def != "" {
+		fmt.Printf("%s [%s]: ", label, def)
+	} else {
+		fmt.Printf("%s: ", label)
+	}
+	answer, _ := reader.ReadString('\n')
+	if answer = strings.TrimSpace(answer); answer == "" {
+		return def
+	}
+	return answer
+}
+
+// pickExtensionClass looks for the classes extending SFSExtension in the
+// sources and returns the one the extension is built around, asking which
+// when there are several. It returns "" when there is none.
+func pickExtensionClass(reader *bufio.Reader, sourceDir string) string {
+	classes := findExtensionClasses(sourceDir)
+	switch len(classes) {
+	case 0:
+		return ""
+	case 1:
+		tprintf("🔎 Found extension class %s\n", classes[0].Name)
+		return classes[0].Name
+	}
+
+	tprintln("🔎 Found several classes extending SFSExtension:")
+	for i, class := range classes {
+		rel, err := filepath.Rel(sourceDir, class.File)
+		if err != nil {
+			rel = class.File
+		}
+		fmt.Printf("   %d) %s (%s)\n", i+1, class.Name, rel)
+	}
+	for {
+		answer := promptLine(reader, tr("Extension class"), "1")
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(classes) {
+			return classes[n-1].Name
+		}
+		for _, class := range classes {
+			if answer == class.Name {
+				return answer
+			}
+		}
+		tprintf("Enter a number from 1 to %d\n", len(classes))
+	}
+}
+
+// confirmWizardAnswer checks the directories as the deploy will, letting
+// the user keep a path that does not look right yet.
+func confirmWizardAnswer(reader *bufio.Reader, key, answer string) bool {
+	switch {
+	case key == "source_dir" && answer != "" && !validateSourceDir(answer):
+		tprintf("⚠️ %s has no src folder with .java files\n", answer)
+	case key == "target_dir" && answer != "" && isLocalTarget(&Config{TargetDir: answer}) && !validateTargetDir(answer):
+		tprintf("⚠️ %s does not look like a SmartFox Server folder\n", answer)
+	default:
+		return true
+	}
+	return strings.HasPrefix(strings.ToLower(promptLine(reader, tr("Use it anyway? (y/n)"), "n")), "y")
+}
+
+func formatConfigValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Slice:
+		var items []string
+		for i := 0; i < v.Len(); i++ {
+			items = append(items, fmt.Sprint(v.Index(i).Interface()))
+		}
+		return strings.Join(items, ", ")
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
@@ -0,0 +1,85 @@
+package sfdeploy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	configLockWait  = 10 * time.Second
+	configLockStale = 30 * time.Second
+	configLockPoll  = 50 * time.Millisecond
+)
+
+// lockConfigFile takes the advisory lock of a config file, a <config>.lock
+// file next to it, so two runs changing the config do not overwrite each
+// other's changes. It waits up to configLockWait for another run to finish.
+// A lock older than configLockStale was left behind by a crashed run and is
+// taken over. The returned func releases the lock.
+func lockConfigFile(path string) (func(), error) {
+	lock := path + ".lock"
+	deadline := time.Now().Add(configLockWait)
+	for {
+		f, err := os.OpenFile(lock, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			return func() { os.Remove(lock) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+
+		if info, err := os.Stat(lock); err == nil && time.Since(info.ModTime()) > configLockStale {
+			verbosef("🔓 Removing stale config lock %s\n", lock)
+			os.Remove(lock)
+			continue
+		}
+		if time.Now().After(deadline) {
+			holder, _ := os.ReadFile(lock)
+			return nil, fmt.Errorf("%s is locked by process %s (remove %s if that run crashed)", path, strings.TrimSpace(string(holder)), lock)
+		}
+		time.Sleep(configLockPoll)
+	}
+}
+
+// writeFileAtomic replaces path with data through a temporary file in the
+// same directory, so a run reading the file at the same time sees the old or
+// the new content but never a truncated file. A symlinked path replaces the
+// file it points to, and an existing file keeps its permissions.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp, perm)
+	}
+	if err == nil {
+		// Windows refuses the rename while another process reads the file
+		err = retryLocked(&Config{}, path, func() error { return os.Rename(tmp, path) })
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
@@ -0,0 +1,140 @@
+package sfdeploy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// copyWorkers is the number of files copied at once, set from copy_workers
+// during directory setup.
+var copyWorkers = runtime.NumCPU()
+
+// copyBuffers hands each copy a 1 MB buffer, far fewer reads and writes than
+// the 32 KB io.Copy uses for large JARs.
+var copyBuffers = sync.Pool{New: func() any {
+	buf := make([]byte, 1<<20)
+	return &buf
+}}
+
+// copyJob is one file copied by copyFiles.
+type copyJob struct {
+	src string
+	dst string
+}
+
+func copyFile(src, dst string) error {
+	sourceFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer sourceFile.Close()
+
+	destFile, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	buf := copyBuffers.Get().(*[]byte)
+	defer copyBuffers.Put(buf)
+
+	// Hide ReadFrom and WriteTo so io.CopyBuffer really uses the buffer
+	_, err = io.CopyBuffer(struct{ io.Writer }{destFile}, struct{ io.Reader }{sourceFile}, *buf)
+	if closeErr := destFile.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// copyFiles runs each job on copyWorkers goroutines, with copyFile unless
+// run is given. Every worker keeps going after a failure, so the returned
+// error lists each file that could not be copied.
+func copyFiles(jobs []copyJob, run func(copyJob) error) error {
+	if run == nil {
+		run = func(job copyJob) error { return copyFile(job.src, job.dst) }
+	}
+
+	sizes := make(map[string]int64, len(jobs))
+	var totalBytes int64
+	for _, job := range jobs {
+		if info, err := os.Stat(job.src); err == nil {
+			sizes[job.src] = info.Size()
+			totalBytes += info.Size()
+		}
+	}
+	var copied atomic.Int64
+	p := startProgress("📦 Copying", totalBytes, func(current, total int64) string {
+		return fmt.Sprintf("%d/%d files, %s", copied.Load(), len(jobs), bytesDetail(current, total))
+	})
+	defer p.finish()
+
+	workers := min(max(copyWorkers, 1), len(jobs))
+	queue := make(chan copyJob)
+	errs := make([][]error, workers)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for job := range queue {
+				if err := run(job); err != nil {
+					errs[w] = append(errs[w], err)
+				}
+				copied.Add(1)
+				p.add(sizes[job.src])
+			}
+		}(w)
+	}
+	for _, job := range jobs {
+		queue <- job
+	}
+	close(queue)
+	wg.Wait()
+
+	var all []error
+	for _, workerErrs := range errs {
+		all = append(all, workerErrs...)
+	}
+	return errors.Join(all...)
+}
+
+// copyDir recursively copies src into dst. A missing src results in an empty dst.
+func copyDir(src, dst string) error {
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(src); os.IsNotExist(err) {
+		return nil
+	}
+
+	// Folders are created while walking so the workers only copy files
+	var jobs []copyJob
+	err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		if info.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		jobs = append(jobs, copyJob{path, target})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return copyFiles(jobs, nil)
+}
@@ -0,0 +1,420 @@
+package sfdeploy
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/zalando/go-keyring"
+)
+
+const (
+	keychainService  = "sfdeploy"
+	secretPrefix     = "secret:"
+	passphraseEnv    = envPrefix + "PASSPHRASE"
+	secretIterations = 600000
+)
+
+// credentialsFile is the passphrase-encrypted store used when the OS
+// keychain is unavailable or credential_store is "file".
+func credentialsFile() string {
+	if dir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(dir, "sfdeploy", "credentials.enc")
+	}
+	return filepath.Join(stateDir, "credentials.enc")
+}
+
+// encryptedSecrets is the credentials file: a JSON map of secrets sealed
+// with AES-256-GCM under a PBKDF2 key derived from the passphrase.
+type encryptedSecrets struct {
+	Salt  []byte `json:"salt"`
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+}
+
+// The passphrase is asked for once per run.
+var (
+	secretsSalt []byte
+	secretsKey  []byte
+)
+
+func useKeychain(config *Config) bool {
+	return config.CredentialStore != "file"
+}
+
+// getSecret looks a secret up in the keychain, then in the credentials
+// file, which also holds secrets stored while the keychain was unavailable.
+func getSecret(config *Config, name string) (string, string, error) {
+	if useKeychain(config) {
+		value, err := keyring.Get(keychainService, name)
+		if err == nil {
+			return value, "keychain", nil
+		}
+		if !errors.Is(err, keyring.ErrNotFound) {
+			debugf("🐛 keychain unavailable: %v\n", err)
+		}
+	}
+
+	if !fileExists(credentialsFile()) {
+		return "", "", fmt.Errorf("secret not found: %s (run 'sfdeploy credentials set %s')", name, name)
+	}
+	secrets, err := loadFileSecrets(false)
+	if err != nil {
+		return "", "", err
+	}
+	value, ok := secrets[name]
+	if !ok {
+		return "", "", fmt.Errorf("secret not found: %s (run 'sfdeploy credentials set %s')", name, name)
+	}
+	return value, "file", nil
+}
+
+// setSecret stores a secret and returns where it went.
+func setSecret(config *Config, name, value string) (string, error) {
+	if useKeychain(config) {
+		err := keyring.Set(keychainService, name, value)
+		if err == nil {
+			return "keychain", nil
+		}
+		tprintf("⚠️ OS keychain unavailable (%v), using %s\n", err, credentialsFile())
+	}
+
+	secrets, err := loadFileSecrets(true)
+	if err != nil {
+		return "", err
+	}
+	secrets[name] = value
+	return "file", saveFileSecrets(secrets)
+}
+
+func deleteSecret(config *Config, name string) error {
+	found := false
+	if useKeychain(config) && keyring.Delete(keychainService, name) == nil {
+		found = true
+	}
+	if fileExists(credentialsFile()) {
+		secrets, err := loadFileSecrets(false)
+		if err != nil {
+			return err
+		}
+		if _, ok := secrets[name]; ok {
+			delete(secrets, name)
+			found = true
+			if err := saveFileSecrets(secrets); err != nil {
+				return err
+			}
+		}
+	}
+	if !found {
+		return fmt.Errorf("secret not found: %s", name)
+	}
+	return nil
+}
+
+// secretsPassphrase reads the passphrase from SFDEPLOY_PASSPHRASE or the
+// terminal. A new file asks for it twice.
+func secretsPassphrase(confirm bool) (string, error) {
+	if passphrase := os.Getenv(passphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+	if *flagNoPrompt || !isTerminal(os.Stdin) {
+		return "", fmt.Errorf("the credentials file needs a passphrase: set %s", passphraseEnv)
+	}
+
+	passphrase, err := readHidden(tr("🔑 Credentials passphrase: "))
+	if err != nil || !confirm {
+		return passphrase, err
+	}
+	again, err := readHidden(tr("🔑 Repeat the passphrase: "))
+	if err != nil {
+		return "", err
+	}
+	if again != passphrase {
+		return "", errors.New("the passphrases do not match")
+	}
+	return passphrase, nil
+}
+
+func readHidden(prompt string) (string, error) {
+	fmt.Print(prompt)
+	value, err := term.ReadPassword(os.Stdin.Fd())
+	fmt.Println()
+	return string(value), err
+}
+
+// loadFileSecrets decrypts the credentials file. A missing file is an empty
+// store when create is set.
+func loadFileSecrets(create bool) (map[string]string, error) {
+	secrets := map[string]string{}
+	data, err := os.ReadFile(credentialsFile())
+	if os.IsNotExist(err) && create {
+		if secretsKey == nil {
+			passphrase, err := secretsPassphrase(true)
+			if err != nil {
+				return nil, err
+			}
+			secretsSalt = make([]byte, 16)
+			rand.Read(secretsSalt)
+			if secretsKey, err = pbkdf2.Key(sha256.New, passphrase, secretsSalt, secretIterations, 32); err != nil {
+				return nil, err
+			}
+		}
+		return secrets, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var sealed encryptedSecrets
+	if err := json.Unmarshal(data, &sealed); err != nil {
+		return nil, fmt.Errorf("invalid credentials file %s: %v", credentialsFile(), err)
+	}
+	if secretsKey == nil {
+		passphrase, err := secretsPassphrase(false)
+		if err != nil {
+			return nil, err
+		}
+		if secretsKey, err = pbkdf2.Key(sha256.New, passphrase, sealed.Salt, secretIterations, 32); err != nil {
+			return nil, err
+		}
+		secretsSalt = sealed.Salt
+	}
+
+	gcm, err := secretsCipher()
+	if err != nil {
+		return nil, err
+	}
+	plain, err := gcm.Open(nil, sealed.Nonce, sealed.Data, nil)
+	if err != nil {
+		secretsKey = nil
+		return nil, fmt.Errorf("wrong passphrase or damaged credentials file %s", credentialsFile())
+	}
+	if err := json.Unmarshal(plain, &secrets); err != nil {
+		return nil, err
+	}
+	return secrets, nil
+}
+
+// saveFileSecrets seals the secrets with a fresh nonce and replaces the
+// file, readable by the current user only.
+func saveFileSecrets(secrets map[string]string) error {
+	gcm, err := secretsCipher()
+	if err != nil {
+		return err
+	}
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	rand.Read(nonce)
+
+	data, err := json.MarshalIndent(encryptedSecrets{secretsSalt, nonce, gcm.Seal(nil, nonce, plain, nil)}, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := credentialsFile()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func secretsCipher() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(secretsKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// resolveSecrets replaces every "secret:<name>" string in the config with
+// the stored secret, so any field can keep its value out of the JSON.
+func resolveSecrets(config *Config) error {
+	return walkSecretRefs(reflect.ValueOf(config).Elem(), func(name string) (string, error) {
+		value, store, err := getSecret(config, name)
+		if err == nil {
+			debugf("🐛 secret %s from %s\n", name, store)
+		}
+		return value, err
+	})
+}
+
+// secretRefs lists the secret names the config refers to.
+func secretRefs(config Config) []string {
+	seen := map[string]bool{}
+	walkSecretRefs(reflect.ValueOf(&config).Elem(), func(name string) (string, error) {
+		seen[name] = true
+		return secretPrefix + name, nil
+	})
+	var names []string
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func walkSecretRefs(v reflect.Value, resolve func(name string) (string, error)) error {
+	switch v.Kind() {
+	case reflect.String:
+		if name, ok := strings.CutPrefix(v.String(), secretPrefix); ok && v.CanSet() {
+			value, err := resolve(name)
+			if err != nil {
+				return err
+			}
+			v.SetString(value)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			// Unexported fields such as the .env variables are not config keys
+			if !v.Type().Field(i).IsExported() {
+				continue
+			}
+			if err := walkSecretRefs(v.Field(i), resolve); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := walkSecretRefs(v.Index(i), resolve); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			value := v.MapIndex(key)
+			if value.Kind() == reflect.Interface {
+				value = value.Elem()
+			}
+			if value.Kind() != reflect.String {
+				continue
+			}
+			if name, ok := strings.CutPrefix(value.String(), secretPrefix); ok {
+				resolved, err := resolve(name)
+				if err != nil {
+					return err
+				}
+				v.SetMapIndex(key, reflect.ValueOf(resolved).Convert(v.Type().Elem()))
+			}
+		}
+	}
+	return nil
+}
+
+// credentialsCommand implements "credentials set", "credentials delete" and
+// "credentials list".
+func credentialsCommand(config *Config) bool {
+	// Only credential_store is needed, so a missing config file is fine
+	*config, _ = readConfigFile()
+
+	name := commandArg(1)
+	switch commandArg(0) {
+	case "set":
+		if name == "" {
+			tprintln("Usage: sfdeploy credentials set <name> [value]")
+			return false
+		}
+		value, err := readSecretValue(name)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return false
+		}
+		store, err := setSecret(config, name, value)
+		if err != nil {
+			tprintf("❌ Failed to store %s: %v\n", name, err)
+			return false
+		}
+		tprintf("✅ Stored %s in the %s; refer to it as \"%s%s\"\n", name, storeDescription(store), secretPrefix, name)
+		return true
+
+	case "delete":
+		if name == "" {
+			tprintln("Usage: sfdeploy credentials delete <name>")
+			return false
+		}
+		if err := deleteSecret(config, name); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return false
+		}
+		tprintf("🗑️ Deleted %s\n", name)
+		return true
+
+	case "", "list":
+		names := secretRefs(*config)
+		for _, raw := range config.Profiles {
+			var profile Config
+			if json.Unmarshal(raw, &profile) == nil {
+				names = append(names, secretRefs(profile)...)
+			}
+		}
+		if len(names) == 0 {
+			tprintf("No %s<name> references in %s\n", secretPrefix, findConfigFile())
+			return true
+		}
+		ok := true
+		seen := map[string]bool{}
+		for _, name := range names {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			if _, store, err := getSecret(config, name); err != nil {
+				fmt.Printf("❌ %s: %v\n", name, err)
+				ok = false
+			} else {
+				fmt.Printf("✅ %s (%s)\n", name, storeDescription(store))
+			}
+		}
+		return ok
+
+	default:
+		tprintf("Unknown credentials command: %s (expected set, delete or list)\n", commandArg(0))
+		return false
+	}
+}
+
+// readSecretValue takes the value from the command line, the terminal
+// without echo, or the first line of piped input.
+func readSecretValue(name string) (string, error) {
+	if value := commandArg(2); value != "" {
+		return value, nil
+	}
+	if isTerminal(os.Stdin) {
+		if *flagNoPrompt {
+			return "", errors.New("no value given")
+		}
+		return readHidden(fmt.Sprintf(tr("Value for %s: "), name))
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if line = strings.TrimRight(line, "\r\n"); line == "" {
+		return "", fmt.Errorf("no value on standard input: %v", err)
+	}
+	return line, nil
+}
+
+func storeDescription(store string) string {
+	if store == "file" {
+		return "credentials file " + credentialsFile()
+	}
+	return "OS keychain"
+}
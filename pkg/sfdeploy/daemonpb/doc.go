@@ -0,0 +1,6 @@
+// Package daemonpb is the gRPC service of "sfdeploy serve" and its
+// generated client. Connect with NewDaemonClient to the grpc_addr of the
+// daemon.
+package daemonpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative daemon.proto
@@ -0,0 +1,125 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// targetHashes returns the SHA-256 of the file currently deployed at each
+// item's target. Files that do not exist yet are left out.
+func targetHashes(config *Config, items []deployItem) map[string]string {
+	hashes := map[string]string{}
+
+	if target, ok := parseShellTarget(config.TargetDir); ok {
+		files := make([]string, len(items))
+		for i, item := range items {
+			files[i] = shellQuote(item.Target)
+		}
+		// sha256sum exits non-zero when some files are missing but still
+		// prints the others
+		script := "cd " + shellQuote(target.path("SFS2X", "extensions")) + " && sha256sum -- " + strings.Join(files, " ") + " 2>/dev/null"
+		output, _ := target.run(config, script)
+		for _, line := range strings.Split(string(output), "\n") {
+			hash, name, ok := strings.Cut(strings.TrimSpace(line), " ")
+			if ok && len(hash) == 64 {
+				hashes[strings.TrimPrefix(strings.TrimSpace(name), "*")] = hash
+			}
+		}
+		return hashes
+	}
+
+	for _, item := range items {
+		if hash, err := hashFile(filepath.Join(extensionsDir(config), filepath.FromSlash(item.Target))); err == nil {
+			hashes[item.Target] = hash
+		}
+	}
+	return hashes
+}
+
+// splitUnchanged separates the items whose target already holds identical
+// content, so only changed files are copied.
+func splitUnchanged(config *Config, items []deployItem) (changed, unchanged []deployItem) {
+	hashes := targetHashes(config, items)
+	for _, item := range items {
+		hash, err := hashFile(item.Source)
+		if err == nil && hashes[item.Target] == hash {
+			unchanged = append(unchanged, item)
+		} else {
+			changed = append(changed, item)
+		}
+	}
+	return changed, unchanged
+}
+
+// obsoleteJars returns the JARs in the extension folder that are not kept
+// as unchanged items, i.e. the ones the deploy replaces or removes.
+func obsoleteJars(config *Config, existing []string, unchanged []deployItem) []string {
+	keep := map[string]bool{}
+	for _, item := range unchanged {
+		keep[item.Target] = true
+	}
+
+	var obsolete []string
+	for _, name := range existing {
+		if strings.HasSuffix(strings.ToLower(name), ".jar") && !keep[config.ExtensionFolder+"/"+name] {
+			obsolete = append(obsolete, name)
+		}
+	}
+	return obsolete
+}
+
+func printUnchanged(unchanged []deployItem) {
+	for _, item := range unchanged {
+		verbosef("   ⏭️ Unchanged: %s\n", item.Target)
+	}
+}
+
+// printCopySummary counts the files a deploy copied and their size; the
+// files themselves are listed with --verbose.
+func printCopySummary(verb string, changed, unchanged []deployItem) {
+	var size int64
+	for _, item := range changed {
+		if info, err := os.Stat(item.Source); err == nil {
+			size += info.Size()
+		}
+	}
+	if verb == "Uploaded" {
+		tprintf("   ✅ Uploaded %d files (%s), %d unchanged\n", len(changed), formatSize(size), len(unchanged))
+	} else {
+		tprintf("   ✅ Copied %d files (%s), %d unchanged\n", len(changed), formatSize(size), len(unchanged))
+	}
+}
+
+var sizeUnits = []string{"B", "KB", "MB", "GB"}
+
+// formatSize prints a byte count the way copy summaries show it, e.g.
+// "14.2 KB".
+func formatSize(n int64) string {
+	if n < 1024 {
+		return fmt.Sprintf("%d B", n)
+	}
+	size, unit := float64(n), 0
+	for size >= 1024 && unit < len(sizeUnits)-1 {
+		size /= 1024
+		unit++
+	}
+	return fmt.Sprintf("%.1f %s", size, sizeUnits[unit])
+}
+
+// parseSize reads a size printed by formatSize back into bytes.
+func parseSize(value, unit string) int64 {
+	size, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0
+	}
+	for _, u := range sizeUnits {
+		if u == unit {
+			return int64(size)
+		}
+		size *= 1024
+	}
+	return 0
+}
@@ -0,0 +1,195 @@
+package sfdeploy
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const defaultMavenRepository = "https://repo1.maven.org/maven2"
+
+var httpClient = &http.Client{Timeout: 60 * time.Second}
+
+// mavenCoordinate is a group:artifact:version dependency declaration.
+type mavenCoordinate struct {
+	Group    string
+	Artifact string
+	Version  string
+}
+
+func parseCoordinate(s string) (mavenCoordinate, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return mavenCoordinate{}, fmt.Errorf("invalid dependency %q (expected group:artifact:version)", s)
+	}
+	return mavenCoordinate{Group: parts[0], Artifact: parts[1], Version: parts[2]}, nil
+}
+
+func (c mavenCoordinate) String() string {
+	return c.Group + ":" + c.Artifact + ":" + c.Version
+}
+
+func (c mavenCoordinate) fileName() string {
+	return c.Artifact + "-" + c.Version + ".jar"
+}
+
+// repoPath is the standard Maven repository layout for the JAR.
+func (c mavenCoordinate) repoPath() string {
+	return path.Join(strings.ReplaceAll(c.Group, ".", "/"), c.Artifact, c.Version, c.fileName())
+}
+
+func mavenRepository(config *Config) string {
+	if config.MavenRepository != "" {
+		return strings.TrimSuffix(config.MavenRepository, "/")
+	}
+	return defaultMavenRepository
+}
+
+// dependencyCacheDir is shared by every project on the machine, so each
+// artifact is only downloaded once.
+func dependencyCacheDir() string {
+	if dir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(dir, "sfdeploy", "maven")
+	}
+	return filepath.Join(stateDir, "maven")
+}
+
+func dependencyJarPath(c mavenCoordinate) string {
+	return filepath.Join(dependencyCacheDir(), filepath.FromSlash(c.repoPath()))
+}
+
+// dependencyJars returns the cached JAR paths of the configured dependencies.
+// Dependencies that have not been downloaded yet are skipped.
+func dependencyJars(config *Config) []string {
+	var jars []string
+	for _, dep := range config.Dependencies {
+		c, err := parseCoordinate(dep)
+		if err != nil {
+			continue
+		}
+		if jar := dependencyJarPath(c); fileExists(jar) {
+			jars = append(jars, jar)
+		}
+	}
+	return jars
+}
+
+// resolveDependencies downloads any configured dependency that is not in the
+// cache yet, verifying it against the repository's SHA-1 checksum.
+func resolveDependencies(config *Config) bool {
+	if len(config.Dependencies) == 0 {
+		return true
+	}
+
+	tprintf("Resolving %d dependencies...\n", len(config.Dependencies))
+	for _, dep := range config.Dependencies {
+		c, err := parseCoordinate(dep)
+		if err != nil {
+			fmt.Println(err)
+			return false
+		}
+
+		jar := dependencyJarPath(c)
+		if fileExists(jar) {
+			continue
+		}
+
+		if *flagDryRun {
+			tprintf("[dry-run] Would download %s\n", c)
+			continue
+		}
+
+		url := mavenRepository(config) + "/" + c.repoPath()
+		tprintf("📥 Downloading %s...\n", c)
+		if err := downloadVerified(url, jar); err != nil {
+			tprintf("❌ Failed to download %s: %v\n", c, err)
+			return false
+		}
+	}
+
+	return true
+}
+
+func downloadVerified(url, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	tmp := dst + ".part"
+	defer os.Remove(tmp)
+
+	if err := downloadFile(url, tmp); err != nil {
+		return err
+	}
+
+	if expected, err := fetchText(url + ".sha1"); err != nil {
+		tprintf("⚠️ Warning: No checksum available for %s\n", filepath.Base(dst))
+	} else {
+		actual, err := sha1File(tmp)
+		if err != nil {
+			return err
+		}
+		if fields := strings.Fields(expected); len(fields) == 0 || !strings.EqualFold(fields[0], actual) {
+			return fmt.Errorf("checksum mismatch")
+		}
+	}
+
+	return os.Rename(tmp, dst)
+}
+
+func downloadFile(url, dst string) error {
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+
+	file, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(file, resp.Body)
+	return err
+}
+
+func fetchText(url string) (string, error) {
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return string(data), err
+}
+
+func sha1File(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha1.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,293 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// deployItem is one file to deploy. Target is slash separated and relative
+// to the SFS2X extensions directory so it applies to local and remote targets.
+type deployItem struct {
+	Source string
+	Target string
+}
+
+func extensionsDir(config *Config) string {
+	return filepath.Join(config.TargetDir, "SFS2X", "extensions")
+}
+
+func extensionDir(config *Config) string {
+	return filepath.Join(extensionsDir(config), config.ExtensionFolder)
+}
+
+func libDir(config *Config) string {
+	return filepath.Join(extensionsDir(config), "__lib__")
+}
+
+// deployItems lists the common JAR, extension JAR, dependency and lib JARs, JSON
+// files and resource files to deploy, followed by the deploy manifest.
+// Missing JSON files are reported and skipped. With json_templates, JSON
+// files are rendered first and the rendered copy is deployed.
+func deployItems(config *Config) ([]deployItem, error) {
+	var items []deployItem
+	data := newTemplateData(config)
+
+	// Common JAR goes to the shared __lib__ folder
+	if config.CommonFile != "" {
+		items = append(items, deployItem{
+			Source: filepath.Join(config.SourceDir, config.CommonFile),
+			Target: "__lib__/" + config.CommonFile,
+		})
+	}
+
+	items = append(items, deployItem{
+		Source: filepath.Join(config.SourceDir, config.ExtensionFile),
+		Target: config.ExtensionFolder + "/" + config.ExtensionFile,
+	})
+
+	// Third-party dependencies go to the extension's own __lib__ folder
+	jars := append(dependencyJars(config), libJars(config)...)
+	if stdlib := kotlinStdlibCopy(config); fileExists(stdlib) && len(projectKotlinFiles(config)) > 0 {
+		jars = append(jars, stdlib)
+	}
+	for _, jar := range jars {
+		items = append(items, deployItem{
+			Source: jar,
+			Target: config.ExtensionFolder + "/__lib__/" + filepath.Base(jar),
+		})
+	}
+
+	for _, jsonFile := range config.DeployJsonFiles {
+		jsonFileName := jsonFile + ".json"
+		sourceJson := filepath.Join(config.JsonSourceDir, jsonFileName)
+
+		if _, err := os.Stat(sourceJson); os.IsNotExist(err) {
+			tprintf("⚠️ Warning: JSON file not found: %s\n", jsonFileName)
+			continue
+		}
+		if sourceIgnore(config).skip(sourceJson, false) {
+			tprintf("⏭️ Skipping %s, excluded by %s\n", jsonFileName, ignoreFile)
+			continue
+		}
+
+		if config.JsonTemplates {
+			rendered, err := renderJSONTemplate(config, sourceJson, jsonFileName, data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to render %s: %v", jsonFileName, err)
+			}
+			sourceJson = rendered
+		}
+
+		items = append(items, deployItem{
+			Source: sourceJson,
+			Target: config.ExtensionFolder + "/" + jsonFileName,
+		})
+	}
+
+	items, err := appendResources(config, items, data)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := writeManifest(config, items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write %s: %v", manifestFile, err)
+	}
+	return append(items, manifest), nil
+}
+
+func deployProject(config *Config) bool {
+	tprintln("🚀 Phase 4: Deploying Project")
+
+	if !resolveDependencies(config) {
+		return false
+	}
+
+	extensions := extensionConfigs(config)
+	if *flagDryRun {
+		if !runHooks(config, "pre", config.PreDeployHooks) {
+			return false
+		}
+		for i := range extensions {
+			if !planDeploy(&extensions[i]) {
+				return false
+			}
+		}
+		return patchZone(config)
+	}
+
+	// Rendering templates can fail, so do it before the server is stopped
+	items := make([][]deployItem, len(extensions))
+	for i := range extensions {
+		var err error
+		if items[i], err = deployItems(&extensions[i]); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return false
+		}
+	}
+
+	if !runHooks(config, "pre", config.PreDeployHooks) {
+		return false
+	}
+
+	if isLocalTarget(config) {
+		if reason := keepRunningReason(config); reason != "" {
+			tprintf("⏭️ %s, leaving the server running\n", reason)
+		} else {
+			stopLocalServer(config)
+		}
+	}
+
+	for i := range extensions {
+		if len(extensions) > 1 {
+			tprintf("🧩 Extension %d/%d: %s\n", i+1, len(extensions), extensions[i].ExtensionFolder)
+		}
+		if !deployExtension(&extensions[i], items[i]) {
+			return false
+		}
+	}
+	return patchZone(config)
+}
+
+// deployExtension copies one extension folder's items to the target.
+func deployExtension(config *Config, items []deployItem) bool {
+	if remote, ok := parseRemoteTarget(config.TargetDir); ok {
+		return deployRemote(config, remote, items)
+	}
+
+	if d, ok := parseDockerTarget(config.TargetDir); ok {
+		return deployDocker(config, d, items)
+	}
+
+	targetExtDir := extensionDir(config)
+
+	if err := os.MkdirAll(targetExtDir, 0755); err != nil {
+		tprintf("❌ Failed to create target directory: %v\n", err)
+		return false
+	}
+
+	tprintf("📁 Deploying to: %s\n", targetExtDir)
+
+	tprintln("📸 Saving snapshot of current deployment...")
+	if err := snapshotExtension(config, "before deploy"); err != nil {
+		tprintf("❌ Failed to snapshot current deployment: %v\n", err)
+		return false
+	}
+	if err := pruneHistory(config); err != nil {
+		tprintf("⚠️ Warning: Could not prune deploy history: %v\n", err)
+	}
+
+	changed, unchanged := splitUnchanged(config, items)
+
+	staging, err := stageLocal(config)
+	if err != nil {
+		tprintf("❌ Failed to prepare staging folder: %v\n", err)
+		return false
+	}
+
+	tprintln("🗑️ Removing old JAR files...")
+	for _, name := range obsoleteJars(config, localFiles(staging), unchanged) {
+		if err := os.Remove(filepath.Join(staging, name)); err != nil {
+			tprintf("⚠️ Warning: Could not remove %s: %v\n", name, err)
+		}
+	}
+
+	for _, name := range staleLibJars(config, items, localLibJars(config)) {
+		path := filepath.Join(staging, "__lib__", name)
+		if err := retryLocked(config, path, func() error { return os.Remove(path) }); err != nil {
+			tprintf("⚠️ Warning: Could not remove %s: %v\n", name, err)
+			continue
+		}
+		tprintf("   🗑️ Removed older version: __lib__/%s\n", name)
+	}
+
+	tprintf("Copying files into %s...\n", stagingFolder(config))
+	printUnchanged(unchanged)
+	jobs := make([]copyJob, 0, len(changed))
+	targets := map[string]string{}
+	for _, item := range changed {
+		target := filepath.Join(extensionsDir(config), filepath.FromSlash(stagedTarget(config, item.Target)))
+
+		dir := filepath.Dir(target)
+		if err := retryLocked(config, dir, func() error { return os.MkdirAll(dir, 0755) }); err != nil {
+			tprintf("❌ Failed to create %s: %v\n", filepath.Dir(target), err)
+			os.RemoveAll(staging)
+			return false
+		}
+		jobs = append(jobs, copyJob{item.Source, target})
+		targets[target] = item.Target
+	}
+
+	err = copyFiles(jobs, func(job copyJob) error {
+		if err := retryLocked(config, job.dst, func() error { return copyFile(job.src, job.dst) }); err != nil {
+			return fmt.Errorf("%s: %w", filepath.Base(job.src), err)
+		}
+		verbosef("   ✅ Copied: %s -> %s\n", filepath.Base(job.src), targets[job.dst])
+		return nil
+	})
+	if err != nil {
+		tprintln("❌ Failed to copy files:")
+		fmt.Printf("   %s\n", strings.ReplaceAll(err.Error(), "\n", "\n   "))
+		os.RemoveAll(staging)
+		return false
+	}
+	printCopySummary("Copied", changed, unchanged)
+
+	tprintln("🔁 Swapping in the new extension folder...")
+	if err := swapLocal(config); err != nil {
+		tprintf("❌ Failed to swap in %s: %v\n", stagingFolder(config), err)
+		return false
+	}
+
+	tprintln("✅ Deployment successful")
+	fmt.Println()
+
+	return true
+}
+
+func cleanupProject(config *Config) bool {
+	tprintln("🧹 Phase 7: Cleaning Up Project")
+
+	if *flagDryRun {
+		return planCleanup(config)
+	}
+
+	tprintln("🗑️ Removing .class files from source directory...")
+	classFilesRemoved := 0
+	for _, srcDir := range sourceRoots(config) {
+		filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return nil
+			}
+			if filepath.Ext(info.Name()) == ".class" {
+				if err := os.Remove(path); err == nil {
+					classFilesRemoved++
+				}
+			}
+			return nil
+		})
+	}
+
+	tprintf("🗑️ Removed %d .class files\n", classFilesRemoved)
+
+	tprintln("🗑️ Removing JAR files from project root...")
+	jarFilesRemoved := 0
+	jarFiles, _ := filepath.Glob(filepath.Join(config.SourceDir, "*.jar"))
+	for _, file := range jarFiles {
+		if err := os.Remove(file); err == nil {
+			jarFilesRemoved++
+			verbosef("   Removed: %s\n", filepath.Base(file))
+		} else {
+			tprintf("⚠️ Warning: Could not remove %s: %v\n", filepath.Base(file), err)
+		}
+	}
+
+	tprintf("🗑️ Removed %d JAR files\n", jarFilesRemoved)
+
+	tprintln("✅ Project cleanup completed")
+	fmt.Println()
+
+	return true
+}
@@ -0,0 +1,152 @@
+package sfdeploy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// deployLockFile sits in the SFS2X folder of a target while a run deploys
+// to or restarts it.
+const deployLockFile = ".sfdeploy.lock"
+
+type deployLock struct {
+	User    string    `json:"user"`
+	Host    string    `json:"host"`
+	PID     int       `json:"pid"`
+	Command string    `json:"command"`
+	Since   time.Time `json:"since"`
+}
+
+func (l deployLock) String() string {
+	return fmt.Sprintf("%s@%s (%s, pid %d) since %s", l.User, l.Host, l.Command, l.PID, l.Since.Local().Format("2006-01-02 15:04:05"))
+}
+
+// lockedTarget is perTarget with the deploy lock of each server held while
+// its phases run, so two teammates cannot deploy to it at the same time.
+func lockedTarget(phases ...phase) phase {
+	return perTarget(withDeployLock(phases...))
+}
+
+func withDeployLock(phases ...phase) phase {
+	return func(config *Config) bool {
+		if *flagDryRun {
+			tprintf("[dry-run] Would lock %s while deploying\n", config.TargetDir)
+			return runPhases(config, phases)
+		}
+
+		release, ok := acquireDeployLock(config)
+		if !ok {
+			return false
+		}
+		defer release()
+		return runPhases(config, phases)
+	}
+}
+
+// acquireDeployLock creates the lock file, or with --force replaces one that
+// another run holds. The returned func removes it again.
+func acquireDeployLock(config *Config) (func(), bool) {
+	host, _ := os.Hostname()
+	lock := deployLock{
+		User:    deployUser(),
+		Host:    host,
+		PID:     os.Getpid(),
+		Command: strings.TrimSpace("sfdeploy " + strings.Join(commandLine, " ")),
+		Since:   time.Now(),
+	}
+	data, err := json.Marshal(lock)
+	if err != nil {
+		tprintf("❌ Failed to lock %s: %v\n", config.TargetDir, err)
+		return nil, false
+	}
+
+	lockTarget, unlock := createLocalLock, removeLocalLock
+	if target, ok := parseShellTarget(config.TargetDir); ok {
+		lockTarget = func(config *Config, data []byte, force bool) ([]byte, error) {
+			return createShellLock(config, target, data, force)
+		}
+		unlock = func(config *Config) error {
+			_, err := target.run(config, "rm -f "+shellQuote(target.path("SFS2X", deployLockFile)))
+			return err
+		}
+	}
+
+	held, err := lockTarget(config, data, *flagForce)
+	if err != nil {
+		tprintf("❌ Failed to lock %s: %v\n", config.TargetDir, err)
+		return nil, false
+	}
+	if held != nil {
+		var holder deployLock
+		if json.Unmarshal(held, &holder) != nil {
+			holder = deployLock{User: "unknown", Command: strings.TrimSpace(string(held))}
+		}
+		if !*flagForce {
+			tprintf("🔒 %s is locked by %s\n", config.TargetDir, holder)
+			tprintln("   Wait for that run to finish, or pass --force if it crashed and left the lock behind")
+			return nil, false
+		}
+		tprintf("⚠️ Taking over the lock of %s held by %s\n", config.TargetDir, holder)
+	}
+	verbosef("🔒 Locked %s\n", config.TargetDir)
+
+	return func() {
+		if err := unlock(config); err != nil {
+			tprintf("⚠️ Warning: Could not remove the deploy lock of %s: %v\n", config.TargetDir, err)
+		}
+	}, true
+}
+
+// createLocalLock writes the lock file unless it exists, in which case its
+// content is returned. force overwrites it, still returning the old holder.
+func createLocalLock(config *Config, data []byte, force bool) ([]byte, error) {
+	file := filepath.Join(config.TargetDir, "SFS2X", deployLockFile)
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if errors.Is(err, os.ErrExist) {
+		held, _ := os.ReadFile(file)
+		if !force {
+			return held, nil
+		}
+		return held, os.WriteFile(file, data, 0644)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	_, err = f.Write(data)
+	return nil, err
+}
+
+func removeLocalLock(config *Config) error {
+	err := os.Remove(filepath.Join(config.TargetDir, "SFS2X", deployLockFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// createShellLock is createLocalLock for SSH and Docker targets. The shell's
+// noclobber option makes creating the file atomic.
+func createShellLock(config *Config, target shellTarget, data []byte, force bool) ([]byte, error) {
+	file := shellQuote(target.path("SFS2X", deployLockFile))
+	content := shellQuote(string(data))
+	script := "if (set -C; printf '%s' " + content + " > " + file + ") 2>/dev/null; then echo LOCKED; else cat " + file
+	if force {
+		script += "; printf '%s' " + content + " > " + file
+	}
+	script += "; fi"
+
+	output, err := target.run(config, script)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %s", err, strings.TrimSpace(string(output)))
+	}
+	if strings.TrimSpace(string(output)) == "LOCKED" {
+		return nil, nil
+	}
+	return output, nil
+}
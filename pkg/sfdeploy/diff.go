@@ -0,0 +1,207 @@
+package sfdeploy
+
+import (
+	"archive/zip"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// diffProject compares the build output with what is deployed in
+// extensions/<folder>, by content hash, without changing the server.
+func diffProject(config *Config) bool {
+	tprintln("🔍 Comparing Build Output With the Deployed Extension")
+
+	if !resolveDependencies(config) {
+		return false
+	}
+	for _, ext := range extensionConfigs(config) {
+		if !diffExtension(&ext) {
+			return false
+		}
+	}
+	return true
+}
+
+func diffExtension(config *Config) bool {
+	tprintf("📁 %s in %s\n", config.ExtensionFolder, config.TargetDir)
+
+	items, err := deployItems(config)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return false
+	}
+	// The manifest is rewritten by every deploy, so it always differs
+	items = items[:len(items)-1]
+
+	hashes := targetHashes(config, items)
+	var added, changed, removed, unchanged int
+	for _, item := range items {
+		hash, err := hashFile(item.Source)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return false
+		}
+		switch deployed, ok := hashes[item.Target]; {
+		case !ok:
+			fmt.Printf("   + %s\n", item.Target)
+			added++
+		case deployed != hash:
+			var entries []string
+			if strings.HasSuffix(strings.ToLower(item.Target), ".jar") {
+				// A rebuilt JAR differs in its timestamps even when every
+				// class is the same
+				var ok bool
+				if entries, ok = diffJar(config, item); ok && len(entries) == 0 {
+					verbosef("   = %s (rebuilt, same contents)\n", item.Target)
+					unchanged++
+					continue
+				}
+			}
+			fmt.Printf("   ~ %s\n", item.Target)
+			for _, entry := range entries {
+				fmt.Printf("       %s\n", entry)
+			}
+			changed++
+		default:
+			verbosef("   = %s\n", item.Target)
+			unchanged++
+		}
+	}
+
+	for _, name := range removedFiles(config, items) {
+		fmt.Printf("   - %s\n", name)
+		removed++
+	}
+
+	if added+changed+removed == 0 {
+		tprintf("   ✅ Up to date, %d files unchanged\n", unchanged)
+	} else {
+		tprintf("   %d new, %d changed, %d removed, %d unchanged\n", added, changed, removed, unchanged)
+	}
+	fmt.Println()
+	return true
+}
+
+// removedFiles lists the JARs a deploy would delete from the extension
+// folder and its __lib__ folder. Other files are left in place by a deploy.
+func removedFiles(config *Config, items []deployItem) []string {
+	var existing, libJars []string
+	if target, ok := parseShellTarget(config.TargetDir); ok {
+		existing = shellFiles(config, target, target.path("SFS2X", "extensions", config.ExtensionFolder))
+		libJars = shellLibJars(config, target)
+	} else {
+		existing = localFiles(extensionDir(config))
+		libJars = localLibJars(config)
+	}
+
+	incoming := map[string]bool{}
+	for _, item := range items {
+		incoming[item.Target] = true
+	}
+
+	var removed []string
+	for _, name := range existing {
+		target := config.ExtensionFolder + "/" + name
+		if strings.HasSuffix(strings.ToLower(name), ".jar") && !incoming[target] {
+			removed = append(removed, target)
+		}
+	}
+	for _, name := range staleLibJars(config, items, libJars) {
+		removed = append(removed, config.ExtensionFolder+"/__lib__/"+name)
+	}
+	return removed
+}
+
+// diffJar lists the classes and resources that differ between a built JAR
+// and the deployed copy. ok is false when either JAR cannot be read.
+func diffJar(config *Config, item deployItem) (entries []string, ok bool) {
+	deployed := filepath.Join(extensionsDir(config), filepath.FromSlash(item.Target))
+	if target, ok := parseShellTarget(config.TargetDir); ok {
+		tmp, err := os.MkdirTemp("", "sfdeploy-diff")
+		if err != nil {
+			return nil, false
+		}
+		defer os.RemoveAll(tmp)
+		deployed = filepath.Join(tmp, path.Base(item.Target))
+		if err := fetchTargetFile(config, target, target.path("SFS2X", "extensions", item.Target), deployed); err != nil {
+			verbosef("     (could not fetch the deployed JAR: %v)\n", err)
+			return nil, false
+		}
+	}
+
+	built, err := jarEntries(item.Source)
+	if err != nil {
+		return nil, false
+	}
+	old, err := jarEntries(deployed)
+	if err != nil {
+		return nil, false
+	}
+
+	var names []string
+	for name := range built {
+		names = append(names, name)
+	}
+	for name := range old {
+		if _, ok := built[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		b, inBuilt := built[name]
+		o, inOld := old[name]
+		switch {
+		case !inOld:
+			entries = append(entries, "+ "+name)
+		case !inBuilt:
+			entries = append(entries, "- "+name)
+		case b != o:
+			entries = append(entries, "~ "+name)
+		}
+	}
+	return entries, true
+}
+
+type jarEntry struct {
+	crc  uint32
+	size uint64
+}
+
+// jarEntries returns the CRC-32 and size of every file in a JAR. The
+// manifest is left out as the jar tool stamps it.
+func jarEntries(file string) (map[string]jarEntry, error) {
+	r, err := zip.OpenReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	entries := map[string]jarEntry{}
+	for _, f := range r.File {
+		if strings.HasSuffix(f.Name, "/") || f.Name == "META-INF/MANIFEST.MF" {
+			continue
+		}
+		entries[f.Name] = jarEntry{f.CRC32, f.UncompressedSize64}
+	}
+	return entries, nil
+}
+
+// fetchTargetFile copies one file from a remote or container target.
+func fetchTargetFile(config *Config, target shellTarget, src, dst string) error {
+	switch t := target.(type) {
+	case remoteTarget:
+		if output, err := t.sftp(config, []string{fmt.Sprintf("get %s %s", sftpQuote(src), sftpQuote(dst))}); err != nil {
+			return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+		}
+		return nil
+	case dockerTarget:
+		return dockerCopy(t.Container+":"+src, dst)
+	}
+	return fmt.Errorf("unsupported target %s", config.TargetDir)
+}
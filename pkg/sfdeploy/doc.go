@@ -0,0 +1,18 @@
+// Package sfdeploy builds SmartFox Server 2X extensions, deploys them to one
+// or more servers and restarts them. It is the engine of the sfdeploy
+// command.
+//
+// Run executes a command line exactly as the command does. To embed a
+// deploy in other tooling, load a config and run a Pipeline:
+//
+//	sfdeploy.SetOptions(sfdeploy.Options{NoPrompt: true})
+//	config, err := sfdeploy.LoadConfig("sfdeploy_config.json")
+//	if err != nil {
+//		return err
+//	}
+//	return sfdeploy.NewPipeline().Run(config)
+//
+// The stages are the Builder, Deployer and Restarter interfaces, so a
+// caller can replace one, e.g. with a custom build, and keep the others.
+// Progress is printed to standard output as in the CLI.
+package sfdeploy
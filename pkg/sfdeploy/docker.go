@@ -0,0 +1,235 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// defaultDockerSFSDir is where SmartFoxServer_2X lives inside the container
+// when the target does not give a path.
+const defaultDockerSFSDir = "/opt/SmartFoxServer_2X"
+
+// dockerTarget is a target_dir of the form docker://container[/path],
+// deployed with docker cp and restarted with docker restart.
+type dockerTarget struct {
+	Container string
+	Dir       string
+}
+
+func parseDockerTarget(target string) (dockerTarget, bool) {
+	rest, ok := strings.CutPrefix(target, "docker://")
+	if !ok {
+		return dockerTarget{}, false
+	}
+	container, dir, _ := strings.Cut(rest, "/")
+	if container == "" {
+		return dockerTarget{}, false
+	}
+	if dir == "" {
+		return dockerTarget{Container: container, Dir: defaultDockerSFSDir}, true
+	}
+	return dockerTarget{Container: container, Dir: path.Clean("/" + dir)}, true
+}
+
+func (d dockerTarget) String() string {
+	return "docker://" + d.Container + d.Dir
+}
+
+func (d dockerTarget) path(elem ...string) string {
+	return path.Join(append([]string{d.Dir}, elem...)...)
+}
+
+// ref is the container:path form used by docker cp.
+func (d dockerTarget) ref(elem ...string) string {
+	return d.Container + ":" + d.path(elem...)
+}
+
+// run executes a shell script inside the container.
+func (d dockerTarget) run(config *Config, script string) ([]byte, error) {
+	return newCommand("docker", "exec", d.Container, "sh", "-c", script).CombinedOutput()
+}
+
+func dockerCopy(src, dst string) error {
+	if output, err := newCommand("docker", "cp", src, dst).CombinedOutput(); err != nil {
+		return fmt.Errorf("docker cp %s %s: %s", src, dst, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func validateDockerTarget(config *Config, d dockerTarget) bool {
+	output, err := d.run(config, "test -d "+shellQuote(d.extensionsDir()))
+	if err != nil {
+		tprintf("No SFS2X/extensions folder in %s: %s\n", d, strings.TrimSpace(string(output)))
+		return false
+	}
+	return true
+}
+
+func (d dockerTarget) extensionsDir() string {
+	return d.path("SFS2X", "extensions")
+}
+
+// dockerLibDir copies the container's lib and __lib__ JARs once into a local
+// cache to compile against.
+func dockerLibDir(config *Config, d dockerTarget) string {
+	cache := filepath.Join(stateDir, "docker", d.Container, "lib")
+
+	if jars, _ := filepath.Glob(filepath.Join(cache, "*.jar")); len(jars) > 0 {
+		return cache
+	}
+
+	if err := os.MkdirAll(cache, 0755); err != nil {
+		tprintf("⚠️ Warning: Could not create %s: %v\n", cache, err)
+		return cache
+	}
+
+	tprintf("📥 Fetching server libraries from container %s...\n", d.Container)
+	if err := dockerCopy(d.ref("SFS2X", "lib")+"/.", cache); err != nil {
+		tprintf("⚠️ Warning: Could not fetch server libraries: %v\n", err)
+	}
+	for _, lib := range []string{d.path("SFS2X", "extensions", "__lib__"), d.path("SFS2X", "extensions", config.ExtensionFolder, "__lib__")} {
+		if _, err := d.run(config, "test -d "+shellQuote(lib)); err == nil {
+			dockerCopy(d.Container+":"+lib+"/.", cache)
+		}
+	}
+	return cache
+}
+
+func deployDocker(config *Config, d dockerTarget, items []deployItem) bool {
+	extDir := d.path("SFS2X", "extensions", config.ExtensionFolder)
+	tprintf("📁 Deploying to: %s\n", d.Container+":"+extDir)
+
+	tprintln("📸 Saving snapshot of current deployment...")
+	if err := snapshotExtension(config, "before deploy"); err != nil {
+		tprintf("❌ Failed to snapshot current deployment: %v\n", err)
+		return false
+	}
+	if err := pruneHistory(config); err != nil {
+		tprintf("⚠️ Warning: Could not prune deploy history: %v\n", err)
+	}
+
+	changed, unchanged := splitUnchanged(config, items)
+
+	stagingDir := extDir + ".staging"
+
+	script := []string{
+		"mkdir -p " + shellQuote(path.Join(d.extensionsDir(), "__lib__")),
+		stageScript(extDir),
+		"mkdir -p " + shellQuote(path.Join(stagingDir, "__lib__")),
+	}
+	for _, name := range obsoleteJars(config, shellFiles(config, d, extDir), unchanged) {
+		script = append(script, "rm -f "+shellQuote(path.Join(stagingDir, name)))
+	}
+	for _, name := range staleLibJars(config, items, shellLibJars(config, d)) {
+		script = append(script, "rm -f "+shellQuote(path.Join(stagingDir, "__lib__", name)))
+	}
+	for _, dir := range stagedSubdirs(config, changed) {
+		script = append(script, "mkdir -p "+shellQuote(path.Join(d.extensionsDir(), dir)))
+	}
+	if output, err := d.run(config, strings.Join(script, " && ")); err != nil {
+		tprintf("❌ Failed to prepare %s: %s\n", stagingDir, strings.TrimSpace(string(output)))
+		return false
+	}
+
+	tprintf("Copying files into the container's %s...\n", stagingFolder(config))
+	printUnchanged(unchanged)
+	for _, item := range changed {
+		if err := dockerCopy(item.Source, d.Container+":"+path.Join(d.extensionsDir(), stagedTarget(config, item.Target))); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			d.run(config, "rm -rf "+shellQuote(stagingDir))
+			return false
+		}
+		verbosef("   ✅ Copied: %s -> %s\n", filepath.Base(item.Source), item.Target)
+	}
+	printCopySummary("Copied", changed, unchanged)
+
+	tprintln("🔁 Swapping in the new extension folder...")
+	if output, err := d.run(config, swapScript(extDir)); err != nil {
+		tprintf("❌ Failed to swap in %s: %s\n", stagingFolder(config), strings.TrimSpace(string(output)))
+		return false
+	}
+
+	tprintln("✅ Deployment successful")
+	fmt.Println()
+
+	return true
+}
+
+// snapshotDocker copies the live extension out of the container into a
+// history entry.
+func snapshotDocker(config *Config, d dockerTarget, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	extDir := d.path("SFS2X", "extensions", config.ExtensionFolder)
+	if _, err := d.run(config, "test -d "+shellQuote(extDir)); err == nil {
+		if err := dockerCopy(d.Container+":"+extDir, filepath.Join(dir, "extension")); err != nil {
+			return err
+		}
+	}
+
+	if config.CommonFile != "" {
+		common := d.path("SFS2X", "extensions", "__lib__", config.CommonFile)
+		if _, err := d.run(config, "test -f "+shellQuote(common)); err == nil {
+			if err := os.MkdirAll(filepath.Join(dir, "__lib__"), 0755); err != nil {
+				return err
+			}
+			if err := dockerCopy(d.Container+":"+common, filepath.Join(dir, "__lib__", config.CommonFile)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return os.MkdirAll(filepath.Join(dir, "extension"), 0755)
+}
+
+// restoreDocker copies a history entry over the live extension in the
+// container.
+func restoreDocker(config *Config, d dockerTarget, entryDir string) error {
+	extDir := d.path("SFS2X", "extensions", config.ExtensionFolder)
+
+	if output, err := d.run(config, "rm -rf "+shellQuote(extDir)); err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	if err := dockerCopy(filepath.Join(entryDir, "extension"), d.Container+":"+extDir); err != nil {
+		return err
+	}
+
+	if config.CommonFile != "" {
+		snapshotCommon := filepath.Join(entryDir, "__lib__", config.CommonFile)
+		if _, err := os.Stat(snapshotCommon); err == nil {
+			return dockerCopy(snapshotCommon, d.ref("SFS2X", "extensions", "__lib__", config.CommonFile))
+		}
+	}
+	return nil
+}
+
+// dockerRestartArgs restarts the container, or only signals it when
+// docker_signal is set.
+func dockerRestartArgs(config *Config, d dockerTarget) []string {
+	if config.DockerSignal != "" {
+		return []string{"kill", "--signal", config.DockerSignal, d.Container}
+	}
+	if config.ShutdownGrace != 0 {
+		return []string{"restart", "--time", strconv.Itoa(int(shutdownGrace(config).Seconds())), d.Container}
+	}
+	return []string{"restart", d.Container}
+}
+
+func restartDocker(config *Config, d dockerTarget) bool {
+	args := dockerRestartArgs(config, d)
+	tprintf("▶️ Running: docker %s\n", strings.Join(args, " "))
+	if output, err := newCommand("docker", args...).CombinedOutput(); err != nil {
+		tprintf("❌ Failed to restart container %s: %s\n", d.Container, strings.TrimSpace(string(output)))
+		return false
+	}
+
+	tprintf("✅ Container %s restarted\n", d.Container)
+	fmt.Println()
+	return true
+}
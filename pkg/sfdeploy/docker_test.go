@@ -0,0 +1,28 @@
+package sfdeploy
+
+import "testing"
+
+func TestParseDockerTarget(t *testing.T) {
+	tests := []struct {
+		target string
+		want   dockerTarget
+		ok     bool
+	}{
+		{"docker://sfs", dockerTarget{Container: "sfs", Dir: defaultDockerSFSDir}, true},
+		{"docker://sfs/", dockerTarget{Container: "sfs", Dir: defaultDockerSFSDir}, true},
+		{"docker://sfs/srv/SmartFoxServer_2X", dockerTarget{Container: "sfs", Dir: "/srv/SmartFoxServer_2X"}, true},
+		{"docker://sfs/srv//sfs/../SFS/", dockerTarget{Container: "sfs", Dir: "/srv/SFS"}, true},
+		{"docker://", dockerTarget{}, false},
+		{"docker:///opt/sfs", dockerTarget{}, false},
+		{"sfs:/opt/sfs", dockerTarget{}, false},
+		{"/opt/SmartFoxServer_2X", dockerTarget{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			got, ok := parseDockerTarget(tt.target)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("parseDockerTarget(%q) = %+v, %v, want %+v, %v", tt.target, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
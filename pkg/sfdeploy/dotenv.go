@@ -0,0 +1,156 @@
+package sfdeploy
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// dotEnvFile holds per-developer variables next to the project config.
+const dotEnvFile = ".env"
+
+var (
+	dotEnvKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+	// ${NAME} or $NAME in unquoted and double-quoted values
+	dotEnvReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}|\$([A-Za-z_][A-Za-z0-9_]*)`)
+)
+
+// parseDotEnv reads KEY=value lines, optionally starting with export.
+// Single-quoted values are taken as is; double-quoted ones understand \n,
+// \t, \" and \\, and like unquoted ones may refer to ${NAME} of the lines
+// above or the environment. An unquoted value ends at " #".
+func parseDotEnv(data []byte) (map[string]string, error) {
+	vars := map[string]string{}
+	expand := func(s string) string {
+		return dotEnvReference.ReplaceAllStringFunc(s, func(ref string) string {
+			name := strings.Trim(ref, "${}")
+			if value, ok := os.LookupEnv(name); ok {
+				return value
+			}
+			return vars[name]
+		})
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if n == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !dotEnvKey.MatchString(key) {
+			return nil, fmt.Errorf("line %d: expected KEY=value", n)
+		}
+		value = strings.TrimSpace(value)
+
+		switch {
+		case strings.HasPrefix(value, "'"):
+			end := strings.Index(value[1:], "'")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: %s has no closing quote", n, key)
+			}
+			value = value[1 : end+1]
+		case strings.HasPrefix(value, `"`):
+			var b strings.Builder
+			closed := false
+			for i := 1; i < len(value); i++ {
+				c := value[i]
+				if c == '"' {
+					closed = true
+					break
+				}
+				if c == '\\' && i+1 < len(value) {
+					i++
+					switch value[i] {
+					case 'n':
+						c = '\n'
+					case 't':
+						c = '\t'
+					case 'r':
+						c = '\r'
+					default:
+						c = value[i]
+					}
+				}
+				b.WriteByte(c)
+			}
+			if !closed {
+				return nil, fmt.Errorf("line %d: %s has no closing quote", n, key)
+			}
+			value = expand(b.String())
+		default:
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = strings.TrimSpace(value[:i])
+			}
+			value = expand(value)
+		}
+		vars[key] = value
+	}
+	return vars, scanner.Err()
+}
+
+// loadDotEnv reads the .env file in the source directory in effect, the
+// folder of .sfdeploy.json, into config. Its variables count as environment
+// variables for the SFDEPLOY_* overrides, templates and hooks, but the real
+// environment always wins, so CI can still override them.
+func loadDotEnv(config *Config) bool {
+	probe := *config
+	applyEnvOverrides(&probe)
+	applyFlagOverrides(&probe)
+	if probe.SourceDir == "" {
+		return true
+	}
+
+	path := filepath.Join(probe.SourceDir, dotEnvFile)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return true
+	}
+	var vars map[string]string
+	if err == nil {
+		vars, err = parseDotEnv(data)
+	}
+	if err != nil {
+		tprintf("Invalid environment file %s: %v\n", path, err)
+		return false
+	}
+	config.dotEnv = vars
+	tprintf("Environment file: %s (%d variables)\n", path, len(vars))
+	return true
+}
+
+// lookupEnv looks a variable up in the environment, then in the .env file.
+func lookupEnv(config *Config, name string) (string, bool) {
+	if value, ok := os.LookupEnv(name); ok {
+		return value, true
+	}
+	value, ok := config.dotEnv[name]
+	return value, ok
+}
+
+// environ is os.Environ with the .env variables the environment does not
+// set, sorted by name.
+func environ(config *Config) []string {
+	env := os.Environ()
+	var names []string
+	for name := range config.dotEnv {
+		if _, ok := os.LookupEnv(name); !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		env = append(env, name+"="+config.dotEnv[name])
+	}
+	return env
+}
@@ -0,0 +1,80 @@
+package sfdeploy
+
+import (
+	"maps"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDotEnv(t *testing.T) {
+	t.Setenv("SFDT_FROM_ENV", "env")
+
+	tests := []struct {
+		name    string
+		data    string
+		want    map[string]string
+		wantErr string
+	}{
+		{"plain, comments and blank lines", "\ufeff# settings\nA=1\n\n  B = two words  \n", map[string]string{"A": "1", "B": "two words"}, ""},
+		{"export and dotted keys", "export SFDEPLOY_HEALTH_TIMEOUT=7\nsmoke.url=http://x\n",
+			map[string]string{"SFDEPLOY_HEALTH_TIMEOUT": "7", "smoke.url": "http://x"}, ""},
+		{"trailing comment", "A=1 # one\nB=a#b\n", map[string]string{"A": "1", "B": "a#b"}, ""},
+		{"single quotes are literal", `A='$HOME \n # x'`, map[string]string{"A": `$HOME \n # x`}, ""},
+		{"double quotes understand escapes", `A="a\tb\n\"c\" \\ # kept"`, map[string]string{"A": "a\tb\n\"c\" \\ # kept"}, ""},
+		{"references to lines above and the environment", "USER_DIR=/home/x\nA=${USER_DIR}/srv\nB=\"$SFDT_FROM_ENV-$MISSING_SFDT\"\n",
+			map[string]string{"USER_DIR": "/home/x", "A": "/home/x/srv", "B": "env-"}, ""},
+		{"the environment wins over the file", "SFDT_FROM_ENV=file\nA=$SFDT_FROM_ENV\n",
+			map[string]string{"SFDT_FROM_ENV": "file", "A": "env"}, ""},
+		{"empty value", "A=\n", map[string]string{"A": ""}, ""},
+		{"no equals sign", "A=1\nJUST_A_WORD\n", nil, "line 2: expected KEY=value"},
+		{"invalid key", "1A=x\n", nil, "line 1: expected KEY=value"},
+		{"unclosed single quote", "A='x\n", nil, "line 1: A has no closing quote"},
+		{"unclosed double quote", "A=\"x\n", nil, "line 1: A has no closing quote"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDotEnv([]byte(tt.data))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseDotEnv() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("parseDotEnv() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// A .env may refer to a stored secret; resolving it must leave the .env
+// variables alone rather than write into the unexported map.
+func TestDotEnvSecretReference(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, dotEnvFile), "SFDEPLOY_ADMIN_PASSWORD=secret:admin\n")
+
+	config := Config{SourceDir: dir}
+	if !loadDotEnv(&config) {
+		t.Fatal("loadDotEnv() failed")
+	}
+	if _, err := applyEnvOverrides(&config); err != nil {
+		t.Fatal(err)
+	}
+	err := walkSecretRefs(reflect.ValueOf(&config).Elem(), func(name string) (string, error) {
+		return "resolved-" + name, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.AdminPassword != "resolved-admin" {
+		t.Errorf("AdminPassword = %q, want %q", config.AdminPassword, "resolved-admin")
+	}
+	if got := config.dotEnv["SFDEPLOY_ADMIN_PASSWORD"]; got != "secret:admin" {
+		t.Errorf(".env variable = %q, want it unchanged", got)
+	}
+}
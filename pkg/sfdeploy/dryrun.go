@@ -0,0 +1,308 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+func planBuild(config *Config) bool {
+	if isMavenProject(config.SourceDir) {
+		tprintf("[dry-run] Would run in %s: %s %s\n",
+			config.SourceDir, mavenCommand(config.SourceDir), strings.Join(mavenArgs(), " "))
+		tprintf("[dry-run] Would copy the JAR from %s to %s\n",
+			buildOutputDir(config, "target"), filepath.Join(config.SourceDir, config.ExtensionFile))
+		fmt.Println()
+		return true
+	}
+
+	if isGradleProject(config.SourceDir) {
+		tprintf("[dry-run] Would run in %s: %s %s\n",
+			config.SourceDir, gradleCommand(config.SourceDir), strings.Join(gradleArgs(config), " "))
+		tprintf("[dry-run] Would copy the JAR from %s to %s\n",
+			buildOutputDir(config, filepath.Join("build", "libs")), filepath.Join(config.SourceDir, config.ExtensionFile))
+		fmt.Println()
+		return true
+	}
+
+	mode, err := packageMode(config)
+	if err != nil {
+		fmt.Println(err)
+		return false
+	}
+
+	roots := sourceRoots(config)
+	javaFiles := projectJavaFiles(config)
+	kotlinFiles := projectKotlinFiles(config)
+	if len(javaFiles) == 0 && len(kotlinFiles) == 0 {
+		tprintln("No Java files found")
+		return false
+	}
+
+	classpath := buildClasspath(config)
+
+	plan, err := planCompile(config, sourceBase(config), javaFiles, classpath)
+	if err != nil {
+		tprintf("Failed to read sources: %v\n", err)
+		return false
+	}
+
+	if plan.Full {
+		tprintf("[dry-run] Would compile all %d Java files:\n", len(plan.Compile))
+	} else {
+		tprintf("[dry-run] Would compile %d of %d Java files:\n", len(plan.Compile), len(javaFiles))
+	}
+	for _, group := range groupByRoot(config, plan.Compile) {
+		if len(roots) > 1 {
+			tprintf("   Module %s:\n", filepath.Base(filepath.Dir(group.dir)))
+		}
+		for _, file := range group.files {
+			fmt.Printf("   %s\n", file)
+		}
+	}
+	if len(kotlinFiles) > 0 {
+		tprintf("[dry-run] Would compile %d Kotlin files with kotlinc into %s\n", len(kotlinFiles), kotlinClassesDir(config))
+	}
+
+	tprintf("[dry-run] Classpath: %s\n", classpath)
+	if options := javacOptions(config); len(options) > 0 {
+		tprintf("[dry-run] javac options: %s\n", strings.Join(options, " "))
+	}
+
+	if mode == packageJar {
+		stage := packageStageDir(config)
+		if config.CommonFile != "" && config.CommonFolder != "" {
+			tprintf("[dry-run] Would create %s from classes and resources in %s\n",
+				filepath.Join(config.SourceDir, config.CommonFile), filepath.Join(stage, config.CommonFolder))
+		}
+		planExtensionJars(config, "classes and resources in "+stage)
+		fmt.Println()
+		return true
+	}
+
+	srcDir := roots[0]
+	if config.CommonFile != "" && config.CommonFolder != "" {
+		tprintf("[dry-run] Would create %s from %s\n",
+			filepath.Join(config.SourceDir, config.CommonFile), filepath.Join(srcDir, config.CommonFolder))
+	}
+	planExtensionJars(config, srcDir)
+	fmt.Println()
+
+	return true
+}
+
+func planExtensionJars(config *Config, from string) {
+	for _, ext := range extensionConfigs(config) {
+		if len(ext.packages) > 0 {
+			tprintf("[dry-run] Would create %s from packages %s of %s\n",
+				filepath.Join(config.SourceDir, ext.ExtensionFile), strings.Join(ext.packages, ", "), from)
+		} else {
+			tprintf("[dry-run] Would create %s from %s\n", filepath.Join(config.SourceDir, ext.ExtensionFile), from)
+		}
+	}
+	if signingEnabled(config) {
+		tprintf("[dry-run] Would sign the JARs with %s from %s\n", config.Signing.Alias, config.Signing.Keystore)
+	}
+}
+
+// planStopServer describes how the deploy phase stops the server.
+func planStopServer(config *Config) {
+	remote, isRemote := parseRemoteTarget(config.TargetDir)
+	_, isDocker := parseDockerTarget(config.TargetDir)
+	share, isShare := targetShare(config)
+	switch {
+	case keepRunningReason(config) != "", isDocker:
+	case isShare && findWindowsService(config) != "":
+		tprintf("[dry-run] Would stop Windows service %s on %s\n", findWindowsService(config), share.Host)
+	case isShare:
+		tprintf("[dry-run] Would leave SmartFox on %s running; %s\n", share.Host, shareRestartHint(share))
+	case useSystemd(config):
+		tprintf("[dry-run] Would run: %s\n", strings.Join(systemctlArgs(config, "stop", config.SystemdUnit), " "))
+	case isRemote:
+		tprintf("[dry-run] Would stop SmartFox on %s\n", remote.Host)
+	case findWindowsService(config) != "":
+		tprintf("[dry-run] Would stop Windows service %s\n", findWindowsService(config))
+	default:
+		tprintf("[dry-run] Would kill processes listening on port %d\n", healthPort(config))
+	}
+}
+
+func planDeploy(config *Config) bool {
+	if remote, ok := parseRemoteTarget(config.TargetDir); ok {
+		planStopServer(config)
+		tprintf("[dry-run] Would snapshot current deployment to %s\n", historyDir(config))
+		items, err := deployItems(config)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return false
+		}
+		changed, unchanged := splitUnchanged(config, items)
+		remoteExtDir := remote.path("SFS2X", "extensions", config.ExtensionFolder)
+		for _, name := range obsoleteJars(config, shellFiles(config, remote, remoteExtDir), unchanged) {
+			tprintf("[dry-run] Would delete: %s:%s\n", remote.Host, path.Join(remoteExtDir, name))
+		}
+		for _, name := range staleLibJars(config, items, shellLibJars(config, remote)) {
+			tprintf("[dry-run] Would delete: %s:%s\n", remote.Host, remote.path("SFS2X", "extensions", config.ExtensionFolder, "__lib__", name))
+		}
+		planUnchanged(unchanged)
+		for _, item := range changed {
+			planCopy(item.Source, remote.Host+":"+path.Join(remote.extensionsDir(), stagedTarget(config, item.Target)))
+		}
+		planSwap(config)
+		fmt.Println()
+		return true
+	}
+
+	if d, ok := parseDockerTarget(config.TargetDir); ok {
+		tprintf("[dry-run] Would snapshot current deployment to %s\n", historyDir(config))
+		items, err := deployItems(config)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return false
+		}
+		changed, unchanged := splitUnchanged(config, items)
+		for _, name := range obsoleteJars(config, shellFiles(config, d, d.path("SFS2X", "extensions", config.ExtensionFolder)), unchanged) {
+			tprintf("[dry-run] Would delete: %s\n", d.ref("SFS2X", "extensions", config.ExtensionFolder, name))
+		}
+		for _, name := range staleLibJars(config, items, shellLibJars(config, d)) {
+			tprintf("[dry-run] Would delete: %s\n", d.ref("SFS2X", "extensions", config.ExtensionFolder, "__lib__", name))
+		}
+		planUnchanged(unchanged)
+		for _, item := range changed {
+			tprintf("[dry-run] Would docker cp: %s -> %s\n", item.Source, d.Container+":"+path.Join(d.extensionsDir(), stagedTarget(config, item.Target)))
+		}
+		planSwap(config)
+		fmt.Println()
+		return true
+	}
+
+	targetExtDir := extensionDir(config)
+
+	tprintf("[dry-run] Would deploy to: %s\n", targetExtDir)
+	planStopServer(config)
+	tprintf("[dry-run] Would snapshot current deployment to %s\n", historyDir(config))
+
+	items, err := deployItems(config)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return false
+	}
+	changed, unchanged := splitUnchanged(config, items)
+	for _, name := range obsoleteJars(config, localFiles(targetExtDir), unchanged) {
+		tprintf("[dry-run] Would delete: %s\n", filepath.Join(targetExtDir, name))
+	}
+	for _, name := range staleLibJars(config, items, localLibJars(config)) {
+		tprintf("[dry-run] Would delete: %s\n", filepath.Join(targetExtDir, "__lib__", name))
+	}
+
+	planUnchanged(unchanged)
+	for _, item := range changed {
+		planCopy(item.Source, filepath.Join(extensionsDir(config), filepath.FromSlash(stagedTarget(config, item.Target))))
+	}
+	planSwap(config)
+	fmt.Println()
+
+	return true
+}
+
+func planCopy(src, dst string) {
+	tprintf("[dry-run] Would copy: %s -> %s\n", src, dst)
+}
+
+func planSwap(config *Config) {
+	tprintf("[dry-run] Would swap %s in for %s\n", stagingFolder(config), config.ExtensionFolder)
+}
+
+func planUnchanged(unchanged []deployItem) {
+	for _, item := range unchanged {
+		tprintf("[dry-run] Would skip unchanged: %s\n", item.Target)
+	}
+}
+
+func planRestart(config *Config) bool {
+	if useAdminRestart(config) {
+		tprintf("[dry-run] Would POST a restart request to %s, falling back to a hard restart on failure\n", adminEndpoint(config))
+	}
+
+	if share, ok := targetShare(config); ok {
+		if service := findWindowsService(config); service != "" {
+			tprintf("[dry-run] Would restart Windows service %s on %s with sc stop and sc start\n", service, share.Host)
+		} else {
+			tprintf("[dry-run] Would fail: SmartFox on %s cannot be restarted from here; %s\n", share.Host, shareRestartHint(share))
+		}
+		fmt.Println()
+		return true
+	}
+
+	if useSystemd(config) {
+		tprintf("[dry-run] Would run: %s\n", strings.Join(systemctlArgs(config, "restart", config.SystemdUnit), " "))
+		tprintf("[dry-run] Would wait up to %s for %s to become active\n", restartTimeout(config), config.SystemdUnit)
+		fmt.Println()
+		return true
+	}
+
+	if remote, ok := parseRemoteTarget(config.TargetDir); ok {
+		tprintf("[dry-run] Would stop SmartFox on %s\n", remote.Host)
+		tprintf("[dry-run] Would run over SSH: cd %s && %s\n", remote.path("SFS2X"), unixStartScript("sfs2x.sh"))
+		fmt.Println()
+		return true
+	}
+
+	if d, ok := parseDockerTarget(config.TargetDir); ok {
+		tprintf("[dry-run] Would run: docker %s\n", strings.Join(dockerRestartArgs(config, d), " "))
+		fmt.Println()
+		return true
+	}
+
+	if runtime.GOOS != "windows" {
+		sfsDir := filepath.Join(config.TargetDir, "SFS2X")
+		tprintf("[dry-run] Would stop the process listening on port %d, giving it up to %s after SIGTERM and %s after SIGKILL to exit\n", healthPort(config), shutdownGrace(config), killWait(config))
+		tprintf("[dry-run] Would check that ports %s are free\n", joinPorts(serverPorts(config), ", "))
+		tprintf("[dry-run] Would run in %s: %s\n", sfsDir, unixStartScript(findLauncher(sfsDir)))
+		fmt.Println()
+		return true
+	}
+
+	if service := findWindowsService(config); service != "" {
+		tprintf("[dry-run] Would restart Windows service %s with sc stop and sc start\n", service)
+		fmt.Println()
+		return true
+	}
+
+	logBat := filepath.Join(config.TargetDir, "sfs_with_logs.bat")
+	startScript := filepath.Join(config.TargetDir, "SFS2X", "sfs2x.bat")
+
+	tprintln("[dry-run] Would close the existing SmartFox CMD window if one is running")
+	tprintf("[dry-run] Would write %s to call %s\n", logBat, startScript)
+	tprintf("[dry-run] Would run: %s\n", strings.Join([]string{"cmd", "/c", "start", "cmd", "/k", logBat}, " "))
+	fmt.Println()
+
+	return true
+}
+
+func planCleanup(config *Config) bool {
+	for _, srcDir := range sourceRoots(config) {
+		filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return nil
+			}
+			if filepath.Ext(info.Name()) == ".class" {
+				tprintf("[dry-run] Would delete: %s\n", path)
+			}
+			return nil
+		})
+	}
+
+	jarFiles, _ := filepath.Glob(filepath.Join(config.SourceDir, "*.jar"))
+	for _, file := range jarFiles {
+		tprintf("[dry-run] Would delete: %s\n", file)
+	}
+
+	tprintln("[dry-run] Compiled classes and JARs created by the build would also be removed")
+	fmt.Println()
+
+	return true
+}
@@ -0,0 +1,238 @@
+package sfdeploy
+
+import (
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultEmailLogLines = 40
+	smtpTimeout          = 30 * time.Second
+)
+
+// emailConfig is the email block of notifications: the SMTP server a
+// summary of each run is mailed through. With tls the connection is TLS
+// from the start (port 465), otherwise STARTTLS is used when the server
+// offers it (port 587).
+type emailConfig struct {
+	Host         string   `json:"smtp_host"`
+	Port         int      `json:"smtp_port"`
+	Username     string   `json:"username"`
+	Password     string   `json:"password"`
+	From         string   `json:"from"`
+	To           []string `json:"to"`
+	TLS          bool     `json:"tls"`
+	OnlyFailures bool     `json:"only_failures"`
+	LogLines     int      `json:"log_lines"`
+}
+
+func emailEnabled(config *Config) bool {
+	return config.Notifications.Email.Host != "" && len(config.Notifications.Email.To) > 0
+}
+
+func smtpAddr(mail emailConfig) string {
+	port := mail.Port
+	if port == 0 {
+		port = 587
+		if mail.TLS {
+			port = 465
+		}
+	}
+	return net.JoinHostPort(mail.Host, strconv.Itoa(port))
+}
+
+func emailFrom(mail emailConfig) string {
+	if mail.From != "" {
+		return mail.From
+	}
+	if strings.Contains(mail.Username, "@") {
+		return mail.Username
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		host = "localhost"
+	}
+	return "sfdeploy@" + host
+}
+
+// emailBody is the summary mailed for a run: the result line, the phases
+// with their durations, the errors printed and the last lines of output.
+func emailBody(config *Config, p notifyPayload, phases []phaseReport, lines []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", p.Message)
+	if p.Profile != "" {
+		fmt.Fprintf(&b, "Profile: %s\n", p.Profile)
+	}
+	if host, err := os.Hostname(); err == nil {
+		fmt.Fprintf(&b, "Host: %s\n", host)
+	}
+	if path := logFilePath(config); !strings.EqualFold(path, "off") {
+		fmt.Fprintf(&b, "Log file: %s\n", absPath(path))
+	}
+
+	if len(phases) > 0 {
+		b.WriteString("\nPhases:\n")
+		for _, phase := range phases {
+			fmt.Fprintf(&b, "  %-40s %6.1fs", phase.Name, phase.Duration)
+			if phase.Files > 0 {
+				fmt.Fprintf(&b, "  %d files", phase.Files)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	var errs []string
+	for _, line := range lines {
+		if logLevel(line) == "error" {
+			errs = append(errs, strings.TrimSpace(line))
+		}
+	}
+	if len(errs) > 0 {
+		b.WriteString("\nErrors:\n")
+		for _, line := range errs {
+			fmt.Fprintf(&b, "  %s\n", line)
+		}
+	}
+
+	keep := config.Notifications.Email.LogLines
+	if keep == 0 {
+		keep = defaultEmailLogLines
+	}
+	if keep > 0 && len(lines) > 0 {
+		if len(lines) > keep {
+			lines = lines[len(lines)-keep:]
+		}
+		fmt.Fprintf(&b, "\nLast %d lines of output:\n", len(lines))
+		for _, line := range lines {
+			fmt.Fprintf(&b, "  %s\n", line)
+		}
+	}
+	return b.String()
+}
+
+// emailMessage formats the mail with its headers and CRLF line endings.
+func emailMessage(from string, to []string, subject, body string) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// sendEmail delivers msg through the SMTP server of mail.
+func sendEmail(mail emailConfig, from string, msg []byte) error {
+	addr := smtpAddr(mail)
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	var conn net.Conn
+	var err error
+	if mail.TLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{ServerName: mail.Host})
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(smtpTimeout))
+
+	c, err := smtp.NewClient(conn, mail.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok && !mail.TLS {
+		if err := c.StartTLS(&tls.Config{ServerName: mail.Host}); err != nil {
+			return err
+		}
+	}
+	if mail.Username != "" {
+		// PlainAuth refuses to send the password over an unencrypted
+		// connection to anything but localhost
+		if err := c.Auth(smtp.PlainAuth("", mail.Username, mail.Password, mail.Host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(from); err != nil {
+		return err
+	}
+	for _, to := range mail.To {
+		if err := c.Rcpt(to); err != nil {
+			return fmt.Errorf("%s: %w", to, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// emailResult mails the summary of a run to notifications.email.to.
+func emailResult(config *Config, name string, success bool, started time.Time) {
+	mail := config.Notifications.Email
+	if success && mail.OnlyFailures {
+		return
+	}
+	// A run that failed before its secrets were resolved cannot log in
+	if strings.HasPrefix(mail.Password, secretPrefix) {
+		return
+	}
+	if *flagDryRun {
+		tprintf("[dry-run] Would email %s through %s\n", strings.Join(mail.To, ", "), smtpAddr(mail))
+		return
+	}
+
+	phases, lines := runReportCollector.since(started)
+	p := newNotifyPayload(config, name, success, started)
+	result := "succeeded"
+	if !success {
+		result = "FAILED"
+	}
+	subject := fmt.Sprintf("[sfdeploy] %s %s: %s -> %s", name, result, p.Extension, p.Target)
+
+	from := emailFrom(mail)
+	msg := emailMessage(from, mail.To, subject, emailBody(config, p, phases, lines))
+	if err := sendEmail(mail, from, msg); err != nil {
+		tprintf("⚠️ Warning: Could not send notification email: %v\n", err)
+		return
+	}
+	tprintf("📧 Emailed the result to %s\n", strings.Join(mail.To, ", "))
+}
+
+// validateEmail checks that the email block can send anything.
+func validateEmail(v *validation, config *Config) {
+	mail := config.Notifications.Email
+	switch {
+	case mail.Host == "" && len(mail.To) == 0:
+		return
+	case mail.Host == "":
+		v.fail("notifications.email.smtp_host is not set")
+	case len(mail.To) == 0:
+		v.fail("notifications.email.to is empty")
+	default:
+		v.pass("Emailing results to %s through %s", strings.Join(mail.To, ", "), smtpAddr(mail))
+	}
+	if mail.Username != "" && mail.Password == "" {
+		v.warn("notifications.email.username is set without a password")
+	}
+}
@@ -0,0 +1,82 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const envPrefix = "SFDEPLOY_"
+
+// envName maps a config JSON key to its environment variable, e.g.
+// source_dir -> SFDEPLOY_SOURCE_DIR.
+func envName(jsonKey string) string {
+	return envPrefix + strings.ToUpper(jsonKey)
+}
+
+// applyEnvOverrides sets every config field whose SFDEPLOY_* variable is
+// defined, in the environment or the .env file. List fields are comma separated. It returns the names applied.
+func applyEnvOverrides(config *Config) ([]string, error) {
+	var applied []string
+
+	v := reflect.ValueOf(config).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		key := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if key == "" || key == "-" {
+			continue
+		}
+
+		name := envName(key)
+		value, ok := lookupEnv(config, name)
+		if !ok {
+			continue
+		}
+
+		field := v.Field(i)
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(value)
+		case reflect.Int:
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return applied, fmt.Errorf("%s: expected a number, got %q", name, value)
+			}
+			field.SetInt(int64(n))
+		case reflect.Bool:
+			b, err := strconv.ParseBool(strings.TrimSpace(value))
+			if err != nil {
+				return applied, fmt.Errorf("%s: expected true or false, got %q", name, value)
+			}
+			field.SetBool(b)
+		case reflect.Slice:
+			if field.Type().Elem().Kind() != reflect.String {
+				continue
+			}
+			var items []string
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			field.Set(reflect.ValueOf(items))
+		default:
+			continue
+		}
+
+		applied = append(applied, name)
+	}
+
+	return applied, nil
+}
+
+func hasEnvOverrides() bool {
+	for _, key := range []string{"source_dir", "target_dir"} {
+		if _, ok := os.LookupEnv(envName(key)); ok {
+			return true
+		}
+	}
+	return false
+}
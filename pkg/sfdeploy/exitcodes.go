@@ -0,0 +1,77 @@
+package sfdeploy
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Exit codes tell scripts which part of a run failed.
+const (
+	exitOK      = 0
+	exitFailure = 1  // failed outside a known phase
+	exitUsage   = 2  // unknown command or invalid flags
+	exitConfig  = 10 // configuration, directories or Java setup
+	exitBuild   = 20
+	exitTest    = 25
+	exitDeploy  = 30 // deploy, rollback, restore and deploy hooks
+	exitRestart = 40
+	exitHealth  = 50
+	exitCleanup = 60
+	exitPhase   = 70 // custom_phases and registered phases
+)
+
+// phaseExitCodes maps each phase to the exit code of its failure. It is
+// filled in init because the phases refer back to the command table.
+var phaseExitCodes = map[uintptr]int{}
+
+func init() {
+	for code, phases := range map[int][]phase{
+		exitConfig:  {setupDirectories, checkDirectories, setupWorkspace, setupJava, configCommand, credentialsCommand},
+		exitBuild:   {buildProject},
+		exitTest:    {runTests},
+		exitDeploy:  {deployProject, postDeployHooks, rollbackDeployment, historyCommand, backupCommand, withDeployLock()},
+		exitRestart: {restartServer},
+		exitHealth:  {checkServerHealth, smokeTest},
+		exitCleanup: {cleanupProject},
+		exitPhase:   {customPhases("build")},
+	} {
+		for _, p := range phases {
+			phaseExitCodes[phaseID(p)] = code
+		}
+	}
+}
+
+func phaseID(p phase) uintptr {
+	return reflect.ValueOf(p).Pointer()
+}
+
+var (
+	failureMu   sync.Mutex
+	failureCode int
+)
+
+// recordFailure notes the exit code for a failed phase. The innermost,
+// earliest failure wins, so a failed deploy inside a cluster run is
+// reported as a deploy failure.
+func recordFailure(p phase) {
+	recordExitCode(phaseExitCodes[phaseID(p)])
+}
+
+// recordExitCode notes code for the run unless an earlier failure did.
+func recordExitCode(code int) {
+	failureMu.Lock()
+	defer failureMu.Unlock()
+	if failureCode == exitOK {
+		failureCode = code
+	}
+}
+
+// exitCode is the code for a failed run.
+func exitCode() int {
+	failureMu.Lock()
+	defer failureMu.Unlock()
+	if failureCode == exitOK {
+		return exitFailure
+	}
+	return failureCode
+}
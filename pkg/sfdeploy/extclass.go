@@ -0,0 +1,57 @@
+package sfdeploy
+
+import (
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// extensionClass is a class of the sources that extends SFSExtension.
+type extensionClass struct {
+	Name string
+	File string
+}
+
+var (
+	// Unlike packagePattern this also matches Kotlin, which has no semicolon
+	sourcePackagePattern = regexp.MustCompile(`(?m)^\s*package\s+([\w.]+)`)
+	// "class Main extends SFSExtension" in Java, "class Main : SFSExtension()"
+	// in Kotlin, with or without the package of SFSExtension
+	extendsPattern = regexp.MustCompile(`\bclass\s+(\w+)(?:\s*\([^)]*\))?\s*(?:extends|:)\s*(?:com\.smartfoxserver\.v2\.extensions\.)?(?:Base)?SFSExtension\b`)
+)
+
+// findExtensionClasses scans the Java and Kotlin sources below src for the
+// classes extending SFSExtension, sorted by name.
+func findExtensionClasses(sourceDir string) []extensionClass {
+	config := &Config{SourceDir: sourceDir}
+	files := append(projectJavaFiles(config), projectKotlinFiles(config)...)
+
+	var classes []extensionClass
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		pkg := ""
+		if m := sourcePackagePattern.FindSubmatch(data); m != nil {
+			pkg = string(m[1]) + "."
+		}
+		for _, m := range extendsPattern.FindAllSubmatch(data, -1) {
+			classes = append(classes, extensionClass{Name: pkg + string(m[1]), File: file})
+		}
+	}
+	sort.Slice(classes, func(i, j int) bool { return classes[i].Name < classes[j].Name })
+	return classes
+}
+
+// extensionFolderFor proposes a folder name for an extension class: its
+// simple name without a trailing "Extension", so com.game.ChessExtension
+// becomes Chess.
+func extensionFolderFor(class string) string {
+	name := class[strings.LastIndex(class, ".")+1:]
+	if folder := strings.TrimSuffix(name, "Extension"); folder != "" {
+		return folder
+	}
+	return name
+}
@@ -0,0 +1,192 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// extensionConfig is one entry of extensions: an extension folder deployed
+// from the same project, e.g. a zone extension next to room extensions.
+type extensionConfig struct {
+	Folder    string         `json:"folder"`
+	File      string         `json:"file"`
+	Packages  []string       `json:"packages"`
+	JsonFiles []string       `json:"deploy_json_files"`
+	Files     []string       `json:"deploy_files"`
+	Resources []resourceRoot `json:"resource_dirs"`
+}
+
+// selectExtensions applies --extension to the extensions list and makes the
+// first entry the project's main extension, which names its build state.
+func selectExtensions(config *Config) bool {
+	if len(config.Extensions) == 0 {
+		return true
+	}
+
+	if *flagExtension != "" {
+		var selected []extensionConfig
+		var names []string
+		for _, ext := range config.Extensions {
+			names = append(names, ext.Folder)
+			if ext.Folder == *flagExtension {
+				selected = append(selected, ext)
+			}
+		}
+		if len(selected) == 0 {
+			tprintf("Extension %s is not in extensions: %s\n", *flagExtension, strings.Join(names, ", "))
+			return false
+		}
+		config.Extensions = selected
+	}
+
+	for _, ext := range config.Extensions {
+		if ext.Folder == "" {
+			tprintln("Every entry of extensions needs a folder")
+			return false
+		}
+	}
+	first := extensionConfigs(config)[0]
+	config.ExtensionFolder, config.ExtensionFile = first.ExtensionFolder, first.ExtensionFile
+	return true
+}
+
+// extensionConfigs returns a config per extension to deploy. Without
+// extensions this is the config itself.
+func extensionConfigs(config *Config) []Config {
+	if len(config.Extensions) == 0 {
+		return []Config{*config}
+	}
+
+	var configs []Config
+	for _, ext := range config.Extensions {
+		c := *config
+		c.Extensions = nil
+		c.ExtensionFolder = ext.Folder
+		c.ExtensionFile = ext.File
+		if c.ExtensionFile == "" {
+			c.ExtensionFile = ext.Folder + ".jar"
+		}
+		c.DeployJsonFiles = ext.JsonFiles
+		c.DeployFiles = ext.Files
+		c.ResourceDirs = ext.Resources
+		c.packages = ext.Packages
+		configs = append(configs, c)
+	}
+	return configs
+}
+
+// eachExtension runs phases once for every extension, e.g. to roll each
+// one back before the server restarts once.
+func eachExtension(phases ...phase) phase {
+	return func(config *Config) bool {
+		configs := extensionConfigs(config)
+		for i := range configs {
+			if len(configs) > 1 {
+				tprintf("🧩 Extension %d/%d: %s\n", i+1, len(configs), configs[i].ExtensionFolder)
+			}
+			if !runPhases(&configs[i], phases) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func packagePath(pkg string) string {
+	return filepath.FromSlash(strings.ReplaceAll(pkg, ".", "/"))
+}
+
+// createExtensionJars builds the JAR of every extension from root, the src
+// folder or the jar mode staging folder. An extension with packages gets
+// only the files under those packages and their subpackages, and jar_files
+// and .sfdeployignore narrow that down further.
+func createExtensionJars(config *Config, root string, withManifest bool) bool {
+	for _, ext := range extensionConfigs(config) {
+		dir := root
+		if len(ext.packages) > 0 {
+			var err error
+			if dir, err = stageExtensionPackages(&ext, root); err != nil {
+				tprintf("Failed to collect the classes of %s: %v\n", ext.ExtensionFolder, err)
+				return false
+			}
+		}
+		if len(ext.JarFiles) > 0 || sourceIgnore(&ext) != nil {
+			var err error
+			if dir, err = stageJarFiles(&ext, dir); err != nil {
+				tprintf("Failed to select the jar_files of %s: %v\n", ext.ExtensionFolder, err)
+				return false
+			}
+		}
+
+		tprintf("Creating %s...\n", ext.ExtensionFile)
+		jarFile := filepath.Join(config.SourceDir, ext.ExtensionFile)
+		if !withManifest {
+			if !createJar(&ext, jarFile, dir, ext.ExtensionFile) {
+				return false
+			}
+			continue
+		}
+
+		manifest := filepath.Join(filepath.Dir(packageStageDir(&ext)), "MANIFEST.MF")
+		if err := writeJarManifest(&ext, manifest); err != nil {
+			tprintf("Failed to write JAR manifest: %v\n", err)
+			return false
+		}
+		if !createJarWithManifest(&ext, jarFile, dir, manifest, ext.ExtensionFile) {
+			return false
+		}
+	}
+	return true
+}
+
+// stageJarFiles copies the files under root that jar_files selects and
+// .sfdeployignore does not exclude into a folder of their own for the jar tool.
+func stageJarFiles(config *Config, root string) (string, error) {
+	matched, err := globFiles(root, config.JarFiles)
+	if err != nil {
+		return "", err
+	}
+	var files []string
+	for _, rel := range matched {
+		if !sourceIgnore(config).skip(filepath.Join(root, filepath.FromSlash(rel)), false) {
+			files = append(files, rel)
+		}
+	}
+	if len(files) == 0 {
+		return "", fmt.Errorf("no files left for the JAR in %s", root)
+	}
+
+	stage := filepath.Join(stateDir, "build", config.ExtensionFolder, "jarfiles")
+	if err := os.RemoveAll(stage); err != nil {
+		return "", err
+	}
+	for _, rel := range files {
+		dst := filepath.Join(stage, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return "", err
+		}
+		if err := copyFile(filepath.Join(root, filepath.FromSlash(rel)), dst); err != nil {
+			return "", err
+		}
+	}
+	return stage, nil
+}
+
+func stageExtensionPackages(config *Config, root string) (string, error) {
+	stage := filepath.Join(stateDir, "build", config.ExtensionFolder, "packages")
+	if err := os.RemoveAll(stage); err != nil {
+		return "", err
+	}
+	for _, pkg := range config.packages {
+		src := filepath.Join(root, packagePath(pkg))
+		if !fileExists(src) {
+			return "", fmt.Errorf("package %s not found in %s", pkg, root)
+		}
+		if err := copyDir(src, filepath.Join(stage, packagePath(pkg))); err != nil {
+			return "", err
+		}
+	}
+	return stage, nil
+}
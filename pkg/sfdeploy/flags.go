@@ -0,0 +1,97 @@
+package sfdeploy
+
+import "flag"
+
+// commandFlags are the command-line flags. Run parses them, and SetOptions
+// sets the ones a library caller needs.
+var commandFlags = flag.NewFlagSet("sfdeploy", flag.ContinueOnError)
+
+var (
+	flagConfig    = commandFlags.String("config", "", "Config file (.json, .yaml, .yml or .toml)")
+	flagProfile   = commandFlags.String("profile", "", "Named profile from the config file to use")
+	flagSource    = commandFlags.String("source", "", "Source project directory (overrides source_dir)")
+	flagTarget    = commandFlags.String("target", "", "SmartFox Server 2X directory (overrides target_dir)")
+	flagExtension = commandFlags.String("extension", "", "Extension folder name (overrides extension_folder, or picks one entry of extensions)")
+	flagJava      = commandFlags.String("java", "", "JDK bin directory (skips auto detection)")
+	flagJVMDebug  = commandFlags.Int("jvm-debug", 0, "Start the server JVM with a JDWP debugger listening on this port (overrides jvm_options.debug_port)")
+	flagAt        = commandFlags.String("at", "", "Wait until this time to run: HH:MM, \"YYYY-MM-DD HH:MM\" or a cron expression like \"30 2 * * 1-5\"")
+	flagHot       = commandFlags.Bool("hot", false, "Replace only the live extension JAR when its classes changed and let SmartFox reload it, without a restart (with all or watch)")
+	flagAll       = commandFlags.Bool("all", false, "Build and deploy every workspace project in dependency order, restarting once (with all, build, test or deploy)")
+	flagForce     = commandFlags.Bool("force", false, "Take over the deploy lock of a target that another run holds")
+	flagNoPrompt  = commandFlags.Bool("no-prompt", false, "Never wait for input; fail instead of prompting")
+	flagNoPause   = commandFlags.Bool("no-pause", false, "Exit right away instead of waiting for Enter at the end")
+	flagSkipTests = commandFlags.Bool("skip-tests", false, "Deploy without running the project's unit tests")
+	flagRebuild   = commandFlags.Bool("rebuild", false, "Recompile every Java file instead of only changed ones")
+	flagDryRun    = commandFlags.Bool("dry-run", false, "Print planned actions without building, copying or restarting")
+	flagQuiet     = commandFlags.Bool("quiet", false, "Print only errors and the final result")
+	flagVerbose   = commandFlags.Bool("verbose", false, "Also print every copied file")
+	flagDebug     = commandFlags.Bool("debug", false, "Also print executed commands and the environment")
+	flagCI        = commandFlags.Bool("ci", false, "CI mode: no prompts or pause, output grouped per phase")
+	flagReport    = commandFlags.String("report", "", "Write a JSON summary of the run (phase durations, files copied, result) to this file")
+	flagLogFormat = commandFlags.String("log-format", "text", "Output format: text, or json for one JSON object per line")
+	flagNoColor   = commandFlags.Bool("no-color", false, "Print without colors (also when NO_COLOR is set or the output is not a terminal)")
+)
+
+// These flags run part of the pipeline, see pipelinePhases.
+var (
+	flagSkipRestart = commandFlags.Bool("skip-restart", false, "Build and deploy, leaving the running server alone (with all, watch or deploy)")
+	flagRestartOnly = commandFlags.Bool("restart-only", false, "Only restart the server, without building or deploying (with all)")
+)
+
+// commandLine is the command line given to Run, and commandArgs holds the
+// positional arguments that follow the command name.
+var (
+	commandLine []string
+	commandArgs []string
+)
+
+// parseArgs parses flags wherever they appear on the command line, not only
+// before the first positional argument, and returns the positional arguments.
+func parseArgs(args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := commandFlags.Parse(args); err != nil {
+			return nil, err
+		}
+		args = commandFlags.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func commandArg(i int) string {
+	if i < len(commandArgs) {
+		return commandArgs[i]
+	}
+	return ""
+}
+
+func hasFlagOverrides() bool {
+	return *flagSource != "" || *flagTarget != "" || *flagExtension != ""
+}
+
+func applyFlagOverrides(config *Config) {
+	if *flagSource != "" {
+		config.SourceDir = *flagSource
+	}
+
+	// An explicit --target deploys to that one server only
+	if *flagTarget != "" {
+		config.TargetDir = *flagTarget
+		config.Targets = nil
+	}
+
+	if *flagExtension != "" {
+		config.ExtensionFolder = *flagExtension
+		if config.ExtensionFile == "" {
+			config.ExtensionFile = *flagExtension + ".jar"
+		}
+	}
+
+	if *flagJVMDebug > 0 {
+		config.JVMOptions.DebugPort = *flagJVMDebug
+	}
+}
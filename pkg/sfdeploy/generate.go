@@ -0,0 +1,270 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// handlerData is what the template of a generated handler references.
+type handlerData struct {
+	Package string // com.mycompany.chess.handlers
+	Class   string // MoveHandler
+	Command string // move, the request name
+}
+
+var requestHandlerTemplate = template.Must(template.New("handler").Parse(`package {{.Package}};
+
+import com.smartfoxserver.v2.entities.User;
+import com.smartfoxserver.v2.entities.data.ISFSObject;
+import com.smartfoxserver.v2.entities.data.SFSObject;
+import com.smartfoxserver.v2.extensions.BaseClientRequestHandler;
+
+/**
+ * Handles the "{{.Command}}" request.
+ */
+public class {{.Class}} extends BaseClientRequestHandler {
+    @Override
+    public void handleClientRequest(User user, ISFSObject params) {
+        ISFSObject response = new SFSObject();
+        // TODO: handle the request
+        send("{{.Command}}", response, user);
+    }
+}
+`))
+
+var (
+	javaIdentifier = regexp.MustCompile(`^[A-Za-z_$][\w$]*$`)
+	// The init method of an extension, up to its opening brace
+	initMethodPattern  = regexp.MustCompile(`\bvoid\s+init\s*\(\s*\)\s*(?:throws\s+[\w.,\s]+)?\{`)
+	importLinePattern  = regexp.MustCompile(`(?m)^import\s+[\w.*]+\s*;[^\n]*\n`)
+	packageLinePattern = regexp.MustCompile(`(?m)^package\s+[\w.]+\s*;[^\n]*\n`)
+)
+
+// generateCommand implements "generate handler <Name> [command]" and
+// "generate constants".
+func generateCommand(config *Config) bool {
+	switch commandArg(0) {
+	case "handler":
+		return generateHandler(config, commandArg(1), commandArg(2))
+	case "constants":
+		if config.ClientConstants == "" {
+			tprintln("❌ Set client_constants to the constants file of the game client first")
+			return false
+		}
+		return updateClientConstants(config)
+	default:
+		tprintf("Unknown generate command: %s (expected handler or constants)\n", commandArg(0))
+		return false
+	}
+}
+
+// mainExtensionClass finds the class of the extension being deployed:
+// zone_main_class, or the class extending SFSExtension whose name gives
+// extension_folder, or the only one.
+func mainExtensionClass(config *Config) (extensionClass, error) {
+	classes := findExtensionClasses(config.SourceDir)
+	if len(classes) == 0 {
+		return extensionClass{}, fmt.Errorf("no class extending SFSExtension in %s", config.SourceDir)
+	}
+	for _, class := range classes {
+		if class.Name == config.ZoneMainClass {
+			return class, nil
+		}
+	}
+	if len(classes) == 1 {
+		return classes[0], nil
+	}
+	var names []string
+	for _, class := range classes {
+		if extensionFolderFor(class.Name) == config.ExtensionFolder {
+			return class, nil
+		}
+		names = append(names, class.Name)
+	}
+	return extensionClass{}, fmt.Errorf("several classes extend SFSExtension (%s); set zone_main_class to pick one", strings.Join(names, ", "))
+}
+
+// generateHandler writes a request handler into the handlers package next
+// to the main extension class, registers it in the class's init method and
+// regenerates client_constants with its request name.
+func generateHandler(config *Config, name, command string) bool {
+	name = strings.TrimSuffix(name, ".java")
+	// buy_item and buyItem both become BuyItemHandler
+	base := strings.TrimSuffix(constantName(name, false), "Handler")
+	if !javaIdentifier.MatchString(name) || base == "" {
+		tprintln("Usage: sfdeploy generate handler <Name> [command], e.g. generate handler BuyItem")
+		return false
+	}
+	class := base + "Handler"
+	if command == "" {
+		command = strings.ToLower(base[:1]) + base[1:]
+	}
+
+	main, err := mainExtensionClass(config)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return false
+	}
+	if filepath.Ext(main.File) != ".java" {
+		tprintf("❌ %s is not a Java file; generate handler patches Java extensions only\n", main.File)
+		return false
+	}
+
+	pkg := "handlers"
+	if dot := strings.LastIndex(main.Name, "."); dot >= 0 {
+		pkg = main.Name[:dot] + ".handlers"
+	}
+	data := handlerData{Package: pkg, Class: class, Command: command}
+	path := filepath.Join(filepath.Dir(main.File), "handlers", class+".java")
+	if _, err := os.Stat(path); err == nil {
+		tprintf("❌ %s already exists\n", path)
+		return false
+	}
+
+	source, err := os.ReadFile(main.File)
+	if err != nil {
+		tprintf("❌ Failed to read %s: %v\n", main.File, err)
+		return false
+	}
+	// Sources written on Windows keep their line endings
+	crlf := strings.Contains(string(source), "\r\n")
+	patched, err := registerHandler(strings.ReplaceAll(string(source), "\r\n", "\n"), data)
+	if crlf {
+		patched = strings.ReplaceAll(patched, "\n", "\r\n")
+	}
+	if err != nil {
+		tprintf("❌ Cannot register %s in %s: %v\n", class, main.File, err)
+		return false
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		tprintf("❌ Failed to create %s: %v\n", filepath.Dir(path), err)
+		return false
+	}
+	var buf strings.Builder
+	requestHandlerTemplate.Execute(&buf, data)
+	if err := os.WriteFile(path, []byte(buf.String()), 0644); err != nil {
+		tprintf("❌ Failed to write %s: %v\n", path, err)
+		return false
+	}
+	fmt.Printf("   + %s\n", path)
+	if err := writeFileAtomic(main.File, []byte(patched), 0644); err != nil {
+		tprintf("❌ Failed to update %s: %v\n", main.File, err)
+		return false
+	}
+	tprintf("   ~ %s (registers \"%s\")\n", main.File, command)
+
+	if config.ClientConstants != "" && !updateClientConstants(config) {
+		return false
+	}
+	tprintf("✅ Generated %s for the \"%s\" request\n", class, command)
+	return true
+}
+
+// registerHandler adds the addRequestHandler call of a handler to the init
+// method of an extension's source, after the handlers registered already,
+// and imports the handler.
+func registerHandler(source string, data handlerData) (string, error) {
+	loc := initMethodPattern.FindStringIndex(source)
+	if loc == nil {
+		return "", fmt.Errorf("no init() method found")
+	}
+	bodyStart := loc[1]
+	bodyEnd := matchingBrace(source, bodyStart)
+	if bodyEnd < 0 {
+		return "", fmt.Errorf("the init() method has no closing brace")
+	}
+	body := source[bodyStart:bodyEnd]
+	if strings.Contains(body, fmt.Sprintf("addRequestHandler(%q", data.Command)) {
+		return "", fmt.Errorf("a handler for \"%s\" is registered already", data.Command)
+	}
+
+	// Insert after the line of the last registration, or first in the body
+	lineStart := strings.LastIndex(source[:loc[0]], "\n") + 1
+	indent := leadingSpace(source[lineStart:]) + "    "
+	if strings.Contains(leadingSpace(source[lineStart:]), "\t") {
+		indent = leadingSpace(source[lineStart:]) + "\t"
+	}
+	at := bodyStart
+	if nl := strings.IndexByte(body, '\n'); nl >= 0 {
+		at = bodyStart + nl + 1
+	}
+	if last := strings.LastIndex(body, "addRequestHandler("); last >= 0 {
+		start := strings.LastIndex(body[:last], "\n") + 1
+		indent = leadingSpace(body[start:])
+		if end := strings.Index(body[last:], ";"); end >= 0 {
+			at = bodyStart + last + end + 1
+			if nl := strings.IndexByte(source[at:], '\n'); nl >= 0 {
+				at += nl + 1
+			}
+		}
+	}
+	line := fmt.Sprintf("%saddRequestHandler(%q, %s.class);\n", indent, data.Command, data.Class)
+	source = source[:at] + line + source[at:]
+
+	return addImport(source, data.Package+"."+data.Class), nil
+}
+
+// addImport imports class after the last import of source, unless the class
+// or its package is imported or the class is in the package of source.
+func addImport(source, class string) string {
+	pkg := class[:strings.LastIndex(class, ".")]
+	if m := sourcePackagePattern.FindStringSubmatch(source); m != nil && m[1] == pkg {
+		return source
+	}
+	line := "import " + class + ";\n"
+	if strings.Contains(source, line) || strings.Contains(source, "import "+pkg+".*;") {
+		return source
+	}
+	if imports := importLinePattern.FindAllStringIndex(source, -1); imports != nil {
+		at := imports[len(imports)-1][1]
+		return source[:at] + line + source[at:]
+	}
+	if loc := packageLinePattern.FindStringIndex(source); loc != nil {
+		return source[:loc[1]] + "\n" + line + source[loc[1]:]
+	}
+	return line + "\n" + source
+}
+
+// matchingBrace returns the index of the brace closing the block that starts
+// at i, skipping strings, characters and comments, or -1.
+func matchingBrace(source string, i int) int {
+	depth := 1
+	for ; i < len(source); i++ {
+		switch c := source[i]; {
+		case c == '"' || c == '\'':
+			for i++; i < len(source) && source[i] != c; i++ {
+				if source[i] == '\\' {
+					i++
+				}
+			}
+		case strings.HasPrefix(source[i:], "//"):
+			if nl := strings.IndexByte(source[i:], '\n'); nl >= 0 {
+				i += nl
+			} else {
+				return -1
+			}
+		case strings.HasPrefix(source[i:], "/*"):
+			if end := strings.Index(source[i+2:], "*/"); end >= 0 {
+				i += end + 3
+			} else {
+				return -1
+			}
+		case c == '{':
+			depth++
+		case c == '}':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func leadingSpace(s string) string {
+	return s[:len(s)-len(strings.TrimLeft(s, " \t"))]
+}
@@ -0,0 +1,106 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// globPattern is one deploy_files or jar_files pattern, matched against slash
+// separated paths. "**" matches any number of folders, and a leading "!"
+// excludes files an earlier pattern included.
+type globPattern struct {
+	negate   bool
+	segments []string
+}
+
+func parseGlobs(patterns []string) ([]globPattern, error) {
+	var globs []globPattern
+	for _, pattern := range patterns {
+		g := globPattern{}
+		pattern, g.negate = strings.CutPrefix(pattern, "!")
+		pattern = strings.Trim(filepath.ToSlash(pattern), "/")
+		if pattern == "" {
+			return nil, fmt.Errorf("empty pattern in %q", patterns)
+		}
+		g.segments = strings.Split(pattern, "/")
+		for _, segment := range g.segments {
+			if _, err := path.Match(segment, ""); err != nil {
+				return nil, fmt.Errorf("invalid pattern %s", pattern)
+			}
+		}
+		globs = append(globs, g)
+	}
+	return globs, nil
+}
+
+// matchGlobs reports whether rel is selected. The last matching pattern
+// decides; with only "!" patterns everything else is selected.
+func matchGlobs(globs []globPattern, rel string) bool {
+	selected := true
+	for _, g := range globs {
+		if !g.negate {
+			selected = false
+			break
+		}
+	}
+
+	name := strings.Split(rel, "/")
+	for _, g := range globs {
+		if matchSegments(g.segments, name) {
+			selected = !g.negate
+		}
+	}
+	return selected
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// globFiles returns the slash separated paths of the files under root that
+// the patterns select, in lexical order.
+func globFiles(root string, patterns []string) ([]string, error) {
+	globs, err := parseGlobs(patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		if rel = filepath.ToSlash(rel); matchGlobs(globs, rel) {
+			files = append(files, rel)
+		}
+		return nil
+	})
+	return files, err
+}
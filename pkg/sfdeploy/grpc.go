@@ -0,0 +1,235 @@
+package sfdeploy
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"net"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"sfdeploy/pkg/sfdeploy/daemonpb"
+)
+
+// logHub copies the console output, line by line, to the Logs streams. A
+// stream that falls behind loses lines rather than slowing the deploy down.
+type logHub struct {
+	mu      sync.Mutex
+	partial []byte
+	subs    map[chan string]struct{}
+}
+
+func (h *logHub) Write(p []byte) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.partial = append(h.partial, p...)
+	for {
+		i := bytes.IndexByte(h.partial, '\n')
+		if i < 0 {
+			break
+		}
+		line := string(h.partial[:i])
+		h.partial = h.partial[i+1:]
+		for sub := range h.subs {
+			select {
+			case sub <- line:
+			default:
+			}
+		}
+	}
+	return len(p), nil
+}
+
+func (h *logHub) subscribe() chan string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	sub := make(chan string, 256)
+	h.subs[sub] = struct{}{}
+	return sub
+}
+
+func (h *logHub) unsubscribe(sub chan string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.subs, sub)
+}
+
+// grpcDaemon serves daemonpb.Daemon on top of the REST daemon's job queue.
+type grpcDaemon struct {
+	daemonpb.UnimplementedDaemonServer
+	d    *daemon
+	logs *logHub
+}
+
+func timestampProto(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func jobProto(job *serveJob) *daemonpb.Job {
+	if job == nil {
+		return nil
+	}
+	return &daemonpb.Job{
+		Id:       int32(job.ID),
+		Action:   job.Action,
+		By:       job.By,
+		Requests: int32(job.Requests),
+		Position: int32(job.Position),
+		Queued:   timestamppb.New(job.Queued),
+		Started:  timestampProto(job.Started),
+		Finished: timestampProto(job.Finished),
+		Success:  job.Success,
+		ExitCode: int32(job.ExitCode),
+	}
+}
+
+func (g *grpcDaemon) queue(action, by string) (*daemonpb.Job, error) {
+	job, _, err := g.d.queue(action, by)
+	if err != nil {
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return jobProto(&job), nil
+}
+
+func (g *grpcDaemon) Deploy(_ context.Context, req *daemonpb.DeployRequest) (*daemonpb.Job, error) {
+	return g.queue("deploy", req.By)
+}
+
+func (g *grpcDaemon) Rollback(_ context.Context, req *daemonpb.RollbackRequest) (*daemonpb.Job, error) {
+	return g.queue("rollback", req.By)
+}
+
+func (g *grpcDaemon) Status(context.Context, *daemonpb.StatusRequest) (*daemonpb.StatusResponse, error) {
+	s := g.d.status()
+	resp := &daemonpb.StatusResponse{State: s.State, Current: jobProto(s.Current), Last: jobProto(s.Last)}
+	for _, job := range s.Queue {
+		resp.Queue = append(resp.Queue, jobProto(&job))
+	}
+	return resp, nil
+}
+
+func (g *grpcDaemon) History(context.Context, *daemonpb.HistoryRequest) (*daemonpb.HistoryResponse, error) {
+	resp := &daemonpb.HistoryResponse{}
+	for _, entry := range g.d.history() {
+		resp.Entries = append(resp.Entries, &daemonpb.HistoryEntry{
+			N:               int32(entry.N),
+			Timestamp:       timestamppb.New(entry.Timestamp),
+			Reason:          entry.Reason,
+			TargetDir:       entry.TargetDir,
+			ExtensionFolder: entry.ExtensionFolder,
+			Files:           entry.Files,
+		})
+	}
+	return resp, nil
+}
+
+// jobDone reports whether job id has finished: jobs run in the order of
+// their ids, so a known id that is neither running nor waiting is done.
+func (d *daemon) jobDone(id int) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if id > d.nextID || (d.current != nil && d.current.ID == id) {
+		return false
+	}
+	for _, job := range d.pending {
+		if job.ID == id {
+			return false
+		}
+	}
+	return true
+}
+
+func (g *grpcDaemon) Logs(req *daemonpb.LogsRequest, stream grpc.ServerStreamingServer[daemonpb.LogLine]) error {
+	id := int(req.JobId)
+	if id != 0 {
+		g.d.mu.Lock()
+		known := id <= g.d.nextID
+		g.d.mu.Unlock()
+		if !known {
+			return status.Errorf(codes.NotFound, "no job %d", id)
+		}
+	}
+
+	sub := g.logs.subscribe()
+	defer g.logs.unsubscribe(sub)
+
+	// The output reaches the hub shortly after it is printed, so once the
+	// job is done the lines still on their way are drained before ending
+	tick := time.NewTicker(250 * time.Millisecond)
+	defer tick.Stop()
+	done := false
+	for {
+		select {
+		case line := <-sub:
+			if err := stream.Send(&daemonpb.LogLine{Text: line}); err != nil {
+				return err
+			}
+		case <-tick.C:
+			if done {
+				return nil
+			}
+			done = id != 0 && g.d.jobDone(id)
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+// checkToken requires the serve_token as "authorization: Bearer <token>"
+// metadata when it is set.
+func (d *daemon) checkToken(ctx context.Context) error {
+	if d.config.ServeToken == "" {
+		return nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 1 && subtle.ConstantTimeCompare([]byte(values[0]), []byte("Bearer "+d.config.ServeToken)) == 1 {
+		return nil
+	}
+	return status.Error(codes.Unauthenticated, "invalid token")
+}
+
+// startGRPC serves the gRPC interface on addr next to the REST API. A
+// failure of the server is sent to failed.
+func startGRPC(d *daemon, addr string, failed chan<- error) (func(), error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := d.checkToken(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := d.checkToken(ss.Context()); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	)
+
+	logs := &logHub{subs: map[chan string]struct{}{}}
+	restore := consoleOut.tee(logs)
+	daemonpb.RegisterDaemonServer(server, &grpcDaemon{d: d, logs: logs})
+
+	go func() {
+		failed <- server.Serve(listener)
+	}()
+	return func() {
+		server.Stop()
+		restore()
+	}, nil
+}
@@ -0,0 +1,164 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultHealthPort    = 9933
+	defaultHealthTimeout = 60
+	healthPollInterval   = time.Second
+)
+
+func healthHost(config *Config) string {
+	if remote, ok := parseRemoteTarget(config.TargetDir); ok {
+		host := remote.Host
+		if at := strings.LastIndex(host, "@"); at >= 0 {
+			host = host[at+1:]
+		}
+		return host
+	}
+	if share, ok := targetShare(config); ok {
+		return share.Host
+	}
+	return "127.0.0.1"
+}
+
+func healthPort(config *Config) int {
+	if config.HealthPort > 0 {
+		return config.HealthPort
+	}
+	return defaultHealthPort
+}
+
+// healthTimeout is how long to wait for the server to come up. A negative
+// health_timeout disables the check.
+func healthTimeout(config *Config) time.Duration {
+	if config.HealthTimeout == 0 {
+		return defaultHealthTimeout * time.Second
+	}
+	return time.Duration(config.HealthTimeout) * time.Second
+}
+
+// checkServerHealth follows the boot log, then waits until the SmartFox TCP
+// port, and the BlueBox HTTP port when health_http_port is set, accept
+// connections. After a restart the old server may still answer for a while,
+// so unless sfdeploy saw it stop, the port only counts once the boot log
+// says READY, the port went down or another process holds it.
+func checkServerHealth(config *Config) bool {
+	timeout := healthTimeout(config)
+	if timeout < 0 {
+		return true
+	}
+
+	tprintln("🩺 Phase 6: Checking Server Health")
+
+	host := healthHost(config)
+	tcpAddr := net.JoinHostPort(host, strconv.Itoa(healthPort(config)))
+	var httpURL string
+	if config.HealthHTTPPort > 0 {
+		httpURL = "http://" + net.JoinHostPort(host, strconv.Itoa(config.HealthHTTPPort)) + "/"
+	}
+
+	if *flagDryRun {
+		tprintf("[dry-run] Would follow %s until the READY line and fail on errors\n", serverLogPath(config))
+		tprintf("[dry-run] Would wait up to %s for %s to accept connections\n", timeout, tcpAddr)
+		if httpURL != "" {
+			tprintf("[dry-run] Would wait up to %s for %s to respond\n", timeout, httpURL)
+		}
+		fmt.Println()
+		return true
+	}
+
+	deadline := time.Now().Add(timeout)
+
+	mark, marked := restartMarkFor(config)
+	restarted := !marked || !mark.wasUp
+	serverUp := func() bool {
+		if !tcpReachable(tcpAddr) {
+			restarted = true
+			return false
+		}
+		if !restarted && mark.pid != "" {
+			if process, ok := findServerProcess(config); ok && process.PID != mark.pid {
+				restarted = true
+			}
+		}
+		return restarted
+	}
+
+	if marked {
+		ready, ok := followBootLog(config, mark.offset, deadline, serverUp)
+		if !ok {
+			return false
+		}
+		restarted = restarted || ready
+	}
+
+	tprintf("⏳ Waiting for %s (timeout %s)...\n", tcpAddr, timeout)
+	if !waitUntil(deadline, serverUp) {
+		if !restarted {
+			tprintf("❌ %s is still answered by the server from before the restart, no new server started within %s\n", tcpAddr, timeout)
+		} else {
+			tprintf("❌ Server did not accept connections on %s within %s\n", tcpAddr, timeout)
+		}
+		return false
+	}
+	tprintf("✅ %s is accepting connections\n", tcpAddr)
+
+	if httpURL != "" {
+		tprintf("⏳ Waiting for BlueBox at %s...\n", httpURL)
+		if !waitUntil(deadline, func() bool { return httpReachable(httpURL) }) {
+			tprintf("❌ BlueBox did not respond at %s within %s\n", httpURL, timeout)
+			return false
+		}
+		tprintf("✅ BlueBox is responding at %s\n", httpURL)
+	}
+	fmt.Println()
+
+	return true
+}
+
+// waitUntil polls ready until it holds or the deadline passes, counting
+// down the time left.
+func waitUntil(deadline time.Time, ready func() bool) bool {
+	total := int64(time.Until(deadline) / time.Second)
+	p := startProgress("⏳ Waiting", total, countdownDetail)
+	defer p.finish()
+
+	for {
+		if ready() {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		p.set(total - int64(time.Until(deadline)/time.Second))
+		time.Sleep(healthPollInterval)
+	}
+}
+
+func tcpReachable(addr string) bool {
+	conn, err := net.DialTimeout("tcp", addr, 2*time.Second)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// httpReachable treats any HTTP response as the server being up.
+func httpReachable(url string) bool {
+	client := http.Client{Timeout: 2 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return true
+}
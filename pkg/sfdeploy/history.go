@@ -0,0 +1,360 @@
+package sfdeploy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	stateDir            = ".sfdeploy"
+	historyMetaFile     = "meta.json"
+	defaultHistoryLimit = 5
+)
+
+// The reasons rollbackTarget finds nothing to roll back to.
+var (
+	errNoSnapshot      = errors.New("no snapshot found - nothing to roll back to")
+	errNoOlderSnapshot = errors.New("no deployment older than the one restored last - nothing to roll back to")
+)
+
+type historyEntry struct {
+	Timestamp       time.Time `json:"timestamp"`
+	Reason          string    `json:"reason"`
+	TargetDir       string    `json:"target_dir"`
+	ExtensionFolder string    `json:"extension_folder"`
+	Files           []string  `json:"files"`
+	// RestoredFrom names the snapshot a "before restore" entry was replaced by
+	RestoredFrom string `json:"restored_from,omitempty"`
+
+	dir string
+}
+
+// historyDir holds the snapshots of one extension. Cluster targets each get
+// their own subfolder.
+func historyDir(config *Config) string {
+	if len(config.Targets) > 0 {
+		return filepath.Join(stateDir, "history", config.ExtensionFolder, clusterKey(config.TargetDir))
+	}
+	return filepath.Join(stateDir, "history", config.ExtensionFolder)
+}
+
+func historyLimit(config *Config) int {
+	if config.HistoryLimit > 0 {
+		return config.HistoryLimit
+	}
+	return defaultHistoryLimit
+}
+
+// snapshotExtension saves the currently deployed extension folder and common
+// JAR as a new history entry. Callers prune with pruneHistory once they no
+// longer need older entries.
+func snapshotExtension(config *Config, reason string) error {
+	return saveSnapshot(config, historyEntry{Reason: reason})
+}
+
+// saveSnapshot is snapshotExtension for an entry with its reason and, for a
+// restore, the snapshot restored.
+func saveSnapshot(config *Config, entry historyEntry) error {
+	now := time.Now()
+	name := now.Format("20060102-150405")
+	dir := filepath.Join(historyDir(config), name)
+	for i := 1; ; i++ {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			break
+		}
+		dir = filepath.Join(historyDir(config), fmt.Sprintf("%s-%d", name, i))
+	}
+
+	if remote, ok := parseRemoteTarget(config.TargetDir); ok {
+		if err := snapshotRemote(config, remote, dir); err != nil {
+			return err
+		}
+	} else if d, ok := parseDockerTarget(config.TargetDir); ok {
+		if err := snapshotDocker(config, d, dir); err != nil {
+			return err
+		}
+	} else if err := snapshotLocal(config, dir); err != nil {
+		return err
+	}
+
+	entry.Timestamp = now
+	entry.TargetDir = config.TargetDir
+	entry.ExtensionFolder = config.ExtensionFolder
+
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			rel, _ := filepath.Rel(filepath.Join(dir, "extension"), path)
+			if strings.HasPrefix(rel, "..") {
+				rel, _ = filepath.Rel(dir, path)
+			}
+			entry.Files = append(entry.Files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, historyMetaFile), data, 0644); err != nil {
+		return err
+	}
+	return backupSnapshot(config, dir)
+}
+
+func snapshotLocal(config *Config, dir string) error {
+	if err := copyDir(extensionDir(config), filepath.Join(dir, "extension")); err != nil {
+		return err
+	}
+
+	if config.CommonFile != "" {
+		commonJar := filepath.Join(libDir(config), config.CommonFile)
+		if _, err := os.Stat(commonJar); err == nil {
+			snapshotLib := filepath.Join(dir, "__lib__")
+			if err := os.MkdirAll(snapshotLib, 0755); err != nil {
+				return err
+			}
+			return copyFile(commonJar, filepath.Join(snapshotLib, config.CommonFile))
+		}
+	}
+
+	return nil
+}
+
+// loadHistory returns the history entries for the configured extension, newest first.
+func loadHistory(config *Config) []historyEntry {
+	var entries []historyEntry
+
+	dirs, _ := os.ReadDir(historyDir(config))
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+
+		dir := filepath.Join(historyDir(config), d.Name())
+		data, err := os.ReadFile(filepath.Join(dir, historyMetaFile))
+		if err != nil {
+			continue
+		}
+
+		var entry historyEntry
+		if json.Unmarshal(data, &entry) != nil {
+			continue
+		}
+		entry.dir = dir
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Timestamp.After(entries[j].Timestamp)
+	})
+
+	return entries
+}
+
+// pruneHistory keeps the newest history_limit entries. Older "before
+// restore" snapshots go first, so repeated rollbacks do not push out the
+// deploys they step back through; the newest one still undoes a rollback.
+func pruneHistory(config *Config) error {
+	entries := loadHistory(config)
+	excess := len(entries) - historyLimit(config)
+	for pass := 0; pass < 2 && excess > 0; pass++ {
+		for i := len(entries) - 1; i > 0 && excess > 0; i-- {
+			if entries[i].dir == "" || (pass == 0 && entries[i].Reason != "before restore") {
+				continue
+			}
+			if err := os.RemoveAll(entries[i].dir); err != nil {
+				return err
+			}
+			entries[i].dir = ""
+			excess--
+		}
+	}
+	return nil
+}
+
+// rollbackDeployment restores the deployment before the live one. Repeated
+// rollbacks keep going back instead of undoing each other.
+func rollbackDeployment(config *Config) bool {
+	tprintln("⏪ Rolling Back Deployment")
+	entries := loadHistory(config)
+	n, err := rollbackTarget(entries)
+	switch {
+	case errors.Is(err, errNoOlderSnapshot):
+		tprintln("❌ No deployment older than the one restored last - nothing to roll back to")
+		return false
+	case err != nil:
+		tprintln("❌ No snapshot found - nothing to roll back to")
+		return false
+	}
+	return restoreEntry(config, entries[n])
+}
+
+// rollbackTarget picks the entry a rollback restores from the history,
+// newest first: the newest deploy snapshot, or after a restore, the newest
+// one older than the snapshot restored. "before restore" snapshots, which
+// hold what a restore replaced, are only restored by history restore.
+func rollbackTarget(entries []historyEntry) (int, error) {
+	var before time.Time
+	if len(entries) > 0 && entries[0].RestoredFrom != "" {
+		before = entries[0].Timestamp
+		for _, entry := range entries {
+			if filepath.Base(entry.dir) == entries[0].RestoredFrom {
+				before = entry.Timestamp
+			}
+		}
+	}
+	for i, entry := range entries {
+		if entry.Reason == "before restore" || (!before.IsZero() && !entry.Timestamp.Before(before)) {
+			continue
+		}
+		return i, nil
+	}
+	if before.IsZero() {
+		return 0, errNoSnapshot
+	}
+	return 0, errNoOlderSnapshot
+}
+
+func historyCommand(config *Config) bool {
+	switch commandArg(0) {
+	case "", "list":
+		return listHistory(config)
+	case "restore":
+		n, err := strconv.Atoi(commandArg(1))
+		if err != nil || n < 1 {
+			tprintln("Usage: sfdeploy history restore <n>")
+			return false
+		}
+		tprintf("⏪ Restoring Deployment #%d\n", n)
+		return withDeployLock(func(config *Config) bool {
+			return restoreHistory(config, n) && restartServer(config) && checkServerHealth(config) && smokeTest(config)
+		})(config)
+	default:
+		tprintf("Unknown history command: %s (expected list or restore)\n", commandArg(0))
+		return false
+	}
+}
+
+func listHistory(config *Config) bool {
+	entries := loadHistory(config)
+	if len(entries) == 0 {
+		tprintf("No deploy history for %s\n", config.ExtensionFolder)
+		fmt.Println()
+		return true
+	}
+
+	tprintf("Deploy history for %s (newest first, keeping %d):\n", config.ExtensionFolder, historyLimit(config))
+	for i, entry := range entries {
+		tprintf("  #%-3d %s  %-15s %d files\n",
+			i+1, entry.Timestamp.Format("2006-01-02 15:04:05"), entry.Reason, len(entry.Files))
+	}
+	fmt.Println()
+
+	return true
+}
+
+// restoreHistory replaces the live extension with history entry n (1 = newest).
+// The current state is saved first so a restore can itself be undone.
+func restoreHistory(config *Config, n int) bool {
+	entries := loadHistory(config)
+	if n > len(entries) {
+		if len(entries) == 0 {
+			tprintln("❌ No snapshot found - nothing to roll back to")
+		} else {
+			tprintf("❌ History entry #%d not found (have %d)\n", n, len(entries))
+		}
+		return false
+	}
+
+	return restoreEntry(config, entries[n-1])
+}
+
+// restoreEntry replaces the live extension with a snapshot, saving the
+// current state as a new snapshot first.
+func restoreEntry(config *Config, entry historyEntry) bool {
+	snapshotExt := filepath.Join(entry.dir, "extension")
+	targetExtDir := extensionDir(config)
+
+	if *flagDryRun {
+		tprintf("[dry-run] Would replace %s with snapshot from %s\n",
+			targetExtDir, entry.Timestamp.Format("2006-01-02 15:04:05"))
+		fmt.Println()
+		return true
+	}
+
+	remote, isRemote := parseRemoteTarget(config.TargetDir)
+	d, isDocker := parseDockerTarget(config.TargetDir)
+	if isRemote {
+		stopRemoteServer(config, remote)
+	} else if !isDocker {
+		stopLocalServer(config)
+	}
+
+	tprintln("📸 Saving snapshot of current deployment...")
+	if err := saveSnapshot(config, historyEntry{Reason: "before restore", RestoredFrom: filepath.Base(entry.dir)}); err != nil {
+		tprintf("❌ Failed to snapshot current deployment: %v\n", err)
+		return false
+	}
+
+	if isRemote {
+		if err := restoreRemote(config, remote, entry.dir); err != nil {
+			tprintf("❌ Failed to restore remote extension: %v\n", err)
+			return false
+		}
+		tprintf("Restored: %s/ from %s\n", config.ExtensionFolder, entry.Timestamp.Format("2006-01-02 15:04:05"))
+		return finishRestore(config)
+	}
+
+	if isDocker {
+		if err := restoreDocker(config, d, entry.dir); err != nil {
+			tprintf("❌ Failed to restore container extension: %v\n", err)
+			return false
+		}
+		tprintf("Restored: %s/ from %s\n", config.ExtensionFolder, entry.Timestamp.Format("2006-01-02 15:04:05"))
+		return finishRestore(config)
+	}
+
+	if err := retryLocked(config, targetExtDir, func() error { return os.RemoveAll(targetExtDir) }); err != nil {
+		tprintf("❌ Failed to clear %s: %v\n", targetExtDir, err)
+		return false
+	}
+
+	if err := copyDir(snapshotExt, targetExtDir); err != nil {
+		tprintf("❌ Failed to restore extension folder: %v\n", err)
+		return false
+	}
+	tprintf("Restored: %s/ from %s\n", config.ExtensionFolder, entry.Timestamp.Format("2006-01-02 15:04:05"))
+
+	if config.CommonFile != "" {
+		snapshotCommon := filepath.Join(entry.dir, "__lib__", config.CommonFile)
+		if _, err := os.Stat(snapshotCommon); err == nil {
+			target := filepath.Join(libDir(config), config.CommonFile)
+			if err := retryLocked(config, target, func() error { return copyFile(snapshotCommon, target) }); err != nil {
+				tprintf("❌ Failed to restore %s: %v\n", config.CommonFile, err)
+				return false
+			}
+			tprintf("Restored: __lib__/%s\n", config.CommonFile)
+		}
+	}
+
+	return finishRestore(config)
+}
+
+func finishRestore(config *Config) bool {
+	if err := pruneHistory(config); err != nil {
+		tprintf("⚠️ Warning: Could not prune deploy history: %v\n", err)
+	}
+
+	tprintln("✅ Rollback successful")
+	fmt.Println()
+
+	return true
+}
@@ -0,0 +1,48 @@
+package sfdeploy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRollbackTarget(t *testing.T) {
+	at := func(minute int) time.Time {
+		return time.Date(2026, 1, 2, 10, minute, 0, 0, time.UTC)
+	}
+	deploy := func(minute int, dir string) historyEntry {
+		return historyEntry{Timestamp: at(minute), Reason: "deploy", dir: "/history/" + dir}
+	}
+	restore := func(minute int, dir, from string) historyEntry {
+		return historyEntry{Timestamp: at(minute), Reason: "before restore", RestoredFrom: from, dir: "/history/" + dir}
+	}
+
+	tests := []struct {
+		name    string
+		entries []historyEntry // newest first
+		want    int
+		wantErr error
+	}{
+		{"no history", nil, 0, errNoSnapshot},
+		{"newest deploy", []historyEntry{deploy(3, "03"), deploy(2, "02")}, 0, nil},
+		{"skips a before restore entry", []historyEntry{restore(4, "04", ""), deploy(3, "03")}, 1, nil},
+		{"after a rollback steps further back",
+			[]historyEntry{restore(5, "05", "03"), deploy(4, "04"), deploy(3, "03"), deploy(2, "02")}, 3, nil},
+		{"after two rollbacks",
+			[]historyEntry{restore(6, "06", "02"), restore(5, "05", "03"), deploy(4, "04"), deploy(3, "03"), deploy(2, "02"), deploy(1, "01")}, 5, nil},
+		{"restored snapshot pruned, falls back to the restore time",
+			[]historyEntry{restore(5, "05", "gone"), deploy(4, "04")}, 1, nil},
+		{"nothing older than the restored one",
+			[]historyEntry{restore(5, "05", "02"), deploy(4, "04"), deploy(2, "02")}, 0, errNoOlderSnapshot},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rollbackTarget(tt.entries)
+			if err != tt.wantErr {
+				t.Fatalf("rollbackTarget() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("rollbackTarget() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// hookEnv is the environment hook commands run with: the current
+// environment and .env plus the deploy's source, target and extension. Local
+// directories are made absolute since hooks run in the source directory.
+func hookEnv(config *Config, stage string) []string {
+	target := config.TargetDir
+	if isLocalTarget(config) {
+		target = absPath(target)
+	}
+	return append(environ(config),
+		"SFDEPLOY_HOOK="+stage,
+		"SFDEPLOY_SOURCE_DIR="+absPath(config.SourceDir),
+		"SFDEPLOY_TARGET_DIR="+target,
+		"SFDEPLOY_EXTENSION="+config.ExtensionFolder,
+		"SFDEPLOY_EXTENSION_FILE="+config.ExtensionFile,
+		"SFDEPLOY_PROFILE="+*flagProfile,
+	)
+}
+
+func hookCommand(line string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return newCommand("cmd", "/C", line)
+	}
+	return newCommand("sh", "-c", line)
+}
+
+// runHooks runs each command in the source directory, stopping at the
+// first one that fails.
+func runHooks(config *Config, stage string, hooks []string) bool {
+	for _, line := range hooks {
+		if *flagDryRun {
+			tprintf("[dry-run] Would run %s-deploy hook: %s\n", stage, line)
+			continue
+		}
+
+		tprintf("🪝 Running %s-deploy hook: %s\n", stage, line)
+		cmd := hookCommand(line)
+		cmd.Dir = config.SourceDir
+		cmd.Env = hookEnv(config, stage)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			tprintf("❌ %s-deploy hook failed: %v\n", stage, err)
+			return false
+		}
+	}
+	return true
+}
+
+// postDeployHooks is the phase that runs post_deploy_hooks once the new
+// extension is deployed (and, for full runs, the server is back up).
+func postDeployHooks(config *Config) bool {
+	if len(config.PostDeployHooks) == 0 {
+		return true
+	}
+	if !runHooks(config, "post", config.PostDeployHooks) {
+		return false
+	}
+	fmt.Println()
+	return true
+}
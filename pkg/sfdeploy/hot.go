@@ -0,0 +1,214 @@
+package sfdeploy
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// hotWatchPhases is the watch cycle of --hot: the changed classes replace
+// the live extension JAR and SmartFox reloads the extension by itself.
+var hotWatchPhases = []phase{buildProject, customPhases("build"), runTests, customPhases("test"),
+	lockedTarget(hotDeploy, customPhases("deploy"), postDeployHooks), cleanupProject}
+
+// hotPhases is the --hot pipeline of a command, which skips the server
+// restart. watch keeps its phases and runs hotWatchPhases for each change.
+func hotPhases(name string) ([]phase, bool) {
+	switch name {
+	case "all":
+		return []phase{setupDirectories, setupJava, buildProject, customPhases("build"), runTests, customPhases("test"),
+			lockedTarget(hotDeploy, customPhases("deploy"), postDeployHooks), cleanupProject}, true
+	case "watch":
+		return []phase{setupDirectories, setupJava, watchProject}, true
+	}
+	return nil, false
+}
+
+// jarClassChanges compares the classes of two JARs by CRC and size and
+// returns the entries that are new or changed in built and the ones built no
+// longer has.
+func jarClassChanges(live, built []byte) (changed, removed []string, err error) {
+	classes := func(data []byte) (map[string]*zip.File, error) {
+		r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, err
+		}
+		entries := map[string]*zip.File{}
+		for _, f := range r.File {
+			if strings.HasSuffix(f.Name, ".class") {
+				entries[f.Name] = f
+			}
+		}
+		return entries, nil
+	}
+
+	old, err := classes(live)
+	if err != nil {
+		return nil, nil, fmt.Errorf("live JAR: %w", err)
+	}
+	cur, err := classes(built)
+	if err != nil {
+		return nil, nil, fmt.Errorf("built JAR: %w", err)
+	}
+	for name, f := range cur {
+		if o, ok := old[name]; !ok || o.CRC32 != f.CRC32 || o.UncompressedSize64 != f.UncompressedSize64 {
+			changed = append(changed, name)
+		}
+	}
+	for name := range old {
+		if _, ok := cur[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(changed)
+	sort.Strings(removed)
+	return changed, removed, nil
+}
+
+// replaceTargetFile writes a file of the target under a temporary name and
+// moves it into place, so the server never sees a half-written JAR.
+func replaceTargetFile(config *Config, rel string, data []byte) error {
+	tmp := rel + ".sfdeploy-tmp"
+	if err := writeTargetFile(config, tmp, data); err != nil {
+		return err
+	}
+	if target, ok := parseShellTarget(config.TargetDir); ok {
+		if output, err := target.run(config, "mv -f "+shellQuote(target.path(tmp))+" "+shellQuote(target.path(rel))); err != nil {
+			return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+		}
+		return nil
+	}
+	return os.Rename(filepath.Join(config.TargetDir, filepath.FromSlash(tmp)), filepath.Join(config.TargetDir, filepath.FromSlash(rel)))
+}
+
+var reloadModePattern = regexp.MustCompile(`<reloadMode>\s*(\w+)\s*</reloadMode>`)
+
+// zoneReloadMode is the reloadMode of the zone's extension: zone_reload_mode,
+// or else what the zone definition on the target says. It is empty when
+// unknown.
+func zoneReloadMode(config *Config) string {
+	if config.ZoneReloadMode != "" {
+		return strings.ToUpper(config.ZoneReloadMode)
+	}
+	if config.ZoneName == "" && config.ZoneFile == "" {
+		return ""
+	}
+	data, err := readTargetFile(config, "SFS2X/zones/"+zoneFileName(config))
+	if err != nil {
+		return ""
+	}
+	if m := reloadModePattern.FindSubmatch(data); m != nil {
+		return strings.ToUpper(string(m[1]))
+	}
+	return ""
+}
+
+// warnSkippedItems names the deploy items that changed but that --hot
+// leaves alone: JSON files and libraries are only read when the server or
+// the extension starts.
+func warnSkippedItems(config *Config) {
+	items, err := deployItems(config)
+	if err != nil {
+		return
+	}
+	var skipped []deployItem
+	for _, item := range items {
+		if item.Target != config.ExtensionFolder+"/"+config.ExtensionFile && !strings.HasSuffix(item.Target, "/"+manifestFile) {
+			skipped = append(skipped, item)
+		}
+	}
+	changed, _ := splitUnchanged(config, skipped)
+	for _, item := range changed {
+		tprintf("⚠️ Warning: %s changed, but --hot only deploys classes; run a full deploy for it\n", item.Target)
+	}
+}
+
+// hotDeploy replaces the live extension JARs of the target with the built
+// ones when their classes changed, leaving the server running. SmartFox
+// reloads an extension whose JAR changes when its zone has reloadMode AUTO;
+// a reloadable extension is reloaded through the admin API instead.
+func hotDeploy(config *Config) bool {
+	tprintln("🔥 Hot Deploy")
+
+	replaced := false
+	for _, ext := range extensionConfigs(config) {
+		changed, ok := hotDeployExtension(&ext)
+		if !ok {
+			return false
+		}
+		replaced = replaced || changed
+	}
+	if !replaced {
+		fmt.Println()
+		return true
+	}
+
+	if useAdminReload(config) {
+		if *flagDryRun {
+			tprintf("[dry-run] Would ask %s to reload %s\n", adminEndpoint(config), config.ExtensionFolder)
+		} else if !reloadViaAdmin(config) {
+			tprintln("⚠️ Warning: the new classes are deployed but not loaded; restart the server or reload the extension")
+		}
+		return true
+	}
+	switch mode := zoneReloadMode(config); mode {
+	case "AUTO":
+	case "":
+		tprintln("   SmartFox reloads the extension only when its zone has reloadMode AUTO")
+	default:
+		tprintf("⚠️ Warning: the zone's reloadMode is %s, so SmartFox will not reload the extension; set zone_reload_mode to AUTO\n", mode)
+	}
+	fmt.Println()
+	return true
+}
+
+// hotDeployExtension replaces the live JAR of one extension and reports
+// whether it did.
+func hotDeployExtension(config *Config) (replaced, ok bool) {
+	rel := "SFS2X/extensions/" + config.ExtensionFolder + "/" + config.ExtensionFile
+	built, err := os.ReadFile(filepath.Join(config.SourceDir, config.ExtensionFile))
+	if err != nil {
+		tprintf("❌ Failed to read %s: %v\n", config.ExtensionFile, err)
+		return false, false
+	}
+	live, err := readTargetFile(config, rel)
+	if err != nil {
+		tprintf("❌ %s is not deployed yet, run a full deploy before using --hot\n", rel)
+		return false, false
+	}
+
+	changed, removed, err := jarClassChanges(live, built)
+	if err != nil {
+		tprintf("❌ Failed to compare %s: %v\n", config.ExtensionFile, err)
+		return false, false
+	}
+	warnSkippedItems(config)
+	if len(changed) == 0 && len(removed) == 0 {
+		tprintf("✅ No class of %s changed\n", config.ExtensionFile)
+		return false, true
+	}
+
+	tprintf("🔥 %s: %d changed, %d removed classes\n", config.ExtensionFile, len(changed), len(removed))
+	for _, name := range changed {
+		verbosef("   ✏️ %s\n", name)
+	}
+	for _, name := range removed {
+		verbosef("   🗑️ %s\n", name)
+	}
+	if *flagDryRun {
+		tprintf("[dry-run] Would replace %s\n", rel)
+		return true, true
+	}
+
+	if err := replaceTargetFile(config, rel, built); err != nil {
+		tprintf("❌ Failed to replace %s: %v\n", rel, err)
+		return false, false
+	}
+	tprintf("✅ Replaced %s without restarting the server\n", rel)
+	return true, true
+}
@@ -0,0 +1,75 @@
+package sfdeploy
+
+import (
+	"archive/zip"
+	"bytes"
+	"slices"
+	"testing"
+)
+
+// testJar builds a JAR of the given entries and contents.
+func testJar(t *testing.T, entries map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range entries {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestJarClassChanges(t *testing.T) {
+	live := map[string]string{
+		"META-INF/MANIFEST.MF": "Manifest-Version: 1.0\n",
+		"com/x/A.class":        "A1",
+		"com/x/B.class":        "B1",
+		"com/x/B$Inner.class":  "I1",
+	}
+
+	tests := []struct {
+		name    string
+		built   map[string]string
+		changed []string
+		removed []string
+	}{
+		{"identical", live, nil, nil},
+		{"changed, added and removed classes", map[string]string{
+			"META-INF/MANIFEST.MF": "Manifest-Version: 1.0\n",
+			"com/x/A.class":        "A2",
+			"com/x/B.class":        "B1",
+			"com/x/C.class":        "C1",
+		}, []string{"com/x/A.class", "com/x/C.class"}, []string{"com/x/B$Inner.class"}},
+		{"non-class entries are ignored", map[string]string{
+			"META-INF/MANIFEST.MF": "Manifest-Version: 1.0\nBuilt-By: ci\n",
+			"config.properties":    "a=1",
+			"com/x/A.class":        "A1",
+			"com/x/B.class":        "B1",
+			"com/x/B$Inner.class":  "I1",
+		}, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed, removed, err := jarClassChanges(testJar(t, live), testJar(t, tt.built))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(changed, tt.changed) {
+				t.Errorf("changed = %v, want %v", changed, tt.changed)
+			}
+			if !slices.Equal(removed, tt.removed) {
+				t.Errorf("removed = %v, want %v", removed, tt.removed)
+			}
+		})
+	}
+
+	if _, _, err := jarClassChanges([]byte("not a jar"), testJar(t, live)); err == nil {
+		t.Error("jarClassChanges() of an invalid live JAR did not fail")
+	}
+}
@@ -0,0 +1,141 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// messageCatalogs maps a language to the translations of the console
+// messages, keyed by the English format strings their call sites pass to
+// tr, tprintf or tprintln, without the trailing newline. A message missing
+// from a catalog is shown in English.
+var messageCatalogs = map[string]map[string]string{
+	"es": messagesES,
+}
+
+// activeCatalog holds the messages of the console language, nil for English.
+var activeCatalog atomic.Pointer[map[string]string]
+
+// translationMark follows levelMark on a translated line, which carries the
+// English text for the log and, after translationSep, the console's.
+const (
+	translationMark = 't'
+	translationSep  = '\x1f'
+)
+
+// tr returns the translation of an English message or format string, or
+// the string itself.
+func tr(message string) string {
+	if messages := activeCatalog.Load(); messages != nil {
+		if translation, ok := (*messages)[message]; ok {
+			return translation
+		}
+	}
+	return message
+}
+
+// tprintf is fmt.Printf for a message of the catalogs: the console shows it
+// in the active language, the log and reports get the English line.
+func tprintf(format string, args ...any) {
+	key, newline := strings.CutSuffix(format, "\n")
+	translation := tr(key)
+	if translation == key {
+		fmt.Printf(format, args...)
+		return
+	}
+	printTranslated(fmt.Sprintf(key, args...), fmt.Sprintf(translation, args...), newline)
+}
+
+// tprintln is fmt.Println for a message of the catalogs.
+func tprintln(message string) {
+	translation := tr(message)
+	if translation == message {
+		fmt.Println(message)
+		return
+	}
+	printTranslated(message, translation, true)
+}
+
+// printTranslated marks a whole translated line for the levelRouter. Text
+// without a newline, such as a prompt, is shown translated straight away.
+func printTranslated(english, shown string, newline bool) {
+	routedMu.Lock()
+	on := routed
+	routedMu.Unlock()
+
+	if !on || !newline || strings.ContainsRune(english+shown, '\n') {
+		if newline {
+			shown += "\n"
+		}
+		fmt.Print(shown)
+		return
+	}
+	fmt.Printf("%c%c%s%c%s\n", levelMark, translationMark, english, translationSep, shown)
+}
+
+// normalizeLanguage reduces a language setting or locale such as
+// "es_ES.UTF-8" to its language, "es".
+func normalizeLanguage(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, ".@"); i >= 0 {
+		tag = tag[:i]
+	}
+	if i := strings.IndexAny(tag, "_-"); i >= 0 {
+		tag = tag[:i]
+	}
+	if tag == "c" || tag == "posix" {
+		return "en"
+	}
+	return tag
+}
+
+// envLanguage is the language of the locale, read the way gettext does.
+func envLanguage() string {
+	for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(key); value != "" {
+			return normalizeLanguage(value)
+		}
+	}
+	return ""
+}
+
+// setLanguage translates the console output into the language, or the
+// locale's for "" and "auto". Languages without a catalog stay English, and
+// so do --ci and --log-format json, whose groups and records are found from
+// the English lines.
+func setLanguage(language string) {
+	language = normalizeLanguage(language)
+	if language == "" || language == "auto" {
+		language = envLanguage()
+	}
+	messages, ok := messageCatalogs[language]
+	if !ok || *flagCI || *flagLogFormat == "json" {
+		activeCatalog.Store(nil)
+		return
+	}
+	activeCatalog.Store(&messages)
+}
+
+// useLanguage switches to the language of config once it is loaded.
+func useLanguage(config *Config) {
+	setLanguage(config.Language)
+}
+
+// validateLanguage checks that language names a language with messages.
+func validateLanguage(v *validation, config *Config) {
+	language := normalizeLanguage(config.Language)
+	if language == "" || language == "auto" || language == "en" {
+		return
+	}
+	if _, ok := messageCatalogs[language]; !ok {
+		var known []string
+		for name := range messageCatalogs {
+			known = append(known, name)
+		}
+		sort.Strings(known)
+		v.warn("language %s has no translations, showing English (have en, %s)", config.Language, strings.Join(known, ", "))
+	}
+}
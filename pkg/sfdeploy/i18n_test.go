@@ -0,0 +1,149 @@
+package sfdeploy
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// translatedMessages returns the messages the package passes as a literal to
+// tr, tprintf, tprintln or the checks of config validate, and the labels of
+// wizardFields, without the trailing newline.
+func translatedMessages(t *testing.T) map[string]bool {
+	t.Helper()
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messages := map[string]bool{}
+	fset := token.NewFileSet()
+	for _, name := range files {
+		// i18n.go passes the messages on
+		if strings.HasSuffix(name, "_test.go") || name == "i18n.go" {
+			continue
+		}
+		file, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		add := func(expr ast.Expr) bool {
+			lit, ok := expr.(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return false
+			}
+			message, err := strconv.Unquote(lit.Value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			messages[strings.TrimSuffix(message, "\n")] = true
+			return true
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			if spec, ok := n.(*ast.ValueSpec); ok && spec.Names[0].Name == "wizardFields" {
+				for _, field := range spec.Values[0].(*ast.CompositeLit).Elts {
+					add(field.(*ast.CompositeLit).Elts[1])
+				}
+				return true
+			}
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+			switch fn := call.Fun.(type) {
+			case *ast.Ident:
+				switch fn.Name {
+				case "tr":
+					// tr also translates labels and formats picked at run time
+					add(call.Args[0])
+				case "tprintf", "tprintln":
+					if !add(call.Args[0]) {
+						t.Errorf("%s: %s is called without a literal message", fset.Position(call.Pos()), fn.Name)
+					}
+				}
+			case *ast.SelectorExpr:
+				if fn.Sel.Name == "pass" || fn.Sel.Name == "warn" || fn.Sel.Name == "fail" {
+					add(call.Args[0])
+				}
+			}
+			return true
+		})
+	}
+	return messages
+}
+
+func TestCatalogKeysAreMessages(t *testing.T) {
+	messages := translatedMessages(t)
+	for language, catalog := range messageCatalogs {
+		for key := range catalog {
+			if !messages[key] {
+				t.Errorf("%s: %q is not a message passed to tr, tprintf or tprintln", language, key)
+			}
+		}
+	}
+}
+
+// formatVerb matches a fmt verb such as %s, %-8s, %.1f or %[2]d.
+var formatVerb = regexp.MustCompile(`%(?:\[\d+\])?[-+# 0]*\d*(?:\.\d+)?([a-zA-Z%])`)
+
+func formatVerbs(format string) []string {
+	var verbs []string
+	for _, m := range formatVerb.FindAllStringSubmatch(format, -1) {
+		verbs = append(verbs, m[1])
+	}
+	slices.Sort(verbs)
+	return verbs
+}
+
+func TestCatalogTranslationsKeepVerbs(t *testing.T) {
+	for language, catalog := range messageCatalogs {
+		for key, translation := range catalog {
+			if want, got := formatVerbs(key), formatVerbs(translation); !slices.Equal(want, got) {
+				t.Errorf("%s: %q has the verbs %v, its translation %q has %v", language, key, want, translation, got)
+			}
+			if strings.Contains(translation, "\n") {
+				t.Errorf("%s: the translation of %q spans lines", language, key)
+			}
+		}
+	}
+}
+
+func TestTranslatedLineRouting(t *testing.T) {
+	marked := string(levelMark) + string(translationMark) + "Compilation successful" + string(translationSep) + "Compilación correcta\n"
+
+	tests := []struct {
+		name    string
+		level   verbosity
+		writes  []string
+		console string
+		log     string
+	}{
+		{"whole line", levelNormal, []string{marked}, "Compilación correcta\n", "Compilation successful\n"},
+		{"split across writes", levelNormal, []string{marked[:12], marked[12:]}, "Compilación correcta\n", "Compilation successful\n"},
+		{"quiet hides it", levelQuiet, []string{marked}, "", "Compilation successful\n"},
+		{"plain lines pass", levelNormal, []string{"javac: warning\n"}, "javac: warning\n", "javac: warning\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var console, log bytes.Buffer
+			router := &levelRouter{console: &consoleWriter{out: &console}, log: &log, level: tt.level, atStart: true}
+			for _, w := range tt.writes {
+				router.Write([]byte(w))
+			}
+			router.flush()
+			if console.String() != tt.console {
+				t.Errorf("console = %q, want %q", console.String(), tt.console)
+			}
+			if log.String() != tt.log {
+				t.Errorf("log = %q, want %q", log.String(), tt.log)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const ignoreFile = ".sfdeployignore"
+
+// ignoreRule is one line of .sfdeployignore, a glob relative to the source
+// directory. Lines without a slash match at any depth, as in .gitignore.
+type ignoreRule struct {
+	globPattern
+	dirOnly bool
+}
+
+// ignoreRules are the parsed .sfdeployignore of a source directory.
+type ignoreRules struct {
+	root  string
+	rules []ignoreRule
+}
+
+var (
+	ignoreMu    sync.Mutex
+	ignoreCache = map[string]*ignoreRules{}
+)
+
+// sourceIgnore returns the project's .sfdeployignore rules, or nil when it
+// has none. The file is read once per run.
+func sourceIgnore(config *Config) *ignoreRules {
+	ignoreMu.Lock()
+	defer ignoreMu.Unlock()
+	if rules, ok := ignoreCache[config.SourceDir]; ok {
+		return rules
+	}
+
+	file := filepath.Join(config.SourceDir, ignoreFile)
+	rules, err := loadIgnoreFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		tprintf("⚠️ Warning: Ignoring %s: %v\n", file, err)
+	}
+	if rules != nil {
+		debugf("🐛 %s: %d patterns\n", file, len(rules.rules))
+	}
+	ignoreCache[config.SourceDir] = rules
+	return rules
+}
+
+func loadIgnoreFile(file string) (*ignoreRules, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := &ignoreRules{root: absPath(filepath.Dir(file))}
+	for n, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := ignoreRule{}
+		line, rule.negate = strings.CutPrefix(line, "!")
+		line, rule.dirOnly = strings.CutSuffix(line, "/")
+		anchored := strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+		if line == "" {
+			continue
+		}
+		rule.segments = strings.Split(line, "/")
+		if !anchored {
+			rule.segments = append([]string{"**"}, rule.segments...)
+		}
+		for _, segment := range rule.segments {
+			if _, err := path.Match(segment, ""); err != nil {
+				return nil, fmt.Errorf("line %d: invalid pattern %s", n+1, line)
+			}
+		}
+		rules.rules = append(rules.rules, rule)
+	}
+	return rules, nil
+}
+
+// skip reports whether the file or folder at p is ignored. Nothing below an
+// ignored folder can be included again, as in git.
+func (r *ignoreRules) skip(p string, isDir bool) bool {
+	if r == nil {
+		return false
+	}
+	rel, err := filepath.Rel(r.root, absPath(p))
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	if rel == ignoreFile {
+		return true
+	}
+
+	segments := strings.Split(filepath.ToSlash(rel), "/")
+	// Build state is never ignored, even when sfdeploy runs in the source directory
+	if segments[0] == stateDir {
+		return false
+	}
+	for i := 1; i < len(segments); i++ {
+		if r.match(segments[:i], true) {
+			return true
+		}
+	}
+	return r.match(segments, isDir)
+}
+
+func (r *ignoreRules) match(segments []string, isDir bool) bool {
+	ignored := false
+	for _, rule := range r.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if matchSegments(rule.segments, segments) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// skipWalk is the filepath.Walk result for an ignored entry.
+func skipWalk(info os.FileInfo) error {
+	if info.IsDir() {
+		return filepath.SkipDir
+	}
+	return nil
+}
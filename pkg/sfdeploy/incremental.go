@@ -0,0 +1,261 @@
+package sfdeploy
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const buildManifestFile = "manifest.json"
+
+// buildManifest records the sources compiled into the class cache so the
+// next build only recompiles what changed.
+type buildManifest struct {
+	Classpath string                  `json:"classpath"`
+	Options   []string                `json:"options"`
+	Kotlin    string                  `json:"kotlin,omitempty"`
+	Sources   map[string]sourceRecord `json:"sources"`
+}
+
+type sourceRecord struct {
+	Hash    string `json:"hash"`
+	Package string `json:"package"`
+}
+
+// compilePlan is the outcome of comparing the sources with the manifest.
+type compilePlan struct {
+	Full      bool
+	Compile   []string // absolute source paths
+	Removed   []string // manifest keys of deleted sources
+	Unchanged int
+
+	kotlin      string
+	compileKeys []string
+	sources     map[string]sourceRecord
+	previous    map[string]sourceRecord // the manifest's, before the build
+}
+
+var packagePattern = regexp.MustCompile(`(?m)^\s*package\s+([\w.]+)\s*;`)
+
+func classCacheDir(config *Config) string {
+	return filepath.Join(stateDir, "build", config.ExtensionFolder, "classes")
+}
+
+func buildManifestPath(config *Config) string {
+	return filepath.Join(stateDir, "build", config.ExtensionFolder, buildManifestFile)
+}
+
+func loadBuildManifest(config *Config) (buildManifest, bool) {
+	var manifest buildManifest
+
+	data, err := os.ReadFile(buildManifestPath(config))
+	if err != nil {
+		return manifest, false
+	}
+	if json.Unmarshal(data, &manifest) != nil || manifest.Sources == nil {
+		return manifest, false
+	}
+	return manifest, true
+}
+
+func saveBuildManifest(config *Config, manifest buildManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(buildManifestPath(config), data, 0644)
+}
+
+func hashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// planCompile decides which sources need compiling. A file is recompiled when
+// its content changed, its class is missing from the cache, or it mentions
+// the class name of a changed or removed file. The whole tree is rebuilt when there is no manifest, the
+// classpath, compiler options or Kotlin sources changed, or --rebuild is set.
+func planCompile(config *Config, srcDir string, javaFiles []string, classpath string) (compilePlan, error) {
+	plan := compilePlan{sources: map[string]sourceRecord{}, kotlin: kotlinSourcesHash(config, srcDir)}
+
+	manifest, ok := loadBuildManifest(config)
+	plan.previous = manifest.Sources
+	plan.Full = !ok || *flagRebuild || manifest.Classpath != classpath || manifest.Kotlin != plan.kotlin ||
+		strings.Join(manifest.Options, "\x00") != strings.Join(javacOptions(config), "\x00")
+
+	absFiles := map[string]string{}
+	for _, file := range javaFiles {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return plan, err
+		}
+		rel, err := filepath.Rel(srcDir, file)
+		if err != nil {
+			return plan, err
+		}
+		key := filepath.ToSlash(rel)
+		absFiles[key] = abs
+
+		hash, err := hashFile(file)
+		if err != nil {
+			return plan, err
+		}
+		plan.sources[key] = sourceRecord{Hash: hash, Package: readPackage(file)}
+	}
+
+	if plan.Full {
+		for _, key := range sortedKeys(plan.sources) {
+			plan.Compile = append(plan.Compile, absFiles[key])
+			plan.compileKeys = append(plan.compileKeys, key)
+		}
+		return plan, nil
+	}
+
+	changed := map[string]bool{}
+	var changedNames []string
+	for key, record := range plan.sources {
+		if old, exists := manifest.Sources[key]; !exists || old.Hash != record.Hash {
+			changed[key] = true
+			changedNames = append(changedNames, className(key))
+		}
+	}
+	for key := range manifest.Sources {
+		if _, exists := plan.sources[key]; !exists {
+			plan.Removed = append(plan.Removed, key)
+			changedNames = append(changedNames, className(key))
+		}
+	}
+
+	if len(changedNames) > 0 {
+		mentions := regexp.MustCompile(`\b(` + strings.Join(quoteAll(changedNames), "|") + `)\b`)
+		for key := range plan.sources {
+			if !changed[key] && fileMatches(absFiles[key], mentions) {
+				changed[key] = true
+			}
+		}
+	}
+
+	// A class deleted without the manifest knowing, e.g. by hand, is rebuilt
+	cacheDir := classCacheDir(config)
+	for key, record := range plan.sources {
+		if !changed[key] && className(key) != "package-info" && !fileExists(classFile(cacheDir, key, record)) {
+			changed[key] = true
+		}
+	}
+
+	for _, key := range sortedKeys(plan.sources) {
+		if changed[key] {
+			plan.Compile = append(plan.Compile, absFiles[key])
+			plan.compileKeys = append(plan.compileKeys, key)
+		} else {
+			plan.Unchanged++
+		}
+	}
+
+	return plan, nil
+}
+
+// classFile is the cached class of a source.
+func classFile(cacheDir, key string, record sourceRecord) string {
+	dir := filepath.Join(cacheDir, filepath.FromSlash(strings.ReplaceAll(record.Package, ".", "/")))
+	return filepath.Join(dir, className(key)+".class")
+}
+
+// removeStaleClasses deletes the cached classes belonging to the sources
+// about to be recompiled or deleted, including nested and inner classes.
+// Their manifest entries go too, so if the compile fails they are not taken
+// for up to date once their sources are back as they were.
+func removeStaleClasses(config *Config, plan compilePlan) error {
+	manifest, ok := loadBuildManifest(config)
+	cacheDir := classCacheDir(config)
+
+	remove := func(key string, record sourceRecord) {
+		class := classFile(cacheDir, key, record)
+		os.Remove(class)
+		matches, _ := filepath.Glob(strings.TrimSuffix(class, ".class") + "$*.class")
+		for _, match := range matches {
+			os.Remove(match)
+		}
+	}
+
+	for _, key := range plan.compileKeys {
+		if old, ok := manifest.Sources[key]; ok {
+			remove(key, old)
+		}
+		remove(key, plan.sources[key])
+		delete(manifest.Sources, key)
+	}
+	for _, key := range plan.Removed {
+		remove(key, manifest.Sources[key])
+		delete(manifest.Sources, key)
+	}
+	if !ok {
+		return nil
+	}
+	return saveBuildManifest(config, manifest)
+}
+
+func sortedKeys(sources map[string]sourceRecord) []string {
+	keys := make([]string, 0, len(sources))
+	for key := range sources {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func readPackage(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	if match := packagePattern.FindSubmatch(data); match != nil {
+		return string(match[1])
+	}
+	return ""
+}
+
+func className(key string) string {
+	return strings.TrimSuffix(filepath.Base(key), filepath.Ext(key))
+}
+
+func quoteAll(names []string) []string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = regexp.QuoteMeta(name)
+	}
+	return quoted
+}
+
+func fileMatches(path string, pattern *regexp.Regexp) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return true
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if pattern.Match(scanner.Bytes()) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package sfdeploy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestPlanCompile(t *testing.T) {
+	const (
+		a       = "package com.x;\nclass A {}\n"
+		b       = "package com.x;\nclass B { A a; }\n"
+		c       = "package com.x;\nclass C {}\n"
+		aEdited = "package com.x;\nclass A { int n; }\n"
+		info    = "package com.x;\n"
+	)
+
+	tests := []struct {
+		name      string
+		previous  map[string]string // sources of the last build, nil for no manifest
+		classpath string            // of the last build
+		sources   map[string]string
+		cached    []string // keys whose class is in the cache
+		full      bool
+		compile   []string
+		removed   []string
+		unchanged int
+	}{
+		{
+			name:    "no manifest",
+			sources: map[string]string{"com/x/A.java": a, "com/x/B.java": b},
+			full:    true,
+			compile: []string{"com/x/A.java", "com/x/B.java"},
+		},
+		{
+			name:      "nothing changed",
+			previous:  map[string]string{"com/x/A.java": a, "com/x/B.java": b},
+			sources:   map[string]string{"com/x/A.java": a, "com/x/B.java": b},
+			cached:    []string{"com/x/A.java", "com/x/B.java"},
+			unchanged: 2,
+		},
+		{
+			name:     "changed file and the files mentioning it",
+			previous: map[string]string{"com/x/A.java": a, "com/x/B.java": b, "com/x/C.java": c},
+			sources:  map[string]string{"com/x/A.java": aEdited, "com/x/B.java": b, "com/x/C.java": c},
+			cached:   []string{"com/x/A.java", "com/x/B.java", "com/x/C.java"},
+			compile:  []string{"com/x/A.java", "com/x/B.java"}, unchanged: 1,
+		},
+		{
+			name:     "new file",
+			previous: map[string]string{"com/x/A.java": a},
+			sources:  map[string]string{"com/x/A.java": a, "com/x/C.java": c},
+			cached:   []string{"com/x/A.java"},
+			compile:  []string{"com/x/C.java"}, unchanged: 1,
+		},
+		{
+			name:     "removed file recompiles the files mentioning it",
+			previous: map[string]string{"com/x/A.java": a, "com/x/B.java": b, "com/x/C.java": c},
+			sources:  map[string]string{"com/x/B.java": b, "com/x/C.java": c},
+			cached:   []string{"com/x/B.java", "com/x/C.java"},
+			compile:  []string{"com/x/B.java"}, removed: []string{"com/x/A.java"}, unchanged: 1,
+		},
+		{
+			name:     "missing class is recompiled",
+			previous: map[string]string{"com/x/A.java": a, "com/x/C.java": c},
+			sources:  map[string]string{"com/x/A.java": a, "com/x/C.java": c},
+			cached:   []string{"com/x/A.java"},
+			compile:  []string{"com/x/C.java"}, unchanged: 1,
+		},
+		{
+			name:      "package-info has no class",
+			previous:  map[string]string{"com/x/A.java": a, "com/x/package-info.java": info},
+			sources:   map[string]string{"com/x/A.java": a, "com/x/package-info.java": info},
+			cached:    []string{"com/x/A.java"},
+			unchanged: 2,
+		},
+		{
+			name:      "classpath changed",
+			previous:  map[string]string{"com/x/A.java": a},
+			classpath: "old.jar",
+			sources:   map[string]string{"com/x/A.java": a},
+			cached:    []string{"com/x/A.java"},
+			full:      true,
+			compile:   []string{"com/x/A.java"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Chdir(dir)
+			srcDir := filepath.Join(dir, "src")
+			config := &Config{SourceDir: dir, ExtensionFolder: "X"}
+
+			var files []string
+			for _, key := range slices.Sorted(maps.Keys(tt.sources)) {
+				file := filepath.Join(srcDir, filepath.FromSlash(key))
+				writeTestFile(t, file, tt.sources[key])
+				files = append(files, file)
+			}
+			for _, key := range tt.cached {
+				writeTestFile(t, classFile(classCacheDir(config), key, sourceRecord{Package: "com.x"}), "")
+			}
+			if tt.previous != nil {
+				manifest := buildManifest{Classpath: tt.classpath, Options: javacOptions(config), Sources: map[string]sourceRecord{}}
+				for key, content := range tt.previous {
+					sum := sha256.Sum256([]byte(content))
+					manifest.Sources[key] = sourceRecord{Hash: hex.EncodeToString(sum[:]), Package: "com.x"}
+				}
+				os.MkdirAll(filepath.Dir(buildManifestPath(config)), 0755)
+				if err := saveBuildManifest(config, manifest); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			plan, err := planCompile(config, srcDir, files, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			slices.Sort(plan.Removed)
+			if plan.Full != tt.full {
+				t.Errorf("Full = %v, want %v", plan.Full, tt.full)
+			}
+			if !slices.Equal(plan.compileKeys, tt.compile) {
+				t.Errorf("compile = %v, want %v", plan.compileKeys, tt.compile)
+			}
+			if !slices.Equal(plan.Removed, tt.removed) {
+				t.Errorf("removed = %v, want %v", plan.Removed, tt.removed)
+			}
+			if plan.Unchanged != tt.unchanged {
+				t.Errorf("unchanged = %d, want %d", plan.Unchanged, tt.unchanged)
+			}
+		})
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,100 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const defaultJavaVersion = "11"
+
+// javaConstraint is one term of java_version, e.g. ">=11".
+type javaConstraint struct {
+	op    string
+	major int
+}
+
+func (c javaConstraint) allows(major int) bool {
+	switch c.op {
+	case ">=":
+		return major >= c.major
+	case ">":
+		return major > c.major
+	case "<=":
+		return major <= c.major
+	case "<":
+		return major < c.major
+	}
+	return major == c.major
+}
+
+var constraintPattern = regexp.MustCompile(`^(>=|<=|>|<|=)?(\d+)(\+)?$`)
+
+// parseJavaVersion parses java_version: space-separated terms that must all
+// hold, each a major version with an optional comparison ("17", ">=11 <18").
+// "11+" is short for ">=11".
+func parseJavaVersion(spec string) ([]javaConstraint, error) {
+	var constraints []javaConstraint
+	for _, term := range strings.Fields(spec) {
+		m := constraintPattern.FindStringSubmatch(term)
+		if m == nil {
+			return nil, fmt.Errorf("invalid java_version term %q", term)
+		}
+		major, _ := strconv.Atoi(m[2])
+		op := m[1]
+		if m[3] != "" {
+			if op != "" {
+				return nil, fmt.Errorf("invalid java_version term %q", term)
+			}
+			op = ">="
+		}
+		constraints = append(constraints, javaConstraint{op, major})
+	}
+	if len(constraints) == 0 {
+		return nil, fmt.Errorf("java_version is empty")
+	}
+	return constraints, nil
+}
+
+func javaVersionSpec(config *Config) string {
+	if config.JavaVersion != "" {
+		return config.JavaVersion
+	}
+	return defaultJavaVersion
+}
+
+// javaVersionMatches reports whether a JDK major version satisfies
+// java_version. An invalid java_version is reported by setupJava.
+func javaVersionMatches(config *Config, major int) bool {
+	constraints, err := parseJavaVersion(javaVersionSpec(config))
+	if err != nil {
+		return false
+	}
+	for _, c := range constraints {
+		if !c.allows(major) {
+			return false
+		}
+	}
+	return true
+}
+
+var javacVersionPattern = regexp.MustCompile(`javac (\d+)(?:\.(\d+))?`)
+
+// javacMajor returns the major version reported by javac -version, mapping
+// the old 1.8 scheme to 8.
+func javacMajor(javacPath string) (int, error) {
+	output, err := newCommand(javacPath, "-version").CombinedOutput()
+	if err != nil {
+		return 0, err
+	}
+	m := javacVersionPattern.FindStringSubmatch(string(output))
+	if m == nil {
+		return 0, fmt.Errorf("unrecognized javac version: %s", strings.TrimSpace(string(output)))
+	}
+	major, _ := strconv.Atoi(m[1])
+	if major == 1 && m[2] != "" {
+		major, _ = strconv.Atoi(m[2])
+	}
+	return major, nil
+}
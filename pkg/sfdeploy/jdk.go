@@ -0,0 +1,210 @@
+package sfdeploy
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// jdkCandidate is a JDK found on the machine.
+type jdkCandidate struct {
+	BinDir string
+	Major  int
+	Source string
+}
+
+// jdkLocation is a bin directory that may hold a JDK, and where it was
+// found.
+type jdkLocation struct {
+	binDir string
+	source string
+}
+
+// jdkGlobs returns the bin directories of the JDK homes matching patterns.
+func jdkGlobs(source string, patterns ...string) []jdkLocation {
+	var locations []jdkLocation
+	for _, pattern := range patterns {
+		matches, _ := filepath.Glob(pattern)
+		for _, home := range matches {
+			locations = append(locations, jdkLocation{filepath.Join(home, "bin"), source})
+		}
+	}
+	return locations
+}
+
+// jdkLocations lists every place a JDK may be installed, in order of preference:
+// JAVA_HOME, PATH, version managers, vendor default locations, on Windows
+// the registry, and last the JDKs sfdeploy downloaded.
+func jdkLocations() []jdkLocation {
+	var locations []jdkLocation
+	if javaHome := os.Getenv("JAVA_HOME"); javaHome != "" {
+		locations = append(locations, jdkLocation{filepath.Join(javaHome, "bin"), "JAVA_HOME"})
+	}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir != "" {
+			locations = append(locations, jdkLocation{dir, "PATH"})
+		}
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		sdkman := os.Getenv("SDKMAN_DIR")
+		if sdkman == "" {
+			sdkman = filepath.Join(home, ".sdkman")
+		}
+		locations = append(locations, jdkGlobs("SDKMAN", filepath.Join(sdkman, "candidates", "java", "*"))...)
+		locations = append(locations, jdkGlobs("jabba", filepath.Join(home, ".jabba", "jdk", "*"))...)
+		locations = append(locations, jdkGlobs("asdf", filepath.Join(home, ".asdf", "installs", "java", "*"))...)
+		locations = append(locations, jdkGlobs("IntelliJ", filepath.Join(home, ".jdks", "*"))...)
+		if runtime.GOOS == "darwin" {
+			locations = append(locations, jdkGlobs("user JVMs", filepath.Join(home, "Library", "Java", "JavaVirtualMachines", "*", "Contents", "Home"))...)
+		}
+	}
+
+	switch runtime.GOOS {
+	case "windows":
+		locations = append(locations, jdkGlobs("Adoptium",
+			`C:\Program Files\Eclipse Adoptium\*`,
+			`C:\Program Files (x86)\Eclipse Adoptium\*`,
+			`C:\Program Files\Eclipse Foundation\*`,
+		)...)
+		locations = append(locations, jdkGlobs("Program Files",
+			`C:\Program Files\Java\*`,
+			`C:\Program Files\OpenJDK\*`,
+			`C:\Program Files\Microsoft\jdk-*`,
+			`C:\Program Files\Zulu\*`,
+			`C:\Program Files\Amazon Corretto\*`,
+		)...)
+		locations = append(locations, registryJDKLocations()...)
+	case "darwin":
+		locations = append(locations, jdkGlobs("JavaVirtualMachines", "/Library/Java/JavaVirtualMachines/*/Contents/Home")...)
+	default:
+		locations = append(locations, jdkGlobs("system", "/usr/lib/jvm/*", "/opt/java/*", "/usr/java/*")...)
+	}
+	return append(locations, cachedJDKs()...)
+}
+
+// registryJDKKeys are the registry keys Oracle, Adoptium and Eclipse
+// installers record their JDKs under.
+var registryJDKKeys = []string{
+	`HKLM\SOFTWARE\JavaSoft\JDK`,
+	`HKLM\SOFTWARE\JavaSoft\Java Development Kit`,
+	`HKLM\SOFTWARE\Eclipse Adoptium\JDK`,
+	`HKLM\SOFTWARE\Eclipse Foundation\JDK`,
+	`HKLM\SOFTWARE\WOW6432Node\JavaSoft\JDK`,
+}
+
+var registryValuePattern = regexp.MustCompile(`^\s+(JavaHome|Path)\s+REG_SZ\s+(.+)$`)
+
+func registryJDKLocations() []jdkLocation {
+	var locations []jdkLocation
+	for _, key := range registryJDKKeys {
+		output, err := newCommand("reg", "query", key, "/s").Output()
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(output), "\n") {
+			if m := registryValuePattern.FindStringSubmatch(strings.TrimRight(line, "\r")); m != nil {
+				locations = append(locations, jdkLocation{filepath.Join(strings.TrimSpace(m[2]), "bin"), "registry"})
+			}
+		}
+	}
+	return locations
+}
+
+func javacIn(binDir string) string {
+	return javaTool(&Config{JavaPath: binDir}, "javac")
+}
+
+// findJDKs returns each distinct JDK matching java_version.
+func findJDKs(config *Config) []jdkCandidate {
+	var found []jdkCandidate
+	seen := map[string]bool{}
+	for _, location := range jdkLocations() {
+		binDir := location.binDir
+		if !hasJavac(binDir) {
+			continue
+		}
+		key := binDir
+		if resolved, err := filepath.EvalSymlinks(javacIn(binDir)); err == nil {
+			key = resolved
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		major, err := javacMajor(javacIn(binDir))
+		if err != nil {
+			debugf("🐛 skipping %s: %v\n", binDir, err)
+			continue
+		}
+		if !javaVersionMatches(config, major) {
+			debugf("🐛 skipping Java %d at %s (%s)\n", major, binDir, location.source)
+			continue
+		}
+		found = append(found, jdkCandidate{binDir, major, location.source})
+	}
+	return found
+}
+
+// findJavaPath returns the bin directory of a JDK matching java_version,
+// asking which one to use when several match.
+func findJavaPath(config *Config) string {
+	candidates := findJDKs(config)
+	switch {
+	case len(candidates) == 1:
+		return candidates[0].BinDir
+	case len(candidates) > 1:
+		return pickJDK(candidates)
+	}
+
+	tprintf("❌ Java %s not found automatically\n", javaVersionSpec(config))
+	if binDir := provisionJDK(config); binDir != "" {
+		return binDir
+	}
+	if *flagNoPrompt {
+		return ""
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	userPath := promptPath(reader, fmt.Sprintf(tr("Please enter the path to a Java %s bin directory (or press Enter to skip)"), javaVersionSpec(config)), "")
+
+	if userPath != "" && hasJavac(userPath) {
+		return userPath
+	}
+
+	return ""
+}
+
+// pickJDK lets the user choose among several matching JDKs. Without a
+// prompt the first, most preferred one is used.
+func pickJDK(candidates []jdkCandidate) string {
+	if *flagNoPrompt {
+		return candidates[0].BinDir
+	}
+
+	tprintln("Several matching JDKs were found:")
+	for i, c := range candidates {
+		fmt.Printf("  %d) Java %d  %s (%s)\n", i+1, c.Major, c.BinDir, c.Source)
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		tprintf("Choose a JDK [1-%d, Enter for 1]: ", len(candidates))
+		response, err := reader.ReadString('\n')
+		response = strings.TrimSpace(response)
+		if response == "" || err != nil {
+			return candidates[0].BinDir
+		}
+		if n, err := strconv.Atoi(response); err == nil && n >= 1 && n <= len(candidates) {
+			tprintln("💡 Set java_path in the config to skip this question")
+			return candidates[n-1].BinDir
+		}
+		tprintf("Please enter a number from 1 to %d\n", len(candidates))
+	}
+}
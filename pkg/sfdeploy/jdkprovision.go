@@ -0,0 +1,260 @@
+package sfdeploy
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// adoptiumAPI is the Eclipse Adoptium API that Temurin JDKs are downloaded
+// from.
+var adoptiumAPI = "https://api.adoptium.net/v3"
+
+// ltsJavaVersions are preferred when java_version allows several releases.
+var ltsJavaVersions = []int{8, 11, 17, 21, 25}
+
+// archiveClient has no overall timeout because JDK and compiler archives
+// are downloads of up to 200 MB.
+var archiveClient = &http.Client{}
+
+// adoptiumAsset is the part of an Adoptium assets response sfdeploy uses.
+type adoptiumAsset struct {
+	ReleaseName string `json:"release_name"`
+	Binary      struct {
+		Package struct {
+			Name     string `json:"name"`
+			Link     string `json:"link"`
+			Checksum string `json:"checksum"`
+			Size     int64  `json:"size"`
+		} `json:"package"`
+	} `json:"binary"`
+}
+
+func jdkCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "sfdeploy", "jdks")
+}
+
+// cachedJDKs lists the JDKs downloaded earlier.
+func cachedJDKs() []jdkLocation {
+	cache := jdkCacheDir()
+	return jdkGlobs("sfdeploy cache", filepath.Join(cache, "*"), filepath.Join(cache, "*", "Contents", "Home"))
+}
+
+// provisionVersion picks the release to download for java_version: the
+// oldest LTS release it allows, or else the oldest release it allows.
+func provisionVersion(config *Config) int {
+	for _, major := range ltsJavaVersions {
+		if javaVersionMatches(config, major) {
+			return major
+		}
+	}
+	for major := 8; major <= 40; major++ {
+		if javaVersionMatches(config, major) {
+			return major
+		}
+	}
+	return 0
+}
+
+func adoptiumPlatform() (string, string) {
+	goos := runtime.GOOS
+	if goos == "darwin" {
+		goos = "mac"
+	}
+	arch := map[string]string{"amd64": "x64", "arm64": "aarch64", "386": "x32", "arm": "arm"}[runtime.GOARCH]
+	return goos, arch
+}
+
+// provisionJDK offers to download a Temurin JDK when no installed JDK
+// matches, and returns its bin directory or "".
+func provisionJDK(config *Config) string {
+	major := provisionVersion(config)
+	if major == 0 {
+		return ""
+	}
+
+	if !config.JDKDownload {
+		if *flagNoPrompt {
+			tprintf("💡 Set jdk_download to true to download Temurin JDK %d automatically\n", major)
+			return ""
+		}
+		if !askYesNo(fmt.Sprintf(tr("Download Eclipse Temurin JDK %d into %s? (y/n): "), major, jdkCacheDir())) {
+			return ""
+		}
+	}
+
+	binDir, err := downloadJDK(major)
+	if err != nil {
+		tprintf("❌ Failed to download JDK %d: %v\n", major, err)
+		return ""
+	}
+	tprintf("✅ Installed JDK %d in %s\n", major, filepath.Dir(binDir))
+	return binDir
+}
+
+func downloadJDK(major int) (string, error) {
+	goos, arch := adoptiumPlatform()
+	url := fmt.Sprintf("%s/assets/latest/%d/hotspot?os=%s&architecture=%s&image_type=jdk&vendor=eclipse", adoptiumAPI, major, goos, arch)
+
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	var assets []adoptiumAsset
+	if err := json.NewDecoder(resp.Body).Decode(&assets); err != nil {
+		return "", fmt.Errorf("invalid Adoptium response: %w", err)
+	}
+	if len(assets) == 0 {
+		return "", fmt.Errorf("no Temurin %d build for %s/%s", major, goos, arch)
+	}
+	asset := assets[0]
+	pkg := asset.Binary.Package
+
+	cache := jdkCacheDir()
+	if err := os.MkdirAll(cache, 0755); err != nil {
+		return "", err
+	}
+	archive := filepath.Join(cache, pkg.Name+".part")
+	defer os.Remove(archive)
+
+	tprintf("📥 Downloading %s (%d MB)...\n", pkg.Name, pkg.Size>>20)
+	if err := downloadArchive(pkg.Link, archive); err != nil {
+		return "", err
+	}
+	if sum, err := hashFile(archive); err != nil {
+		return "", err
+	} else if !strings.EqualFold(sum, pkg.Checksum) {
+		return "", fmt.Errorf("checksum mismatch for %s", pkg.Name)
+	}
+
+	tprintf("📦 Extracting %s...\n", pkg.Name)
+	extract := filepath.Join(cache, asset.ReleaseName+".extract")
+	os.RemoveAll(extract)
+	defer os.RemoveAll(extract)
+	if strings.HasSuffix(pkg.Name, ".zip") {
+		err = unzipTo(archive, extract)
+	} else {
+		err = untarGz(archive, extract)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// The archive holds a single top-level folder named after the release
+	entries, err := os.ReadDir(extract)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) != 1 || !entries[0].IsDir() {
+		return "", fmt.Errorf("unexpected layout in %s", pkg.Name)
+	}
+	home := filepath.Join(cache, asset.ReleaseName)
+	os.RemoveAll(home)
+	if err := os.Rename(filepath.Join(extract, entries[0].Name()), home); err != nil {
+		return "", err
+	}
+
+	binDir := filepath.Join(home, "bin")
+	if macHome := filepath.Join(home, "Contents", "Home"); fileExists(macHome) {
+		binDir = filepath.Join(macHome, "bin")
+	}
+	if !hasJavac(binDir) {
+		return "", fmt.Errorf("no javac in %s", binDir)
+	}
+	return binDir, nil
+}
+
+func downloadArchive(url, dst string) error {
+	resp, err := archiveClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+
+	file, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	p := startProgress("📥 Downloading", max(resp.ContentLength, 0), bytesDetail)
+	defer p.finish()
+	_, err = io.Copy(file, io.TeeReader(resp.Body, p))
+	return err
+}
+
+// untarGz extracts a .tar.gz archive, keeping file modes and symlinks.
+func untarGz(path, dir string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		dst := filepath.Join(dir, filepath.FromSlash(header.Name))
+		if !strings.HasPrefix(dst, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return fmt.Errorf("invalid path in archive: %s", header.Name)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(dst, 0755); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+				return err
+			}
+			if err := os.Symlink(header.Linkname, dst); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(header.Mode)&0777)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(out, tr)
+			out.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// jvmConfig holds JVM options sfdeploy writes into the SFS2X launcher.
+type jvmConfig struct {
+	MinHeap   string   `json:"min_heap"`
+	MaxHeap   string   `json:"max_heap"`
+	DebugPort int      `json:"debug_port"`
+	Args      []string `json:"args"`
+}
+
+const (
+	jvmBlockBegin = "sfdeploy jvm_options begin, managed by sfdeploy"
+	jvmBlockEnd   = "sfdeploy jvm_options end"
+	jvmOptsVar    = "SFDEPLOY_JVM_OPTS"
+)
+
+// javaCommand matches the java executable in a launcher line, quoted or not,
+// with or without a path and .exe.
+var javaCommand = regexp.MustCompile(`(?i)(^|[\s"'/\\@])java(\.exe)?["']?(\s|$)`)
+
+// jvmArgs are the options jvm_options asks for, in launcher order.
+func jvmArgs(config *Config) []string {
+	jvm := config.JVMOptions
+	var args []string
+	if jvm.MinHeap != "" {
+		args = append(args, "-Xms"+jvm.MinHeap)
+	}
+	if jvm.MaxHeap != "" {
+		args = append(args, "-Xmx"+jvm.MaxHeap)
+	}
+	args = append(args, jvm.Args...)
+	if jvm.DebugPort > 0 {
+		args = append(args, "-agentlib:jdwp=transport=dt_socket,server=y,suspend=n,address=*:"+strconv.Itoa(jvm.DebugPort))
+	}
+	return args
+}
+
+// launcherFile is the launcher of the target whose JVM options are patched.
+func launcherFile(config *Config) string {
+	if _, ok := parseShellTarget(config.TargetDir); !ok && runtime.GOOS == "windows" {
+		return "sfs2x.bat"
+	}
+	return "sfs2x.sh"
+}
+
+// isJavaLine reports whether line runs java rather than mentioning it in a
+// comment.
+func isJavaLine(line string, bat bool) bool {
+	trimmed := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "@")))
+	if bat && (strings.HasPrefix(trimmed, "rem ") || strings.HasPrefix(trimmed, "::")) || !bat && strings.HasPrefix(trimmed, "#") {
+		return false
+	}
+	return javaCommand.MatchString(line)
+}
+
+// patchLauncher rewrites a launcher script so the JVM starts with args. The
+// options live in a marked block that is replaced on every run, and the
+// java command line refers to them through SFDEPLOY_JVM_OPTS, so patching
+// again changes nothing and empty args undo the patch. install4j launchers
+// pick the options up from INSTALL4J_ADD_VM_PARAMS instead.
+func patchLauncher(script string, bat bool, args []string) (string, error) {
+	newline := "\n"
+	if strings.Contains(script, "\r\n") {
+		newline = "\r\n"
+	}
+	lines := strings.Split(strings.ReplaceAll(script, "\r\n", "\n"), "\n")
+
+	ref := " $" + jvmOptsVar
+	if bat {
+		ref = " %" + jvmOptsVar + "%"
+	}
+
+	// Drop the previous block and references
+	var kept []string
+	inBlock := false
+	for _, line := range lines {
+		switch {
+		case strings.Contains(line, jvmBlockBegin):
+			inBlock = true
+		case inBlock:
+			inBlock = !strings.Contains(line, jvmBlockEnd)
+		default:
+			kept = append(kept, strings.Replace(line, ref, "", 1))
+		}
+	}
+	lines = kept
+	if len(args) == 0 {
+		return strings.Join(lines, newline), nil
+	}
+
+	install4j := strings.Contains(script, "INSTALL4J")
+	var block []string
+	if bat {
+		block = []string{
+			"rem " + jvmBlockBegin,
+			fmt.Sprintf(`set "%s=%s"`, jvmOptsVar, strings.Join(args, " ")),
+			"rem " + jvmBlockEnd,
+		}
+	} else {
+		block = []string{"# " + jvmBlockBegin, jvmOptsVar + "=" + shellQuote(strings.Join(args, " "))}
+		if install4j {
+			block = append(block, `INSTALL4J_ADD_VM_PARAMS="$INSTALL4J_ADD_VM_PARAMS $`+jvmOptsVar+`"`, "export INSTALL4J_ADD_VM_PARAMS")
+		}
+		block = append(block, "# "+jvmBlockEnd)
+	}
+
+	if !install4j {
+		found := false
+		for i, line := range lines {
+			if !isJavaLine(line, bat) {
+				continue
+			}
+			// Insert after "java", "java.exe" or its closing quote
+			end := javaCommand.FindStringSubmatchIndex(line)[6]
+			lines[i] = line[:end] + ref + line[end:]
+			found = true
+		}
+		if !found {
+			return "", fmt.Errorf("no java command found")
+		}
+	}
+
+	// The block goes after the shebang or @echo off
+	at := 0
+	if len(lines) > 0 && (strings.HasPrefix(lines[0], "#!") || bat && strings.EqualFold(strings.TrimSpace(lines[0]), "@echo off")) {
+		at = 1
+	}
+	lines = append(lines[:at:at], append(block, lines[at:]...)...)
+	return strings.Join(lines, newline), nil
+}
+
+// applyJVMOptions brings the launcher of the target in line with
+// jvm_options before the server is started. The launcher is saved once as
+// <launcher>.sfdeploy-orig before it is first changed.
+func applyJVMOptions(config *Config) bool {
+	args := jvmArgs(config)
+	launcher := launcherFile(config)
+	rel := "SFS2X/" + launcher
+
+	data, err := readTargetFile(config, rel)
+	if err != nil {
+		if len(args) > 0 {
+			tprintf("⚠️ Warning: jvm_options are set but %s could not be read: %v\n", launcher, err)
+		}
+		return true
+	}
+	if len(args) == 0 && !strings.Contains(string(data), jvmBlockBegin) {
+		return true
+	}
+	if len(args) > 0 && launcher == "sfs2x.bat" && findWindowsService(config) != "" {
+		tprintln("⚠️ Warning: jvm_options only apply to sfs2x.bat, not to the Windows service")
+	}
+
+	patched, err := patchLauncher(string(data), launcher == "sfs2x.bat", args)
+	if err != nil {
+		tprintf("❌ Failed to apply jvm_options to %s: %v\n", launcher, err)
+		return false
+	}
+	if patched == string(data) {
+		verbosef("   JVM options in %s are up to date\n", launcher)
+		return true
+	}
+
+	if len(args) == 0 {
+		tprintf("☕ Removing the JVM options from %s\n", launcher)
+	} else {
+		tprintf("☕ Setting the JVM options in %s: %s\n", launcher, strings.Join(args, " "))
+	}
+	if *flagDryRun {
+		tprintf("[dry-run] Would rewrite %s\n", rel)
+		return true
+	}
+
+	if _, err := readTargetFile(config, rel+".sfdeploy-orig"); err != nil {
+		if err := writeTargetFile(config, rel+".sfdeploy-orig", data); err != nil {
+			tprintf("❌ Failed to back up %s: %v\n", launcher, err)
+			return false
+		}
+	}
+	if err := writeTargetFile(config, rel, []byte(patched)); err != nil {
+		tprintf("❌ Failed to write %s: %v\n", launcher, err)
+		return false
+	}
+	// A copy into a remote or container target does not keep the mode
+	if target, ok := parseShellTarget(config.TargetDir); ok && launcher == "sfs2x.sh" {
+		if output, err := target.run(config, "chmod +x "+shellQuote(target.path(rel))); err != nil {
+			tprintf("⚠️ Warning: Could not make %s executable: %s\n", launcher, strings.TrimSpace(string(output)))
+		}
+	}
+	return true
+}
@@ -0,0 +1,227 @@
+package sfdeploy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// defaultKotlinVersion is the compiler downloaded when kotlinc is not
+// installed and kotlin_version is not set.
+const defaultKotlinVersion = "2.0.21"
+
+const kotlinStdlibJar = "kotlin-stdlib.jar"
+
+func findKotlinFiles(config *Config, srcDir string) []string {
+	var files []string
+	ignore := sourceIgnore(config)
+	filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && ignore.skip(path, info.IsDir()) {
+			return skipWalk(info)
+		}
+		if err == nil && strings.HasSuffix(strings.ToLower(info.Name()), ".kt") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files
+}
+
+func kotlinClassesDir(config *Config) string {
+	return filepath.Join(stateDir, "build", config.ExtensionFolder, "kotlin-classes")
+}
+
+// kotlinStdlibCopy is where the build keeps the Kotlin runtime that is
+// deployed next to the extension JAR.
+func kotlinStdlibCopy(config *Config) string {
+	return filepath.Join(stateDir, "build", config.ExtensionFolder, kotlinStdlibJar)
+}
+
+// kotlinSourcesHash fingerprints the Kotlin sources so a change to them
+// triggers a full rebuild of the Java sources that may use them.
+func kotlinSourcesHash(config *Config, base string) string {
+	files := projectKotlinFiles(config)
+	if len(files) == 0 {
+		return ""
+	}
+	sort.Strings(files)
+
+	sum := sha256.New()
+	for _, file := range files {
+		hash, _ := hashFile(file)
+		rel, _ := filepath.Rel(base, file)
+		fmt.Fprintf(sum, "%s %s\n", filepath.ToSlash(rel), hash)
+	}
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+func kotlinVersion(config *Config) string {
+	if config.KotlinVersion != "" {
+		return config.KotlinVersion
+	}
+	return defaultKotlinVersion
+}
+
+func kotlincName() string {
+	if runtime.GOOS == "windows" {
+		return "kotlinc.bat"
+	}
+	return "kotlinc"
+}
+
+func kotlinCacheDir(config *Config) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "sfdeploy", "kotlin", kotlinVersion(config))
+}
+
+// findKotlinc returns kotlinc from kotlinc_path, PATH, KOTLIN_HOME, SDKMAN
+// or an earlier download, downloading the compiler when none is found.
+func findKotlinc(config *Config) (string, error) {
+	if config.KotlincPath != "" {
+		path := config.KotlincPath
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			path = filepath.Join(path, kotlincName())
+		}
+		if !fileExists(path) {
+			return "", fmt.Errorf("kotlinc_path %s does not exist", config.KotlincPath)
+		}
+		return path, nil
+	}
+
+	if path, err := exec.LookPath(kotlincName()); err == nil {
+		return path, nil
+	}
+
+	var candidates []string
+	if home := os.Getenv("KOTLIN_HOME"); home != "" {
+		candidates = append(candidates, filepath.Join(home, "bin", kotlincName()))
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates, filepath.Join(home, ".sdkman", "candidates", "kotlin", "current", "bin", kotlincName()))
+	}
+	candidates = append(candidates, filepath.Join(kotlinCacheDir(config), "kotlinc", "bin", kotlincName()))
+	for _, path := range candidates {
+		if fileExists(path) {
+			return path, nil
+		}
+	}
+
+	return downloadKotlinc(config)
+}
+
+// downloadKotlinc fetches the compiler release from GitHub into the cache.
+func downloadKotlinc(config *Config) (string, error) {
+	version := kotlinVersion(config)
+	name := "kotlin-compiler-" + version + ".zip"
+	url := "https://github.com/JetBrains/kotlin/releases/download/v" + version + "/" + name
+
+	cache := kotlinCacheDir(config)
+	zipPath := filepath.Join(cache, name)
+	defer os.Remove(zipPath)
+
+	tprintf("📥 kotlinc not found, downloading Kotlin %s...\n", version)
+	if err := os.MkdirAll(cache, 0755); err != nil {
+		return "", err
+	}
+	if err := downloadArchive(url, zipPath); err != nil {
+		return "", err
+	}
+	if expected, err := fetchText(url + ".sha256"); err != nil {
+		tprintf("⚠️ Warning: No checksum available for %s\n", name)
+	} else if actual, err := hashFile(zipPath); err != nil {
+		return "", err
+	} else if fields := strings.Fields(expected); len(fields) == 0 || !strings.EqualFold(fields[0], actual) {
+		return "", fmt.Errorf("checksum mismatch for %s", name)
+	}
+
+	os.RemoveAll(filepath.Join(cache, "kotlinc"))
+	if err := unzipTo(zipPath, cache); err != nil {
+		return "", err
+	}
+
+	// Zip entries carry no permissions, so make the launch scripts executable
+	bin := filepath.Join(cache, "kotlinc", "bin")
+	if entries, err := os.ReadDir(bin); err == nil && runtime.GOOS != "windows" {
+		for _, entry := range entries {
+			os.Chmod(filepath.Join(bin, entry.Name()), 0755)
+		}
+	}
+
+	path := filepath.Join(bin, kotlincName())
+	if !fileExists(path) {
+		return "", fmt.Errorf("no %s in %s", kotlincName(), name)
+	}
+	return path, nil
+}
+
+// kotlinStdlib finds the runtime library shipped with kotlinc.
+func kotlinStdlib(kotlinc string) string {
+	if resolved, err := filepath.EvalSymlinks(kotlinc); err == nil {
+		kotlinc = resolved
+	}
+	home := filepath.Dir(filepath.Dir(kotlinc))
+	for _, path := range []string{
+		filepath.Join(home, "lib", kotlinStdlibJar),
+		filepath.Join(home, "libexec", "lib", kotlinStdlibJar),
+	} {
+		if fileExists(path) {
+			return path
+		}
+	}
+	return ""
+}
+
+// compileKotlin compiles the Kotlin sources into their own class folder.
+// The Java sources are passed along so Kotlin code can use Java classes;
+// javac then sees the Kotlin classes on its classpath.
+func compileKotlin(config *Config, kotlinFiles, javaFiles []string, classpath string) bool {
+	kotlinc, err := findKotlinc(config)
+	if err != nil {
+		tprintf("Kotlin compiler not available: %v\n", err)
+		return false
+	}
+
+	outDir := kotlinClassesDir(config)
+	os.RemoveAll(outDir)
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		tprintf("Failed to create %s: %v\n", outDir, err)
+		return false
+	}
+
+	tprintf("Compiling %d Kotlin files...\n", len(kotlinFiles))
+	args := []string{"-cp", classpath, "-d", absPath(outDir)}
+	if config.JavaRelease != "" {
+		args = append(args, "-jvm-target", config.JavaRelease)
+	}
+	for _, file := range append(kotlinFiles, javaFiles...) {
+		args = append(args, absPath(file))
+	}
+
+	cmd := newCommand(kotlinc, args...)
+	cmd.Env = javaHomeEnv(config)
+	p := startProgress("🔨 Compiling Kotlin", 0, nil)
+	output, err := cmd.CombinedOutput()
+	p.finish()
+	if err != nil {
+		tprintf("Kotlin compilation failed: %s\n", string(output))
+		return false
+	}
+
+	if stdlib := kotlinStdlib(kotlinc); stdlib != "" {
+		if err := copyFile(stdlib, kotlinStdlibCopy(config)); err != nil {
+			tprintf("Warning: Could not keep %s for deploy: %v\n", kotlinStdlibJar, err)
+		}
+	} else {
+		tprintf("Warning: %s not found next to %s, deploy it with lib_jars\n", kotlinStdlibJar, kotlinc)
+	}
+	return true
+}
@@ -0,0 +1,97 @@
+package sfdeploy
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// versionSuffix matches the -<version> part of names like gson-2.10.1.jar or
+// netty-all-4.1.100.Final.jar.
+var versionSuffix = regexp.MustCompile(`-\d[\w.+-]*$`)
+
+// libJars expands the lib_jars entries (files, directories or globs relative
+// to source_dir) into the JARs to deploy.
+func libJars(config *Config) []string {
+	var jars []string
+	for _, lib := range config.LibJars {
+		if !filepath.IsAbs(lib) {
+			lib = filepath.Join(config.SourceDir, lib)
+		}
+		matches := expandClasspathEntry(lib)
+		if len(matches) == 0 {
+			tprintf("⚠️ Warning: lib_jars entry matched no JAR files: %s\n", lib)
+		}
+		jars = append(jars, matches...)
+	}
+	return jars
+}
+
+// artifactName strips the version and extension from a JAR file name, so
+// gson-2.8.9.jar and gson-2.10.1.jar are recognised as the same artifact.
+func artifactName(file string) string {
+	name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	return versionSuffix.ReplaceAllString(name, "")
+}
+
+// staleLibJars returns the JARs in the extension's __lib__ folder that are
+// other versions of a JAR about to be deployed there.
+func staleLibJars(config *Config, items []deployItem, existing []string) []string {
+	prefix := config.ExtensionFolder + "/__lib__/"
+
+	incoming := map[string]string{}
+	for _, item := range items {
+		if name, ok := strings.CutPrefix(item.Target, prefix); ok {
+			incoming[artifactName(name)] = name
+		}
+	}
+
+	var stale []string
+	for _, name := range existing {
+		if !strings.HasSuffix(strings.ToLower(name), ".jar") {
+			continue
+		}
+		if current, ok := incoming[artifactName(name)]; ok && current != name {
+			stale = append(stale, name)
+		}
+	}
+	return stale
+}
+
+func localLibJars(config *Config) []string {
+	return localFiles(filepath.Join(extensionDir(config), "__lib__"))
+}
+
+func shellLibJars(config *Config, target shellTarget) []string {
+	return shellFiles(config, target, target.path("SFS2X", "extensions", config.ExtensionFolder, "__lib__"))
+}
+
+// localFiles and shellFiles list the file names in a target directory.
+func localFiles(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names
+}
+
+func shellFiles(config *Config, target shellTarget, dir string) []string {
+	output, err := target.run(config, "ls -1p "+shellQuote(dir)+" 2>/dev/null")
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, name := range strings.FieldsFunc(string(output), func(r rune) bool { return r == '\n' || r == '\r' }) {
+		if !strings.HasSuffix(name, "/") {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,201 @@
+package sfdeploy
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+const (
+	defaultListenAddr   = "127.0.0.1:9090"
+	defaultListenBranch = "main"
+	maxWebhookBody      = 5 << 20
+)
+
+// pushEvent is the part of a GitHub or GitLab push payload sfdeploy uses.
+type pushEvent struct {
+	Ref    string `json:"ref"`
+	After  string `json:"after"`
+	Pusher struct {
+		Name string `json:"name"`
+	} `json:"pusher"`
+	UserName string `json:"user_name"`
+}
+
+func (e pushEvent) by() string {
+	if e.Pusher.Name != "" {
+		return e.Pusher.Name
+	}
+	return e.UserName
+}
+
+func listenBranch(config *Config) string {
+	if config.ListenBranch != "" {
+		return config.ListenBranch
+	}
+	return defaultListenBranch
+}
+
+// verifyWebhook checks the GitHub HMAC signature or the GitLab token
+// against webhook_secret.
+func verifyWebhook(config *Config, r *http.Request, body []byte) bool {
+	if config.WebhookSecret == "" {
+		return true
+	}
+	if signature := r.Header.Get("X-Hub-Signature-256"); signature != "" {
+		mac := hmac.New(sha256.New, []byte(config.WebhookSecret))
+		mac.Write(body)
+		expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+		return hmac.Equal([]byte(signature), []byte(expected))
+	}
+	if token := r.Header.Get("X-Gitlab-Token"); token != "" {
+		return subtle.ConstantTimeCompare([]byte(token), []byte(config.WebhookSecret)) == 1
+	}
+	return false
+}
+
+// webhookHandler accepts push events for the listened branch and queues a
+// deploy. Pushes that arrive while one is queued are merged into it.
+func webhookHandler(config *Config, pushes chan<- pushEvent) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "POST a push webhook here", http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBody))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !verifyWebhook(config, r, body) {
+			tprintf("⚠️ Rejected webhook from %s: bad signature\n", r.RemoteAddr)
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+
+		event := r.Header.Get("X-GitHub-Event")
+		if event == "" {
+			event = r.Header.Get("X-Gitlab-Event")
+		}
+		if event == "ping" {
+			fmt.Fprintln(w, "pong")
+			return
+		}
+		if event != "push" && event != "Push Hook" {
+			fmt.Fprintf(w, "ignored %s event\n", event)
+			return
+		}
+
+		var push pushEvent
+		if err := json.Unmarshal(body, &push); err != nil {
+			http.Error(w, "invalid payload: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if push.Ref != "refs/heads/"+listenBranch(config) {
+			fmt.Fprintf(w, "ignored push to %s\n", push.Ref)
+			return
+		}
+
+		select {
+		case pushes <- push:
+		default:
+			// A deploy is already queued and will pick up this push too
+		}
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprintln(w, "deploy queued")
+	}
+}
+
+func listenForPushes(config *Config) bool {
+	tprintln("👂 Listen Mode")
+
+	addr := config.ListenAddr
+	if addr == "" {
+		addr = defaultListenAddr
+	}
+	if config.WebhookSecret == "" {
+		tprintln("⚠️ Warning: webhook_secret is not set, anyone who can reach this port can trigger a deploy")
+	}
+
+	pushes := make(chan pushEvent, 1)
+	server := &http.Server{Addr: addr, Handler: webhookHandler(config, pushes), ReadHeaderTimeout: 10 * time.Second}
+
+	failed := make(chan error, 1)
+	go func() {
+		failed <- server.ListenAndServe()
+	}()
+	defer server.Close()
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	tprintf("Listening on %s for pushes to %s (Ctrl+C to stop)\n", addr, listenBranch(config))
+	fmt.Println()
+
+	for {
+		select {
+		case push := <-pushes:
+			runListenCycle(config, push)
+			tprintf("Listening on %s for pushes to %s (Ctrl+C to stop)\n", addr, listenBranch(config))
+			fmt.Println()
+
+		case err := <-failed:
+			tprintf("❌ Webhook listener failed: %v\n", err)
+			return false
+
+		case <-interrupt:
+			tprintln("Stopping listen mode")
+			return true
+		}
+	}
+}
+
+// runListenCycle pulls the branch and runs the same pipeline as watch mode.
+func runListenCycle(config *Config, push pushEvent) {
+	commit := push.After
+	if len(commit) > 8 {
+		commit = commit[:8]
+	}
+	tprintf("📬 Push to %s (%s) by %s at %s\n", listenBranch(config), commit, push.by(), time.Now().Format("15:04:05"))
+
+	started := time.Now()
+
+	tprintf("⬇️ Pulling %s...\n", listenBranch(config))
+	output, err := newCommand("git", "-C", config.SourceDir, "pull", "--ff-only", "origin", listenBranch(config)).CombinedOutput()
+	if err != nil {
+		tprintf("❌ git pull failed: %v\n", err)
+		fmt.Println(strings.TrimSpace(string(output)))
+		fmt.Println()
+		notifyResult(config, "all", false, started)
+		recordMetrics(config, "listen", false, started)
+		return
+	}
+	fmt.Println(strings.TrimSpace(string(output)))
+	fmt.Println()
+
+	for _, run := range watchPhases {
+		if !run(config) {
+			tprintln("❌ Deploy failed, waiting for the next push")
+			fmt.Println()
+			notifyResult(config, "all", false, started)
+			recordMetrics(config, "listen", false, started)
+			return
+		}
+	}
+
+	notifyResult(config, "all", true, started)
+	recordMetrics(config, "listen", true, started)
+	tprintln("Hot deploy completed successfully!")
+	fmt.Println()
+}
@@ -0,0 +1,46 @@
+package sfdeploy
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestVerifyWebhook(t *testing.T) {
+	body := []byte(`{"ref":"refs/heads/main"}`)
+	sign := func(secret string, body []byte) string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	tests := []struct {
+		name    string
+		secret  string
+		headers map[string]string
+		want    bool
+	}{
+		{"no secret accepts anything", "", nil, true},
+		{"GitHub signature", "s3cret", map[string]string{"X-Hub-Signature-256": sign("s3cret", body)}, true},
+		{"GitHub signature with another secret", "s3cret", map[string]string{"X-Hub-Signature-256": sign("other", body)}, false},
+		{"GitHub signature of another body", "s3cret", map[string]string{"X-Hub-Signature-256": sign("s3cret", []byte("{}"))}, false},
+		{"GitHub signature without sha256=", "s3cret", map[string]string{"X-Hub-Signature-256": sign("s3cret", body)[7:]}, false},
+		{"GitLab token", "s3cret", map[string]string{"X-Gitlab-Token": "s3cret"}, true},
+		{"wrong GitLab token", "s3cret", map[string]string{"X-Gitlab-Token": "s3cre"}, false},
+		{"signature wins over token", "s3cret", map[string]string{"X-Hub-Signature-256": "sha256=00", "X-Gitlab-Token": "s3cret"}, false},
+		{"unsigned request", "s3cret", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/", nil)
+			for key, value := range tt.headers {
+				r.Header.Set(key, value)
+			}
+			if got := verifyWebhook(&Config{WebhookSecret: tt.secret}, r, body); got != tt.want {
+				t.Errorf("verifyWebhook() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
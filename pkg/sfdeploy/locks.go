@@ -0,0 +1,122 @@
+package sfdeploy
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultLockRetry = 30
+	lockRetryInitial = 250 * time.Millisecond
+	lockRetryMax     = 5 * time.Second
+)
+
+// Windows error codes for a file that another process holds open.
+const (
+	errorAccessDenied     syscall.Errno = 5
+	errorSharingViolation syscall.Errno = 32
+	errorLockViolation    syscall.Errno = 33
+)
+
+// lockRetry is how long to keep retrying a locked file. A negative
+// lock_retry fails right away.
+func lockRetry(config *Config) time.Duration {
+	if config.LockRetry == 0 {
+		return defaultLockRetry * time.Second
+	}
+	return time.Duration(config.LockRetry) * time.Second
+}
+
+func isLockError(err error) bool {
+	if runtime.GOOS != "windows" {
+		return false
+	}
+	var errno syscall.Errno
+	if !errors.As(err, &errno) {
+		return false
+	}
+	return errno == errorAccessDenied || errno == errorSharingViolation || errno == errorLockViolation
+}
+
+// retryLocked runs op, which writes, renames or removes path, and retries it
+// with exponential backoff while Windows reports the file as locked. On
+// Windows a JVM that is still shutting down keeps its JARs open for a while
+// after its port closes.
+func retryLocked(config *Config, path string, op func() error) error {
+	err := op()
+	if err != nil && isNetworkError(err) {
+		err = retryNetwork(config, path, op, err)
+	}
+	limit := lockRetry(config)
+	if err == nil || !isLockError(err) || limit < 0 {
+		return err
+	}
+
+	tprintf("🔒 %s is locked: %v\n", path, err)
+	if holder := lockHolder(path); holder != "" {
+		tprintf("   Held by %s\n", holder)
+	}
+	tprintf("⏳ Retrying for up to %s...\n", limit)
+
+	deadline := time.Now().Add(limit)
+	delay := lockRetryInitial
+	for time.Now().Add(delay).Before(deadline) {
+		time.Sleep(delay)
+		if err = op(); err == nil {
+			tprintf("🔓 %s was released\n", path)
+			return nil
+		}
+		if !isLockError(err) {
+			return err
+		}
+		delay = min(delay*2, lockRetryMax)
+	}
+	return fmt.Errorf("%w (still locked after %s)", err, limit)
+}
+
+var handleLine = regexp.MustCompile(`^(\S+)\s+pid:\s*(\d+)`)
+
+// lockHolder names the processes holding path: exactly when Sysinternals
+// handle.exe is on PATH, otherwise the SmartFox and Java processes still
+// running.
+func lockHolder(path string) string {
+	if handle, err := exec.LookPath("handle.exe"); err == nil {
+		output, _ := newCommand(handle, "-accepteula", "-nobanner", path).Output()
+		var holders []string
+		for _, line := range strings.Split(string(output), "\n") {
+			if m := handleLine.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+				holders = append(holders, fmt.Sprintf("%s (PID %s)", m[1], m[2]))
+			}
+		}
+		if len(holders) > 0 {
+			return strings.Join(holders, ", ")
+		}
+	}
+
+	output, err := newCommand("tasklist", "/fo", "csv", "/nh").Output()
+	if err != nil {
+		return ""
+	}
+	var running []string
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Split(line, ",")
+		if len(fields) < 2 {
+			continue
+		}
+		name := strings.Trim(fields[0], `"`)
+		switch strings.ToLower(name) {
+		case "sfs2x.exe", "sfs2x-service.exe", "java.exe", "javaw.exe", "wrapper.exe":
+			running = append(running, fmt.Sprintf("%s (PID %s)", name, strings.Trim(fields[1], `"`)))
+		}
+	}
+	if len(running) == 0 {
+		return ""
+	}
+	return "one of " + strings.Join(running, ", ") + " (put handle.exe on PATH to know which)"
+}
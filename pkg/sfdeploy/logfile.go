@@ -0,0 +1,128 @@
+package sfdeploy
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultLogFile     = "sfdeploy.log"
+	defaultLogMaxSize  = 1024 // KB
+	defaultLogMaxFiles = 5
+)
+
+// transcript receives a copy of everything printed during the run. Output
+// is held in memory until the config says where the log file goes.
+type transcript struct {
+	mu      sync.Mutex
+	file    *os.File
+	pending bytes.Buffer
+	closed  bool
+}
+
+var runLog = &transcript{}
+
+func (t *transcript) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.file != nil {
+		return t.file.Write(p)
+	}
+	if !t.closed {
+		t.pending.Write(p)
+	}
+	return len(p), nil
+}
+
+func logFilePath(config *Config) string {
+	if config.LogFile != "" {
+		return config.LogFile
+	}
+	return defaultLogFile
+}
+
+// openLogFile starts writing the transcript to log_file, rotating it first
+// when it has grown past log_max_size or log_each_run is set.
+func openLogFile(config *Config) {
+	runLog.mu.Lock()
+	defer runLog.mu.Unlock()
+
+	if runLog.file != nil || runLog.closed {
+		return
+	}
+	path := logFilePath(config)
+	if strings.EqualFold(path, "off") {
+		runLog.closed = true
+		runLog.pending.Reset()
+		return
+	}
+
+	if err := rotateLogFile(config, path); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️ Warning: Could not rotate %s: %v\n", path, err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️ Warning: Could not open log file %s: %v\n", path, err)
+		runLog.closed = true
+		return
+	}
+
+	fmt.Fprintf(file, "\n===== %s sfdeploy %s =====\n", time.Now().Format("2006-01-02 15:04:05"), strings.Join(commandLine, " "))
+	file.Write(runLog.pending.Bytes())
+	runLog.pending.Reset()
+	runLog.file = file
+}
+
+// closeLogFile flushes the transcript. If the log file was never opened,
+// for example because the config failed to load, the default location is
+// used so that failure is recorded too.
+func closeLogFile() {
+	openLogFile(&Config{})
+
+	runLog.mu.Lock()
+	defer runLog.mu.Unlock()
+	if runLog.file != nil {
+		runLog.file.Close()
+		runLog.file = nil
+	}
+	runLog.closed = true
+}
+
+// rotateLogFile shifts path to path.1, path.1 to path.2 and so on, keeping
+// log_max_files old logs.
+func rotateLogFile(config *Config, path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+
+	maxSize := config.LogMaxSize
+	if maxSize <= 0 {
+		maxSize = defaultLogMaxSize
+	}
+	if !config.LogEachRun && info.Size() < int64(maxSize)*1024 {
+		return nil
+	}
+
+	keep := config.LogMaxFiles
+	if keep <= 0 {
+		keep = defaultLogMaxFiles
+	}
+
+	os.Remove(fmt.Sprintf("%s.%d", path, keep))
+	for i := keep - 1; i >= 1; i-- {
+		old := fmt.Sprintf("%s.%d", path, i)
+		if _, err := os.Stat(old); err == nil {
+			if err := os.Rename(old, fmt.Sprintf("%s.%d", path, i+1)); err != nil {
+				return err
+			}
+		}
+	}
+	return os.Rename(path, path+".1")
+}
@@ -0,0 +1,86 @@
+package sfdeploy
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// logRecord is one line of --log-format json output.
+type logRecord struct {
+	Time    string `json:"time"`
+	Level   string `json:"level"`
+	Phase   string `json:"phase,omitempty"`
+	Message string `json:"message"`
+	Files   int    `json:"files,omitempty"`
+}
+
+// phaseLine matches the headline every phase prints, e.g.
+// "🚀 Phase 4: Deploying Project".
+var phaseLine = regexp.MustCompile(`Phase \d+: (.+)$`)
+
+// copySummary matches the line that counts the files a deploy copied and
+// their size.
+var copySummary = regexp.MustCompile(`^(?:Copied|Uploaded) (\d+) files(?: \(([\d.]+) (B|KB|MB|GB)\))?`)
+
+// writeJSONLog converts text output line by line. The level comes from the
+// leading emoji, the phase from the last phase headline, and each phase
+// that copied files ends with a record counting them.
+func writeJSONLog(r io.Reader, out io.Writer) {
+	enc := json.NewEncoder(out)
+	emit := func(rec logRecord) {
+		rec.Time = time.Now().Format(time.RFC3339Nano)
+		enc.Encode(rec)
+	}
+
+	phase, files := "", 0
+	endPhase := func() {
+		if phase != "" && files > 0 {
+			emit(logRecord{Level: "info", Phase: phase, Message: "phase finished", Files: files})
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRightFunc(scanner.Text(), unicode.IsSpace)
+		message := strings.TrimLeftFunc(line, isDecoration)
+		if message == "" {
+			continue
+		}
+
+		if m := phaseLine.FindStringSubmatch(message); m != nil {
+			endPhase()
+			phase, files = m[1], 0
+		}
+		if m := copySummary.FindStringSubmatch(message); m != nil {
+			files, _ = strconv.Atoi(m[1])
+		}
+
+		emit(logRecord{Level: logLevel(line), Phase: phase, Message: message})
+	}
+	endPhase()
+}
+
+func logLevel(line string) string {
+	line = strings.TrimSpace(line)
+	switch {
+	case strings.HasPrefix(line, "❌"):
+		return "error"
+	case strings.HasPrefix(line, "⚠️"), strings.HasPrefix(line, "Warning:"):
+		return "warn"
+	}
+	return "info"
+}
+
+// isDecoration reports whether r is leading indentation or part of an
+// emoji, which the JSON messages leave out.
+func isDecoration(r rune) bool {
+	return unicode.IsSpace(r) || unicode.Is(unicode.So, r) || unicode.Is(unicode.Sk, r) ||
+		unicode.Is(unicode.Mn, r) || r == '\u200d'
+}
@@ -0,0 +1,90 @@
+package sfdeploy
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+const manifestFile = "deploy-manifest.json"
+
+// version is the sfdeploy version, set at build time with
+// -ldflags "-X sfdeploy/pkg/sfdeploy.version=1.2.0". Otherwise the module version is used.
+var version = ""
+
+func toolVersion() string {
+	if version != "" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "dev"
+}
+
+// deployManifest is written into the extension folder on every deploy to
+// record exactly what is running on the server.
+type deployManifest struct {
+	Extension   string            `json:"extension"`
+	Commit      string            `json:"commit,omitempty"`
+	Branch      string            `json:"branch,omitempty"`
+	Dirty       bool              `json:"dirty"`
+	DeployedAt  string            `json:"deployed_at"`
+	DeployedBy  string            `json:"deployed_by"`
+	Host        string            `json:"host,omitempty"`
+	Profile     string            `json:"profile,omitempty"`
+	ToolVersion string            `json:"tool_version"`
+	Files       map[string]string `json:"files"`
+}
+
+// gitOutput runs a git command in the source directory.
+func gitOutput(config *Config, args ...string) (string, error) {
+	output, err := newCommand("git", append([]string{"-C", config.SourceDir}, args...)...).Output()
+	return strings.TrimSpace(string(output)), err
+}
+
+// writeManifest creates the manifest for the given items, with the SHA-256
+// of each, and returns the deploy item for it.
+func writeManifest(config *Config, items []deployItem) (deployItem, error) {
+	m := deployManifest{
+		Extension:   config.ExtensionFolder,
+		DeployedAt:  time.Now().Format(time.RFC3339),
+		DeployedBy:  deployUser(),
+		Profile:     *flagProfile,
+		ToolVersion: toolVersion(),
+		Files:       map[string]string{},
+	}
+	m.Host, _ = os.Hostname()
+
+	if commit, err := gitOutput(config, "rev-parse", "HEAD"); err == nil {
+		m.Commit = commit
+		m.Branch, _ = gitOutput(config, "rev-parse", "--abbrev-ref", "HEAD")
+		status, _ := gitOutput(config, "status", "--porcelain")
+		m.Dirty = status != ""
+	}
+
+	for _, item := range items {
+		if hash, err := hashFile(item.Source); err == nil {
+			m.Files[item.Target] = hash
+		}
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return deployItem{}, err
+	}
+
+	dir := filepath.Join(stateDir, "manifest", config.ExtensionFolder)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return deployItem{}, err
+	}
+	path := filepath.Join(dir, manifestFile)
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return deployItem{}, err
+	}
+
+	return deployItem{Source: path, Target: config.ExtensionFolder + "/" + manifestFile}, nil
+}
@@ -0,0 +1,648 @@
+package sfdeploy
+
+// messagesES is the Spanish message catalog.
+var messagesES = map[string]string{
+	// Runs
+	"====  SpookyZone Hot Deploy CLI Tool ====": "====  SpookyZone Hot Deploy: herramienta de línea de comandos ====",
+	"Command '%s' completed successfully!":      "¡El comando '%s' terminó correctamente!",
+	"❌ Command '%s' failed":                     "❌ El comando '%s' falló",
+	"Hot deploy completed successfully!":        "¡Despliegue en caliente completado correctamente!",
+	"Press Enter to exit...":                    "Pulsa Intro para salir...",
+	"Unknown command: %s":                       "Comando desconocido: %s",
+	"Config file not found: %s":                 "No se encontró el archivo de configuración: %s",
+	"Run 'sfdeploy config edit' to create one":  "Ejecuta 'sfdeploy config edit' para crear uno",
+	"Please enter 'y' or 'n'":                   "Responde 'y' (sí) o 'n' (no)",
+	"⚠️ Warning: %v":                            "⚠️ Advertencia: %v",
+
+	"--all works with the all, build, test and deploy commands, and without --source or --extension": "--all funciona con los comandos all, build, test y deploy, y sin --source ni --extension",
+	"Invalid --at %q: %v": "--at no válido %q: %v",
+	"⚠️ Running the targets one after another, parallel only applies to a group of the command itself": "⚠️ Los destinos se ejecutan uno tras otro; parallel solo se aplica a un grupo del propio comando",
+	"❌ Could not find the sfdeploy executable: %v":                                                     "❌ No se encontró el ejecutable de sfdeploy: %v",
+	"🖥️ Running %d targets in parallel, the output of each follows when it is done":                    "🖥️ Ejecutando %d destinos en paralelo; la salida de cada uno aparece cuando termina",
+	"❌ Could not run sfdeploy for %s: %v":                                                              "❌ No se pudo ejecutar sfdeploy para %s: %v",
+	"❌ Could not read the target to run: %v":                                                           "❌ No se pudo leer el destino que ejecutar: %v",
+	"❌ Phase %d of '%s' does not act on the targets":                                                   "❌ La fase %d de '%s' no actúa sobre los destinos",
+	"🔒 %s is locked: %v":                                                                               "🔒 %s está bloqueado: %v",
+	"   Held by %s":                                                                                    "   Lo tiene %s",
+	"⏳ Retrying for up to %s...":                                                                       "⏳ Reintentando durante hasta %s...",
+	"🔓 %s was released":                                                                                "🔓 %s se liberó",
+	"📂 Recent %s:":                                                                                     "📂 %s recientes:",
+	"⏰ '%s' scheduled for %s (in %s, Ctrl+C to cancel)":                                                "⏰ '%s' programado para %s (dentro de %s, Ctrl+C para cancelar)",
+	"[dry-run] Would wait until the scheduled time":                                                    "[dry-run] Se esperaría hasta la hora programada",
+	"⏰ Starting scheduled '%s' at %s":                                                                  "⏰ Iniciando '%s' programado a las %s",
+	"Scheduled run cancelled":                                                                          "Ejecución programada cancelada",
+	"❌ The TUI needs an interactive terminal":                                                          "❌ La TUI necesita una terminal interactiva",
+	// Phases
+	"Phase 1: Directory Setup":              "Fase 1: Preparación de directorios",
+	"Phase 2: Building Project":             "Fase 2: Compilación del proyecto",
+	"🧪 Phase 3: Running Tests":              "🧪 Fase 3: Ejecución de pruebas",
+	"🚀 Phase 4: Deploying Project":          "🚀 Fase 4: Despliegue del proyecto",
+	"🔄 Phase 5: Restarting SmartFox Server": "🔄 Fase 5: Reinicio del servidor SmartFox",
+	"🩺 Phase 6: Checking Server Health":     "🩺 Fase 6: Comprobación del estado del servidor",
+	"🧹 Phase 7: Cleaning Up Project":        "🧹 Fase 7: Limpieza del proyecto",
+	"🔌 Phase: %s":                           "🔌 Fase: %s",
+	"❌ Phase %s failed: %v":                 "❌ La fase %s falló: %v",
+	"Source: %s":                            "Origen: %s",
+	"Target: %s":                            "Destino: %s",
+	"Targets: %s":                           "Destinos: %s",
+	"Extension: %s":                         "Extensión: %s",
+	"Extensions: %s":                        "Extensiones: %s",
+	"Profile: %s":                           "Perfil: %s",
+	"🖥️ Target %d/%d: %s":                   "🖥️ Destino %d/%d: %s",
+	"🧩 Extension %d/%d: %s":                 "🧩 Extensión %d/%d: %s",
+	"📦 Project %d/%d: %s (%s)":              "📦 Proyecto %d/%d: %s (%s)",
+	"📋 Cluster Summary":                     "📋 Resumen del clúster",
+	"   ⏭️ %s (skipped)":                    "   ⏭️ %s (omitido)",
+	"⏭️ %s, leaving the server running":     "⏭️ %s, el servidor sigue en marcha",
+	"🪝 Running %s-deploy hook: %s":          "🪝 Ejecutando el hook %s-deploy: %s",
+
+	"Profile not found: %s":                                        "No se encontró el perfil: %s",
+	"Available profiles: %s":                                       "Perfiles disponibles: %s",
+	"Invalid profile %s: %v":                                       "Perfil %s no válido: %v",
+	"Source directory is invalid":                                  "El directorio de origen no es válido",
+	"Invalid environment override: %v":                             "Variable de entorno no válida: %v",
+	"Environment overrides: %s":                                    "Valores tomados del entorno: %s",
+	"Invalid project config %s: %v":                                "Configuración del proyecto %s no válida: %v",
+	"Invalid config file %s: %v":                                   "Archivo de configuración %s no válido: %v",
+	"Project config: %s":                                           "Configuración del proyecto: %s",
+	"Remote target directory is invalid or unreachable: %s":        "El directorio de destino remoto no es válido o no es accesible: %s",
+	"Docker target is invalid or the container is not running: %s": "El destino Docker no es válido o el contenedor no está en marcha: %s",
+	"Target directory is invalid: %s":                              "El directorio de destino no es válido: %s",
+	"Java %s not found":                                            "No se encontró Java %s",
+	"⚠️ Warning: Could not determine the JDK version: %v":          "⚠️ Advertencia: No se pudo determinar la versión del JDK: %v",
+	"Java: %s": "Java: %s",
+	"⚠️ Warning: Java %d does not match java_version %s": "⚠️ Advertencia: Java %d no coincide con java_version %s",
+	"Java %d: %s":                                                         "Java %d: %s",
+	"Warning: sfs2x.jar not found at %s":                                  "Advertencia: No se encontró sfs2x.jar en %s",
+	"Warning: sfs2x-core.jar not found at %s":                             "Advertencia: No se encontró sfs2x-core.jar en %s",
+	"Invalid environment file %s: %v":                                     "Archivo de entorno %s no válido: %v",
+	"Environment file: %s (%d variables)":                                 "Archivo de entorno: %s (%d variables)",
+	"Several matching JDKs were found:":                                   "Se encontraron varios JDK compatibles:",
+	"Choose a JDK [1-%d, Enter for 1]: ":                                  "Elige un JDK [1-%d, Intro para 1]: ",
+	"💡 Set jdk_download to true to download Temurin JDK %d automatically": "💡 Define jdk_download como true para descargar Temurin JDK %d automáticamente",
+	"❌ Failed to download JDK %d: %v":                                     "❌ No se pudo descargar el JDK %d: %v",
+	"✅ Installed JDK %d in %s":                                            "✅ JDK %d instalado en %s",
+	"[dry-run] Would run phase: %s":                                       "[dry-run] Se ejecutaría la fase: %s",
+	"❌ %s failed: %v":                                                     "❌ %s falló: %v",
+	"[dry-run] Would run phase %s: %s":                                    "[dry-run] Se ejecutaría la fase %s: %s",
+	"⚠️ Warning: %s: %s":                                                  "⚠️ Advertencia: %s: %s",
+	"Fix the config file, or check it with 'sfdeploy config validate'":    "Corrige el archivo de configuración, o compruébalo con 'sfdeploy config validate'",
+	"❌ Required setting missing: %s":                                      "❌ Falta un ajuste obligatorio: %s",
+	"Set them in %s, or run 'sfdeploy config edit'":                       "Defínelos en %s, o ejecuta 'sfdeploy config edit'",
+	"🔎 SmartFox %s":                                                       "🔎 SmartFox %s",
+	"⚠️ Warning: %s":                                                      "⚠️ Advertencia: %s",
+	"Phase 1: Workspace Setup":                                            "Fase 1: Configuración del espacio de trabajo",
+	"--all needs a workspace list in %s":                                  "--all necesita una lista de espacio de trabajo en %s",
+	"Invalid workspace: %v":                                               "Espacio de trabajo no válido: %v",
+	"Workspace: %s":                                                       "Espacio de trabajo: %s",
+	"Invalid config of workspace project %s: %v":                          "Configuración no válida del proyecto %s del espacio de trabajo: %v",
+	"Source directory of %s is invalid: %s":                               "El directorio de origen de %s no es válido: %s",
+	"Workspace project %s has no extension_folder":                        "El proyecto %s del espacio de trabajo no tiene extension_folder",
+	// Build
+	"Cleaning old class files...":  "Limpiando archivos .class antiguos...",
+	"Compiling Java files...":      "Compilando archivos Java...",
+	"Compiling %d Kotlin files...": "Compilando %d archivos Kotlin...",
+	"Compilation successful":       "Compilación correcta",
+	"Found %d Java files":          "%d archivos Java encontrados",
+	"Incremental build: %d changed or dependent, %d unchanged, %d removed": "Compilación incremental: %d modificados o dependientes, %d sin cambios, %d eliminados",
+	"Creating %s...":                                      "Creando %s...",
+	"%s created successfully":                             "%s creado correctamente",
+	"No Java files found":                                 "No se encontraron archivos Java",
+	"Failed to read sources: %v":                          "No se pudieron leer las fuentes: %v",
+	"Failed to write JAR manifest: %v":                    "No se pudo escribir el manifiesto del JAR: %v",
+	"📥 Downloading %s...":                                 "📥 Descargando %s...",
+	"📥 Downloading %s (%d MB)...":                         "📥 Descargando %s (%d MB)...",
+	"📦 Extracting %s...":                                  "📦 Extrayendo %s...",
+	"📦 Build cache: %s":                                   "📦 Caché de compilación: %s",
+	"🕶️ Obfuscating %d JARs with ProGuard...":             "🕶️ Ofuscando %d JAR con ProGuard...",
+	"❌ ProGuard failed: %s":                               "❌ ProGuard falló: %s",
+	"🔏 Signed %s as %s":                                   "🔏 %s firmado como %s",
+	"❌ Signing %s failed: %s":                             "❌ No se pudo firmar %s: %s",
+	"❌ Java %s not found automatically":                   "❌ No se encontró Java %s automáticamente",
+	"💡 Set java_path in the config to skip this question": "💡 Define java_path en la configuración para no ver esta pregunta",
+	"Please enter a number from 1 to %d":                  "Introduce un número del 1 al %d",
+	"Please enter the path to a Java %s bin directory (or press Enter to skip)": "Introduce la ruta a un directorio bin de Java %s (o pulsa Intro para omitirlo)",
+
+	"Found %d Kotlin files":                                              "%d archivos Kotlin encontrados",
+	"Full rebuild":                                                       "Recompilación completa",
+	"Failed to update build manifest: %v":                                "No se pudo actualizar el manifiesto de compilación: %v",
+	"Failed to create class cache: %v":                                   "No se pudo crear la caché de clases: %v",
+	"Restored %d sources from the build cache, %d left to compile":       "%d fuentes restauradas desde la caché de compilación, quedan %d por compilar",
+	"Failed to create %s: %v":                                            "No se pudo crear %s: %v",
+	"Compilation failed: %s":                                             "La compilación falló: %s",
+	"Warning: Could not save build manifest: %v":                         "Advertencia: No se pudo guardar el manifiesto de compilación: %v",
+	"Failed to copy compiled classes: %v":                                "No se pudieron copiar las clases compiladas: %v",
+	"Failed to copy compiled Kotlin classes: %v":                         "No se pudieron copiar las clases Kotlin compiladas: %v",
+	"Warning: extra_libs entry matched no JAR files: %s":                 "Advertencia: La entrada de extra_libs no coincide con ningún JAR: %s",
+	"Warning: No JAR files found in %s":                                  "Advertencia: No se encontraron archivos JAR en %s",
+	"   %d class files, %.1f MB":                                         "   %d archivos .class, %.1f MB",
+	"❌ Failed to remove %s: %v":                                          "❌ No se pudo eliminar %s: %v",
+	"🧹 Removed %d cached class files (%.1f MB) from %s":                  "🧹 %d archivos .class en caché eliminados (%.1f MB) de %s",
+	"Unknown cache command: %s (expected info or clean)":                 "Comando cache desconocido: %s (se esperaba info o clean)",
+	"Maven project detected, running %s %s...":                           "Proyecto Maven detectado, ejecutando %s %s...",
+	"Maven build successful":                                             "Compilación con Maven correcta",
+	"Gradle project detected, running %s %s...":                          "Proyecto Gradle detectado, ejecutando %s %s...",
+	"Gradle build successful":                                            "Compilación con Gradle correcta",
+	"Build output not found: %s":                                         "No se encontró el resultado de la compilación: %s",
+	"No class files found in %s":                                         "No se encontraron archivos .class en %s",
+	"Failed to copy %s: %v":                                              "No se pudo copiar %s: %v",
+	"%s created from %s":                                                 "%s creado a partir de %s",
+	"No JAR or class files found in %s":                                  "No se encontraron archivos JAR ni .class en %s",
+	"Warning: %s not found, skipping %s":                                 "Advertencia: No se encontró %s, se omite %s",
+	"JAR creation failed for %s: %s":                                     "No se pudo crear el JAR de %s: %s",
+	"⚠️ Warning: %q and %q both make the client constant %s, keeping %q": "⚠️ Advertencia: %q y %q generan la misma constante de cliente %s, se conserva %q",
+	"❌ client_constants: %v":                                             "❌ client_constants: %v",
+	"❌ %s was not generated by sfdeploy; move it away or point client_constants at another file": "❌ %s no lo generó sfdeploy; muévelo o apunta client_constants a otro archivo",
+	"❌ Failed to read %s: %v":                                              "❌ No se pudo leer %s: %v",
+	"[dry-run] Would update client constants %s":                           "[dry-run] Se actualizarían las constantes de cliente %s",
+	"📝 Updated client constants %s (%d requests, %d responses and events)": "📝 Constantes de cliente %s actualizadas (%d peticiones, %d respuestas y eventos)",
+	"Resolving %d dependencies...":                                         "Resolviendo %d dependencias...",
+	"[dry-run] Would download %s":                                          "[dry-run] Se descargaría %s",
+	"❌ Failed to download %s: %v":                                          "❌ No se pudo descargar %s: %v",
+	"⚠️ Warning: No checksum available for %s":                             "⚠️ Advertencia: No hay suma de comprobación para %s",
+	"Extension %s is not in extensions: %s":                                "La extensión %s no está en extensions: %s",
+	"Every entry of extensions needs a folder":                             "Cada entrada de extensions necesita una carpeta",
+	"Failed to collect the classes of %s: %v":                              "No se pudieron reunir las clases de %s: %v",
+	"Failed to select the jar_files of %s: %v":                             "No se pudieron seleccionar los jar_files de %s: %v",
+	"📥 kotlinc not found, downloading Kotlin %s...":                        "📥 No se encontró kotlinc, descargando Kotlin %s...",
+	"Kotlin compiler not available: %v":                                    "El compilador de Kotlin no está disponible: %v",
+	"Kotlin compilation failed: %s":                                        "La compilación de Kotlin falló: %s",
+	"Warning: Could not keep %s for deploy: %v":                            "Advertencia: No se pudo conservar %s para el despliegue: %v",
+	"Warning: %s not found next to %s, deploy it with lib_jars":            "Advertencia: No se encontró %s junto a %s; despliégalo con lib_jars",
+	"⚠️ Warning: lib_jars entry matched no JAR files: %s":                  "⚠️ Advertencia: La entrada de lib_jars no coincide con ningún JAR: %s",
+	"❌ Module %s has no src folder: %s":                                    "❌ El módulo %s no tiene carpeta src: %s",
+	"Failed to stage classes for packaging: %v":                            "No se pudieron preparar las clases para empaquetar: %v",
+	"⚠️ Warning: processor_path entry matched no JAR files: %s":            "⚠️ Advertencia: La entrada de processor_path no coincide con ningún JAR: %s",
+	"[dry-run] Would obfuscate %d JARs: %s %s":                             "[dry-run] Se ofuscarían %d JAR: %s %s",
+	"✅ Obfuscated, mapping saved to %s":                                    "✅ Ofuscado, mapa guardado en %s",
+	"⚠️ Warning: Resource folder not found: %s":                            "⚠️ Advertencia: No se encontró la carpeta de recursos: %s",
+	"❌ signing.alias is not set":                                           "❌ signing.alias no está definido",
+	// Tests
+	"Compiling %d test files...":    "Compilando %d archivos de prueba...",
+	"✅ Tests passed":                "✅ Pruebas superadas",
+	"No tests found":                "No se encontraron pruebas",
+	"❌ Tests failed: %v":            "❌ Las pruebas fallaron: %v",
+	"❌ Test compilation failed: %s": "❌ La compilación de las pruebas falló: %s",
+	"❌ Smoke test failed: %v":       "❌ La prueba de humo falló: %v",
+
+	"💨 Smoke test: logging into zone %s on %s as %s":                                "💨 Prueba de humo: iniciando sesión en la zona %s de %s como %s",
+	"[dry-run] Would send extension request '%s' and check the response":            "[dry-run] Se enviaría la petición de extensión '%s' y se comprobaría la respuesta",
+	"✅ Logged into zone %s":                                                         "✅ Sesión iniciada en la zona %s",
+	"❌ Smoke test failed: '%s' %v":                                                  "❌ La prueba de humo falló: '%s' %v",
+	"✅ Extension answered '%s' as expected":                                         "✅ La extensión respondió a '%s' como se esperaba",
+	"⏭️ Skipping tests (--skip-tests)":                                              "⏭️ Se omiten las pruebas (--skip-tests)",
+	"✅ Tests ran as part of the Maven build":                                        "✅ Las pruebas se ejecutaron como parte de la compilación con Maven",
+	"⚠️ Warning: %d test files in %s but test_classpath is not set, skipping tests": "⚠️ Advertencia: %d archivos de prueba en %s pero test_classpath no está definido, se omiten las pruebas",
+	"[dry-run] Would compile and run %d test files from %s":                         "[dry-run] Se compilarían y ejecutarían %d archivos de prueba de %s",
+	"[dry-run] Would run: %s --console=plain test":                                  "[dry-run] Se ejecutaría: %s --console=plain test",
+	"Running %s test...":                                                            "Ejecutando %s test...",
+	"No test classes (*Test, *Tests) found":                                         "No se encontraron clases de prueba (*Test, *Tests)",
+	"Running tests...":                                                              "Ejecutando las pruebas...",
+	"   Use --skip-tests to deploy anyway":                                          "   Usa --skip-tests para desplegar de todos modos",
+	// Deploy
+	"📁 Deploying to: %s":                                "📁 Desplegando en: %s",
+	"📸 Saving snapshot of current deployment...":        "📸 Guardando una instantánea del despliegue actual...",
+	"❌ Failed to snapshot current deployment: %v":       "❌ No se pudo guardar la instantánea del despliegue actual: %v",
+	"⚠️ Warning: Could not prune deploy history: %v":    "⚠️ Advertencia: No se pudo recortar el historial de despliegues: %v",
+	"❌ Failed to prepare staging folder: %v":            "❌ No se pudo preparar la carpeta temporal: %v",
+	"❌ Failed to create target directory: %v":           "❌ No se pudo crear el directorio de destino: %v",
+	"🗑️ Removing old JAR files...":                      "🗑️ Eliminando archivos JAR antiguos...",
+	"   🗑️ Removed older version: __lib__/%s":           "   🗑️ Versión anterior eliminada: __lib__/%s",
+	"⚠️ Warning: Could not remove %s: %v":               "⚠️ Advertencia: No se pudo eliminar %s: %v",
+	"⚠️ Warning: JSON file not found: %s":               "⚠️ Advertencia: No se encontró el archivo JSON: %s",
+	"⏭️ Skipping %s, excluded by %s":                    "⏭️ Se omite %s, excluido por %s",
+	"Copying files into %s...":                          "Copiando archivos en %s...",
+	"   ✅ Copied %d files (%s), %d unchanged":           "   ✅ %d archivos copiados (%s), %d sin cambios",
+	"   ✅ Uploaded %d files (%s), %d unchanged":         "   ✅ %d archivos subidos (%s), %d sin cambios",
+	"❌ Failed to create %s: %v":                         "❌ No se pudo crear %s: %v",
+	"🔁 Swapping in the new extension folder...":         "🔁 Colocando la nueva carpeta de la extensión...",
+	"❌ Failed to swap in %s: %v":                        "❌ No se pudo colocar %s: %v",
+	"✅ Deployment successful":                           "✅ Despliegue correcto",
+	"🗑️ Removing .class files from source directory...": "🗑️ Eliminando archivos .class del directorio de origen...",
+	"🗑️ Removed %d .class files":                        "🗑️ %d archivos .class eliminados",
+	"🗑️ Removing JAR files from project root...":        "🗑️ Eliminando archivos JAR de la raíz del proyecto...",
+	"🗑️ Removed %d JAR files":                           "🗑️ %d archivos JAR eliminados",
+	"✅ Project cleanup completed":                       "✅ Limpieza del proyecto completada",
+	"❌ No snapshot found - nothing to roll back to":     "❌ No hay ninguna instantánea: no hay nada que revertir",
+	"💾 Backup written: %s":                              "💾 Copia de seguridad guardada: %s",
+
+	"backup_dir is not configured":                          "backup_dir no está configurado",
+	"Usage: sfdeploy backup restore <n|file.zip>":           "Uso: sfdeploy backup restore <n|archivo.zip>",
+	"Unknown backup command: %s (expected list or restore)": "Comando backup desconocido: %s (se esperaba list o restore)",
+	"No backups for %s in %s":                               "No hay copias de seguridad de %s en %s",
+	"Backups for %s in %s (newest first):":                  "Copias de seguridad de %s en %s (de la más reciente a la más antigua):",
+	"❌ Backup #%d not found (have %d)":                      "❌ No se encontró la copia de seguridad #%d (hay %d)",
+	"⏪ Restoring Backup %s":                                 "⏪ Restaurando la copia de seguridad %s",
+	"❌ Failed to create temporary directory: %v":            "❌ No se pudo crear el directorio temporal: %v",
+	"❌ Failed to unpack %s: %v":                             "❌ No se pudo descomprimir %s: %v",
+	"[dry-run] Would lock %s while deploying":               "[dry-run] Se bloquearía %s durante el despliegue",
+	"❌ Failed to lock %s: %v":                               "❌ No se pudo bloquear %s: %v",
+	"🔒 %s is locked by %s":                                  "🔒 %s está bloqueado por %s",
+	"   Wait for that run to finish, or pass --force if it crashed and left the lock behind": "   Espera a que termine esa ejecución, o usa --force si falló y dejó el bloqueo",
+	"⚠️ Taking over the lock of %s held by %s":                                               "⚠️ Tomando el bloqueo de %s que tenía %s",
+	"⚠️ Warning: Could not remove the deploy lock of %s: %v":                                 "⚠️ Advertencia: No se pudo quitar el bloqueo de despliegue de %s: %v",
+	"🔍 Comparing Build Output With the Deployed Extension":                                   "🔍 Comparando el resultado de la compilación con la extensión desplegada",
+	"📁 %s in %s":                                                                       "📁 %s en %s",
+	"   ✅ Up to date, %d files unchanged":                                              "   ✅ Actualizado, %d archivos sin cambios",
+	"   %d new, %d changed, %d removed, %d unchanged":                                  "   %d nuevos, %d modificados, %d eliminados, %d sin cambios",
+	"No SFS2X/extensions folder in %s: %s":                                             "No hay carpeta SFS2X/extensions en %s: %s",
+	"⚠️ Warning: Could not create %s: %v":                                              "⚠️ Advertencia: No se pudo crear %s: %v",
+	"📥 Fetching server libraries from container %s...":                                 "📥 Obteniendo las bibliotecas del servidor del contenedor %s...",
+	"⚠️ Warning: Could not fetch server libraries: %v":                                 "⚠️ Advertencia: No se pudieron obtener las bibliotecas del servidor: %v",
+	"❌ Failed to prepare %s: %s":                                                       "❌ No se pudo preparar %s: %s",
+	"Copying files into the container's %s...":                                         "Copiando archivos en %s del contenedor...",
+	"❌ Failed to swap in %s: %s":                                                       "❌ No se pudo poner en su lugar %s: %s",
+	"▶️ Running: docker %s":                                                            "▶️ Ejecutando: docker %s",
+	"❌ Failed to restart container %s: %s":                                             "❌ No se pudo reiniciar el contenedor %s: %s",
+	"✅ Container %s restarted":                                                         "✅ Contenedor %s reiniciado",
+	"⏪ Rolling Back Deployment":                                                        "⏪ Revirtiendo el despliegue",
+	"❌ No deployment older than the one restored last - nothing to roll back to":       "❌ No hay ningún despliegue anterior al último restaurado - no hay nada a lo que volver",
+	"Usage: sfdeploy history restore <n>":                                              "Uso: sfdeploy history restore <n>",
+	"⏪ Restoring Deployment #%d":                                                       "⏪ Restaurando el despliegue #%d",
+	"Unknown history command: %s (expected list or restore)":                           "Comando history desconocido: %s (se esperaba list o restore)",
+	"No deploy history for %s":                                                         "No hay historial de despliegues de %s",
+	"Deploy history for %s (newest first, keeping %d):":                                "Historial de despliegues de %s (del más reciente al más antiguo, se conservan %d):",
+	"  #%-3d %s  %-15s %d files":                                                       "  #%-3d %s  %-15s %d archivos",
+	"❌ History entry #%d not found (have %d)":                                          "❌ No se encontró la entrada del historial #%d (hay %d)",
+	"[dry-run] Would replace %s with snapshot from %s":                                 "[dry-run] Se reemplazaría %s por la instantánea de %s",
+	"❌ Failed to restore remote extension: %v":                                         "❌ No se pudo restaurar la extensión remota: %v",
+	"Restored: %s/ from %s":                                                            "Restaurado: %s/ desde %s",
+	"❌ Failed to restore container extension: %v":                                      "❌ No se pudo restaurar la extensión del contenedor: %v",
+	"❌ Failed to clear %s: %v":                                                         "❌ No se pudo vaciar %s: %v",
+	"❌ Failed to restore extension folder: %v":                                         "❌ No se pudo restaurar la carpeta de la extensión: %v",
+	"❌ Failed to restore %s: %v":                                                       "❌ No se pudo restaurar %s: %v",
+	"Restored: __lib__/%s":                                                             "Restaurado: __lib__/%s",
+	"✅ Rollback successful":                                                            "✅ Reversión correcta",
+	"[dry-run] Would run %s-deploy hook: %s":                                           "[dry-run] Se ejecutaría el hook %s-deploy: %s",
+	"❌ %s-deploy hook failed: %v":                                                      "❌ El hook %s-deploy falló: %v",
+	"⚠️ Warning: %s changed, but --hot only deploys classes; run a full deploy for it": "⚠️ Advertencia: %s cambió, pero --hot solo despliega clases; haz un despliegue completo para ello",
+	"🔥 Hot Deploy":                                                                     "🔥 Despliegue en caliente",
+	"[dry-run] Would ask %s to reload %s":                                              "[dry-run] Se pediría a %s que recargue %s",
+	"⚠️ Warning: the new classes are deployed but not loaded; restart the server or reload the extension":              "⚠️ Advertencia: las clases nuevas están desplegadas pero no cargadas; reinicia el servidor o recarga la extensión",
+	"   SmartFox reloads the extension only when its zone has reloadMode AUTO":                                         "   SmartFox solo recarga la extensión cuando su zona tiene reloadMode AUTO",
+	"⚠️ Warning: the zone's reloadMode is %s, so SmartFox will not reload the extension; set zone_reload_mode to AUTO": "⚠️ Advertencia: el reloadMode de la zona es %s, así que SmartFox no recargará la extensión; define zone_reload_mode como AUTO",
+	"❌ %s is not deployed yet, run a full deploy before using --hot":                                                   "❌ %s aún no está desplegado; haz un despliegue completo antes de usar --hot",
+	"❌ Failed to compare %s: %v":                                                       "❌ No se pudo comparar %s: %v",
+	"✅ No class of %s changed":                                                         "✅ No cambió ninguna clase de %s",
+	"🔥 %s: %d changed, %d removed classes":                                             "🔥 %s: %d clases modificadas, %d eliminadas",
+	"[dry-run] Would replace %s":                                                       "[dry-run] Se reemplazaría %s",
+	"❌ Failed to replace %s: %v":                                                       "❌ No se pudo reemplazar %s: %v",
+	"✅ Replaced %s without restarting the server":                                      "✅ %s reemplazado sin reiniciar el servidor",
+	"⚠️ Warning: Ignoring %s: %v":                                                      "⚠️ Advertencia: Se ignora %s: %v",
+	"📥 Fetching server libraries from %s...":                                           "📥 Obteniendo las bibliotecas del servidor de %s...",
+	"⚠️ Warning: Could not fetch server libraries: %s":                                 "⚠️ Advertencia: No se pudieron obtener las bibliotecas del servidor: %s",
+	"⚠️ Warning: Could not write %s: %v":                                               "⚠️ Advertencia: No se pudo escribir %s: %v",
+	"⚠️ Warning: Could not stop remote server: %s":                                     "⚠️ Advertencia: No se pudo detener el servidor remoto: %s",
+	"📁 Deploying to: %s:%s":                                                            "📁 Desplegando en: %s:%s",
+	"❌ Failed to prepare staging folder: %s":                                           "❌ No se pudo preparar la carpeta temporal: %s",
+	"Uploading files over SFTP into %s...":                                             "Subiendo archivos por SFTP a %s...",
+	"▶️ Starting SmartFox on %s with %s...":                                            "▶️ Iniciando SmartFox en %s con %s...",
+	"❌ Failed to start remote server: %s":                                              "❌ No se pudo iniciar el servidor remoto: %s",
+	"✅ Server started on remote host":                                                  "✅ Servidor iniciado en el equipo remoto",
+	"📝 Console output: %s:%s":                                                          "📝 Salida de consola: %s:%s",
+	"📡 %s is unreachable: %v":                                                          "📡 %s no es accesible: %v",
+	"📡 %s is reachable again":                                                          "📡 %s vuelve a ser accesible",
+	"📡 Target is on network share %s (server %s)":                                      "📡 El destino está en el recurso de red %s (servidor %s)",
+	"❌ Network share %s is not reachable: %v":                                          "❌ El recurso de red %s no es accesible: %v",
+	"🔍 Stopping Windows service %s on %s...":                                           "🔍 Deteniendo el servicio de Windows %s en %s...",
+	"⚠️ Warning: Cannot stop SmartFox on %s from here; %s":                             "⚠️ Advertencia: No se puede detener SmartFox en %s desde aquí; %s",
+	"❌ %s is on network share %s, so SmartFox on %s cannot be restarted from here; %s": "❌ %s está en el recurso de red %s, así que SmartFox en %s no se puede reiniciar desde aquí; %s",
+	"⚠️ Warning: zone settings are configured but zone_name is empty":                  "⚠️ Advertencia: hay ajustes de zona configurados pero zone_name está vacío",
+	"🧩 Checking zone definition %s...":                                                 "🧩 Comprobando la definición de zona %s...",
+	"❌ Failed to parse %s: %v":                                                         "❌ No se pudo analizar %s: %v",
+	"⚠️ Warning: <%s> not found in %s":                                                 "⚠️ Advertencia: No se encontró <%s> en %s",
+	"   Zone definition is up to date":                                                 "   La definición de zona está al día",
+	"✅ Updated %s":                                                                     "✅ %s actualizado",
+	// Server
+	"🔍 Stopping running SmartFox server...":                                    "🔍 Deteniendo el servidor SmartFox en marcha...",
+	"🔍 Killing processes on port %d...":                                        "🔍 Terminando los procesos del puerto %d...",
+	"🔫 Asking process %s using port %d to exit":                                "🔫 Pidiendo al proceso %s que usa el puerto %d que termine",
+	"⏳ Waiting up to %s for the server to exit...":                             "⏳ Esperando hasta %s a que el servidor termine...",
+	"🔫 Still running after %s, force killing process %s":                       "🔫 Sigue en marcha tras %s, forzando el cierre del proceso %s",
+	"⚠️ Warning: Port %d is still in use after force killing process %s":       "⚠️ Advertencia: el puerto %d sigue en uso tras forzar el cierre del proceso %s",
+	"🔍 Stopping Windows service %s...":                                         "🔍 Deteniendo el servicio de Windows %s...",
+	"🔍 Stopping systemd unit %s...":                                            "🔍 Deteniendo la unidad de systemd %s...",
+	"🔍 Stopping SmartFox on %s...":                                             "🔍 Deteniendo SmartFox en %s...",
+	"⚠️ Could not find SmartFox CMD window - will create new one":              "⚠️ No se encontró la ventana CMD de SmartFox: se creará una nueva",
+	"⚠️ Falling back to a restart":                                             "⚠️ Se recurre a un reinicio",
+	"⏳ Giving the server %s to start...":                                       "⏳ Dejando %s al servidor para arrancar...",
+	"▶️ Creating new CMD window for SmartFox server...":                        "▶️ Creando una nueva ventana CMD para el servidor SmartFox...",
+	"❌ Failed to start server: %v":                                             "❌ No se pudo iniciar el servidor: %v",
+	"▶️ Starting SmartFox server with %s...":                                   "▶️ Iniciando el servidor SmartFox con %s...",
+	"✅ Server started in the background":                                       "✅ Servidor iniciado en segundo plano",
+	"❌ SmartFox is still listening on port %d, not starting a second instance": "❌ SmartFox sigue escuchando en el puerto %d, no se inicia una segunda instancia",
+	"🔄 Running restart_command: %s":                                            "🔄 Ejecutando restart_command: %s",
+	"❌ restart_command failed: %v":                                             "❌ restart_command falló: %v",
+	"📝 Follow the server logs with: tail -f %s":                                "📝 Sigue los registros del servidor con: tail -f %s",
+
+	"🛰️ Requesting graceful restart via %s...":                                                              "🛰️ Solicitando un reinicio ordenado mediante %s...",
+	"⚠️ Admin API restart failed: %v":                                                                       "⚠️ El reinicio mediante la API de administración falló: %v",
+	"⚠️ Falling back to a hard restart":                                                                     "⚠️ Se recurre a un reinicio forzado",
+	"✅ Restart requested through the admin API":                                                             "✅ Reinicio solicitado mediante la API de administración",
+	"♻️ Reloading %s in %s via %s...":                                                                       "♻️ Recargando %s en %s mediante %s...",
+	"⚠️ Admin API reload of %s failed: %v":                                                                  "⚠️ La recarga de %s mediante la API de administración falló: %v",
+	"✅ Extension reloaded through the admin API, the server kept running":                                   "✅ Extensión recargada mediante la API de administración, el servidor siguió en marcha",
+	"Unknown admin command: %s (expected install)":                                                          "Comando admin desconocido: %s (se esperaba install)",
+	"❌ Set admin_port, admin_user and admin_password first; the bridge only answers authenticated requests": "❌ Define primero admin_port, admin_user y admin_password; el puente solo responde a peticiones autenticadas",
+	"⚠️ Warning: admin_url is set, so sfdeploy keeps using %s instead of the bridge":                        "⚠️ Advertencia: admin_url está definido, así que sfdeploy sigue usando %s en lugar del puente",
+	"❌ Failed to write %s: %v":                                                                              "❌ No se pudo escribir %s: %v",
+	"🔨 Compiling the admin bridge...":                                                                       "🔨 Compilando el puente de administración...",
+	"❌ Compiling the admin bridge failed: %s":                                                               "❌ La compilación del puente de administración falló: %s",
+	"✅ Installed the admin bridge, listening on %s:%d once started":                                         "✅ Puente de administración instalado, escuchará en %s:%d cuando se inicie",
+	"💡 Start it from the init() of your zone extension, then restart the server once:":                      "💡 Inícialo desde el init() de la extensión de tu zona y reinicia el servidor una vez:",
+	"❌ SFTP upload failed: %s":                                                                              "❌ La subida por SFTP falló: %s",
+	"❌ Failed to copy %s: %v":                                                                               "❌ No se pudo copiar %s: %v",
+	"⚠️ Warning: jvm_options are set but %s could not be read: %v":                                          "⚠️ Advertencia: jvm_options está definido pero no se pudo leer %s: %v",
+	"⚠️ Warning: jvm_options only apply to sfs2x.bat, not to the Windows service":                           "⚠️ Advertencia: jvm_options solo se aplica a sfs2x.bat, no al servicio de Windows",
+	"❌ Failed to apply jvm_options to %s: %v":                                                               "❌ No se pudo aplicar jvm_options a %s: %v",
+	"☕ Removing the JVM options from %s":                                                                    "☕ Quitando las opciones de la JVM de %s",
+	"☕ Setting the JVM options in %s: %s":                                                                   "☕ Definiendo las opciones de la JVM en %s: %s",
+	"[dry-run] Would rewrite %s":                                                                            "[dry-run] Se reescribiría %s",
+	"❌ Failed to back up %s: %v":                                                                            "❌ No se pudo hacer copia de seguridad de %s: %v",
+	"⚠️ Warning: Could not make %s executable: %s":                                                          "⚠️ Advertencia: No se pudo hacer ejecutable %s: %s",
+	"❌ Port %d is already in use by PID %s (%s)":                                                            "❌ El puerto %d ya lo usa el PID %s (%s)",
+	"   Stop that process, or set server_ports to the ports your server actually binds":                     "   Detén ese proceso, o define server_ports con los puertos que usa realmente tu servidor",
+	"[dry-run] Would run restart_command: %s":                                                               "[dry-run] Se ejecutaría restart_command: %s",
+	"✅ Found SmartFox CMD window PID: %s (from %s)":                                                         "✅ PID de la ventana CMD de SmartFox encontrado: %s (desde %s)",
+	"🔍 Searching all CMD windows for SmartFox...":                                                           "🔍 Buscando SmartFox en todas las ventanas CMD...",
+	"🔍 Found Java process: %s":                                                                              "🔍 Proceso Java encontrado: %s",
+	"🎯 Found SmartFox Java process PID: %s with parent: %s":                                                 "🎯 PID del proceso Java de SmartFox encontrado: %s con padre: %s",
+	"✅ Found SmartFox CMD window PID: %s (parent of Java process)":                                          "✅ PID de la ventana CMD de SmartFox encontrado: %s (padre del proceso Java)",
+	"⚠️ Warning: reloadable is set, but reloading needs admin_port or admin_url; restarting the server":     "⚠️ Advertencia: reloadable está definido, pero recargar necesita admin_port o admin_url; se reinicia el servidor",
+	"[dry-run] Would ask %s to reload %s, restarting the server only if that fails":                         "[dry-run] Se pediría a %s que recargue %s, reiniciando el servidor solo si falla",
+	"❌ Failed to create log batch file: %v":                                                                 "❌ No se pudo crear el archivo por lotes de registro: %v",
+	"✅ Server started in new CMD window with logs":                                                          "✅ Servidor iniciado en una ventana CMD nueva con registros",
+	"📝 Check the new CMD window for server logs and status":                                                 "📝 Consulta la ventana CMD nueva para ver los registros y el estado del servidor",
+	"🔍 Checking if stored CMD window PID %s is still alive...":                                              "🔍 Comprobando si el PID guardado de la ventana CMD %s sigue activo...",
+	"✅ Found existing SmartFox CMD window":                                                                  "✅ Se encontró la ventana CMD de SmartFox existente",
+	"🔄 Since we need to see logs, creating new CMD window...":                                               "🔄 Para ver los registros, se crea una ventana CMD nueva...",
+	"🗑️ Closed old CMD window PID: %s":                                                                      "🗑️ Ventana CMD anterior cerrada, PID: %s",
+	"❌ No SmartFox launcher found in %s":                                                                    "❌ No se encontró ningún lanzador de SmartFox en %s",
+	"❌ Failed to start server: %v %s":                                                                       "❌ No se pudo iniciar el servidor: %v %s",
+	"⚠️ Warning: Could not stop %s: %s":                                                                     "⚠️ Advertencia: No se pudo detener %s: %s",
+	"▶️ Restarting systemd unit %s...":                                                                      "▶️ Reiniciando la unidad systemd %s...",
+	"❌ systemctl restart %s failed: %s":                                                                     "❌ systemctl restart %s falló: %s",
+	"⏳ Waiting for %s to become active...":                                                                  "⏳ Esperando a que %s esté activo...",
+	"✅ %s is active":                                                                                        "✅ %s está activo",
+	"▶️ Starting Windows service %s...":                                                                     "▶️ Iniciando el servicio de Windows %s...",
+	"✅ Service restarted":                                                                                   "✅ Servicio reiniciado",
+	// Health
+	"⏳ Waiting for %s (timeout %s)...":                          "⏳ Esperando a %s (límite %s)...",
+	"❌ Server did not accept connections on %s within %s":       "❌ El servidor no aceptó conexiones en %s en %s",
+	"✅ %s is accepting connections":                             "✅ %s acepta conexiones",
+	"⏳ Waiting for BlueBox at %s...":                            "⏳ Esperando a BlueBox en %s...",
+	"❌ BlueBox did not respond at %s within %s":                 "❌ BlueBox no respondió en %s en %s",
+	"✅ BlueBox is responding at %s":                             "✅ BlueBox responde en %s",
+	"📜 Following %s...":                                         "📜 Siguiendo %s...",
+	"✅ SmartFoxServer reported READY":                           "✅ SmartFoxServer indicó READY",
+	"⚠️ Warning: The READY line did not appear in smartfox.log": "⚠️ Advertencia: La línea READY no apareció en smartfox.log",
+	"❌ The server logged %d error(s) while booting:":            "❌ El servidor registró %d error(es) al arrancar:",
+
+	"[dry-run] Would follow %s until the READY line and fail on errors":                             "[dry-run] Se seguiría %s hasta la línea READY, fallando ante errores",
+	"[dry-run] Would wait up to %s for %s to accept connections":                                    "[dry-run] Se esperaría hasta %s a que %s acepte conexiones",
+	"[dry-run] Would wait up to %s for %s to respond":                                               "[dry-run] Se esperaría hasta %s a que %s responda",
+	"❌ %s is still answered by the server from before the restart, no new server started within %s": "❌ %s sigue respondiendo el servidor de antes del reinicio; no arrancó ningún servidor nuevo en %s",
+	// Watch, listen and serve
+	"👀 Watch Mode": "👀 Modo vigilancia",
+	"Watching %s for .java changes (Ctrl+C to stop)":    "Vigilando cambios .java en %s (Ctrl+C para detener)",
+	"🔁 Change detected at %s":                           "🔁 Cambio detectado a las %s",
+	"❌ Hot deploy failed, waiting for the next change":  "❌ El despliegue en caliente falló, esperando al siguiente cambio",
+	"Stopping watch mode":                               "Deteniendo el modo vigilancia",
+	"👂 Listen Mode":                                     "👂 Modo escucha",
+	"Listening on %s for pushes to %s (Ctrl+C to stop)": "Escuchando en %s los push a %s (Ctrl+C para detener)",
+	"📬 Push to %s (%s) by %s at %s":                     "📬 Push a %s (%s) de %s a las %s",
+	"📡 Daemon Mode":                                     "📡 Modo demonio",
+	"Job %d (%s) completed successfully!":               "¡El trabajo %d (%s) terminó correctamente!",
+	"❌ Job %d (%s) failed":                              "❌ El trabajo %d (%s) falló",
+
+	"⚠️ Rejected webhook from %s: bad signature":                                                 "⚠️ Webhook de %s rechazado: firma incorrecta",
+	"⚠️ Warning: webhook_secret is not set, anyone who can reach this port can trigger a deploy": "⚠️ Advertencia: webhook_secret no está definido; cualquiera que llegue a este puerto puede lanzar un despliegue",
+	"❌ Webhook listener failed: %v":                                                              "❌ El receptor de webhooks falló: %v",
+	"Stopping listen mode":                                                                       "Deteniendo el modo listen",
+	"⬇️ Pulling %s...":                                                                           "⬇️ Actualizando %s...",
+	"❌ Deploy failed, waiting for the next push":                                                 "❌ El despliegue falló, esperando al siguiente push",
+	"📥 %s%s merged into job %d, position %d":                                                     "📥 %s%s unido al trabajo %d, posición %d",
+	"📥 %s%s queued as job %d, position %d":                                                       "📥 %s%s en cola como trabajo %d, posición %d",
+	"📡 %s requested through the API%s (job %d) at %s":                                            "📡 %s solicitado mediante la API%s (trabajo %d) a las %s",
+	"   %d requests merged into this run":                                                        "   %d peticiones unidas en esta ejecución",
+	"⚠️ Rejected API request from %s: bad token":                                                 "⚠️ Petición a la API de %s rechazada: token incorrecto",
+	"❌ gRPC server failed: %v":                                                                   "❌ El servidor gRPC falló: %v",
+	"Serving gRPC on %s":                                                                         "Sirviendo gRPC en %s",
+	"Serving the API on http://%s (Ctrl+C to stop)":                                              "Sirviendo la API en http://%s (Ctrl+C para detener)",
+	"❌ Daemon server failed: %v":                                                                 "❌ El servidor del demonio falló: %v",
+	"Stopping daemon mode, dropping %d queued jobs":                                              "Deteniendo el modo demonio, se descartan %d trabajos en cola",
+	"Stopping daemon mode":                                                                       "Deteniendo el modo demonio",
+	"❌ Failed to start file watcher: %v":                                                         "❌ No se pudo iniciar el observador de archivos: %v",
+	"❌ Failed to watch %s: %v":                                                                   "❌ No se pudo observar %s: %v",
+	"⚠️ Watcher error: %v":                                                                       "⚠️ Error del observador: %v",
+	// Notifications
+	"📣 Notification sent":                               "📣 Notificación enviada",
+	"📧 Emailed the result to %s":                        "📧 Resultado enviado por correo a %s",
+	"⚠️ Warning: Could not send notification email: %v": "⚠️ Advertencia: No se pudo enviar el correo de notificación: %v",
+
+	"[dry-run] Would email %s through %s":           "[dry-run] Se enviaría un correo a %s mediante %s",
+	"[dry-run] Would notify %s":                     "[dry-run] Se notificaría a %s",
+	"⚠️ Warning: Could not encode notification: %v": "⚠️ Advertencia: No se pudo codificar la notificación: %v",
+	"⚠️ Warning: Could not send notification: %v":   "⚠️ Advertencia: No se pudo enviar la notificación: %v",
+	"⚠️ Warning: Notification webhook returned %s":  "⚠️ Advertencia: El webhook de notificación devolvió %s",
+	// Validate and stats
+	"🔍 Validating configuration":                                                  "🔍 Validando la configuración",
+	"📈 Deploy Stats (last %d of %d runs)":                                         "📈 Estadísticas de despliegue (últimas %d de %d ejecuciones)",
+	"No runs recorded yet in %s":                                                  "Aún no hay ejecuciones registradas en %s",
+	"Success rate: %d/%d (%.0f%%)":                                                "Tasa de éxito: %d/%d (%.0f%%)",
+	"Trends (recent successful runs against the ones before):":                    "Tendencias (ejecuciones correctas recientes frente a las anteriores):",
+	"Invalid number of runs: %s":                                                  "Número de ejecuciones no válido: %s",
+	"⚠️ %-8s %7s → %-7s %+4.0f%%  slower":                                         "⚠️ %-8s %7s → %-7s %+4.0f%%  más lento",
+	"📊 Status of %s":                                                              "📊 Estado de %s",
+	"   Server:   📡 %s, on network share %s":                                      "   Servidor: 📡 %s, en el recurso de red %s",
+	"   Server:   ✅ running (PID %s, up %s)":                                      "   Servidor: ✅ en marcha (PID %s, activo desde hace %s)",
+	"   Server:   ❌ not running":                                                  "   Servidor: ❌ detenido",
+	"   Port:     ✅ %s open":                                                      "   Puerto:   ✅ %s abierto",
+	"   Port:     ❌ %s closed":                                                    "   Puerto:   ❌ %s cerrado",
+	"   Lock:     🔒 %s":                                                           "   Bloqueo:  🔒 %s",
+	"   %s: no deploy manifest, not deployed by sfdeploy yet":                     "   %s: sin manifiesto de despliegue, sfdeploy aún no lo ha desplegado",
+	"      %d files, sfdeploy %s":                                                 "      %d archivos, sfdeploy %s",
+	"Unknown config command: %s (expected validate, set, edit, export or import)": "Comando config desconocido: %s (se esperaba validate, set, edit, export o import)",
+	"%d passed, %d warnings, %d failed":                                           "%d correctos, %d advertencias, %d fallidos",
+	"%s entry matched no JAR files: %s":                                           "La entrada %s no coincide con ningún JAR: %s",
+	"%s not found at %s":                                                          "No se encontró %s en %s",
+	"Cannot write to %s: %v":                                                      "No se puede escribir en %s: %v",
+	"Config file %s: %v":                                                          "Archivo de configuración %s: %v",
+	"Config file not loaded (%v), using flags and environment only":               "Archivo de configuración no cargado (%v), solo se usan las opciones y el entorno",
+	"Config file: %s":                                                             "Archivo de configuración: %s",
+	"Could not determine the JDK version of %s: %v":                               "No se pudo determinar la versión del JDK de %s: %v",
+	"Docker target: %s":                                                           "Destino Docker: %s",
+	"Emailing results to %s through %s":                                           "Los resultados se envían por correo a %s mediante %s",
+	"JSON file not found: %s":                                                     "No se encontró el archivo JSON: %s",
+	"JSON file: %s":                                                               "Archivo JSON: %s",
+	"Java %d at %s does not match java_version %s":                                "Java %d en %s no coincide con java_version %s",
+	"Keystore not found: %s":                                                      "No se encontró el almacén de claves: %s",
+	"Module has no src folder: %s":                                                "El módulo no tiene carpeta src: %s",
+	"Modules: %v":                                                                 "Módulos: %v",
+	"No .java or .kt files under %s":                                              "No hay archivos .java ni .kt en %s",
+	"No SFS2X folder in %s":                                                       "No hay carpeta SFS2X en %s",
+	"No SmartFox launcher in %s":                                                  "No hay lanzador de SmartFox en %s",
+	"No jarsigner in %s":                                                          "No hay jarsigner en %s",
+	"No javac in %s":                                                              "No hay javac en %s",
+	"Package %s of %s not found in %s":                                            "No se encontró el paquete %s de %s en %s",
+	"ProGuard JAR not found: %s":                                                  "No se encontró el JAR de ProGuard: %s",
+	"ProGuard rules not found: %s":                                                "No se encontraron las reglas de ProGuard: %s",
+	"ProGuard: %s":                                                                "ProGuard: %s",
+	"Remote target: %s":                                                           "Destino remoto: %s",
+	"Required setting missing: %s":                                                "Falta un ajuste obligatorio: %s",
+	"Resource folder not found: %s":                                               "No se encontró la carpeta de recursos: %s",
+	"Resources: %d files from %s":                                                 "Recursos: %d archivos de %s",
+	"Signing with %s from %s":                                                     "Firma con %s de %s",
+	"Source directory has no src folder: %s":                                      "El directorio de origen no tiene carpeta src: %s",
+	"Source directory not found: %s":                                              "No se encontró el directorio de origen: %s",
+	"Source: %s, %d modules":                                                      "Origen: %s, %d módulos",
+	"Target: %s (%s)":                                                             "Destino: %s (%s)",
+	"The extensions list is invalid":                                              "La lista de extensions no es válida",
+	"The project config, profile, overrides or secrets could not be applied": "No se pudieron aplicar la configuración del proyecto, el perfil, los valores del entorno o los secretos",
+	"Writable: %s":            "Con permiso de escritura: %s",
+	"Zone file not found: %s": "No se encontró el archivo de zona: %s",
+	"Zone file: %s":           "Archivo de zona: %s",
+	"deploy_files of %s matched no files in %s":                                  "deploy_files de %s no coincide con ningún archivo de %s",
+	"deploy_files of %s: %v":                                                     "deploy_files de %s: %v",
+	"deploy_files: %d files from %s":                                             "deploy_files: %d archivos de %s",
+	"jar_files: %v":                                                              "jar_files: %v",
+	"notifications.email.smtp_host is not set":                                   "notifications.email.smtp_host no está definido",
+	"notifications.email.to is empty":                                            "notifications.email.to está vacío",
+	"notifications.email.username is set without a password":                     "notifications.email.username está definido sin contraseña",
+	"proguard.rules is empty, ProGuard runs with its defaults and keeps nothing": "proguard.rules está vacío, ProGuard usa sus valores por defecto y no conserva nada",
+	"resource_dirs %s has no matching files":                                     "resource_dirs %s no tiene archivos coincidentes",
+	"resource_dirs %s: %v":                                                       "resource_dirs %s: %v",
+	"signing.alias is not set":                                                   "signing.alias no está definido",
+	"source_dir is not set":                                                      "source_dir no está definido",
+	"target_dir is not set":                                                      "target_dir no está definido",
+	"When":                                                                       "Cuándo",
+	"Command":                                                                    "Comando",
+	"Result":                                                                     "Estado",
+	"Total":                                                                      "Total",
+	"Build":                                                                      "Compil.",
+	"Tests":                                                                      "Pruebas",
+	"Deploy":                                                                     "Despl.",
+	"Restart":                                                                    "Reinic.",
+	"Files":                                                                      "Arch.",
+	"Size":                                                                       "Tamaño",
+
+	// Dry run
+	"[dry-run] Would run in %s: %s %s":             "[dry-run] Se ejecutaría en %s: %s %s",
+	"[dry-run] Would copy the JAR from %s to %s":   "[dry-run] Se copiaría el JAR de %s a %s",
+	"[dry-run] Would compile all %d Java files:":   "[dry-run] Se compilarían los %d archivos Java:",
+	"[dry-run] Would compile %d of %d Java files:": "[dry-run] Se compilarían %d de %d archivos Java:",
+	"   Module %s:": "   Módulo %s:",
+	"[dry-run] Would compile %d Kotlin files with kotlinc into %s":                            "[dry-run] Se compilarían %d archivos Kotlin con kotlinc en %s",
+	"[dry-run] Classpath: %s":                                                                 "[dry-run] Classpath: %s",
+	"[dry-run] javac options: %s":                                                             "[dry-run] Opciones de javac: %s",
+	"[dry-run] Would create %s from classes and resources in %s":                              "[dry-run] Se crearía %s a partir de las clases y recursos de %s",
+	"[dry-run] Would create %s from %s":                                                       "[dry-run] Se crearía %s a partir de %s",
+	"[dry-run] Would create %s from packages %s of %s":                                        "[dry-run] Se crearía %s a partir de los paquetes %s de %s",
+	"[dry-run] Would sign the JARs with %s from %s":                                           "[dry-run] Se firmarían los JAR con %s de %s",
+	"[dry-run] Would stop Windows service %s on %s":                                           "[dry-run] Se detendría el servicio de Windows %s en %s",
+	"[dry-run] Would leave SmartFox on %s running; %s":                                        "[dry-run] SmartFox en %s seguiría en marcha; %s",
+	"[dry-run] Would run: %s":                                                                 "[dry-run] Se ejecutaría: %s",
+	"[dry-run] Would stop SmartFox on %s":                                                     "[dry-run] Se detendría SmartFox en %s",
+	"[dry-run] Would stop Windows service %s":                                                 "[dry-run] Se detendría el servicio de Windows %s",
+	"[dry-run] Would kill processes listening on port %d":                                     "[dry-run] Se finalizarían los procesos que escuchan en el puerto %d",
+	"[dry-run] Would snapshot current deployment to %s":                                       "[dry-run] Se guardaría una instantánea del despliegue actual en %s",
+	"[dry-run] Would delete: %s:%s":                                                           "[dry-run] Se eliminaría: %s:%s",
+	"[dry-run] Would delete: %s":                                                              "[dry-run] Se eliminaría: %s",
+	"[dry-run] Would docker cp: %s -> %s":                                                     "[dry-run] Se ejecutaría docker cp: %s -> %s",
+	"[dry-run] Would deploy to: %s":                                                           "[dry-run] Se desplegaría en: %s",
+	"[dry-run] Would copy: %s -> %s":                                                          "[dry-run] Se copiaría: %s -> %s",
+	"[dry-run] Would swap %s in for %s":                                                       "[dry-run] Se pondría %s en lugar de %s",
+	"[dry-run] Would skip unchanged: %s":                                                      "[dry-run] Se omitiría sin cambios: %s",
+	"[dry-run] Would POST a restart request to %s, falling back to a hard restart on failure": "[dry-run] Se enviaría un POST de reinicio a %s, con reinicio forzado si falla",
+	"[dry-run] Would restart Windows service %s on %s with sc stop and sc start":              "[dry-run] Se reiniciaría el servicio de Windows %s en %s con sc stop y sc start",
+	"[dry-run] Would fail: SmartFox on %s cannot be restarted from here; %s":                  "[dry-run] Fallaría: SmartFox en %s no se puede reiniciar desde aquí; %s",
+	"[dry-run] Would wait up to %s for %s to become active":                                   "[dry-run] Se esperaría hasta %s a que %s esté activo",
+	"[dry-run] Would run over SSH: cd %s && %s":                                               "[dry-run] Se ejecutaría por SSH: cd %s && %s",
+	"[dry-run] Would run: docker %s":                                                          "[dry-run] Se ejecutaría: docker %s",
+	"[dry-run] Would stop the process listening on port %d, giving it up to %s after SIGTERM and %s after SIGKILL to exit": "[dry-run] Se detendría el proceso que escucha en el puerto %d, dándole hasta %s tras SIGTERM y %s tras SIGKILL para salir",
+	"[dry-run] Would check that ports %s are free":                                   "[dry-run] Se comprobaría que los puertos %s están libres",
+	"[dry-run] Would run in %s: %s":                                                  "[dry-run] Se ejecutaría en %s: %s",
+	"[dry-run] Would restart Windows service %s with sc stop and sc start":           "[dry-run] Se reiniciaría el servicio de Windows %s con sc stop y sc start",
+	"[dry-run] Would close the existing SmartFox CMD window if one is running":       "[dry-run] Se cerraría la ventana CMD de SmartFox si hay una abierta",
+	"[dry-run] Would write %s to call %s":                                            "[dry-run] Se escribiría %s para llamar a %s",
+	"[dry-run] Compiled classes and JARs created by the build would also be removed": "[dry-run] También se eliminarían las clases compiladas y los JAR creados por la compilación",
+
+	// Config and credentials
+	"❌ Cannot read %s: %v":                              "❌ No se puede leer %s: %v",
+	"   - %s (detected on each machine)":                "   - %s (se detecta en cada máquina)",
+	"❌ The value of %s would be exported in plain text": "❌ El valor de %s se exportaría en texto plano",
+	"❌ Not writing %s; store it with 'sfdeploy credentials set <name>', refer to it as secret:<name> and export again": "❌ No se escribe %s; guárdalo con 'sfdeploy credentials set <name>', refiérete a él como secret:<name> y vuelve a exportar",
+	"   ? %s (%s, asked for on import)":                                                       "   ? %s (%s, se pregunta al importar)",
+	"✅ Exported %d setting(s) of %s to %s":                                                    "✅ %d ajuste(s) de %s exportados a %s",
+	"💡 Share it with your team; they run: sfdeploy config import %s":                          "💡 Compártelo con tu equipo; ellos ejecutan: sfdeploy config import %s",
+	"❌ Failed to store %s: %v":                                                                "❌ No se pudo guardar %s: %v",
+	"🔐 Stored %s in the %s":                                                                   "🔐 %s guardado en %s",
+	"❌ %s is not an sfdeploy bundle (made by sfdeploy config export)":                         "❌ %s no es un paquete de sfdeploy (creado con sfdeploy config export)",
+	"❌ %s was exported by a newer sfdeploy (bundle version %d); update sfdeploy first":        "❌ %s se exportó con un sfdeploy más reciente (versión de paquete %d); actualiza sfdeploy primero",
+	"❌ Invalid config in %s: %v":                                                              "❌ Configuración no válida en %s: %v",
+	"📦 Importing %s into %s":                                                                  "📦 Importando %s en %s",
+	"Enter the paths on this machine (press Enter to leave a setting out)":                    "Introduce las rutas de esta máquina (pulsa Intro para dejar fuera un ajuste)",
+	"❌ Failed to set %s: %v":                                                                  "❌ No se pudo definir %s: %v",
+	"✅ Imported %d setting(s) into %s":                                                        "✅ %d ajuste(s) importados en %s",
+	"💡 Left out: %s; set them with sfdeploy config set <key> <value> or SFDEPLOY_* variables": "💡 Fuera: %s; defínelos con sfdeploy config set <key> <value> o con variables SFDEPLOY_*",
+	"💡 Check the result with: sfdeploy config validate":                                       "💡 Comprueba el resultado con: sfdeploy config validate",
+	"Usage: sfdeploy config set <key> <value> (e.g. config set extension_folder MyExtension)": "Uso: sfdeploy config set <key> <value> (p. ej. config set extension_folder MyExtension)",
+	"❌ Invalid value for %s: %v":                                                              "❌ Valor no válido para %s: %v",
+	"❌ Failed to update %s: %v":                                                               "❌ No se pudo actualizar %s: %v",
+	"✅ Set %s = %s in profile %s of %s":                                                       "✅ %s = %s definido en el perfil %s de %s",
+	"✅ Set %s = %s in %s":                                                                     "✅ %s = %s definido en %s",
+	"❌ config edit needs prompts; use config set instead":                                     "❌ config edit necesita preguntas interactivas; usa config set en su lugar",
+	"❌ Cannot edit %s: %v":                                                                    "❌ No se puede editar %s: %v",
+	"✏️ Editing %s (press Enter to keep a value)":                                             "✏️ Editando %s (pulsa Intro para conservar un valor)",
+	"✏️ Creating %s":                                                                          "✏️ Creando %s",
+	"No changes":                                                                              "Sin cambios",
+	"✅ Saved %d setting(s) to %s":                                                             "✅ %d ajuste(s) guardados en %s",
+	"🔎 Found extension class %s":                                                              "🔎 Clase de extensión encontrada: %s",
+	"🔎 Found several classes extending SFSExtension:":                                         "🔎 Se encontraron varias clases que extienden SFSExtension:",
+	"Enter a number from 1 to %d":                                                             "Introduce un número del 1 al %d",
+	"⚠️ OS keychain unavailable (%v), using %s":                                               "⚠️ El llavero del sistema no está disponible (%v), se usa %s",
+	"Usage: sfdeploy credentials set <name> [value]":                                          "Uso: sfdeploy credentials set <name> [value]",
+	"✅ Stored %s in the %s; refer to it as \"%s%s\"":                                          "✅ %s guardado en %s; refiérete a él como \"%s%s\"",
+	"Usage: sfdeploy credentials delete <name>":                                               "Uso: sfdeploy credentials delete <name>",
+	"🗑️ Deleted %s":                                                                           "🗑️ %s eliminado",
+	"No %s<name> references in %s":                                                            "No hay referencias %s<name> en %s",
+	"Unknown credentials command: %s (expected set, delete or list)":                          "Comando credentials desconocido: %s (se esperaba set, delete o list)",
+	"Extension class":                                                                         "Clase de la extensión",
+	"⚠️ %s has no src folder with .java files":                                                "⚠️ %s no tiene una carpeta src con archivos .java",
+	"⚠️ %s does not look like a SmartFox Server folder":                                       "⚠️ %s no parece una carpeta de SmartFox Server",
+	"Use it anyway? (y/n)":                                                                    "¿Usarlo de todos modos? (y/n)",
+	"🔑 Credentials passphrase: ":                                                              "🔑 Frase de paso de las credenciales: ",
+	"🔑 Repeat the passphrase: ":                                                               "🔑 Repite la frase de paso: ",
+	"Value for %s: ":                                                                          "Valor de %s: ",
+	"Download Eclipse Temurin JDK %d into %s? (y/n): ":                                        "¿Descargar Eclipse Temurin JDK %d en %s? (y/n): ",
+	"Path for %s":                                           "Ruta de %s",
+	"Value of %s":                                           "Valor de %s",
+	"%s (hidden, Enter to leave out): ":                     "%s (oculto, Intro para dejarlo fuera): ",
+	"%s (%s or a path)":                                     "%s (%s o una ruta)",
+	"Source directory (contains src/)":                      "Directorio de origen (contiene src/)",
+	"SmartFox Server directory (contains SFS2X/)":           "Directorio de SmartFox Server (contiene SFS2X/)",
+	"Extension folder name":                                 "Nombre de la carpeta de la extensión",
+	"Zone to register the extension in (optional)":          "Zona en la que registrar la extensión (opcional)",
+	"Extension main class":                                  "Clase principal de la extensión",
+	"Folder with the JSON files to deploy":                  "Carpeta con los archivos JSON que desplegar",
+	"JSON files to deploy, without .json (comma separated)": "Archivos JSON que desplegar, sin .json (separados por comas)",
+	"Java version":                                          "Versión de Java",
+
+	// Scaffolding
+	"❌ Set client_constants to the constants file of the game client first":            "❌ Define primero client_constants con el archivo de constantes del cliente del juego",
+	"Unknown generate command: %s (expected handler or constants)":                     "Comando generate desconocido: %s (se esperaba handler o constants)",
+	"Usage: sfdeploy generate handler <Name> [command], e.g. generate handler BuyItem": "Uso: sfdeploy generate handler <Name> [command], p. ej. generate handler BuyItem",
+	"❌ %s is not a Java file; generate handler patches Java extensions only":           "❌ %s no es un archivo Java; generate handler solo modifica extensiones Java",
+	"❌ %s already exists":                   "❌ %s ya existe",
+	"❌ Cannot register %s in %s: %v":        "❌ No se puede registrar %s en %s: %v",
+	"   ~ %s (registers \"%s\")":            "   ~ %s (registra \"%s\")",
+	"✅ Generated %s for the \"%s\" request": "✅ %s generado para la petición \"%s\"",
+	"❌ %s is not a Java class name with a package, e.g. com.mycompany.chess.ChessExtension": "❌ %s no es un nombre de clase Java con paquete, p. ej. com.mycompany.chess.ChessExtension",
+	"❌ Not creating the project, these files already exist: %s":                             "❌ No se crea el proyecto, estos archivos ya existen: %s",
+	"🧱 Creating extension project %s in %s":                                                 "🧱 Creando el proyecto de extensión %s en %s",
+	"✅ Created %s, deploying to %s":                                                         "✅ %s creado, se despliega en %s",
+	"✅ Created %s":                                                                          "✅ %s creado",
+	"💡 No SmartFox Server found; set it with: sfdeploy config set target_dir <path>":        "💡 No se encontró SmartFox Server; defínelo con: sfdeploy config set target_dir <path>",
+	"💡 Next: cd %s && sfdeploy all":                                                         "💡 Siguiente paso: cd %s && sfdeploy all",
+	"💡 Next: sfdeploy all":                                                                  "💡 Siguiente paso: sfdeploy all",
+}
@@ -0,0 +1,246 @@
+package sfdeploy
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const (
+	metricsFile  = "metrics.jsonl"
+	metricsLimit = 1000
+
+	defaultStatsRuns = 20
+	// trendWindow is how many successful runs are averaged on each side of
+	// a trend. A slowdown is flagged from trendThreshold and at least
+	// trendMinSeconds, so sub-second jitter goes unnoticed.
+	trendWindow     = 10
+	trendThreshold  = 0.2
+	trendMinSeconds = 1.0
+)
+
+// runMetrics is one line of the metrics store: a run of a command, or a
+// cycle of watch, listen or serve.
+type runMetrics struct {
+	Time      time.Time `json:"time"`
+	Command   string    `json:"command"`
+	Success   bool      `json:"success"`
+	Extension string    `json:"extension,omitempty"`
+	Target    string    `json:"target,omitempty"`
+	Duration  float64   `json:"duration_seconds"`
+	Build     float64   `json:"build_seconds,omitempty"`
+	Tests     float64   `json:"test_seconds,omitempty"`
+	Deploy    float64   `json:"deploy_seconds,omitempty"`
+	Restart   float64   `json:"restart_seconds,omitempty"`
+	Files     int       `json:"files_copied"`
+	Bytes     int64     `json:"bytes_copied"`
+}
+
+func metricsPath() string {
+	return filepath.Join(stateDir, metricsFile)
+}
+
+// recordsMetrics reports whether runs of the command are worth recording.
+// Watch, listen and serve record each of their cycles instead.
+func recordsMetrics(name string) bool {
+	return name == "build" || name == "test" || notifies(name)
+}
+
+// collectMetrics adds up the phases of the run that started at started.
+// The restart time runs until the health check passed.
+func collectMetrics(config *Config, name string, success bool, started time.Time) runMetrics {
+	phases, _ := runReportCollector.since(started)
+	m := runMetrics{
+		Time:      started,
+		Command:   name,
+		Success:   success,
+		Extension: config.ExtensionFolder,
+		Target:    notifyTarget(config),
+		Duration:  time.Since(started).Round(time.Millisecond).Seconds(),
+	}
+	for _, p := range phases {
+		switch p.Name {
+		case "Building Project":
+			m.Build += p.Duration
+		case "Running Tests":
+			m.Tests += p.Duration
+		case "Deploying Project":
+			m.Deploy += p.Duration
+		case "Restarting SmartFox Server", "Checking Server Health":
+			m.Restart += p.Duration
+		}
+		m.Files += p.Files
+		m.Bytes += p.Bytes
+	}
+	return m
+}
+
+// recordMetrics appends the run to the metrics store, keeping the last
+// metricsLimit runs. Recording never fails the run.
+func recordMetrics(config *Config, name string, success bool, started time.Time) {
+	if *flagDryRun || config.SourceDir == "" {
+		return
+	}
+	m := collectMetrics(config, name, success, started)
+
+	runs := loadMetrics()
+	runs = append(runs, m)
+	if len(runs) > metricsLimit {
+		runs = runs[len(runs)-metricsLimit:]
+	}
+	if err := saveMetrics(runs); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️ Warning: Could not record metrics in %s: %v\n", metricsPath(), err)
+	}
+}
+
+// loadMetrics reads the metrics store, oldest run first. Lines that do not
+// parse are skipped.
+func loadMetrics() []runMetrics {
+	file, err := os.Open(metricsPath())
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var runs []runMetrics
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var m runMetrics
+		if json.Unmarshal(scanner.Bytes(), &m) == nil {
+			runs = append(runs, m)
+		}
+	}
+	return runs
+}
+
+func saveMetrics(runs []runMetrics) error {
+	if err := os.MkdirAll(stateDir, 0755); err != nil {
+		return err
+	}
+	var data []byte
+	for _, m := range runs {
+		line, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+		data = append(append(data, line...), '\n')
+	}
+	tmp := metricsPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, metricsPath())
+}
+
+func seconds(s float64) string {
+	if s == 0 {
+		return "-"
+	}
+	return strconv.FormatFloat(s, 'f', 1, 64) + "s"
+}
+
+// metricTrend compares the average of a metric over the last trendWindow
+// successful runs with the trendWindow before them.
+func metricTrend(runs []runMetrics, value func(runMetrics) float64) (before, after float64, ok bool) {
+	var values []float64
+	for _, m := range runs {
+		if v := value(m); m.Success && v > 0 {
+			values = append(values, v)
+		}
+	}
+	if len(values) < 2 {
+		return 0, 0, false
+	}
+	n := min(trendWindow, len(values)/2)
+	average := func(vs []float64) float64 {
+		sum := 0.0
+		for _, v := range vs {
+			sum += v
+		}
+		return sum / float64(len(vs))
+	}
+	recent := values[len(values)-n:]
+	earlier := values[len(values)-2*n : len(values)-n]
+	return average(earlier), average(recent), true
+}
+
+// statsCommand implements "stats [n]": the last n recorded runs and how
+// build, test, deploy and restart times have moved.
+func statsCommand(config *Config) bool {
+	limit := defaultStatsRuns
+	if arg := commandArg(0); arg != "" {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 1 {
+			tprintf("Invalid number of runs: %s\n", arg)
+			return false
+		}
+		limit = n
+	}
+
+	all := loadMetrics()
+	if len(all) == 0 {
+		tprintf("No runs recorded yet in %s\n", metricsPath())
+		return true
+	}
+	runs := all[max(0, len(all)-limit):]
+
+	tprintf("📈 Deploy Stats (last %d of %d runs)\n", len(runs), len(all))
+	fmt.Println()
+	fmt.Printf("  %-16s  %-8s  %-6s  %7s  %7s  %7s  %7s  %7s  %5s  %9s\n",
+		tr("When"), tr("Command"), tr("Result"), tr("Total"), tr("Build"), tr("Tests"), tr("Deploy"), tr("Restart"), tr("Files"), tr("Size"))
+	succeeded := 0
+	for _, m := range runs {
+		result := "❌"
+		if m.Success {
+			result = "✅"
+			succeeded++
+		}
+		size := "-"
+		if m.Bytes > 0 {
+			size = formatSize(m.Bytes)
+		}
+		// The emoji is two columns wide
+		fmt.Printf("  %-16s  %-8s  %s      %7s  %7s  %7s  %7s  %7s  %5d  %9s\n",
+			m.Time.Local().Format("2006-01-02 15:04"), m.Command, result, seconds(m.Duration),
+			seconds(m.Build), seconds(m.Tests), seconds(m.Deploy), seconds(m.Restart), m.Files, size)
+	}
+	fmt.Println()
+	tprintf("Success rate: %d/%d (%.0f%%)\n", succeeded, len(runs), 100*float64(succeeded)/float64(len(runs)))
+
+	trends := []struct {
+		name  string
+		value func(runMetrics) float64
+	}{
+		{"Build", func(m runMetrics) float64 { return m.Build }},
+		{"Tests", func(m runMetrics) float64 { return m.Tests }},
+		{"Deploy", func(m runMetrics) float64 { return m.Deploy }},
+		{"Restart", func(m runMetrics) float64 { return m.Restart }},
+	}
+	header := false
+	for _, t := range trends {
+		before, after, ok := metricTrend(all, t.value)
+		if !ok || before == 0 {
+			continue
+		}
+		if !header {
+			fmt.Println()
+			tprintln("Trends (recent successful runs against the ones before):")
+			header = true
+		}
+		change := math.Round(100 * (after - before) / before)
+		if change == 0 {
+			change = 0 // not -0
+		}
+		if change >= 100*trendThreshold && after-before >= trendMinSeconds {
+			tprintf("⚠️ %-8s %7s → %-7s %+4.0f%%  slower\n", t.name, seconds(before), seconds(after), change)
+		} else {
+			fmt.Printf("   %-8s %7s → %-7s %+4.0f%%\n", t.name, seconds(before), seconds(after), change)
+		}
+	}
+	return true
+}
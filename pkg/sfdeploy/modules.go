@@ -0,0 +1,276 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// sourceModule is one module of a multi-module project: a folder of
+// source_dir with its own src folder, compiled after the modules it depends
+// on. Dir defaults to the name. Without depends_on the dependencies are
+// taken from the imports of the module's sources.
+type sourceModule struct {
+	Name      string   `json:"name"`
+	Dir       string   `json:"dir"`
+	DependsOn []string `json:"depends_on"`
+}
+
+func (m sourceModule) srcDir(config *Config) string {
+	return filepath.Join(config.SourceDir, m.Dir, "src")
+}
+
+var importPattern = regexp.MustCompile(`(?m)^\s*import\s+(?:static\s+)?([\w.]+)`)
+
+// listModules returns the configured modules, or when source_dir has no src
+// folder of its own, its subfolders that have one, sorted by name.
+func listModules(config *Config) []sourceModule {
+	if len(config.Modules) > 0 {
+		modules := append([]sourceModule(nil), config.Modules...)
+		for i := range modules {
+			if modules[i].Dir == "" {
+				modules[i].Dir = modules[i].Name
+			}
+			if modules[i].Name == "" {
+				modules[i].Name = filepath.Base(filepath.Clean(modules[i].Dir))
+			}
+		}
+		return modules
+	}
+
+	if config.SourceDir == "" || fileExists(filepath.Join(config.SourceDir, "src")) {
+		return nil
+	}
+	entries, err := os.ReadDir(config.SourceDir)
+	if err != nil {
+		return nil
+	}
+	var modules []sourceModule
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") && fileExists(filepath.Join(config.SourceDir, entry.Name(), "src")) {
+			modules = append(modules, sourceModule{Name: entry.Name(), Dir: entry.Name()})
+		}
+	}
+	return modules
+}
+
+// inferModuleDeps fills in the dependencies of the modules without
+// depends_on: a module depends on the modules declaring the packages it
+// imports from.
+func inferModuleDeps(config *Config, modules []sourceModule) {
+	owners := map[string][]string{}
+	imports := make([][]string, len(modules))
+	for i, m := range modules {
+		srcDir := m.srcDir(config)
+		for _, file := range append(findJavaFiles(config, srcDir), findKotlinFiles(config, srcDir)...) {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				continue
+			}
+			if match := sourcePackagePattern.FindSubmatch(data); match != nil {
+				pkg := string(match[1])
+				if !slices.Contains(owners[pkg], m.Name) {
+					owners[pkg] = append(owners[pkg], m.Name)
+				}
+			}
+			for _, match := range importPattern.FindAllSubmatch(data, -1) {
+				imports[i] = append(imports[i], string(match[1]))
+			}
+		}
+	}
+
+	for i := range modules {
+		if modules[i].DependsOn != nil {
+			continue
+		}
+		deps := map[string]bool{}
+		for _, name := range imports[i] {
+			// The package is a prefix of the imported class or member
+			for pkg := name; strings.Contains(pkg, "."); {
+				pkg = pkg[:strings.LastIndex(pkg, ".")]
+				for _, owner := range owners[pkg] {
+					if owner != modules[i].Name && !slices.Contains(owners[pkg], modules[i].Name) {
+						deps[owner] = true
+					}
+				}
+			}
+		}
+		modules[i].DependsOn = []string{}
+		for dep := range deps {
+			modules[i].DependsOn = append(modules[i].DependsOn, dep)
+		}
+		sort.Strings(modules[i].DependsOn)
+	}
+}
+
+// moduleOrder sorts the modules so that every module comes after the
+// modules it depends on, keeping the listed order otherwise.
+func moduleOrder(modules []sourceModule) ([]sourceModule, error) {
+	index := map[string]int{}
+	for i, m := range modules {
+		if _, ok := index[m.Name]; ok {
+			return nil, fmt.Errorf("module %s is listed twice", m.Name)
+		}
+		index[m.Name] = i
+	}
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make([]int, len(modules))
+	var order []sourceModule
+	var path []string
+
+	var visit func(i int) error
+	visit = func(i int) error {
+		name := modules[i].Name
+		switch state[i] {
+		case done:
+			return nil
+		case visiting:
+			for j, n := range path {
+				if n == name {
+					return fmt.Errorf("module dependency cycle: %s", strings.Join(append(path[j:], name), " → "))
+				}
+			}
+		}
+
+		state[i] = visiting
+		path = append(path, name)
+		for _, dep := range modules[i].DependsOn {
+			j, ok := index[dep]
+			if !ok {
+				return fmt.Errorf("module %s depends on %s, which is not a module", name, dep)
+			}
+			if err := visit(j); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = done
+		order = append(order, modules[i])
+		return nil
+	}
+
+	for i := range modules {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// projectModules returns the modules of the project in build order, or
+// none for a project with a single src folder.
+func projectModules(config *Config) ([]sourceModule, error) {
+	if config.modules != nil {
+		return config.modules, nil
+	}
+	modules := listModules(config)
+	if len(modules) == 0 {
+		return nil, nil
+	}
+	inferModuleDeps(config, modules)
+	return moduleOrder(modules)
+}
+
+// checkModules makes sure every module has sources and its dependencies
+// can be ordered, and keeps the order for the rest of the run.
+func checkModules(config *Config) bool {
+	modules, err := projectModules(config)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return false
+	}
+	for _, m := range modules {
+		if !fileExists(m.srcDir(config)) {
+			tprintf("❌ Module %s has no src folder: %s\n", m.Name, m.srcDir(config))
+			return false
+		}
+	}
+	if len(modules) > 0 {
+		var names []string
+		for _, m := range modules {
+			names = append(names, m.Name)
+		}
+		verbosef("📦 Modules in build order: %s\n", strings.Join(names, ", "))
+	}
+	config.modules = modules
+	return true
+}
+
+// sourceRoots lists the src folders of the project in build order.
+func sourceRoots(config *Config) []string {
+	modules, err := projectModules(config)
+	if err != nil {
+		// checkModules reports the error, fall back to the listed order
+		modules = listModules(config)
+	}
+	if len(modules) == 0 {
+		return []string{filepath.Join(config.SourceDir, "src")}
+	}
+	var roots []string
+	for _, m := range modules {
+		roots = append(roots, m.srcDir(config))
+	}
+	return roots
+}
+
+// sourceBase is the folder the build manifest keys are relative to: the src
+// folder, or source_dir for a multi-module project, so that equal paths in
+// two modules stay apart.
+func sourceBase(config *Config) string {
+	if roots := sourceRoots(config); len(roots) == 1 {
+		return roots[0]
+	}
+	return config.SourceDir
+}
+
+// projectJavaFiles finds the Java sources of every module.
+func projectJavaFiles(config *Config) []string {
+	var files []string
+	for _, root := range sourceRoots(config) {
+		files = append(files, findJavaFiles(config, root)...)
+	}
+	return files
+}
+
+// projectKotlinFiles finds the Kotlin sources of every module.
+func projectKotlinFiles(config *Config) []string {
+	var files []string
+	for _, root := range sourceRoots(config) {
+		files = append(files, findKotlinFiles(config, root)...)
+	}
+	return files
+}
+
+// compileGroup is the part of a compile plan that belongs to one src folder.
+type compileGroup struct {
+	dir   string
+	files []string
+}
+
+// groupByRoot splits the files to compile by src folder, in build order, so
+// that each module is compiled against the classes of the modules before it.
+func groupByRoot(config *Config, files []string) []compileGroup {
+	roots := sourceRoots(config)
+	var groups []compileGroup
+	for _, root := range roots {
+		group := compileGroup{dir: root}
+		prefix := absPath(root) + string(filepath.Separator)
+		for _, file := range files {
+			if strings.HasPrefix(absPath(file), prefix) {
+				group.files = append(group.files, file)
+			}
+		}
+		if len(group.files) > 0 {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
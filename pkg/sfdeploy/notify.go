@@ -0,0 +1,166 @@
+package sfdeploy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/user"
+	"strings"
+	"time"
+)
+
+// notifyConfig is the notifications block: where to post the result of each
+// run. Format is slack, discord or json, and is guessed from the URL when
+// empty.
+type notifyConfig struct {
+	WebhookURL string      `json:"webhook_url"`
+	Format     string      `json:"format"`
+	Email      emailConfig `json:"email"`
+}
+
+// notifyPayload is the body posted for the json format.
+type notifyPayload struct {
+	Command   string  `json:"command"`
+	Success   bool    `json:"success"`
+	Extension string  `json:"extension"`
+	Target    string  `json:"target"`
+	Profile   string  `json:"profile,omitempty"`
+	User      string  `json:"user"`
+	Commit    string  `json:"commit,omitempty"`
+	Duration  float64 `json:"duration_seconds"`
+	Message   string  `json:"message"`
+}
+
+// notifies reports whether a run of the command changes the server and is
+// therefore worth telling the team about.
+func notifies(name string) bool {
+	switch name {
+	case "all", "deploy", "restart", "rollback":
+		return true
+	case "history", "backup":
+		return commandArg(0) == "restore"
+	}
+	return false
+}
+
+func deployUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	for _, key := range []string{"USER", "USERNAME"} {
+		if name := os.Getenv(key); name != "" {
+			return name
+		}
+	}
+	return "unknown"
+}
+
+// gitCommit returns the short commit of the source directory, or "" when it
+// is not a git checkout.
+func gitCommit(config *Config) string {
+	commit, err := gitOutput(config, "rev-parse", "--short", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return commit
+}
+
+func notifyFormat(config *Config) string {
+	if config.Notifications.Format != "" {
+		return strings.ToLower(config.Notifications.Format)
+	}
+	url := config.Notifications.WebhookURL
+	switch {
+	case strings.Contains(url, "hooks.slack.com"):
+		return "slack"
+	case strings.Contains(url, "discord.com/api/webhooks"), strings.Contains(url, "discordapp.com/api/webhooks"):
+		return "discord"
+	}
+	return "json"
+}
+
+func notifyTarget(config *Config) string {
+	if len(config.Targets) > 0 {
+		return strings.Join(config.Targets, ", ")
+	}
+	return config.TargetDir
+}
+
+// notifyResult posts the outcome of a run to the configured webhook and
+// emails it. A failing notification is only a warning; it never changes the
+// run's result.
+func notifyResult(config *Config, name string, success bool, started time.Time) {
+	if !notifies(name) {
+		return
+	}
+	if config.Notifications.WebhookURL != "" {
+		postNotification(config, name, success, started)
+	}
+	if emailEnabled(config) {
+		emailResult(config, name, success, started)
+	}
+}
+
+func newNotifyPayload(config *Config, name string, success bool, started time.Time) notifyPayload {
+	p := notifyPayload{
+		Command:   name,
+		Success:   success,
+		Extension: config.ExtensionFolder,
+		Target:    notifyTarget(config),
+		Profile:   *flagProfile,
+		User:      deployUser(),
+		Commit:    gitCommit(config),
+		Duration:  time.Since(started).Round(100 * time.Millisecond).Seconds(),
+	}
+
+	status := "✅ succeeded"
+	if !success {
+		status = "❌ failed"
+	}
+	p.Message = fmt.Sprintf("sfdeploy %s %s: %s -> %s by %s", name, status, p.Extension, p.Target, p.User)
+	if p.Commit != "" {
+		p.Message += " (commit " + p.Commit + ")"
+	}
+	p.Message += fmt.Sprintf(" in %.1fs", p.Duration)
+	return p
+}
+
+func postNotification(config *Config, name string, success bool, started time.Time) {
+	// A run that failed before its secrets were resolved has no URL to post to
+	if strings.HasPrefix(config.Notifications.WebhookURL, secretPrefix) {
+		return
+	}
+	if *flagDryRun {
+		tprintf("[dry-run] Would notify %s\n", config.Notifications.WebhookURL)
+		return
+	}
+
+	p := newNotifyPayload(config, name, success, started)
+	var body any = p
+	switch notifyFormat(config) {
+	case "slack":
+		body = map[string]string{"text": p.Message}
+	case "discord":
+		body = map[string]string{"content": p.Message}
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		tprintf("⚠️ Warning: Could not encode notification: %v\n", err)
+		return
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Post(config.Notifications.WebhookURL, "application/json", bytes.NewReader(data))
+	if err != nil {
+		tprintf("⚠️ Warning: Could not send notification: %v\n", err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		tprintf("⚠️ Warning: Notification webhook returned %s\n", resp.Status)
+		return
+	}
+	tprintln("📣 Notification sent")
+}
@@ -0,0 +1,275 @@
+package sfdeploy
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sync"
+)
+
+// consoleFile is the real stdout while startOutput has redirected os.Stdout.
+var consoleFile = os.Stdout
+
+// consoleOut is where routed output is shown. The TUI points it at its log
+// pane while it owns the terminal.
+var consoleOut = &switchWriter{}
+
+type switchWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *switchWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
+
+// set replaces the destination and returns the previous one.
+func (s *switchWriter) set(w io.Writer) io.Writer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev := s.w
+	s.w = w
+	return prev
+}
+
+// tee copies everything written from now on to w as well. The returned func
+// restores the previous destination.
+func (s *switchWriter) tee(w io.Writer) func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev := s.w
+	s.w = io.MultiWriter(prev, w)
+	return func() { s.set(prev) }
+}
+
+// startOutput routes everything written to stdout, including the output of
+// child processes, through a pipe so it can be copied to the log file and,
+// with --log-format json, turned into JSON lines. The returned func flushes
+// the output and must run before exit.
+func startOutput() (func(), error) {
+	applyCIMode()
+	setLanguage("")
+
+	var console io.Writer = os.Stdout
+	var jsonDone chan struct{}
+	var jsonIn *io.PipeWriter
+	var groups *groupWriter
+
+	switch *flagLogFormat {
+	case "", "text":
+		// The groups are found from the English phase headlines, which is
+		// why setLanguage keeps CI runs in English
+		if *flagCI {
+			groups = &groupWriter{out: &consoleWriter{out: console}}
+			console = groups
+		}
+	case "json":
+		// Prompts cannot be answered through a log pipeline
+		*flagNoPrompt = true
+
+		var jsonOut *io.PipeReader
+		jsonOut, jsonIn = io.Pipe()
+		jsonDone = make(chan struct{})
+		go func(out io.Writer) {
+			writeJSONLog(jsonOut, out)
+			close(jsonDone)
+		}(console)
+		console = jsonIn
+	default:
+		return nil, fmt.Errorf("unknown --log-format %q (expected text or json)", *flagLogFormat)
+	}
+
+	level, err := outputLevel()
+	if err != nil {
+		return nil, err
+	}
+	if level == levelQuiet {
+		// Prompts would be hidden, so never wait for one
+		*flagNoPrompt = true
+	}
+
+	// Progress bars are only drawn for someone watching a terminal
+	if groups == nil && jsonIn == nil && level != levelQuiet && isTerminal(os.Stdout) {
+		terminal = &terminalWriter{out: console}
+		console = terminal
+	}
+	restoreColor := func() {}
+	if groups == nil && jsonIn == nil {
+		colored := false
+		if colorEnabled(os.Stdout) {
+			if restore, ok := enableColor(os.Stdout); ok {
+				restoreColor, colored = restore, true
+			}
+		}
+		console = &consoleWriter{out: console, colored: colored}
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	consoleFile = stdout
+
+	consoleOut.set(console)
+	router := &levelRouter{console: consoleOut, log: io.MultiWriter(runLog, runReportCollector), level: level, atStart: true}
+	done := make(chan struct{})
+	go func() {
+		io.Copy(router, r)
+		router.flush()
+		close(done)
+	}()
+	setRouted(level, true)
+
+	return func() {
+		setRouted(level, false)
+		os.Stdout = stdout
+		terminal = nil
+		w.Close()
+		<-done
+		restoreColor()
+		if groups != nil {
+			groups.Close()
+		}
+		if jsonIn != nil {
+			jsonIn.Close()
+			<-jsonDone
+		}
+		closeLogFile()
+	}, nil
+}
+
+// levelRouter copies output to the log file and the console. Lines marked
+// by verbosef or debugf only reach the console at that verbosity, and with
+// --quiet only errors and the result do. Other output is passed through as
+// it arrives, so prompts without a newline still show.
+type levelRouter struct {
+	console io.Writer
+	log     io.Writer
+	level   verbosity
+	atStart bool
+	held    bool
+	line    []byte
+}
+
+func (r *levelRouter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		if r.atStart {
+			r.held = p[0] == levelMark || r.level == levelQuiet
+			r.atStart = false
+		}
+
+		i := bytes.IndexByte(p, '\n')
+		chunk := p
+		if i >= 0 {
+			chunk = p[:i+1]
+		}
+		p = p[len(chunk):]
+
+		if r.held {
+			r.line = append(r.line, chunk...)
+		} else {
+			r.console.Write(chunk)
+			r.log.Write(chunk)
+		}
+		if i >= 0 {
+			r.flush()
+			r.atStart = true
+		}
+	}
+	return n, nil
+}
+
+// flush routes a held line once it is complete.
+func (r *levelRouter) flush() {
+	line := r.line
+	r.line = nil
+	if len(line) == 0 {
+		return
+	}
+
+	if len(line) > 1 && line[0] == levelMark && line[1] == translationMark {
+		english, _, _ := bytes.Cut(line[2:], []byte{translationSep})
+		r.log.Write(append(slices.Clip(english), '\n'))
+		if r.level > levelQuiet || isQuietLine(string(english)) {
+			r.console.Write(line)
+		}
+		return
+	}
+	if line[0] == levelMark && len(line) > 1 {
+		content := line[2:]
+		r.log.Write(content)
+		if r.level >= markLevel(line[1]) {
+			r.console.Write(content)
+		}
+		return
+	}
+
+	r.log.Write(line)
+	if isQuietLine(string(line)) {
+		r.console.Write(line)
+	}
+}
+
+// consoleWriter colors the console output line by line, see lineColor, and
+// shows the translated text of the lines tprintf marked, colored by their
+// English text. Text arriving without a newline, such as a prompt, is passed
+// on straight away, and the rest of its line keeps the color its start was
+// given.
+type consoleWriter struct {
+	out     io.Writer
+	colored bool
+	color   string
+	midLine bool
+}
+
+func (c *consoleWriter) Write(p []byte) (int, error) {
+	if !c.colored && bytes.IndexByte(p, levelMark) < 0 {
+		if len(p) > 0 {
+			c.midLine = p[len(p)-1] != '\n'
+		}
+		return c.out.Write(p)
+	}
+
+	var b bytes.Buffer
+	for rest := p; len(rest) > 0; {
+		segment := rest
+		i := bytes.IndexByte(rest, '\n')
+		if i >= 0 {
+			segment = rest[:i]
+		}
+		if !c.midLine {
+			english := segment
+			if len(segment) > 1 && segment[0] == levelMark && segment[1] == translationMark {
+				english, segment, _ = bytes.Cut(segment[2:], []byte{translationSep})
+			}
+			if c.colored {
+				c.color = lineColor(string(english))
+			}
+		}
+		if c.color != "" && len(segment) > 0 {
+			b.WriteString(c.color)
+			b.Write(segment)
+			b.WriteString(ansiReset)
+		} else {
+			b.Write(segment)
+		}
+		if i < 0 {
+			c.midLine = true
+			break
+		}
+		b.WriteByte('\n')
+		c.midLine = false
+		rest = rest[i+1:]
+	}
+	if _, err := c.out.Write(b.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
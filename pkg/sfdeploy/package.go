@@ -0,0 +1,140 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Packaging modes for javac builds. "src" is the original behaviour of
+// jarring the whole src folder; "jar" packs only compiled classes and
+// resources, with a manifest.
+const (
+	packageSrc = "src"
+	packageJar = "jar"
+)
+
+func packageMode(config *Config) (string, error) {
+	// Several src folders cannot be jarred as one, so modules are packaged
+	// from the compiled classes
+	modules := len(sourceRoots(config)) > 1
+	switch strings.ToLower(config.Package) {
+	case "":
+		if modules {
+			return packageJar, nil
+		}
+		return packageSrc, nil
+	case packageSrc:
+		if modules {
+			return "", fmt.Errorf("package %q needs a single src folder, use %q for a project with modules", packageSrc, packageJar)
+		}
+		return packageSrc, nil
+	case packageJar:
+		return packageJar, nil
+	}
+	return "", fmt.Errorf("unknown package mode %q (expected %q or %q)", config.Package, packageSrc, packageJar)
+}
+
+func packageStageDir(config *Config) string {
+	return filepath.Join(stateDir, "build", config.ExtensionFolder, "package")
+}
+
+// stagePackage assembles the compiled classes and every non-Java file under
+// the src folders into a clean staging directory, leaving the sources out of
+// the JAR.
+func stagePackage(config *Config, roots []string) (string, error) {
+	stage := packageStageDir(config)
+	if err := os.RemoveAll(stage); err != nil {
+		return "", err
+	}
+	if err := copyDir(classCacheDir(config), stage); err != nil {
+		return "", err
+	}
+	if kotlinDir := kotlinClassesDir(config); fileExists(kotlinDir) {
+		if err := copyDir(kotlinDir, stage); err != nil {
+			return "", err
+		}
+	}
+
+	ignore := sourceIgnore(config)
+	for _, srcDir := range roots {
+		if err := stageResources(stage, srcDir, ignore); err != nil {
+			return "", err
+		}
+	}
+	return stage, nil
+}
+
+// stageResources copies the non-source files of srcDir into stage.
+func stageResources(stage, srcDir string, ignore *ignoreRules) error {
+	return filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if ignore.skip(path, info.IsDir()) {
+			return skipWalk(info)
+		}
+		if info.IsDir() {
+			return nil
+		}
+		name := strings.ToLower(info.Name())
+		if strings.HasSuffix(name, ".java") || strings.HasSuffix(name, ".kt") || strings.HasSuffix(name, ".class") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(stage, rel)
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return err
+		}
+		return copyFile(path, dst)
+	})
+}
+
+func writeJarManifest(config *Config, path string) error {
+	now := time.Now()
+	lines := []string{
+		"Implementation-Title: " + config.ExtensionFolder,
+		"Implementation-Version: " + now.Format("20060102.150405"),
+		"Built-By: sfdeploy",
+		"Build-Date: " + now.Format(time.RFC3339),
+	}
+	// The jar tool requires the manifest to end with a newline
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
+
+// packageClasses builds the common and extension JARs from the staged
+// classes and resources in jar mode.
+func packageClasses(config *Config, roots []string) bool {
+	stage, err := stagePackage(config, roots)
+	if err != nil {
+		tprintf("Failed to stage classes for packaging: %v\n", err)
+		return false
+	}
+
+	manifest := filepath.Join(filepath.Dir(stage), "MANIFEST.MF")
+	if err := writeJarManifest(config, manifest); err != nil {
+		tprintf("Failed to write JAR manifest: %v\n", err)
+		return false
+	}
+
+	if config.CommonFile != "" && config.CommonFolder != "" {
+		tprintf("Creating %s...\n", config.CommonFile)
+		commonJarFile := filepath.Join(config.SourceDir, config.CommonFile)
+		if !createJarWithManifest(config, commonJarFile, filepath.Join(stage, config.CommonFolder), manifest, config.CommonFile) {
+			return false
+		}
+	}
+
+	if !createExtensionJars(config, stage, true) {
+		return false
+	}
+	fmt.Println()
+
+	return true
+}
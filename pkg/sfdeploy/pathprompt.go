@@ -0,0 +1,321 @@
+package sfdeploy
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/x/term"
+)
+
+// maxPathSuggestions caps the directories listed for completion.
+const maxPathSuggestions = 50
+
+// promptPath asks for a directory like promptLine. On a terminal Tab
+// completes the path, cycling through the matches on repeated presses, and
+// the best match is shown as a hint while typing; names match by prefix or,
+// failing that, fuzzily. Paths pasted or dropped onto the window are cleaned
+// up either way.
+func promptPath(reader *bufio.Reader, label, def string) string {
+	if terminal == nil || !isTerminal(os.Stdin) {
+		return cleanPathInput(promptLine(reader, label, def))
+	}
+	answer, ok := editPath(reader, label, def)
+	if !ok {
+		return cleanPathInput(promptLine(reader, label, def))
+	}
+	return answer
+}
+
+// cleanPathInput undoes what terminals and file managers add to a dropped
+// path: surrounding quotes, a file:// URL, backslash escapes in front of
+// spaces and other characters outside Windows, and a trailing separator.
+func cleanPathInput(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		s = s[1 : len(s)-1]
+	}
+	if rest, ok := strings.CutPrefix(s, "file://"); ok {
+		if decoded, err := url.PathUnescape(rest); err == nil {
+			rest = decoded
+		}
+		// file:///C:/Games is C:/Games on Windows
+		if runtime.GOOS == "windows" && len(rest) > 2 && rest[0] == '/' && rest[2] == ':' {
+			rest = rest[1:]
+		}
+		s = rest
+	}
+	if runtime.GOOS != "windows" && strings.Contains(s, `\`) {
+		var b strings.Builder
+		for i := 0; i < len(s); i++ {
+			if s[i] == '\\' && i+1 < len(s) {
+				i++
+			}
+			b.WriteByte(s[i])
+		}
+		s = b.String()
+	}
+	// A completed folder ends in a separator, which the config does not need
+	for len(s) > 1 && isPathSeparator(rune(s[len(s)-1])) && !strings.HasSuffix(s, ":"+s[len(s)-1:]) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+func isPathSeparator(r rune) bool {
+	return r == '/' || (runtime.GOOS == "windows" && r == '\\')
+}
+
+// splitPathInput splits what was typed into the directory to list and the
+// start of the name in it, e.g. "C:\Ga" into "C:\" and "Ga".
+func splitPathInput(input string) (dir, name string) {
+	i := strings.LastIndexFunc(input, isPathSeparator)
+	if i < 0 {
+		if runtime.GOOS == "windows" && len(input) == 2 && input[1] == ':' {
+			return input, ""
+		}
+		return "", input
+	}
+	return input[:i+1], input[i+1:]
+}
+
+func expandHome(dir string) string {
+	if dir == "~" || strings.HasPrefix(dir, "~/") || strings.HasPrefix(dir, `~\`) {
+		if home, err := os.UserHomeDir(); err == nil {
+			return home + dir[1:]
+		}
+	}
+	return dir
+}
+
+// fuzzyScore rates how well name matches the typed letters in order, lower
+// being better, or -1 when it does not. Letters next to each other and
+// close to the start score best.
+func fuzzyScore(name, typed string) int {
+	name, typed = strings.ToLower(name), strings.ToLower(typed)
+	score, last := 0, -1
+	at := 0
+	for _, r := range typed {
+		i := strings.IndexRune(name[at:], r)
+		if i < 0 {
+			return -1
+		}
+		pos := at + i
+		if last >= 0 {
+			score += pos - last - 1
+		} else {
+			score += pos
+		}
+		last = pos
+		at = pos + len(string(r))
+	}
+	return score
+}
+
+// pathCompletions lists the directories input can complete to, as the full
+// text to put in the prompt: prefix matches first, then fuzzy ones.
+func pathCompletions(input string) []string {
+	dir, name := splitPathInput(input)
+	listDir := expandHome(dir)
+	if listDir == "" {
+		listDir = "."
+	}
+	entries, err := os.ReadDir(listDir)
+	if err != nil {
+		return nil
+	}
+
+	type match struct {
+		name  string
+		score int
+	}
+	var prefixed, fuzzy []match
+	for _, entry := range entries {
+		isDir := entry.IsDir()
+		if entry.Type()&os.ModeSymlink != 0 {
+			info, err := os.Stat(filepath.Join(listDir, entry.Name()))
+			isDir = err == nil && info.IsDir()
+		}
+		if !isDir || (strings.HasPrefix(entry.Name(), ".") && !strings.HasPrefix(name, ".")) {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(strings.ToLower(entry.Name()), strings.ToLower(name)):
+			prefixed = append(prefixed, match{entry.Name(), 0})
+		case name != "":
+			if score := fuzzyScore(entry.Name(), name); score >= 0 {
+				fuzzy = append(fuzzy, match{entry.Name(), score})
+			}
+		}
+	}
+	sort.SliceStable(fuzzy, func(i, j int) bool { return fuzzy[i].score < fuzzy[j].score })
+
+	separator := string(filepath.Separator)
+	if strings.HasSuffix(dir, "/") {
+		separator = "/"
+	}
+	var completions []string
+	for _, m := range append(prefixed, fuzzy...) {
+		completions = append(completions, dir+m.name+separator)
+		if len(completions) == maxPathSuggestions {
+			break
+		}
+	}
+	return completions
+}
+
+// commonPrefix is the longest start the completions share, ignoring case
+// the way the matches do.
+func commonPrefix(completions []string) string {
+	prefix := completions[0]
+	for _, c := range completions[1:] {
+		n := 0
+		for n < len(prefix) && n < len(c) && unicode.ToLower(rune(prefix[n])) == unicode.ToLower(rune(c[n])) {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	return prefix
+}
+
+// pathHint is shown after the typed path: the rest of the best completion,
+// or the fuzzy match it would become, and how many others there are.
+func pathHint(input string, completions []string) string {
+	if len(completions) == 0 || input == "" {
+		return ""
+	}
+	best := completions[0]
+	var hint string
+	if strings.HasPrefix(strings.ToLower(best), strings.ToLower(input)) {
+		hint = best[len(input):]
+	} else {
+		hint = "  → " + best
+	}
+	if len(completions) > 1 {
+		hint += fmt.Sprintf("  (+%d, Tab)", len(completions)-1)
+	}
+	return hint
+}
+
+// editPath reads a path in raw mode, drawing the line through the progress
+// bar writer so it never reaches the log file. It returns false when the
+// terminal cannot be switched to raw mode.
+func editPath(reader *bufio.Reader, label, def string) (string, bool) {
+	fd := os.Stdin.Fd()
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return "", false
+	}
+	restore := func() { term.Restore(fd, state) }
+	defer restore()
+
+	prompt := label + ": "
+	if def != "" {
+		prompt = fmt.Sprintf("%s [%s]: ", label, def)
+	}
+	width := 0
+	if w, _, err := term.GetSize(consoleFile.Fd()); err == nil {
+		width = w
+	}
+
+	var line []rune
+	var cycle []string
+	cycleAt := 0
+	redraw := func() {
+		text := []rune(prompt + string(line))
+		hint := []rune(pathHint(string(line), pathCompletions(string(line))))
+		// Keep everything on one line so it can be redrawn: the end of a
+		// long path, and the hint only while it fits
+		if width > 0 && len(text) >= width-2 {
+			text = append([]rune("…"), text[len(text)-(width-4):]...)
+		}
+		if width > 0 && len(text)+len(hint) >= width-2 {
+			hint = nil
+		}
+		if len(hint) > 0 && colorEnabled(consoleFile) {
+			hint = []rune(ansiFaint + string(hint) + ansiReset)
+		}
+		terminal.draw(string(text) + string(hint))
+	}
+	redraw()
+
+	for {
+		r, _, err := reader.ReadRune()
+		if err != nil {
+			terminal.clear()
+			return "", false
+		}
+		if r != '\t' {
+			cycle = nil
+		}
+		switch r {
+		case '\r', '\n':
+			terminal.clear()
+			answer := cleanPathInput(string(line))
+			fmt.Printf("%s%s\n", prompt, answer)
+			if answer == "" {
+				return def, true
+			}
+			return answer, true
+		case 3: // Ctrl+C
+			terminal.clear()
+			restore()
+			fmt.Println("^C")
+			os.Exit(130)
+		case '\t':
+			if cycle == nil {
+				completions := pathCompletions(string(line))
+				switch {
+				case len(completions) == 0:
+				case len(completions) == 1:
+					line = []rune(completions[0])
+				case len(commonPrefix(completions)) > len(string(line)):
+					line = []rune(commonPrefix(completions))
+				default:
+					cycle, cycleAt = completions, 0
+					line = []rune(cycle[0])
+				}
+			} else {
+				cycleAt = (cycleAt + 1) % len(cycle)
+				line = []rune(cycle[cycleAt])
+			}
+		case 127, 8: // Backspace
+			if len(line) > 0 {
+				line = line[:len(line)-1]
+			}
+		case 21: // Ctrl+U
+			line = nil
+		case 23: // Ctrl+W removes the last folder
+			end := len(line)
+			for end > 0 && isPathSeparator(line[end-1]) {
+				end--
+			}
+			for end > 0 && !isPathSeparator(line[end-1]) {
+				end--
+			}
+			line = line[:end]
+		case 27:
+			// Arrow and function keys: ESC [ or ESC O, then up to a final
+			// letter
+			if next, _, err := reader.ReadRune(); err == nil && (next == '[' || next == 'O') {
+				for {
+					c, _, err := reader.ReadRune()
+					if err != nil || (c >= 0x40 && c <= 0x7e) {
+						break
+					}
+				}
+			}
+		default:
+			if unicode.IsPrint(r) {
+				line = append(line, r)
+			}
+		}
+		redraw()
+	}
+}
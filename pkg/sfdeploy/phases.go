@@ -0,0 +1,110 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// phaseStages are the built-in stages a custom phase can run after.
+var phaseStages = []string{"build", "test", "deploy", "restart"}
+
+// Phase is a custom pipeline step, e.g. asset bundling or schema generation,
+// registered from Go with RegisterPhase.
+type Phase interface {
+	Name() string
+	Run(config *Config) error
+}
+
+// customPhase is one entry of custom_phases: a command run after one of
+// the built-in stages.
+type customPhase struct {
+	Name    string `json:"name"`
+	After   string `json:"after"`
+	Command string `json:"command"`
+	Dir     string `json:"dir"`
+}
+
+var registeredPhases = map[string][]Phase{}
+
+// RegisterPhase adds p to every pipeline, the CLI commands included, right
+// after the built-in stage after: "build", "test", "deploy" or "restart".
+// Phases of one stage run in the order they were registered, before the
+// custom_phases of the config.
+func RegisterPhase(after string, p Phase) error {
+	if !slices.Contains(phaseStages, after) {
+		return fmt.Errorf("unknown stage %q (expected %s)", after, strings.Join(phaseStages, ", "))
+	}
+	registeredPhases[after] = append(registeredPhases[after], p)
+	return nil
+}
+
+// checkCustomPhases rejects custom_phases entries that would never run.
+func checkCustomPhases(config *Config) error {
+	for _, p := range config.CustomPhases {
+		switch {
+		case p.Command == "":
+			return fmt.Errorf("custom phase %q has no command", p.Name)
+		case !slices.Contains(phaseStages, p.After):
+			return fmt.Errorf("custom phase %q: unknown after %q (expected %s)", p.Name, p.After, strings.Join(phaseStages, ", "))
+		}
+	}
+	return nil
+}
+
+// customPhases is the pipeline phase that runs the registered phases and
+// custom_phases of a stage.
+func customPhases(stage string) phase {
+	return func(config *Config) bool {
+		for _, p := range registeredPhases[stage] {
+			if *flagDryRun {
+				tprintf("[dry-run] Would run phase: %s\n", p.Name())
+				continue
+			}
+			tprintf("🔌 Phase: %s\n", p.Name())
+			if err := p.Run(config); err != nil {
+				tprintf("❌ %s failed: %v\n", p.Name(), err)
+				return false
+			}
+			fmt.Println()
+		}
+
+		for _, p := range config.CustomPhases {
+			if p.After == stage && !runCustomPhase(config, p) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// runCustomPhase runs the command of a custom_phases entry in the source
+// directory, or dir below it, with the environment of the deploy hooks.
+func runCustomPhase(config *Config, p customPhase) bool {
+	name := p.Name
+	if name == "" {
+		name = p.Command
+	}
+	if *flagDryRun {
+		tprintf("[dry-run] Would run phase %s: %s\n", name, p.Command)
+		return true
+	}
+
+	tprintf("🔌 Phase: %s\n", name)
+	cmd := hookCommand(p.Command)
+	cmd.Dir = config.SourceDir
+	if p.Dir != "" {
+		cmd.Dir = filepath.Join(config.SourceDir, p.Dir)
+	}
+	cmd.Env = append(hookEnv(config, p.After), "SFDEPLOY_PHASE="+name)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		tprintf("❌ Phase %s failed: %v\n", name, err)
+		return false
+	}
+	fmt.Println()
+	return true
+}
@@ -0,0 +1,155 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// defaultServerPorts are the ports a stock SFS2X binds: the socket port and
+// the HTTP port of BlueBox and the AdminTool.
+var defaultServerPorts = []int{9933, 8080}
+
+// portOwner is a process found listening on a port the server needs.
+type portOwner struct {
+	Port     int
+	PID      string
+	Command  string
+	Expected bool
+}
+
+// serverPorts lists the ports the server binds: server_ports when set, else
+// the health check port, health_http_port, the JVM debug port and the port
+// of the admin endpoint on top of the defaults.
+func serverPorts(config *Config) []int {
+	if len(config.ServerPorts) > 0 {
+		return config.ServerPorts
+	}
+	ports := slices.Clone(defaultServerPorts)
+	ports = append(ports, healthPort(config), config.HealthHTTPPort, config.JVMOptions.DebugPort, adminEndpointPort(config))
+
+	var unique []int
+	for _, port := range ports {
+		if port > 0 && !slices.Contains(unique, port) {
+			unique = append(unique, port)
+		}
+	}
+	return unique
+}
+
+// unixPortScript prints "port pid expected command" for each of ports that
+// something listens on. expected is 1 when the owner is the server in
+// pidFile or one of its children.
+func unixPortScript(ports []int, pidFile string) string {
+	return unixPidScript(shellQuote(pidFile)) +
+		`ours=""; [ -n "$pid" ] && ours=" $pid $(pgrep -P $pid 2>/dev/null | tr '\n' ' ') "; ` +
+		`for port in ` + joinPorts(ports, " ") + `; do ` +
+		`p=$(lsof -t -iTCP:$port -sTCP:LISTEN 2>/dev/null | head -n 1); ` +
+		`[ -z "$p" ] && p=$(fuser $port/tcp 2>/dev/null | awk '{print $1}'); ` +
+		`[ -z "$p" ] && continue; ` +
+		`case "$ours" in *" $p "*) e=1 ;; *) e=0 ;; esac; ` +
+		`echo "$port $p $e $(ps -o args= -p $p 2>/dev/null)"; done; true`
+}
+
+func parsePortOwners(output string) []portOwner {
+	var owners []portOwner
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(strings.TrimSpace(line), " ", 4)
+		if len(fields) < 3 {
+			continue
+		}
+		port, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		owner := portOwner{Port: port, PID: fields[1], Expected: fields[2] == "1"}
+		if len(fields) == 4 {
+			owner.Command = fields[3]
+		}
+		owners = append(owners, owner)
+	}
+	return owners
+}
+
+// windowsPortOwners finds the listeners of ports with netstat and names them
+// with tasklist.
+func windowsPortOwners(ports []int) []portOwner {
+	output, err := newCommand("netstat", "-ano").Output()
+	if err != nil {
+		return nil
+	}
+
+	var owners []portOwner
+	for _, line := range strings.Split(string(output), "\n") {
+		parts := strings.Fields(line)
+		if len(parts) < 5 || !strings.Contains(line, "LISTENING") {
+			continue
+		}
+		i := strings.LastIndex(parts[1], ":")
+		port, err := strconv.Atoi(parts[1][i+1:])
+		if err != nil || !slices.Contains(ports, port) || slices.ContainsFunc(owners, func(o portOwner) bool { return o.Port == port }) {
+			continue
+		}
+
+		owner := portOwner{Port: port, PID: parts[len(parts)-1]}
+		if out, err := newCommand("tasklist", "/fi", fmt.Sprintf("PID eq %s", owner.PID), "/fo", "csv", "/nh").Output(); err == nil {
+			if name, _, ok := strings.Cut(strings.TrimSpace(string(out)), ","); ok {
+				owner.Command = strings.Trim(name, `"`)
+			}
+		}
+		owners = append(owners, owner)
+	}
+	return owners
+}
+
+// portOwners reports who listens on the server ports of the target.
+func portOwners(config *Config) []portOwner {
+	ports := serverPorts(config)
+	if remote, ok := parseRemoteTarget(config.TargetDir); ok {
+		output, _ := remote.run(config, unixPortScript(ports, remote.path("SFS2X", serverPidFile)))
+		return parsePortOwners(string(output))
+	}
+	if runtime.GOOS == "windows" {
+		return windowsPortOwners(ports)
+	}
+	output, _ := newCommand("sh", "-c", unixPortScript(ports, filepath.Join(config.TargetDir, "SFS2X", serverPidFile))).Output()
+	return parsePortOwners(string(output))
+}
+
+// checkServerPorts makes sure no other program holds a port the server is
+// about to bind, which SFS2X would only report in its own log. Ports held by
+// the server in the pidfile are left to the start script, which refuses to
+// start a second instance.
+func checkServerPorts(config *Config) bool {
+	var foreign []portOwner
+	for _, owner := range portOwners(config) {
+		if !owner.Expected {
+			foreign = append(foreign, owner)
+		}
+	}
+	if len(foreign) == 0 {
+		verbosef("✅ Ports %s are free\n", joinPorts(serverPorts(config), ", "))
+		return true
+	}
+
+	for _, owner := range foreign {
+		command := owner.Command
+		if command == "" {
+			command = "unknown command"
+		}
+		tprintf("❌ Port %d is already in use by PID %s (%s)\n", owner.Port, owner.PID, command)
+	}
+	tprintln("   Stop that process, or set server_ports to the ports your server actually binds")
+	return false
+}
+
+func joinPorts(ports []int, sep string) string {
+	var list []string
+	for _, port := range ports {
+		list = append(list, strconv.Itoa(port))
+	}
+	return strings.Join(list, sep)
+}
@@ -0,0 +1,69 @@
+package sfdeploy
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// processorPath expands processor_path the same way as lib_jars.
+func processorPath(config *Config) []string {
+	var jars []string
+	for _, entry := range config.ProcessorPath {
+		if !filepath.IsAbs(entry) {
+			entry = filepath.Join(config.SourceDir, entry)
+		}
+		for _, jar := range expandClasspathEntry(entry) {
+			jars = append(jars, absPath(jar))
+		}
+	}
+	return jars
+}
+
+// warnProcessorPath reports processor_path entries that match no JAR, which
+// otherwise surface as confusing "cannot find symbol" errors from javac.
+func warnProcessorPath(config *Config) {
+	for _, entry := range config.ProcessorPath {
+		path := entry
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(config.SourceDir, path)
+		}
+		if len(expandClasspathEntry(path)) == 0 {
+			tprintf("⚠️ Warning: processor_path entry matched no JAR files: %s\n", path)
+		}
+	}
+}
+
+// processorOptions returns the javac options for annotation processing:
+// -processorpath, -processor and one -A option per processor_options entry.
+// Without processor_path javac looks for processors on the classpath.
+func processorOptions(config *Config) []string {
+	var options []string
+	if jars := processorPath(config); len(jars) > 0 {
+		options = append(options, "-processorpath", strings.Join(jars, string(os.PathListSeparator)))
+	}
+	if len(config.Processors) > 0 {
+		options = append(options, "-processor", strings.Join(config.Processors, ","))
+	}
+
+	keys := make([]string, 0, len(config.ProcessorOpts))
+	for key := range config.ProcessorOpts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if value := config.ProcessorOpts[key]; value != "" {
+			options = append(options, "-A"+key+"="+value)
+		} else {
+			options = append(options, "-A"+key)
+		}
+	}
+	return options
+}
+
+// generatedSourcesDir receives the sources written by annotation processors,
+// keeping them out of src/ where the next build would compile them again.
+func generatedSourcesDir(config *Config) string {
+	return filepath.Join(stateDir, "build", config.ExtensionFolder, "generated-sources")
+}
@@ -0,0 +1,194 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	progressDelay    = 500 * time.Millisecond // no bar for steps that finish sooner
+	progressRedraw   = 100 * time.Millisecond
+	progressLogEvery = 10 * time.Second // status line interval without a terminal
+	progressWidth    = 24
+)
+
+// terminal is set when stdout is an interactive terminal and plain text is
+// printed, and progress bars are drawn through it. Nil otherwise.
+var terminal *terminalWriter
+
+// terminalWriter passes console output through, first erasing a progress
+// bar drawn on the current line. The bar is redrawn on the next tick.
+type terminalWriter struct {
+	mu  sync.Mutex
+	out io.Writer
+	bar int // width of the bar on screen
+}
+
+func (t *terminalWriter) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.erase()
+	return t.out.Write(p)
+}
+
+func (t *terminalWriter) draw(line string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.erase()
+	fmt.Fprint(t.out, "\r"+line)
+	t.bar = len([]rune(line))
+}
+
+func (t *terminalWriter) clear() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.erase()
+}
+
+// erase overwrites the bar with spaces rather than an ANSI sequence, which
+// older Windows consoles do not understand. The extra spaces cover the
+// emoji that take two columns.
+func (t *terminalWriter) erase() {
+	if t.bar > 0 {
+		fmt.Fprint(t.out, "\r"+strings.Repeat(" ", t.bar+2)+"\r")
+		t.bar = 0
+	}
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0 && os.Getenv("TERM") != "dumb"
+}
+
+// progress tracks a long step: files compiled, bytes copied or seconds
+// waited. On a terminal it draws a bar; otherwise it prints a status line
+// every progressLogEvery so logs show the tool is still working.
+type progress struct {
+	label   string
+	total   int64
+	current atomic.Int64
+	detail  func(current, total int64) string
+	started time.Time
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// startProgress starts reporting a step of total units. A total of 0 shows
+// only the elapsed time. detail describes the position, e.g. "3/12 files".
+func startProgress(label string, total int64, detail func(current, total int64) string) *progress {
+	p := &progress{label: label, total: total, detail: detail, started: time.Now(),
+		stop: make(chan struct{}), done: make(chan struct{})}
+	go p.run()
+	return p
+}
+
+func (p *progress) add(n int64) { p.current.Add(n) }
+
+func (p *progress) set(n int64) { p.current.Store(n) }
+
+// Write counts the bytes passing through, for use with io.TeeReader.
+func (p *progress) Write(b []byte) (int, error) {
+	p.add(int64(len(b)))
+	return len(b), nil
+}
+
+// finish stops reporting and removes the bar.
+func (p *progress) finish() {
+	close(p.stop)
+	<-p.done
+}
+
+func (p *progress) run() {
+	defer close(p.done)
+
+	interval := progressRedraw
+	if terminal == nil {
+		interval = progressLogEvery
+	}
+	delay := time.NewTimer(progressDelay)
+	defer delay.Stop()
+	select {
+	case <-p.stop:
+		return
+	case <-delay.C:
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if terminal != nil {
+			terminal.draw(p.line(true))
+		}
+		select {
+		case <-p.stop:
+			if terminal != nil {
+				terminal.clear()
+			}
+			return
+		case <-ticker.C:
+			if terminal == nil {
+				fmt.Println("   " + p.line(false))
+			}
+		}
+	}
+}
+
+func (p *progress) line(bar bool) string {
+	current := min(p.current.Load(), p.total)
+	if p.total <= 0 {
+		current = p.current.Load()
+	}
+	elapsed := time.Since(p.started).Truncate(time.Second)
+
+	var b strings.Builder
+	b.WriteString(p.label)
+	if p.total > 0 {
+		percent := current * 100 / p.total
+		if bar {
+			filled := int(current * progressWidth / p.total)
+			fmt.Fprintf(&b, " [%s%s]", strings.Repeat("█", filled), strings.Repeat("░", progressWidth-filled))
+		}
+		fmt.Fprintf(&b, " %3d%%", percent)
+	}
+	if p.detail != nil {
+		b.WriteString("  " + p.detail(current, p.total))
+	}
+	fmt.Fprintf(&b, "  %s", elapsed)
+	return b.String()
+}
+
+func countDetail(unit string) func(current, total int64) string {
+	return func(current, total int64) string {
+		return fmt.Sprintf("%d/%d %s", current, total, unit)
+	}
+}
+
+func bytesDetail(current, total int64) string {
+	if total <= 0 {
+		return fmt.Sprintf("%.1f MB", float64(current)/(1<<20))
+	}
+	return fmt.Sprintf("%.1f/%.1f MB", float64(current)/(1<<20), float64(total)/(1<<20))
+}
+
+// countdownDetail shows the time left of a wait tracked in seconds.
+func countdownDetail(current, total int64) string {
+	return fmt.Sprintf("%ds left", total-current)
+}
+
+// waitWithCountdown sleeps for d, showing the time left.
+func waitWithCountdown(label string, d time.Duration) {
+	seconds := int64(d / time.Second)
+	p := startProgress(label, seconds, countdownDetail)
+	defer p.finish()
+
+	deadline := time.Now().Add(d)
+	for now := time.Now(); now.Before(deadline); now = time.Now() {
+		p.set(seconds - int64(deadline.Sub(now)/time.Second))
+		time.Sleep(min(time.Second, deadline.Sub(now)))
+	}
+}
@@ -0,0 +1,162 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// proguardConfig runs ProGuard over the built JARs before they are
+// deployed. Rules files are relative to source_dir.
+type proguardConfig struct {
+	Jar   string   `json:"jar"`
+	Rules []string `json:"rules"`
+}
+
+func proguardEnabled(config *Config) bool {
+	return config.ProGuard.Jar != ""
+}
+
+// proguardMapping is where the mapping of the last obfuscated build is kept,
+// for reading the stack traces of the obfuscated extension.
+func proguardMapping(config *Config) string {
+	return filepath.Join(stateDir, "build", config.ExtensionFolder, "proguard-mapping.txt")
+}
+
+// proguardOutDir holds the obfuscated JARs until they replace the originals.
+// ProGuard writes a folder for an output without a JAR extension, so they
+// keep their names here.
+func proguardOutDir(config *Config) string {
+	return filepath.Join(stateDir, "build", config.ExtensionFolder, "proguard")
+}
+
+// obfuscatedJars are the JARs the build created: the common JAR and the
+// extension JARs. They go through ProGuard together so that the names shared
+// between them are mapped the same way.
+func obfuscatedJars(config *Config) []string {
+	var jars []string
+	if config.CommonFile != "" && config.CommonFolder != "" {
+		jars = append(jars, filepath.Join(config.SourceDir, config.CommonFile))
+	}
+	for _, ext := range extensionConfigs(config) {
+		jars = append(jars, filepath.Join(config.SourceDir, ext.ExtensionFile))
+	}
+	return jars
+}
+
+// jdkLibraryJars are the JDK classes ProGuard resolves the extension against:
+// the java.base module, or rt.jar of Java 8.
+func jdkLibraryJars(config *Config) string {
+	home := filepath.Dir(absPath(config.JavaPath))
+	if jmod := filepath.Join(home, "jmods", "java.base.jmod"); fileExists(jmod) {
+		return jmod + "(!**.jar;!module-info.class)"
+	}
+	if rt := filepath.Join(home, "jre", "lib", "rt.jar"); fileExists(rt) {
+		return rt
+	}
+	return ""
+}
+
+// proguardArgs obfuscates each of jars into proguardOutDir.
+func proguardArgs(config *Config, jars []string) []string {
+	args := []string{"-jar", absPath(config.ProGuard.Jar)}
+	for _, rules := range config.ProGuard.Rules {
+		if !filepath.IsAbs(rules) {
+			rules = filepath.Join(config.SourceDir, rules)
+		}
+		args = append(args, "@"+absPath(rules))
+	}
+	for _, jar := range jars {
+		args = append(args, "-injars", absPath(jar), "-outjars", absPath(filepath.Join(proguardOutDir(config), filepath.Base(jar))))
+	}
+
+	libs := buildClasspath(config)
+	if stdlib := kotlinStdlibCopy(config); fileExists(stdlib) {
+		libs += string(os.PathListSeparator) + absPath(stdlib)
+	}
+	if jdk := jdkLibraryJars(config); jdk != "" {
+		libs += string(os.PathListSeparator) + jdk
+	}
+	return append(args, "-libraryjars", libs, "-printmapping", absPath(proguardMapping(config)))
+}
+
+// obfuscateJars runs ProGuard over the JARs of the build, replaces them with
+// the obfuscated ones and signs the result, since ProGuard drops the
+// signatures.
+func obfuscateJars(config *Config) bool {
+	if !proguardEnabled(config) {
+		return true
+	}
+
+	var jars []string
+	for _, jar := range obfuscatedJars(config) {
+		if *flagDryRun || fileExists(jar) {
+			jars = append(jars, jar)
+		}
+	}
+	if len(jars) == 0 {
+		return true
+	}
+
+	args := proguardArgs(config, jars)
+	if *flagDryRun {
+		tprintf("[dry-run] Would obfuscate %d JARs: %s %s\n", len(jars), javaTool(config, "java"), strings.Join(args, " "))
+		fmt.Println()
+		return true
+	}
+
+	tprintf("🕶️ Obfuscating %d JARs with ProGuard...\n", len(jars))
+	outDir := proguardOutDir(config)
+	os.RemoveAll(outDir)
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		tprintf("❌ Failed to create %s: %v\n", outDir, err)
+		return false
+	}
+	cmd := newCommand(javaTool(config, "java"), args...)
+	cmd.Dir = config.SourceDir
+	p := startProgress("🕶️ ProGuard", 0, nil)
+	output, err := cmd.CombinedOutput()
+	p.finish()
+	if err != nil {
+		tprintf("❌ ProGuard failed: %s\n", strings.TrimSpace(string(output)))
+		return false
+	}
+	verbosef("%s", output)
+
+	for _, jar := range jars {
+		if err := copyFile(filepath.Join(outDir, filepath.Base(jar)), jar); err != nil {
+			tprintf("❌ Failed to replace %s: %v\n", filepath.Base(jar), err)
+			return false
+		}
+		if !runJarsigner(config, jar, filepath.Base(jar)) {
+			return false
+		}
+	}
+	tprintf("✅ Obfuscated, mapping saved to %s\n", proguardMapping(config))
+	fmt.Println()
+	return true
+}
+
+// validateProGuard checks the ProGuard JAR and rules files.
+func validateProGuard(v *validation, config *Config) {
+	if !proguardEnabled(config) {
+		return
+	}
+	if fileExists(config.ProGuard.Jar) {
+		v.pass("ProGuard: %s", config.ProGuard.Jar)
+	} else {
+		v.fail("ProGuard JAR not found: %s", config.ProGuard.Jar)
+	}
+	if len(config.ProGuard.Rules) == 0 {
+		v.warn("proguard.rules is empty, ProGuard runs with its defaults and keeps nothing")
+	}
+	for _, rules := range config.ProGuard.Rules {
+		if !filepath.IsAbs(rules) {
+			rules = filepath.Join(config.SourceDir, rules)
+		}
+		if !fileExists(rules) {
+			v.fail("ProGuard rules not found: %s", rules)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// deployDurationBuckets are the upper bounds, in seconds, of the job
+// duration histogram.
+var deployDurationBuckets = []float64{5, 10, 30, 60, 120, 300, 600, 1800}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func (h *histogram) observe(v float64) {
+	if h.counts == nil {
+		h.counts = make([]uint64, len(deployDurationBuckets))
+	}
+	for i, bound := range deployDurationBuckets {
+		if v <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+// promMetrics counts the daemon's jobs for GET /metrics. A restart failure
+// is a job that failed restarting the server or its health check.
+type promMetrics struct {
+	mu              sync.Mutex
+	jobs            map[string]map[string]uint64
+	durations       map[string]*histogram
+	restartFailures uint64
+	lastSuccess     time.Time
+}
+
+func jobResult(success bool) string {
+	if success {
+		return "success"
+	}
+	return "failure"
+}
+
+func (m *promMetrics) observe(job *serveJob) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.jobs == nil {
+		m.jobs = map[string]map[string]uint64{}
+		m.durations = map[string]*histogram{}
+	}
+	if m.jobs[job.Action] == nil {
+		m.jobs[job.Action] = map[string]uint64{}
+		m.durations[job.Action] = &histogram{}
+	}
+	m.jobs[job.Action][jobResult(job.Success)]++
+	m.durations[job.Action].observe(job.Finished.Sub(*job.Started).Seconds())
+	if job.ExitCode == exitRestart || job.ExitCode == exitHealth {
+		m.restartFailures++
+	}
+	if job.Success {
+		m.lastSuccess = *job.Finished
+	}
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// write prints the metrics in the Prometheus text format. Every action has
+// its series from the start, so rates and alerts work before the first job.
+func (m *promMetrics) write(w io.Writer, status serveStatus) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var actions []string
+	for action := range serveActions {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+
+	fmt.Fprintln(w, "# HELP sfdeploy_deploys_total Jobs run by the daemon, by action and result.")
+	fmt.Fprintln(w, "# TYPE sfdeploy_deploys_total counter")
+	for _, action := range actions {
+		for _, result := range []string{"success", "failure"} {
+			fmt.Fprintf(w, "sfdeploy_deploys_total{action=%q,result=%q} %d\n", action, result, m.jobs[action][result])
+		}
+	}
+
+	fmt.Fprintln(w, "# HELP sfdeploy_deploy_duration_seconds How long the daemon's jobs ran.")
+	fmt.Fprintln(w, "# TYPE sfdeploy_deploy_duration_seconds histogram")
+	for _, action := range actions {
+		h := m.durations[action]
+		if h == nil {
+			h = &histogram{}
+		}
+		for i, bound := range deployDurationBuckets {
+			var n uint64
+			if h.counts != nil {
+				n = h.counts[i]
+			}
+			fmt.Fprintf(w, "sfdeploy_deploy_duration_seconds_bucket{action=%q,le=%q} %d\n", action, formatFloat(bound), n)
+		}
+		fmt.Fprintf(w, "sfdeploy_deploy_duration_seconds_bucket{action=%q,le=\"+Inf\"} %d\n", action, h.count)
+		fmt.Fprintf(w, "sfdeploy_deploy_duration_seconds_sum{action=%q} %s\n", action, formatFloat(h.sum))
+		fmt.Fprintf(w, "sfdeploy_deploy_duration_seconds_count{action=%q} %d\n", action, h.count)
+	}
+
+	fmt.Fprintln(w, "# HELP sfdeploy_restart_failures_total Jobs that failed restarting the server or its health check.")
+	fmt.Fprintln(w, "# TYPE sfdeploy_restart_failures_total counter")
+	fmt.Fprintf(w, "sfdeploy_restart_failures_total %d\n", m.restartFailures)
+
+	fmt.Fprintln(w, "# HELP sfdeploy_last_success_timestamp_seconds When the last job succeeded, 0 before the first.")
+	fmt.Fprintln(w, "# TYPE sfdeploy_last_success_timestamp_seconds gauge")
+	last := 0.0
+	if !m.lastSuccess.IsZero() {
+		last = float64(m.lastSuccess.UnixMilli()) / 1000
+	}
+	fmt.Fprintf(w, "sfdeploy_last_success_timestamp_seconds %s\n", formatFloat(last))
+
+	running := 0
+	if status.Current != nil {
+		running = 1
+	}
+	fmt.Fprintln(w, "# HELP sfdeploy_job_running Whether a job is running.")
+	fmt.Fprintln(w, "# TYPE sfdeploy_job_running gauge")
+	fmt.Fprintf(w, "sfdeploy_job_running %d\n", running)
+	fmt.Fprintln(w, "# HELP sfdeploy_queue_length Jobs waiting to run.")
+	fmt.Fprintln(w, "# TYPE sfdeploy_queue_length gauge")
+	fmt.Fprintf(w, "sfdeploy_queue_length %d\n", len(status.Queue))
+}
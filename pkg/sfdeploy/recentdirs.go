@@ -0,0 +1,112 @@
+package sfdeploy
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// maxRecentDirs is how many directories are remembered per setting.
+const maxRecentDirs = 8
+
+// recentDirKeys are the wizard settings whose answers are remembered.
+var recentDirKeys = map[string]string{
+	"source_dir": "source directories",
+	"target_dir": "SmartFox Server directories",
+}
+
+// recentDirsFile holds the source and target directories entered in
+// "config edit", shared by every project of the user.
+func recentDirsFile() string {
+	if dir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(dir, "sfdeploy", "recent-dirs.json")
+	}
+	return filepath.Join(stateDir, "recent-dirs.json")
+}
+
+// loadRecentDirs returns the remembered directories by setting, most
+// recent first. A missing or unreadable file remembers nothing.
+func loadRecentDirs() map[string][]string {
+	recent := map[string][]string{}
+	data, err := os.ReadFile(recentDirsFile())
+	if err == nil {
+		json.Unmarshal(data, &recent)
+	}
+	return recent
+}
+
+// rememberDirs moves the directories of a saved config to the top of the
+// history, as absolute paths so they can be picked from any project.
+func rememberDirs(dirs map[string]string) {
+	path := recentDirsFile()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		verbosef("⚠️ Cannot save recent directories: %v\n", err)
+		return
+	}
+	unlock, err := lockConfigFile(path)
+	if err != nil {
+		verbosef("⚠️ Cannot save recent directories: %v\n", err)
+		return
+	}
+	defer unlock()
+
+	recent := loadRecentDirs()
+	for key, dir := range dirs {
+		if dir == "" || recentDirKeys[key] == "" {
+			continue
+		}
+		if _, ok := parseShellTarget(dir); !ok {
+			dir = absPath(dir)
+		}
+		list := []string{dir}
+		for _, old := range recent[key] {
+			if old != dir && len(list) < maxRecentDirs {
+				list = append(list, old)
+			}
+		}
+		recent[key] = list
+	}
+	data, _ := json.MarshalIndent(recent, "", "  ")
+	if err := writeFileAtomic(path, append(data, '\n'), 0644); err != nil {
+		verbosef("⚠️ Cannot save recent directories: %v\n", err)
+	}
+}
+
+// promptRecentDir asks for a source or target directory, first listing the
+// ones entered before, in this or other projects, to pick by number. Local
+// directories that no longer exist are left out. Without a history it is
+// the plain path prompt.
+func promptRecentDir(reader *bufio.Reader, key, label, def string) string {
+	var choices []string
+	for _, dir := range loadRecentDirs()[key] {
+		if _, ok := parseShellTarget(dir); !ok {
+			if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+				continue
+			}
+		}
+		choices = append(choices, dir)
+	}
+	if len(choices) == 0 {
+		return promptPath(reader, label, def)
+	}
+
+	tprintf("📂 Recent %s:\n", recentDirKeys[key])
+	for i, dir := range choices {
+		fmt.Printf("   %d) %s\n", i+1, dir)
+	}
+	numbers := "1"
+	if len(choices) > 1 {
+		numbers = fmt.Sprintf("1-%d", len(choices))
+	}
+	answer := promptPath(reader, fmt.Sprintf(tr("%s (%s or a path)"), label, numbers), def)
+	if n, err := strconv.Atoi(strings.TrimSpace(answer)); err == nil && n >= 1 && n <= len(choices) {
+		if _, err := os.Stat(answer); err != nil {
+			return choices[n-1]
+		}
+	}
+	return answer
+}
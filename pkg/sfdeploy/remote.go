@@ -0,0 +1,338 @@
+package sfdeploy
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// remoteTarget is a target_dir of the form [user@]host:/path, deployed over
+// SFTP and restarted over SSH using the system OpenSSH client.
+type remoteTarget struct {
+	Host string
+	Dir  string
+}
+
+// parseRemoteTarget recognises [user@]host:/abs/path. A single-letter host is
+// treated as a Windows drive (C:/...) rather than a remote target.
+func parseRemoteTarget(target string) (remoteTarget, bool) {
+	if strings.Contains(target, "://") {
+		return remoteTarget{}, false
+	}
+	host, dir, ok := strings.Cut(target, ":")
+	if !ok || len(host) < 2 || !strings.HasPrefix(dir, "/") || strings.ContainsAny(host, `/\`) {
+		return remoteTarget{}, false
+	}
+	return remoteTarget{Host: host, Dir: path.Clean(dir)}, true
+}
+
+// shellTarget is a non-local target whose files can be inspected by running
+// shell scripts on it: a remote host over SSH or a Docker container.
+type shellTarget interface {
+	run(config *Config, script string) ([]byte, error)
+	path(elem ...string) string
+}
+
+func parseShellTarget(target string) (shellTarget, bool) {
+	if remote, ok := parseRemoteTarget(target); ok {
+		return remote, true
+	}
+	if d, ok := parseDockerTarget(target); ok {
+		return d, true
+	}
+	return nil, false
+}
+
+// isLocalTarget reports whether target_dir is a directory on this machine.
+func isLocalTarget(config *Config) bool {
+	_, ok := parseShellTarget(config.TargetDir)
+	return !ok
+}
+
+func (r remoteTarget) String() string {
+	return r.Host + ":" + r.Dir
+}
+
+func (r remoteTarget) path(elem ...string) string {
+	return path.Join(append([]string{r.Dir}, elem...)...)
+}
+
+func (r remoteTarget) extensionsDir() string {
+	return r.path("SFS2X", "extensions")
+}
+
+func sshArgs(config *Config) []string {
+	args := append([]string{}, config.SSHOptions...)
+	if *flagNoPrompt {
+		args = append(args, "-o", "BatchMode=yes")
+	}
+	return args
+}
+
+// run executes a shell script on the remote host over SSH.
+func (r remoteTarget) run(config *Config, script string) ([]byte, error) {
+	args := append(sshArgs(config), r.Host, script)
+	return newCommand("ssh", args...).CombinedOutput()
+}
+
+// sftp runs a batch of sftp commands. Commands prefixed with - may fail
+// without aborting the batch.
+func (r remoteTarget) sftp(config *Config, commands []string) ([]byte, error) {
+	args := append(sshArgs(config), "-b", "-", r.Host)
+	cmd := newCommand("sftp", args...)
+	cmd.Stdin = strings.NewReader(strings.Join(commands, "\n") + "\n")
+	return cmd.CombinedOutput()
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func sftpQuote(s string) string {
+	return `"` + strings.ReplaceAll(filepath.ToSlash(s), `"`, `\"`) + `"`
+}
+
+// remoteLauncher finds which SmartFox launcher the remote install provides.
+// Remote hosts are assumed to be Unix-like since they are reached over SSH.
+func remoteLauncher(config *Config, remote remoteTarget) (string, error) {
+	script := fmt.Sprintf("cd %s && for l in %s; do if [ -f \"$l\" ]; then echo \"$l\"; exit 0; fi; done; exit 1",
+		shellQuote(remote.path("SFS2X")), strings.Join(launcherCandidates("linux"), " "))
+	output, err := remote.run(config, script)
+	if err != nil {
+		return "", fmt.Errorf("no SmartFox launcher found: %s", strings.TrimSpace(string(output)))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func validateRemoteTargetDir(config *Config, remote remoteTarget) bool {
+	if _, err := remoteLauncher(config, remote); err != nil {
+		fmt.Println(err)
+		return false
+	}
+	return true
+}
+
+// remoteLibCache is the local copy of the server's JARs, one per install:
+// two servers on one host have a cache each.
+func remoteLibCache(remote remoteTarget) string {
+	sum := sha256.Sum256([]byte(remote.Host + ":" + remote.Dir))
+	name := strings.NewReplacer("@", "_", ":", "_").Replace(remote.Host) + "-" + hex.EncodeToString(sum[:6])
+	return filepath.Join(stateDir, "remote", name, "lib")
+}
+
+// remoteLibListing lists the server's JARs with their size and time, which
+// tells whether the cached copy is still current.
+func remoteLibListing(config *Config, remote remoteTarget) ([]byte, error) {
+	script := fmt.Sprintf("cd %s && ls -ln lib/*.jar extensions/__lib__/*.jar %s 2>/dev/null; true",
+		shellQuote(remote.path("SFS2X")), shellQuote("extensions/"+config.ExtensionFolder+"/__lib__")+"/*.jar")
+	return remote.run(config, script)
+}
+
+// remoteLibDir downloads the server's lib and __lib__ JARs into a local cache
+// so remote targets can still be compiled against. The cache is fetched again
+// when the JARs on the server change and with --rebuild; when the server
+// cannot be reached the cached copy is used.
+func remoteLibDir(config *Config, remote remoteTarget) string {
+	cache := remoteLibCache(remote)
+	listingFile := filepath.Join(filepath.Dir(cache), "lib.list")
+
+	jars, _ := filepath.Glob(filepath.Join(cache, "*.jar"))
+	listing, err := remoteLibListing(config, remote)
+	if err != nil && len(jars) > 0 {
+		return cache
+	}
+	if cached, _ := os.ReadFile(listingFile); len(jars) > 0 && !*flagRebuild && bytes.Equal(cached, listing) {
+		return cache
+	}
+
+	os.RemoveAll(cache)
+	if err := os.MkdirAll(cache, 0755); err != nil {
+		tprintf("⚠️ Warning: Could not create %s: %v\n", cache, err)
+		return cache
+	}
+
+	tprintf("📥 Fetching server libraries from %s...\n", remote.Host)
+	output, err := remote.sftp(config, []string{
+		fmt.Sprintf("get %s %s", sftpQuote(remote.path("SFS2X", "lib", "*.jar")), sftpQuote(cache)),
+		fmt.Sprintf("-get %s %s", sftpQuote(remote.path("SFS2X", "extensions", "__lib__", "*.jar")), sftpQuote(cache)),
+		fmt.Sprintf("-get %s %s", sftpQuote(remote.path("SFS2X", "extensions", config.ExtensionFolder, "__lib__", "*.jar")), sftpQuote(cache)),
+	})
+	if err != nil {
+		tprintf("⚠️ Warning: Could not fetch server libraries: %s\n", strings.TrimSpace(string(output)))
+		return cache
+	}
+	if err := os.WriteFile(listingFile, listing, 0644); err != nil {
+		tprintf("⚠️ Warning: Could not write %s: %v\n", listingFile, err)
+	}
+	return cache
+}
+
+func stopRemoteServer(config *Config, remote remoteTarget) {
+	if useSystemd(config) {
+		stopSystemd(config)
+		return
+	}
+
+	tprintf("🔍 Stopping SmartFox on %s...\n", remote.Host)
+	output, err := remote.run(config, unixStopScript(config, remote.path("SFS2X")))
+	if err != nil {
+		tprintf("⚠️ Warning: Could not stop remote server: %s\n", strings.TrimSpace(string(output)))
+		return
+	}
+	printStopOutput(output)
+}
+
+func deployRemote(config *Config, remote remoteTarget, items []deployItem) bool {
+	remoteExtDir := remote.path("SFS2X", "extensions", config.ExtensionFolder)
+	tprintf("📁 Deploying to: %s:%s\n", remote.Host, remoteExtDir)
+
+	if reason := keepRunningReason(config); reason != "" {
+		tprintf("⏭️ %s, leaving the server running\n", reason)
+	} else {
+		stopRemoteServer(config, remote)
+	}
+
+	tprintln("📸 Saving snapshot of current deployment...")
+	if err := snapshotExtension(config, "before deploy"); err != nil {
+		tprintf("❌ Failed to snapshot current deployment: %v\n", err)
+		return false
+	}
+	if err := pruneHistory(config); err != nil {
+		tprintf("⚠️ Warning: Could not prune deploy history: %v\n", err)
+	}
+
+	changed, unchanged := splitUnchanged(config, items)
+	stagingDir := remoteExtDir + ".staging"
+
+	if output, err := remote.run(config, "mkdir -p "+shellQuote(remote.extensionsDir())+" && "+stageScript(remoteExtDir)); err != nil {
+		tprintf("❌ Failed to prepare staging folder: %s\n", strings.TrimSpace(string(output)))
+		return false
+	}
+
+	var commands []string
+	for _, name := range obsoleteJars(config, shellFiles(config, remote, remoteExtDir), unchanged) {
+		commands = append(commands, "-rm "+sftpQuote(path.Join(stagingDir, name)))
+	}
+	if config.CommonFile != "" {
+		commands = append(commands, "-mkdir "+sftpQuote(remote.path("SFS2X", "extensions", "__lib__")))
+	}
+	if len(config.Dependencies) > 0 || len(config.LibJars) > 0 {
+		commands = append(commands, "-mkdir "+sftpQuote(path.Join(stagingDir, "__lib__")))
+		for _, name := range staleLibJars(config, items, shellLibJars(config, remote)) {
+			commands = append(commands, "-rm "+sftpQuote(path.Join(stagingDir, "__lib__", name)))
+		}
+	}
+	for _, dir := range stagedSubdirs(config, changed) {
+		commands = append(commands, "-mkdir "+sftpQuote(path.Join(remote.extensionsDir(), dir)))
+	}
+	for _, item := range changed {
+		commands = append(commands,
+			fmt.Sprintf("put %s %s", sftpQuote(item.Source), sftpQuote(path.Join(remote.extensionsDir(), stagedTarget(config, item.Target)))))
+	}
+
+	tprintf("Uploading files over SFTP into %s...\n", stagingFolder(config))
+	if len(commands) > 0 {
+		if output, err := remote.sftp(config, commands); err != nil {
+			tprintf("❌ SFTP upload failed: %s\n", strings.TrimSpace(string(output)))
+			remote.run(config, "rm -rf "+shellQuote(stagingDir))
+			return false
+		}
+	}
+
+	printUnchanged(unchanged)
+	for _, item := range changed {
+		verbosef("   ✅ Uploaded: %s -> %s\n", filepath.Base(item.Source), item.Target)
+	}
+	printCopySummary("Uploaded", changed, unchanged)
+
+	tprintln("🔁 Swapping in the new extension folder...")
+	if output, err := remote.run(config, swapScript(remoteExtDir)); err != nil {
+		tprintf("❌ Failed to swap in %s: %s\n", stagingFolder(config), strings.TrimSpace(string(output)))
+		return false
+	}
+
+	tprintln("✅ Deployment successful")
+	fmt.Println()
+
+	return true
+}
+
+// snapshotRemote downloads the live remote extension into a history entry.
+func snapshotRemote(config *Config, remote remoteTarget, dir string) error {
+	commands := []string{
+		fmt.Sprintf("-get -r %s %s",
+			sftpQuote(remote.path("SFS2X", "extensions", config.ExtensionFolder)), sftpQuote(filepath.Join(dir, "extension"))),
+	}
+	if config.CommonFile != "" {
+		if err := os.MkdirAll(filepath.Join(dir, "__lib__"), 0755); err != nil {
+			return err
+		}
+		commands = append(commands, fmt.Sprintf("-get %s %s",
+			sftpQuote(remote.path("SFS2X", "extensions", "__lib__", config.CommonFile)),
+			sftpQuote(filepath.Join(dir, "__lib__", config.CommonFile))))
+	}
+
+	if output, err := remote.sftp(config, commands); err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	return os.MkdirAll(filepath.Join(dir, "extension"), 0755)
+}
+
+// restoreRemote uploads a history entry over the live remote extension.
+func restoreRemote(config *Config, remote remoteTarget, entryDir string) error {
+	remoteExtDir := remote.path("SFS2X", "extensions", config.ExtensionFolder)
+
+	if output, err := remote.run(config, "rm -rf "+shellQuote(remoteExtDir)); err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+
+	commands := []string{
+		fmt.Sprintf("put -r %s %s", sftpQuote(filepath.Join(entryDir, "extension")), sftpQuote(remoteExtDir)),
+	}
+	if config.CommonFile != "" {
+		snapshotCommon := filepath.Join(entryDir, "__lib__", config.CommonFile)
+		if _, err := os.Stat(snapshotCommon); err == nil {
+			commands = append(commands, fmt.Sprintf("put %s %s",
+				sftpQuote(snapshotCommon), sftpQuote(remote.path("SFS2X", "extensions", "__lib__", config.CommonFile))))
+		}
+	}
+
+	if output, err := remote.sftp(config, commands); err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func restartRemote(config *Config, remote remoteTarget) bool {
+	launcher, err := remoteLauncher(config, remote)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return false
+	}
+
+	stopRemoteServer(config, remote)
+
+	if !checkServerPorts(config) {
+		return false
+	}
+
+	tprintf("▶️ Starting SmartFox on %s with %s...\n", remote.Host, launcher)
+	script := fmt.Sprintf("cd %s && %s", shellQuote(remote.path("SFS2X")), unixStartScript(launcher))
+	if output, err := remote.run(config, script); err != nil {
+		tprintf("❌ Failed to start remote server: %s\n", strings.TrimSpace(string(output)))
+		return false
+	}
+
+	tprintln("✅ Server started on remote host")
+	if launcher != "sfs2x-service" {
+		tprintf("📝 Console output: %s:%s\n", remote.Host, remote.path("SFS2X", "logs", "sfdeploy-console.log"))
+	}
+	fmt.Println()
+
+	return true
+}
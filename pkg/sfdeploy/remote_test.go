@@ -0,0 +1,50 @@
+package sfdeploy
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseRemoteTarget(t *testing.T) {
+	tests := []struct {
+		target string
+		want   remoteTarget
+		ok     bool
+	}{
+		{"deploy@game1:/opt/SmartFoxServer_2X", remoteTarget{Host: "deploy@game1", Dir: "/opt/SmartFoxServer_2X"}, true},
+		{"game1:/opt/sfs/", remoteTarget{Host: "game1", Dir: "/opt/sfs"}, true},
+		{"game1:/opt/../srv/./sfs", remoteTarget{Host: "game1", Dir: "/srv/sfs"}, true},
+		{"C:/SmartFoxServer_2X", remoteTarget{}, false},
+		{`C:\SmartFoxServer_2X`, remoteTarget{}, false},
+		{"game1:relative/path", remoteTarget{}, false},
+		{"/opt/SmartFoxServer_2X", remoteTarget{}, false},
+		{"./servers/a:/b", remoteTarget{}, false},
+		{"docker://sfs:/opt", remoteTarget{}, false},
+		{`\\fileserver\share`, remoteTarget{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			got, ok := parseRemoteTarget(tt.target)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("parseRemoteTarget(%q) = %+v, %v, want %+v, %v", tt.target, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestRemoteLibCache(t *testing.T) {
+	a := remoteLibCache(remoteTarget{Host: "deploy@game1", Dir: "/opt/sfs"})
+	if a != remoteLibCache(remoteTarget{Host: "deploy@game1", Dir: "/opt/sfs"}) {
+		t.Errorf("remoteLibCache() differs between runs for one install")
+	}
+	if b := remoteLibCache(remoteTarget{Host: "deploy@game1", Dir: "/opt/sfs-staging"}); a == b {
+		t.Errorf("remoteLibCache() = %s for two installs on one host", a)
+	}
+	if c := remoteLibCache(remoteTarget{Host: "deploy@game2", Dir: "/opt/sfs"}); a == c {
+		t.Errorf("remoteLibCache() = %s for two hosts", a)
+	}
+	if !strings.HasPrefix(a, filepath.Join(stateDir, "remote", "deploy_game1-")) {
+		t.Errorf("remoteLibCache() = %s, want it named after the host", a)
+	}
+}
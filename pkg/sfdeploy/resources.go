@@ -0,0 +1,96 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// resourceRoot is one entry of resource_dirs: a folder of the repository
+// whose files are deployed to target, a subfolder of the extension folder.
+type resourceRoot struct {
+	Source string   `json:"source"`
+	Target string   `json:"target"`
+	Files  []string `json:"files"`
+}
+
+// targetFolder is the cleaned target subfolder, "" for the extension folder.
+func (r resourceRoot) targetFolder() (string, error) {
+	target := strings.Trim(filepath.ToSlash(r.Target), "/")
+	for _, segment := range strings.Split(target, "/") {
+		if segment == ".." {
+			return "", fmt.Errorf("resource_dirs target %s must stay inside the extension folder", r.Target)
+		}
+	}
+	return path.Clean("/" + target)[1:], nil
+}
+
+// resourceDir is the folder deploy_files are matched in.
+func resourceDir(config *Config) string {
+	if config.JsonSourceDir != "" {
+		return config.JsonSourceDir
+	}
+	return config.SourceDir
+}
+
+// appendResources adds the deploy_files matches and the files of every
+// resource_dirs entry to items. Files already listed, e.g. by
+// deploy_json_files, are not added twice.
+func appendResources(config *Config, items []deployItem, data templateData) ([]deployItem, error) {
+	listed := map[string]bool{}
+	for _, item := range items {
+		listed[item.Target] = true
+	}
+
+	var err error
+	if len(config.DeployFiles) > 0 {
+		if items, err = appendResourceFiles(config, items, listed, resourceDir(config), config.DeployFiles, "", data); err != nil {
+			return nil, fmt.Errorf("deploy_files: %v", err)
+		}
+	}
+
+	for _, root := range config.ResourceDirs {
+		target, err := root.targetFolder()
+		if err != nil {
+			return nil, err
+		}
+		if !fileExists(root.Source) {
+			tprintf("⚠️ Warning: Resource folder not found: %s\n", root.Source)
+			continue
+		}
+		if items, err = appendResourceFiles(config, items, listed, root.Source, root.Files, target, data); err != nil {
+			return nil, fmt.Errorf("resource_dirs %s: %v", root.Source, err)
+		}
+	}
+	return items, nil
+}
+
+// appendResourceFiles adds the files under dir that patterns select (all
+// of them without patterns), keeping their path below dir inside target.
+func appendResourceFiles(config *Config, items []deployItem, listed map[string]bool, dir string, patterns []string, target string, data templateData) ([]deployItem, error) {
+	files, err := globFiles(dir, patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rel := range files {
+		name := path.Join(target, rel)
+		item := deployItem{
+			Source: filepath.Join(dir, filepath.FromSlash(rel)),
+			Target: config.ExtensionFolder + "/" + name,
+		}
+		if listed[item.Target] || sourceIgnore(config).skip(item.Source, false) {
+			continue
+		}
+		listed[item.Target] = true
+
+		if config.JsonTemplates && strings.EqualFold(path.Ext(rel), ".json") {
+			if item.Source, err = renderJSONTemplate(config, item.Source, name, data); err != nil {
+				return nil, fmt.Errorf("failed to render %s: %v", name, err)
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
@@ -0,0 +1,81 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// restartData is what restart_command can reference besides the JSON
+// template values, e.g. "supervisorctl restart sfs2x" or
+// "{{.SFSDir}}/restart.sh {{.Extension}}". For a remote or container target
+// the directories are paths on the host or in the container, and Host names
+// the host or container.
+type restartData struct {
+	templateData
+	TargetDir string
+	SFSDir    string
+	SourceDir string
+	Zone      string
+	Host      string
+}
+
+func newRestartData(config *Config) restartData {
+	data := restartData{
+		templateData: newTemplateData(config),
+		SourceDir:    absPath(config.SourceDir),
+		Zone:         config.ZoneName,
+	}
+	if remote, ok := parseRemoteTarget(config.TargetDir); ok {
+		data.Host, data.TargetDir, data.SFSDir = remote.Host, remote.Dir, remote.path("SFS2X")
+	} else if d, ok := parseDockerTarget(config.TargetDir); ok {
+		data.Host, data.TargetDir, data.SFSDir = d.Container, d.Dir, d.path("SFS2X")
+	} else {
+		data.TargetDir = absPath(config.TargetDir)
+		data.SFSDir = filepath.Join(data.TargetDir, "SFS2X")
+	}
+	return data
+}
+
+// renderRestartCommand fills in the template variables of restart_command.
+func renderRestartCommand(config *Config) (string, error) {
+	tmpl, err := template.New("restart_command").Funcs(templateFuncs).Option("missingkey=error").Parse(config.RestartCommand)
+	if err != nil {
+		return "", fmt.Errorf("restart_command: %w", err)
+	}
+	var line strings.Builder
+	if err := tmpl.Execute(&line, newRestartData(config)); err != nil {
+		return "", fmt.Errorf("restart_command: %w", err)
+	}
+	return line.String(), nil
+}
+
+// runRestartCommand restarts the server with restart_command in place of
+// the built-in restart. It runs in the source directory with the
+// environment of the deploy hooks.
+func runRestartCommand(config *Config) bool {
+	line, err := renderRestartCommand(config)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return false
+	}
+	if *flagDryRun {
+		tprintf("[dry-run] Would run restart_command: %s\n", line)
+		fmt.Println()
+		return true
+	}
+
+	tprintf("🔄 Running restart_command: %s\n", line)
+	cmd := hookCommand(line)
+	cmd.Dir = config.SourceDir
+	cmd.Env = hookEnv(config, "restart")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		tprintf("❌ restart_command failed: %v\n", err)
+		return false
+	}
+	return true
+}
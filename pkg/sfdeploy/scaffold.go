@@ -0,0 +1,187 @@
+package sfdeploy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// javaClassName matches a fully qualified Java class name.
+var javaClassName = regexp.MustCompile(`^(?:[A-Za-z_$][\w$]*\.)*[A-Za-z_$][\w$]*$`)
+
+// scaffoldData is what the templates of new projects reference.
+type scaffoldData struct {
+	Package string // com.mycompany.chess
+	Class   string // ChessExtension
+	Name    string // Chess, the extension folder
+}
+
+var extensionTemplate = template.Must(template.New("extension").Parse(`package {{.Package}};
+
+import {{.Package}}.handlers.HelloHandler;
+import com.smartfoxserver.v2.extensions.SFSExtension;
+
+public class {{.Class}} extends SFSExtension {
+    @Override
+    public void init() {
+        addRequestHandler("hello", HelloHandler.class);
+        trace("{{.Name}} extension started");
+    }
+
+    @Override
+    public void destroy() {
+        super.destroy();
+        trace("{{.Name}} extension stopped");
+    }
+}
+`))
+
+var helloHandlerTemplate = template.Must(template.New("handler").Parse(`package {{.Package}}.handlers;
+
+import com.smartfoxserver.v2.entities.User;
+import com.smartfoxserver.v2.entities.data.ISFSObject;
+import com.smartfoxserver.v2.entities.data.SFSObject;
+import com.smartfoxserver.v2.extensions.BaseClientRequestHandler;
+
+/**
+ * Answers the "hello" request with a greeting for its "name" parameter, or
+ * for the user.
+ */
+public class HelloHandler extends BaseClientRequestHandler {
+    @Override
+    public void handleClientRequest(User user, ISFSObject params) {
+        String name = params.containsKey("name") ? params.getUtfString("name") : user.getName();
+
+        ISFSObject response = new SFSObject();
+        response.putUtfString("message", "Hello, " + name + "!");
+        send("hello", response, user);
+    }
+}
+`))
+
+// scaffoldGitignore keeps build output, sfdeploy's state, the machine
+// config and .env out of git; the team settings are in .sfdeploy.json.
+const scaffoldGitignore = `*.class
+*.jar
+` + stateDir + `/
+` + configFile + `
+` + dotEnvFile + `
+`
+
+// projectName turns a directory name into a Java identifier, so
+// chess-server becomes ChessServer.
+func projectName(dir string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range filepath.Base(absPath(dir)) {
+		switch {
+		case unicode.IsLetter(r) || (unicode.IsDigit(r) && b.Len() > 0):
+			if upper {
+				r = unicode.ToUpper(r)
+			}
+			b.WriteRune(r)
+			upper = false
+		default:
+			upper = true
+		}
+	}
+	if b.Len() == 0 {
+		return "My"
+	}
+	return b.String()
+}
+
+// initCommand implements "init [directory] [main class]": it creates an
+// extension project that builds and deploys as is, with the main class, a
+// sample request handler, the team's .sfdeploy.json and a machine config
+// pointing at the SmartFox Server found on this machine. Existing files are
+// never overwritten.
+func initCommand(config *Config) bool {
+	dir := commandArg(0)
+	if dir == "" {
+		dir = "."
+	}
+	name := projectName(dir)
+	class := commandArg(1)
+	if class == "" {
+		class = "com.mycompany." + strings.ToLower(name) + "." + name + "Extension"
+	}
+	if !javaClassName.MatchString(class) || !strings.Contains(class, ".") {
+		tprintf("❌ %s is not a Java class name with a package, e.g. com.mycompany.chess.ChessExtension\n", class)
+		return false
+	}
+	dot := strings.LastIndex(class, ".")
+	data := scaffoldData{Package: class[:dot], Class: class[dot+1:], Name: extensionFolderFor(class)}
+	srcDir := filepath.Join(append([]string{dir, "src"}, strings.Split(data.Package, ".")...)...)
+
+	targetDir := findSmartFoxServer()
+	// The machine config lives in the project, so paths are relative to it
+	machine, _ := json.MarshalIndent(struct {
+		SourceDir string `json:"source_dir"`
+		TargetDir string `json:"target_dir,omitempty"`
+	}{".", targetDir}, "", "  ")
+	project, _ := json.MarshalIndent(struct {
+		ExtensionFolder string `json:"extension_folder"`
+		JavaVersion     string `json:"java_version"`
+	}{data.Name, defaultJavaVersion}, "", "  ")
+
+	files := []struct {
+		path    string
+		content []byte
+	}{
+		{filepath.Join(srcDir, data.Class+".java"), renderScaffold(extensionTemplate, data)},
+		{filepath.Join(srcDir, "handlers", "HelloHandler.java"), renderScaffold(helloHandlerTemplate, data)},
+		{filepath.Join(dir, projectConfigFile), append(project, '\n')},
+		{filepath.Join(dir, configFile), append(machine, '\n')},
+		{filepath.Join(dir, ".gitignore"), []byte(scaffoldGitignore)},
+	}
+	var existing []string
+	for _, file := range files {
+		if _, err := os.Stat(file.path); err == nil {
+			existing = append(existing, file.path)
+		}
+	}
+	if len(existing) > 0 {
+		tprintf("❌ Not creating the project, these files already exist: %s\n", strings.Join(existing, ", "))
+		return false
+	}
+
+	tprintf("🧱 Creating extension project %s in %s\n", data.Name, dir)
+	for _, file := range files {
+		if err := os.MkdirAll(filepath.Dir(file.path), 0755); err != nil {
+			tprintf("❌ Failed to create %s: %v\n", filepath.Dir(file.path), err)
+			return false
+		}
+		if err := os.WriteFile(file.path, file.content, 0644); err != nil {
+			tprintf("❌ Failed to write %s: %v\n", file.path, err)
+			return false
+		}
+		fmt.Printf("   + %s\n", file.path)
+	}
+
+	fmt.Println()
+	if targetDir != "" {
+		tprintf("✅ Created %s, deploying to %s\n", data.Name, targetDir)
+	} else {
+		tprintf("✅ Created %s\n", data.Name)
+		tprintln("💡 No SmartFox Server found; set it with: sfdeploy config set target_dir <path>")
+	}
+	if dir != "." {
+		tprintf("💡 Next: cd %s && sfdeploy all\n", dir)
+	} else {
+		tprintln("💡 Next: sfdeploy all")
+	}
+	return true
+}
+
+func renderScaffold(t *template.Template, data scaffoldData) []byte {
+	var buf bytes.Buffer
+	t.Execute(&buf, data)
+	return buf.Bytes()
+}
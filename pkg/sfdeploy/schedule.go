@@ -0,0 +1,157 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// scheduledCommands are the commands --at can delay.
+var scheduledCommands = []string{"all", "build", "test", "deploy", "restart", "rollback"}
+
+// cronField is the set of allowed values of one field of a cron expression.
+type cronField map[int]bool
+
+// parseCronField parses "*", "5", "1-5", "*/15", "0-30/10" and lists of them.
+func parseCronField(s string, min, max int) (cronField, error) {
+	field := cronField{}
+	for _, part := range strings.Split(s, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid step in %q", part)
+			}
+			step, part = n, part[:i]
+		}
+
+		lo, hi := min, max
+		if part != "*" {
+			from, to, isRange := strings.Cut(part, "-")
+			var err error
+			if lo, err = strconv.Atoi(from); err != nil {
+				return nil, fmt.Errorf("invalid value %q", part)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(to); err != nil {
+					return nil, fmt.Errorf("invalid range %q", part)
+				}
+			}
+			if lo < min || hi > max || lo > hi {
+				return nil, fmt.Errorf("%q is outside %d-%d", part, min, max)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			field[v] = true
+		}
+	}
+	return field, nil
+}
+
+// nextCronTime returns the first minute after from that matches the
+// five-field expression "minute hour day-of-month month day-of-week". As in
+// cron, a day matches either day field when both are restricted.
+func nextCronTime(expr string, from time.Time) (time.Time, error) {
+	parts := strings.Fields(expr)
+	if len(parts) != 5 {
+		return time.Time{}, fmt.Errorf("expected 5 fields, got %d", len(parts))
+	}
+	limits := [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+	var fields [5]cronField
+	for i, part := range parts {
+		field, err := parseCronField(part, limits[i][0], limits[i][1])
+		if err != nil {
+			return time.Time{}, err
+		}
+		fields[i] = field
+	}
+	// Sunday is both 0 and 7
+	if fields[4][7] {
+		fields[4][0] = true
+	}
+	anyDom, anyDow := parts[2] == "*", parts[4] == "*"
+
+	t := from.Truncate(time.Minute).Add(time.Minute)
+	for end := t.AddDate(5, 0, 0); t.Before(end); t = t.Add(time.Minute) {
+		if !fields[3][int(t.Month())] || !fields[1][t.Hour()] || !fields[0][t.Minute()] {
+			continue
+		}
+		dom, dow := fields[2][t.Day()], fields[4][int(t.Weekday())]
+		if (anyDom || anyDow) && dom && dow || !anyDom && !anyDow && (dom || dow) {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q never matches", expr)
+}
+
+// scheduledTime parses --at: a time of day ("02:30", today or tomorrow), a
+// date and time ("2026-03-01 02:30") or a cron expression, whose next match
+// is used.
+func scheduledTime(at string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation("15:04", at, now.Location()); err == nil {
+		next := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, now.Location())
+		if !next.After(now) {
+			next = next.AddDate(0, 0, 1)
+		}
+		return next, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02 15:04", at, now.Location()); err == nil {
+		if !t.After(now) {
+			return time.Time{}, fmt.Errorf("%s is in the past", at)
+		}
+		return t, nil
+	}
+	if len(strings.Fields(at)) == 5 {
+		t, err := nextCronTime(at, now)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid cron expression: %v", err)
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("expected HH:MM, \"YYYY-MM-DD HH:MM\" or a cron expression")
+}
+
+// schedulePhases delays phases until at. The setup phases still run first,
+// so a broken config is reported right away rather than at the deploy time.
+func schedulePhases(name string, phases []phase, at time.Time) []phase {
+	setup := []uintptr{phaseID(setupDirectories), phaseID(setupWorkspace), phaseID(setupJava)}
+	i := 0
+	for i < len(phases) && slices.Contains(setup, phaseID(phases[i])) {
+		i++
+	}
+	wait := func(config *Config) bool {
+		return waitForSchedule(name, at)
+	}
+	return append(phases[:i:i], append([]phase{wait}, phases[i:]...)...)
+}
+
+func waitForSchedule(name string, at time.Time) bool {
+	tprintf("⏰ '%s' scheduled for %s (in %s, Ctrl+C to cancel)\n", name, at.Format("2006-01-02 15:04"), time.Until(at).Round(time.Second))
+
+	if *flagDryRun {
+		tprintln("[dry-run] Would wait until the scheduled time")
+		fmt.Println()
+		return true
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	timer := time.NewTimer(time.Until(at))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		tprintf("⏰ Starting scheduled '%s' at %s\n", name, time.Now().Format("15:04:05"))
+		fmt.Println()
+		return true
+	case <-interrupt:
+		tprintln("Scheduled run cancelled")
+		return false
+	}
+}
@@ -0,0 +1,390 @@
+package sfdeploy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// schemaIssue is a problem found in the config file, at a line and column
+// when the format keeps them (JSON and YAML). Warnings do not stop a run.
+type schemaIssue struct {
+	Line    int
+	Column  int
+	Key     string
+	Message string
+	Warning bool
+}
+
+func (i schemaIssue) String() string {
+	var b strings.Builder
+	if i.Line > 0 {
+		fmt.Fprintf(&b, "line %d, column %d: ", i.Line, i.Column)
+	}
+	if i.Key != "" {
+		b.WriteString(i.Key + ": ")
+	}
+	b.WriteString(i.Message)
+	return b.String()
+}
+
+var rawMessageType = reflect.TypeOf(json.RawMessage{})
+
+// checkConfigFile checks the config file in use against the Config struct,
+// which is its schema: syntax errors, keys the tool does not know, values
+// of the wrong type and keys given twice.
+func checkConfigFile() ([]schemaIssue, error) {
+	path := findConfigFile()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return checkConfigData(path, data), nil
+}
+
+func checkConfigData(path string, data []byte) []schemaIssue {
+	var root yaml.Node
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &root); err != nil {
+			return []schemaIssue{{Message: err.Error()}}
+		}
+	case ".toml":
+		// TOML keeps no positions once decoded, so its keys are checked
+		// through the JSON it converts to
+		var generic map[string]any
+		if _, err := toml.Decode(string(data), &generic); err != nil {
+			var parseErr toml.ParseError
+			if errors.As(err, &parseErr) {
+				return []schemaIssue{{Line: parseErr.Position.Line, Column: parseErr.Position.Col, Message: parseErr.Message}}
+			}
+			return []schemaIssue{{Message: err.Error()}}
+		}
+		converted, err := json.Marshal(generic)
+		if err != nil || yaml.Unmarshal(converted, &root) != nil {
+			return nil
+		}
+		root.Line, root.Column = 0, 0
+		clearPositions(&root)
+	default:
+		if err := json.Unmarshal(data, new(any)); err != nil {
+			issue := schemaIssue{Message: err.Error()}
+			var syntaxErr *json.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				// The offset is just past the offending character
+				issue.Line, issue.Column = offsetPosition(data, max(0, syntaxErr.Offset-1))
+				issue.Message = "invalid JSON: " + syntaxErr.Error()
+			}
+			return []schemaIssue{issue}
+		}
+		// A JSON document is also YAML, which keeps the positions
+		if yaml.Unmarshal(data, &root) != nil {
+			return nil
+		}
+	}
+
+	if len(root.Content) == 0 {
+		return nil
+	}
+	var issues []schemaIssue
+	checkNode(&issues, root.Content[0], reflect.TypeOf(Config{}), "")
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Line != issues[j].Line {
+			return issues[i].Line < issues[j].Line
+		}
+		return issues[i].Column < issues[j].Column
+	})
+	return issues
+}
+
+func clearPositions(node *yaml.Node) {
+	node.Line, node.Column = 0, 0
+	for _, child := range node.Content {
+		clearPositions(child)
+	}
+}
+
+// offsetPosition turns a byte offset into a line and column.
+func offsetPosition(data []byte, offset int64) (line, column int) {
+	offset = min(offset, int64(len(data)))
+	line, column = 1, 1
+	for _, c := range string(data[:offset]) {
+		if c == '\n' {
+			line, column = line+1, 1
+		} else {
+			column++
+		}
+	}
+	return line, column
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// nodeKind names what a node holds, the way a type error reports it.
+func nodeKind(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "an object"
+	case yaml.SequenceNode:
+		return "a list"
+	}
+	switch node.Tag {
+	case "!!str":
+		return fmt.Sprintf("the string %q", node.Value)
+	case "!!int", "!!float":
+		return "the number " + node.Value
+	case "!!bool":
+		return node.Value
+	}
+	return node.Value
+}
+
+// expectedKind describes the JSON a Go type unmarshals from.
+func expectedKind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+		return "an object"
+	case reflect.Slice, reflect.Array:
+		return "a list"
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "a whole number"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	}
+	return t.String()
+}
+
+func checkNode(issues *[]schemaIssue, node *yaml.Node, t reflect.Type, key string) {
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	// null leaves a field at its zero value
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return
+	}
+	wrongType := func() {
+		message := fmt.Sprintf("expected %s, got %s", expectedKind(t), nodeKind(node))
+		if t.Kind() == reflect.String && node.Kind == yaml.ScalarNode {
+			message += " (put it in quotes)"
+		}
+		*issues = append(*issues, schemaIssue{Line: node.Line, Column: node.Column, Key: key, Message: message})
+	}
+
+	switch {
+	case t == rawMessageType:
+		// Profiles hold the same keys as the config
+		if strings.HasPrefix(key, "profiles.") {
+			checkNode(issues, node, reflect.TypeOf(Config{}), key)
+		}
+		return
+	case t.Kind() == reflect.Interface:
+		return
+	case t.Kind() == reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			wrongType()
+			return
+		}
+		checkObject(issues, node, t, key)
+	case t.Kind() == reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			wrongType()
+			return
+		}
+		seen := map[string]bool{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			name := node.Content[i]
+			checkDuplicate(issues, seen, name, key)
+			checkNode(issues, node.Content[i+1], t.Elem(), joinKey(key, name.Value))
+		}
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		if node.Kind != yaml.SequenceNode {
+			wrongType()
+			return
+		}
+		for i, item := range node.Content {
+			checkNode(issues, item, t.Elem(), key+"["+strconv.Itoa(i)+"]")
+		}
+	default:
+		if node.Kind != yaml.ScalarNode {
+			wrongType()
+			return
+		}
+		ok := false
+		switch t.Kind() {
+		case reflect.String:
+			// YAML dates reach the config as strings
+			ok = node.Tag == "!!str" || node.Tag == "!!timestamp"
+		case reflect.Bool:
+			ok = node.Tag == "!!bool"
+		case reflect.Float32, reflect.Float64:
+			ok = node.Tag == "!!int" || node.Tag == "!!float"
+		default:
+			ok = node.Tag == "!!int"
+		}
+		if !ok {
+			wrongType()
+		}
+	}
+}
+
+// checkObject checks the keys of an object against the json tags of the
+// struct it decodes into. Like encoding/json, keys match regardless of case.
+func checkObject(issues *[]schemaIssue, node *yaml.Node, t reflect.Type, key string) {
+	fields := map[string]reflect.StructField{}
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "" || name == "-" {
+			continue
+		}
+		fields[strings.ToLower(name)] = field
+		names = append(names, name)
+	}
+
+	seen := map[string]bool{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		name, value := node.Content[i], node.Content[i+1]
+		checkDuplicate(issues, seen, name, key)
+		// Editors use $schema to find a schema for completion
+		if key == "" && name.Value == "$schema" {
+			continue
+		}
+		field, ok := fields[strings.ToLower(name.Value)]
+		if !ok {
+			message := "unknown key, it is ignored"
+			if suggestion := closestKey(name.Value, names); suggestion != "" {
+				message += fmt.Sprintf(" (did you mean %s?)", suggestion)
+			}
+			*issues = append(*issues, schemaIssue{Line: name.Line, Column: name.Column, Key: joinKey(key, name.Value), Message: message, Warning: true})
+			continue
+		}
+		checkNode(issues, value, field.Type, joinKey(key, name.Value))
+	}
+}
+
+// checkDuplicate warns about a key given twice in one object, where the
+// last value silently wins.
+func checkDuplicate(issues *[]schemaIssue, seen map[string]bool, name *yaml.Node, key string) {
+	folded := strings.ToLower(name.Value)
+	if seen[folded] {
+		*issues = append(*issues, schemaIssue{Line: name.Line, Column: name.Column, Key: joinKey(key, name.Value),
+			Message: "given more than once, this value replaces the earlier one", Warning: true})
+	}
+	seen[folded] = true
+}
+
+// closestKey returns the known key a mistyped one most likely meant, or ""
+// when none is close.
+func closestKey(name string, known []string) string {
+	best, bestDistance := "", 0
+	for _, candidate := range known {
+		d := editDistance(strings.ToLower(name), candidate)
+		if best == "" || d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	if bestDistance > max(2, len(name)/3) {
+		return ""
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between two strings.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// missingRequired lists the settings a deploy cannot do without, once the
+// config file, profile, environment and flags are applied.
+func missingRequired(config *Config) []string {
+	var missing []string
+	if config.SourceDir == "" && len(config.Modules) == 0 {
+		missing = append(missing, fmt.Sprintf("source_dir (or %s, --source)", envName("source_dir")))
+	}
+	if config.TargetDir == "" && len(config.Targets) == 0 {
+		missing = append(missing, fmt.Sprintf("target_dir (or targets, %s, --target)", envName("target_dir")))
+	}
+	if config.ExtensionFolder == "" && len(config.Extensions) == 0 {
+		missing = append(missing, fmt.Sprintf("extension_folder (or extensions, %s, --extension)", envName("extension_folder")))
+	}
+	return missing
+}
+
+// reportConfigFile prints the problems of the config file, naming the file
+// and the line of each, and reports whether it can be used.
+func reportConfigFile() bool {
+	issues, err := checkConfigFile()
+	if os.IsNotExist(err) {
+		return true
+	}
+	if err != nil {
+		tprintf("❌ Cannot read %s: %v\n", findConfigFile(), err)
+		return false
+	}
+	path := findConfigFile()
+	failed := false
+	for _, issue := range issues {
+		if issue.Warning {
+			tprintf("⚠️ Warning: %s: %s\n", path, issue)
+		} else {
+			fmt.Printf("❌ %s: %s\n", path, issue)
+			failed = true
+		}
+	}
+	if failed {
+		tprintln("Fix the config file, or check it with 'sfdeploy config validate'")
+	}
+	return !failed
+}
+
+// reportMissingRequired prints the required settings that are not set.
+func reportMissingRequired(config *Config) bool {
+	missing := missingRequired(config)
+	for _, key := range missing {
+		tprintf("❌ Required setting missing: %s\n", key)
+	}
+	if len(missing) > 0 {
+		tprintf("Set them in %s, or run 'sfdeploy config edit'\n", findConfigFile())
+	}
+	return len(missing) == 0
+}
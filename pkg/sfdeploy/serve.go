@@ -0,0 +1,310 @@
+package sfdeploy
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+const (
+	defaultServeAddr = "127.0.0.1:9091"
+	maxServeQueue    = 20
+)
+
+// serveActions are the runs the daemon can be asked for.
+var serveActions = map[string][]phase{
+	"deploy":   watchPhases,
+	"rollback": {lockedTarget(eachExtension(rollbackDeployment), restartServer, checkServerHealth, smokeTest)},
+}
+
+// serveJob is one run requested through the API. Requests counts the
+// requests merged into it while it was waiting, and Position is its place in
+// the queue, 1 being next.
+type serveJob struct {
+	ID       int        `json:"id"`
+	Action   string     `json:"action"`
+	By       string     `json:"by,omitempty"`
+	Requests int        `json:"requests"`
+	Position int        `json:"position,omitempty"`
+	Queued   time.Time  `json:"queued"`
+	Started  *time.Time `json:"started,omitempty"`
+	Finished *time.Time `json:"finished,omitempty"`
+	Success  bool       `json:"success"`
+	ExitCode int        `json:"exit_code"`
+}
+
+type serveStatus struct {
+	State   string     `json:"state"`
+	Current *serveJob  `json:"current,omitempty"`
+	Queue   []serveJob `json:"queue"`
+	Last    *serveJob  `json:"last,omitempty"`
+}
+
+// errQueueFull is returned for a request when maxServeQueue jobs are
+// already waiting.
+var errQueueFull = errors.New("the queue is full")
+
+// daemon holds the state shared between the API handlers and the loop that
+// runs the jobs. Jobs run one at a time in the order they were queued.
+type daemon struct {
+	config *Config
+	wake   chan struct{}
+
+	mu      sync.Mutex
+	nextID  int
+	current *serveJob
+	pending []*serveJob
+	last    *serveJob
+	metrics promMetrics
+}
+
+// queued copies the waiting jobs with their positions. d.mu must be held.
+func (d *daemon) queued() []serveJob {
+	jobs := []serveJob{}
+	for i, job := range d.pending {
+		copied := *job
+		copied.Position = i + 1
+		jobs = append(jobs, copied)
+	}
+	return jobs
+}
+
+func (d *daemon) status() serveStatus {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	status := serveStatus{State: "idle", Queue: d.queued(), Last: d.last}
+	if len(d.pending) > 0 {
+		status.State = "queued"
+	}
+	if d.current != nil {
+		job := *d.current
+		status.Current = &job
+		status.State = "running"
+	}
+	return status
+}
+
+// queue adds a run of action to the queue. A request for the same action as
+// the last waiting job is merged into it instead, since that job would run
+// the same pipeline on the same sources; merged reports it.
+func (d *daemon) queue(action, by string) (job serveJob, merged bool, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if n := len(d.pending); n > 0 && d.pending[n-1].Action == action {
+		last := d.pending[n-1]
+		last.Requests++
+		tprintf("📥 %s%s merged into job %d, position %d\n", action, requestedBy(by), last.ID, n)
+		job = *last
+		job.Position = n
+		return job, true, nil
+	}
+	if len(d.pending) >= maxServeQueue {
+		return serveJob{}, false, errQueueFull
+	}
+
+	d.nextID++
+	queued := &serveJob{ID: d.nextID, Action: action, By: by, Requests: 1, Queued: time.Now()}
+	d.pending = append(d.pending, queued)
+	if d.current != nil || len(d.pending) > 1 {
+		tprintf("📥 %s%s queued as job %d, position %d\n", action, requestedBy(by), queued.ID, len(d.pending))
+	}
+	d.signal()
+	job = *queued
+	job.Position = len(d.pending)
+	return job, false, nil
+}
+
+func requestedBy(by string) string {
+	if by == "" {
+		return ""
+	}
+	return " by " + by
+}
+
+// signal wakes the loop that runs the jobs.
+func (d *daemon) signal() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// next takes the first waiting job, or returns nil when there is none.
+func (d *daemon) next() *serveJob {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.pending) == 0 {
+		return nil
+	}
+	job := d.pending[0]
+	d.pending = d.pending[1:]
+	started := time.Now()
+	job.Started = &started
+	d.current = job
+	return job
+}
+
+func (d *daemon) run(job *serveJob) {
+	started := *job.Started
+	tprintf("📡 %s requested through the API%s (job %d) at %s\n", job.Action, requestedBy(job.By), job.ID, started.Format("15:04:05"))
+	if job.Requests > 1 {
+		tprintf("   %d requests merged into this run\n", job.Requests)
+	}
+	fmt.Println()
+
+	code := exitOK
+	if err := runStage(job.Action, d.config, serveActions[job.Action]...); err != nil {
+		code = err.(*PhaseError).Code
+		tprintf("❌ Job %d (%s) failed\n", job.ID, job.Action)
+	} else {
+		tprintf("Job %d (%s) completed successfully!\n", job.ID, job.Action)
+	}
+	fmt.Println()
+	notifyResult(d.config, job.Action, code == exitOK, started)
+	recordMetrics(d.config, job.Action, code == exitOK, started)
+
+	finished := time.Now()
+	d.mu.Lock()
+	job.Finished = &finished
+	job.Success = code == exitOK
+	job.ExitCode = code
+	d.last, d.current = job, nil
+	d.mu.Unlock()
+	d.metrics.observe(job)
+	d.signal()
+}
+
+// serveHistory is a deploy history entry numbered as for history restore.
+type serveHistory struct {
+	N int `json:"n"`
+	historyEntry
+}
+
+func (d *daemon) history() []serveHistory {
+	targets := d.config.Targets
+	if len(targets) == 0 {
+		targets = []string{d.config.TargetDir}
+	}
+
+	list := []serveHistory{}
+	for _, target := range targets {
+		targetConfig := *d.config
+		targetConfig.TargetDir = target
+		for _, ext := range extensionConfigs(&targetConfig) {
+			for i, entry := range loadHistory(&ext) {
+				list = append(list, serveHistory{N: i + 1, historyEntry: entry})
+			}
+		}
+	}
+	return list
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+// authorized checks the bearer token when serve_token is set.
+func (d *daemon) authorized(r *http.Request) bool {
+	if d.config.ServeToken == "" {
+		return true
+	}
+	expected := "Bearer " + d.config.ServeToken
+	return subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(expected)) == 1
+}
+
+func (d *daemon) handler() http.Handler {
+	mux := http.NewServeMux()
+	for action := range serveActions {
+		mux.HandleFunc("POST /"+action, func(w http.ResponseWriter, r *http.Request) {
+			job, _, err := d.queue(action, r.URL.Query().Get("by"))
+			if err != nil {
+				writeJSON(w, http.StatusTooManyRequests, d.status())
+				return
+			}
+			writeJSON(w, http.StatusAccepted, job)
+		})
+	}
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, d.status())
+	})
+	mux.HandleFunc("GET /history", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, d.history())
+	})
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		d.metrics.write(w, d.status())
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !d.authorized(r) {
+			tprintf("⚠️ Rejected API request from %s: bad token\n", r.RemoteAddr)
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func serveAPI(config *Config) bool {
+	tprintln("📡 Daemon Mode")
+
+	addr := config.ServeAddr
+	if addr == "" {
+		addr = defaultServeAddr
+	}
+
+	d := &daemon{config: config, wake: make(chan struct{}, 1)}
+	server := &http.Server{Addr: addr, Handler: d.handler(), ReadHeaderTimeout: 10 * time.Second}
+
+	failed := make(chan error, 2)
+	go func() {
+		failed <- server.ListenAndServe()
+	}()
+	defer server.Close()
+
+	if config.GRPCAddr != "" {
+		stop, err := startGRPC(d, config.GRPCAddr, failed)
+		if err != nil {
+			tprintf("❌ gRPC server failed: %v\n", err)
+			return false
+		}
+		defer stop()
+		tprintf("Serving gRPC on %s\n", config.GRPCAddr)
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	tprintf("Serving the API on http://%s (Ctrl+C to stop)\n", addr)
+	fmt.Println()
+
+	for {
+		select {
+		case <-d.wake:
+			if job := d.next(); job != nil {
+				d.run(job)
+			}
+
+		case err := <-failed:
+			tprintf("❌ Daemon server failed: %v\n", err)
+			return false
+
+		case <-interrupt:
+			if queued := len(d.status().Queue); queued > 0 {
+				tprintf("Stopping daemon mode, dropping %d queued jobs\n", queued)
+			} else {
+				tprintln("Stopping daemon mode")
+			}
+			return true
+		}
+	}
+}
@@ -0,0 +1,510 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var smartFoxCmdPid string
+
+// serverPidFile, in the SFS2X folder, holds the PID of the server sfdeploy
+// last started: the launcher on Unix, the CMD window on Windows.
+const serverPidFile = ".sfdeploy.pid"
+
+const (
+	defaultRestartTimeout = 60
+	defaultShutdownWait   = 10
+	defaultShutdownGrace  = 3
+	defaultKillWait       = 5
+)
+
+// restartTimeout bounds how long the Windows service or systemd unit may
+// take to stop or start.
+func restartTimeout(config *Config) time.Duration {
+	if config.RestartTimeout > 0 {
+		return time.Duration(config.RestartTimeout) * time.Second
+	}
+	return defaultRestartTimeout * time.Second
+}
+
+// stopWait is the wait of one step of the stop sequence: the configured
+// seconds, def when unset, or none when negative.
+func stopWait(seconds, def int) time.Duration {
+	switch {
+	case seconds < 0:
+		return 0
+	case seconds == 0:
+		return time.Duration(def) * time.Second
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// shutdownWait is how long the server gets to exit after a graceful
+// shutdown request.
+func shutdownWait(config *Config) time.Duration {
+	return stopWait(config.ShutdownWait, defaultShutdownWait)
+}
+
+// shutdownGrace is how long a killed server gets to exit before it is
+// killed forcefully. The wait ends as soon as the process is gone.
+func shutdownGrace(config *Config) time.Duration {
+	return stopWait(config.ShutdownGrace, defaultShutdownGrace)
+}
+
+// killWait is how long a force-killed server may take to disappear before
+// the stop is reported as failed.
+func killWait(config *Config) time.Duration {
+	return stopWait(config.KillWait, defaultKillWait)
+}
+
+// startupWait is a fixed pause after the server is started, for setups
+// where the health check cannot tell when it is ready.
+func startupWait(config *Config) time.Duration {
+	return time.Duration(max(config.StartupWait, 0)) * time.Second
+}
+
+// launcherCandidates lists the SmartFox launchers shipped for an OS, in order
+// of preference. sfs2x.sh is preferred on Unix because its console output can
+// be captured to a log file.
+func launcherCandidates(goos string) []string {
+	if goos == "windows" {
+		return []string{"sfs2x.bat"}
+	}
+	return []string{"sfs2x.sh", "sfs2x-service"}
+}
+
+// findLauncher returns the first launcher present in sfsDir, or "".
+func findLauncher(sfsDir string) string {
+	for _, name := range launcherCandidates(runtime.GOOS) {
+		if _, err := os.Stat(filepath.Join(sfsDir, name)); err == nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// unixPidScript sets $pid to the PID in pidFile when that process is still a
+// running SmartFox, so a reused PID is never killed, and to "" otherwise.
+func unixPidScript(pidFile string) string {
+	return `pid=$(cat ` + pidFile + ` 2>/dev/null); ` +
+		`ps -p "${pid:-0}" -o args= 2>/dev/null | grep -q -e sfs2x -e java || pid=""; `
+}
+
+// unixStopScript stops the server in the pidfile of sfsDir together with its
+// child JVM, or else whatever listens on the health port using lsof or
+// fuser. Each
+// step waits for the processes to exit before escalating to the next: the
+// graceful sfs2x-service stop for a server sfdeploy did not start, SIGTERM,
+// then SIGKILL.
+func unixStopScript(config *Config, sfsDir string) string {
+	service := shellQuote(sfsDir + "/sfs2x-service")
+	port := strconv.Itoa(healthPort(config))
+	seconds := func(d time.Duration) string { return strconv.Itoa(int(d.Seconds())) }
+	return unixPidScript(shellQuote(sfsDir+"/"+serverPidFile)) +
+		`if [ -n "$pid" ]; then pids="$pid $(pgrep -P $pid 2>/dev/null | tr '\n' ' ')"; from="from ` + serverPidFile + `"; ` +
+		`else pids=$(lsof -t -iTCP:` + port + ` -sTCP:LISTEN 2>/dev/null || fuser ` + port + `/tcp 2>/dev/null); from="using port ` + port + `"; fi; ` +
+		`rm -f ` + shellQuote(sfsDir+"/"+serverPidFile) + `; ` +
+		`alive() { for p in $pids; do kill -0 $p 2>/dev/null && return 0; done; return 1; }; ` +
+		`wait_for() { i=0; while [ $i -lt $1 ] && alive; do sleep 1; i=$((i+1)); done; ! alive; }; ` +
+		`if [ -n "$pids" ]; then stopped=; ` +
+		`if [ -z "$pid" ] && [ -x ` + service + ` ]; then echo "Requesting shutdown of" $pids "$from with sfs2x-service stop"; ` + service + ` stop > /dev/null 2>&1; wait_for ` + seconds(shutdownWait(config)) + ` && stopped=1; fi; ` +
+		`if [ -z "$stopped" ]; then echo "Sending SIGTERM to" $pids "$from"; kill $pids 2>/dev/null; wait_for ` + seconds(shutdownGrace(config)) + ` && stopped=1; fi; ` +
+		`if [ -z "$stopped" ]; then echo "Still running after ` + shutdownGrace(config).String() + `, sending SIGKILL to" $pids; kill -9 $pids 2>/dev/null; wait_for ` + seconds(killWait(config)) + ` && stopped=1; fi; ` +
+		`[ -z "$stopped" ] && echo "Could not stop" $pids; fi; true`
+}
+
+// printStopOutput prints the steps unixStopScript logged.
+func printStopOutput(output []byte) {
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line != "" {
+			fmt.Printf("🔫 %s\n", line)
+		}
+	}
+}
+
+// unixStartScript starts the launcher in the background from the SFS2X
+// directory, sending console output to logs/sfdeploy-console.log and its PID
+// to the pidfile. It refuses to start while the server in the pidfile still
+// runs, rather than starting a second instance.
+func unixStartScript(launcher string) string {
+	if launcher == "sfs2x-service" {
+		return "./sfs2x-service start"
+	}
+	return unixPidScript(serverPidFile) +
+		`if [ -n "$pid" ]; then echo "SmartFox is still running as PID $pid"; exit 1; fi; ` +
+		"mkdir -p logs && { nohup ./" + launcher + " > logs/sfdeploy-console.log 2>&1 < /dev/null & echo $! > " + serverPidFile + "; }"
+}
+
+// stopLocalServer stops a local SmartFox before its files are replaced,
+// through systemd or the Windows service when it is managed by one.
+func stopLocalServer(config *Config) {
+	if share, ok := targetShare(config); ok {
+		stopShareServer(config, share)
+		return
+	}
+	if useSystemd(config) {
+		stopSystemd(config)
+		return
+	}
+
+	if service := findWindowsService(config); service != "" {
+		tprintf("🔍 Stopping Windows service %s...\n", service)
+		if err := stopWindowsService(config, service); err != nil {
+			tprintf("⚠️ Warning: %v\n", err)
+		}
+		return
+	}
+
+	findAndStoreSmartFoxCmdWindow(config)
+
+	tprintf("🔍 Killing processes on port %d...\n", healthPort(config))
+	killServerPort(config)
+}
+
+// localServerAddr is the health port of a SmartFox on this machine.
+func localServerAddr(config *Config) string {
+	return net.JoinHostPort("127.0.0.1", strconv.Itoa(healthPort(config)))
+}
+
+// killServerPort stops whatever listens on the health port of this machine.
+func killServerPort(config *Config) {
+	if runtime.GOOS != "windows" {
+		output, _ := newCommand("sh", "-c", unixStopScript(config, filepath.Join(config.TargetDir, "SFS2X"))).CombinedOutput()
+		printStopOutput(output)
+		return
+	}
+
+	cmd := newCommand("netstat", "-ano")
+	output, err := cmd.Output()
+	if err != nil {
+		return
+	}
+
+	var pids []string
+	portSuffix := fmt.Sprintf(":%d ", healthPort(config))
+	lines := strings.Split(string(output), "\n")
+	for _, line := range lines {
+		if strings.Contains(line, portSuffix) && strings.Contains(line, "LISTENING") {
+			parts := strings.Fields(line)
+			if len(parts) >= 5 && !slices.Contains(pids, parts[len(parts)-1]) {
+				pids = append(pids, parts[len(parts)-1])
+			}
+		}
+	}
+	if len(pids) == 0 {
+		return
+	}
+
+	stopped := func() bool { return !tcpReachable(localServerAddr(config)) }
+	taskkill := func(force bool) {
+		for _, pid := range pids {
+			args := []string{"/PID", pid}
+			if force {
+				args = append(args, "/T", "/F")
+			}
+			newCommand("taskkill", args...).Run()
+		}
+	}
+
+	// Without /F taskkill asks the process to close, like closing its window
+	tprintf("🔫 Asking process %s using port %d to exit\n", strings.Join(pids, " "), healthPort(config))
+	taskkill(false)
+	if grace := shutdownGrace(config); grace > 0 {
+		tprintf("⏳ Waiting up to %s for the server to exit...\n", grace)
+	}
+	if waitUntil(time.Now().Add(shutdownGrace(config)), stopped) {
+		return
+	}
+
+	tprintf("🔫 Still running after %s, force killing process %s\n", shutdownGrace(config), strings.Join(pids, " "))
+	taskkill(true)
+	if !waitUntil(time.Now().Add(killWait(config)), stopped) {
+		tprintf("⚠️ Warning: Port %d is still in use after force killing process %s\n", healthPort(config), strings.Join(pids, " "))
+	}
+}
+
+// windowAlive reports whether pid is a running cmd.exe.
+func windowAlive(pid string) bool {
+	output, err := newCommand("tasklist", "/fi", fmt.Sprintf("PID eq %s", pid), "/fo", "csv").Output()
+	return err == nil && strings.Contains(string(output), "cmd.exe")
+}
+
+func findAndStoreSmartFoxCmdWindow(config *Config) {
+	if runtime.GOOS != "windows" {
+		return
+	}
+
+	if data, err := os.ReadFile(filepath.Join(config.TargetDir, "SFS2X", serverPidFile)); err == nil {
+		if pid := strings.TrimSpace(string(data)); pid != "" && windowAlive(pid) {
+			smartFoxCmdPid = pid
+			tprintf("✅ Found SmartFox CMD window PID: %s (from %s)\n", smartFoxCmdPid, serverPidFile)
+			return
+		}
+	}
+
+	tprintln("🔍 Searching all CMD windows for SmartFox...")
+
+	portSuffix := fmt.Sprintf(":%d ", healthPort(config))
+	javaCmd := newCommand("wmic", "process", "where", "name='java.exe'", "get", "ProcessId,ParentProcessId,CommandLine", "/format:csv")
+	javaOutput, err := javaCmd.Output()
+	if err == nil {
+		javaLines := strings.Split(string(javaOutput), "\n")
+		for _, javaLine := range javaLines {
+			if strings.Contains(javaLine, "java.exe") {
+				tprintf("🔍 Found Java process: %s\n", strings.TrimSpace(javaLine))
+
+				parts := strings.Split(javaLine, ",")
+				if len(parts) >= 3 {
+					javaPid := strings.TrimSpace(parts[len(parts)-1])
+					parentPid := strings.TrimSpace(parts[len(parts)-2])
+
+					netstatCmd := newCommand("netstat", "-ano")
+					netstatOutput, err := netstatCmd.Output()
+					if err == nil {
+						netstatLines := strings.Split(string(netstatOutput), "\n")
+						for _, netLine := range netstatLines {
+							if strings.Contains(netLine, portSuffix) && strings.Contains(netLine, "LISTENING") && strings.Contains(netLine, javaPid) {
+								tprintf("🎯 Found SmartFox Java process PID: %s with parent: %s\n", javaPid, parentPid)
+
+								if windowAlive(parentPid) {
+									smartFoxCmdPid = parentPid
+									tprintf("✅ Found SmartFox CMD window PID: %s (parent of Java process)\n", smartFoxCmdPid)
+									return
+								}
+								break
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	if smartFoxCmdPid == "" {
+		tprintln("⚠️ Could not find SmartFox CMD window - will create new one")
+	}
+}
+
+func restartServer(config *Config) bool {
+	tprintln("🔄 Phase 5: Restarting SmartFox Server")
+
+	if !applyJVMOptions(config) {
+		return false
+	}
+
+	if config.Reloadable && adminEndpoint(config) == "" {
+		tprintln("⚠️ Warning: reloadable is set, but reloading needs admin_port or admin_url; restarting the server")
+	}
+	if useAdminReload(config) {
+		if *flagDryRun {
+			tprintf("[dry-run] Would ask %s to reload %s, restarting the server only if that fails\n", adminEndpoint(config), config.ExtensionFolder)
+		} else if reloadViaAdmin(config) {
+			return true
+		} else {
+			tprintln("⚠️ Falling back to a restart")
+		}
+	}
+
+	if *flagDryRun && config.RestartCommand != "" {
+		return runRestartCommand(config)
+	}
+	if *flagDryRun {
+		return planRestart(config)
+	}
+
+	markRestart(config)
+
+	if !startServer(config) {
+		return false
+	}
+
+	if wait := startupWait(config); wait > 0 {
+		tprintf("⏳ Giving the server %s to start...\n", wait)
+		waitWithCountdown("⏳ Starting", wait)
+		fmt.Println()
+	}
+	return true
+}
+
+// keepRunningReason says why the deploy phase leaves the server running
+// instead of stopping it before the files are replaced, or is empty.
+func keepRunningReason(config *Config) string {
+	switch {
+	case *flagSkipRestart:
+		return "--skip-restart"
+	case config.RestartCommand != "":
+		return "restart_command configured"
+	case useAdminRestart(config):
+		return "Admin API restart configured"
+	}
+	return ""
+}
+
+// startServer stops and starts SmartFox with whichever mechanism the target
+// uses, or restart_command.
+func startServer(config *Config) bool {
+	if config.RestartCommand != "" {
+		return runRestartCommand(config)
+	}
+	if useAdminRestart(config) && restartViaAdmin(config) {
+		return true
+	}
+
+	if share, ok := targetShare(config); ok {
+		return restartShare(config, share)
+	}
+
+	if useSystemd(config) {
+		return restartSystemd(config)
+	}
+
+	if remote, ok := parseRemoteTarget(config.TargetDir); ok {
+		return restartRemote(config, remote)
+	}
+
+	if d, ok := parseDockerTarget(config.TargetDir); ok {
+		return restartDocker(config, d)
+	}
+
+	if runtime.GOOS != "windows" {
+		return restartUnix(config)
+	}
+
+	if service := findWindowsService(config); service != "" {
+		return restartWindowsService(config, service)
+	}
+
+	startScript := filepath.Join(config.TargetDir, "SFS2X", "sfs2x.bat")
+
+	if !stopRunningServer(config) {
+		return false
+	}
+
+	tprintln("▶️ Creating new CMD window for SmartFox server...")
+
+	logBat := filepath.Join(config.TargetDir, "sfs_with_logs.bat")
+	logContent := fmt.Sprintf(`@echo off
+title SmartFox Server 2X - Hot Deploy
+echo.
+echo ========================================
+echo   SmartFox Server 2X - Hot Deploy
+echo   Starting server with logs...
+echo ========================================
+echo.
+cd /d "%s"
+call "%s"
+echo.
+echo ========================================
+echo   Server stopped. Press any key to close.
+echo ========================================
+pause
+`, filepath.Join(config.TargetDir, "SFS2X"), startScript)
+
+	if err := os.WriteFile(logBat, []byte(logContent), 0644); err != nil {
+		tprintf("❌ Failed to create log batch file: %v\n", err)
+		return false
+	}
+
+	// Start-Process reports the PID of the new window for the pidfile
+	script := fmt.Sprintf("(Start-Process cmd -ArgumentList '/k', '\"%s\"' -WorkingDirectory '%s' -PassThru).Id",
+		strings.ReplaceAll(logBat, "'", "''"), strings.ReplaceAll(filepath.Dir(logBat), "'", "''"))
+	output, err := newCommand("powershell", "-NoProfile", "-Command", script).Output()
+	if err != nil {
+		tprintf("❌ Failed to start server: %v\n", err)
+		return false
+	}
+	if pid := strings.TrimSpace(string(output)); pid != "" {
+		smartFoxCmdPid = pid
+		if err := os.WriteFile(filepath.Join(config.TargetDir, "SFS2X", serverPidFile), []byte(pid+"\n"), 0644); err != nil {
+			tprintf("⚠️ Warning: Could not write %s: %v\n", serverPidFile, err)
+		}
+	}
+
+	go func() {
+		time.Sleep(5 * time.Second)
+		os.Remove(logBat)
+	}()
+
+	tprintln("✅ Server started in new CMD window with logs")
+	tprintln("📝 Check the new CMD window for server logs and status")
+	fmt.Println()
+
+	return true
+}
+
+// stopRunningServer stops the local SmartFox a restart replaces, whether or
+// not a deploy stopped it already, and refuses to go on while the health
+// port is still taken, so a restart never starts a second server.
+func stopRunningServer(config *Config) bool {
+	if runtime.GOOS == "windows" {
+		// A restart without a deploy before it has not looked for the window yet
+		if smartFoxCmdPid == "" {
+			findAndStoreSmartFoxCmdWindow(config)
+		}
+
+		if smartFoxCmdPid != "" {
+			tprintf("🔍 Checking if stored CMD window PID %s is still alive...\n", smartFoxCmdPid)
+
+			if windowAlive(smartFoxCmdPid) {
+				tprintln("✅ Found existing SmartFox CMD window")
+				tprintln("🔄 Since we need to see logs, creating new CMD window...")
+
+				// /T takes the server JVM running in the window down with it
+				newCommand("taskkill", "/PID", smartFoxCmdPid, "/T", "/F").Run()
+				tprintf("🗑️ Closed old CMD window PID: %s\n", smartFoxCmdPid)
+			}
+
+			smartFoxCmdPid = ""
+		}
+	}
+
+	if runtime.GOOS != "windows" || tcpReachable(localServerAddr(config)) {
+		tprintln("🔍 Stopping running SmartFox server...")
+		killServerPort(config)
+	}
+	if tcpReachable(localServerAddr(config)) {
+		tprintf("❌ SmartFox is still listening on port %d, not starting a second instance\n", healthPort(config))
+		return false
+	}
+	markServerStopped(config)
+	return checkServerPorts(config)
+}
+
+func restartUnix(config *Config) bool {
+	sfsDir := filepath.Join(config.TargetDir, "SFS2X")
+
+	launcher := findLauncher(sfsDir)
+	if launcher == "" {
+		tprintf("❌ No SmartFox launcher found in %s\n", sfsDir)
+		return false
+	}
+
+	if !stopRunningServer(config) {
+		return false
+	}
+
+	tprintf("▶️ Starting SmartFox server with %s...\n", launcher)
+
+	cmd := newCommand("sh", "-c", unixStartScript(launcher))
+	cmd.Dir = sfsDir
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		tprintf("❌ Failed to start server: %v %s\n", err, strings.TrimSpace(string(output)))
+		return false
+	}
+
+	tprintln("✅ Server started in the background")
+	if launcher != "sfs2x-service" {
+		tprintf("📝 Follow the server logs with: tail -f %s\n", filepath.Join(sfsDir, "logs", "sfdeploy-console.log"))
+	}
+	fmt.Println()
+
+	return true
+}
@@ -0,0 +1,366 @@
+package sfdeploy
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// SFSObject type IDs of the SFS2X binary protocol.
+const (
+	sfsNull byte = iota
+	sfsBool
+	sfsByte
+	sfsShort
+	sfsInt
+	sfsLong
+	sfsFloat
+	sfsDouble
+	sfsString
+	sfsBoolArray
+	sfsByteArray
+	sfsShortArray
+	sfsIntArray
+	sfsLongArray
+	sfsFloatArray
+	sfsDoubleArray
+	sfsStringArray
+	sfsArray
+	sfsObject
+	sfsClass
+	sfsText
+)
+
+// Packet header bits.
+const (
+	sfsBinaryFlag     = 0x80
+	sfsEncryptedFlag  = 0x40
+	sfsCompressedFlag = 0x20
+	sfsBigSizeFlag    = 0x08
+)
+
+// sfsMessage is a protocol message: a controller and action ID with the
+// request or response parameters.
+type sfsMessage struct {
+	Controller byte
+	Action     int16
+	Params     map[string]any
+}
+
+// encodeSFSObject serializes obj. Values are typed by their Go type: byte,
+// int16, int32, int64, float64, string, bool, nil, map[string]any and []any.
+func encodeSFSObject(buf *bytes.Buffer, obj map[string]any) error {
+	buf.WriteByte(sfsObject)
+	binary.Write(buf, binary.BigEndian, int16(len(obj)))
+
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		binary.Write(buf, binary.BigEndian, int16(len(key)))
+		buf.WriteString(key)
+		if err := encodeSFSValue(buf, obj[key]); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func encodeSFSValue(buf *bytes.Buffer, value any) error {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteByte(sfsNull)
+	case bool:
+		buf.WriteByte(sfsBool)
+		if v {
+			buf.WriteByte(1)
+		} else {
+			buf.WriteByte(0)
+		}
+	case byte:
+		buf.WriteByte(sfsByte)
+		buf.WriteByte(v)
+	case int16:
+		buf.WriteByte(sfsShort)
+		binary.Write(buf, binary.BigEndian, v)
+	case int32:
+		buf.WriteByte(sfsInt)
+		binary.Write(buf, binary.BigEndian, v)
+	case int64:
+		buf.WriteByte(sfsLong)
+		binary.Write(buf, binary.BigEndian, v)
+	case float64:
+		buf.WriteByte(sfsDouble)
+		binary.Write(buf, binary.BigEndian, v)
+	case string:
+		if len(v) > math.MaxInt16 {
+			buf.WriteByte(sfsText)
+			binary.Write(buf, binary.BigEndian, int32(len(v)))
+		} else {
+			buf.WriteByte(sfsString)
+			binary.Write(buf, binary.BigEndian, int16(len(v)))
+		}
+		buf.WriteString(v)
+	case map[string]any:
+		return encodeSFSObject(buf, v)
+	case []any:
+		buf.WriteByte(sfsArray)
+		binary.Write(buf, binary.BigEndian, int16(len(v)))
+		for i, item := range v {
+			if err := encodeSFSValue(buf, item); err != nil {
+				return fmt.Errorf("[%d]: %w", i, err)
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported type %T", value)
+	}
+	return nil
+}
+
+// sfsParam converts a value decoded from JSON config to the type it is sent
+// as: whole numbers become ints, other numbers doubles.
+func sfsParam(value any) any {
+	switch v := value.(type) {
+	case float64:
+		if v == math.Trunc(v) && v >= math.MinInt32 && v <= math.MaxInt32 {
+			return int32(v)
+		}
+		if v == math.Trunc(v) && math.Abs(v) < 1<<63 {
+			return int64(v)
+		}
+		return v
+	case map[string]any:
+		obj := make(map[string]any, len(v))
+		for key, item := range v {
+			obj[key] = sfsParam(item)
+		}
+		return obj
+	case []any:
+		arr := make([]any, len(v))
+		for i, item := range v {
+			arr[i] = sfsParam(item)
+		}
+		return arr
+	}
+	return value
+}
+
+// sfsReader decodes SFSObject data. Numbers decode to float64 and typed
+// arrays to []any, so decoded values compare directly with JSON values.
+type sfsReader struct {
+	r   *bytes.Reader
+	err error
+}
+
+func (d *sfsReader) read(v any) {
+	if d.err == nil {
+		d.err = binary.Read(d.r, binary.BigEndian, v)
+	}
+}
+
+func (d *sfsReader) bytes(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || n > d.r.Len() {
+		d.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	b := make([]byte, n)
+	_, d.err = io.ReadFull(d.r, b)
+	return b
+}
+
+func (d *sfsReader) short() int {
+	var n int16
+	d.read(&n)
+	return int(n)
+}
+
+func (d *sfsReader) value() any {
+	var kind byte
+	d.read(&kind)
+	if d.err != nil {
+		return nil
+	}
+	return d.typed(kind)
+}
+
+// typed decodes a value of the given type whose type byte has been read.
+func (d *sfsReader) typed(kind byte) any {
+	switch kind {
+	case sfsNull:
+		return nil
+	case sfsBool:
+		var v byte
+		d.read(&v)
+		return v != 0
+	case sfsByte:
+		var v int8
+		d.read(&v)
+		return float64(v)
+	case sfsShort:
+		return float64(d.short())
+	case sfsInt:
+		var v int32
+		d.read(&v)
+		return float64(v)
+	case sfsLong:
+		var v int64
+		d.read(&v)
+		return float64(v)
+	case sfsFloat:
+		var v float32
+		d.read(&v)
+		return float64(v)
+	case sfsDouble:
+		var v float64
+		d.read(&v)
+		return v
+	case sfsString:
+		return string(d.bytes(d.short()))
+	case sfsText:
+		var n int32
+		d.read(&n)
+		return string(d.bytes(int(n)))
+	case sfsByteArray:
+		var n int32
+		d.read(&n)
+		arr := []any{}
+		for _, b := range d.bytes(int(n)) {
+			arr = append(arr, float64(int8(b)))
+		}
+		return arr
+	case sfsBoolArray, sfsShortArray, sfsIntArray, sfsLongArray, sfsFloatArray, sfsDoubleArray, sfsStringArray:
+		// A typed array is a count followed by untagged values of its element type
+		element := kind - sfsBoolArray + sfsBool
+		if kind == sfsStringArray {
+			element = sfsString
+		}
+		n := d.short()
+		arr := []any{}
+		for i := 0; i < n && d.err == nil; i++ {
+			arr = append(arr, d.typed(element))
+		}
+		return arr
+	case sfsArray:
+		n := d.short()
+		arr := []any{}
+		for i := 0; i < n && d.err == nil; i++ {
+			arr = append(arr, d.value())
+		}
+		return arr
+	case sfsObject:
+		n := d.short()
+		obj := map[string]any{}
+		for i := 0; i < n && d.err == nil; i++ {
+			key := string(d.bytes(d.short()))
+			obj[key] = d.value()
+		}
+		return obj
+	}
+
+	d.err = fmt.Errorf("unsupported SFSObject type %d", kind)
+	return nil
+}
+
+func decodeSFSObject(data []byte) (map[string]any, error) {
+	d := &sfsReader{r: bytes.NewReader(data)}
+	obj, ok := d.value().(map[string]any)
+	if d.err != nil {
+		return nil, d.err
+	}
+	if !ok {
+		return nil, fmt.Errorf("message is not an SFSObject")
+	}
+	return obj, nil
+}
+
+func writeSFSMessage(w io.Writer, msg sfsMessage) error {
+	var body bytes.Buffer
+	err := encodeSFSObject(&body, map[string]any{
+		"c": msg.Controller,
+		"a": msg.Action,
+		"p": msg.Params,
+	})
+	if err != nil {
+		return err
+	}
+
+	var packet bytes.Buffer
+	if body.Len() > math.MaxUint16 {
+		packet.WriteByte(sfsBinaryFlag | sfsBigSizeFlag)
+		binary.Write(&packet, binary.BigEndian, uint32(body.Len()))
+	} else {
+		packet.WriteByte(sfsBinaryFlag)
+		binary.Write(&packet, binary.BigEndian, uint16(body.Len()))
+	}
+	packet.Write(body.Bytes())
+
+	_, err = w.Write(packet.Bytes())
+	return err
+}
+
+func readSFSMessage(r io.Reader) (sfsMessage, error) {
+	var header [1]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return sfsMessage{}, err
+	}
+	if header[0]&sfsBinaryFlag == 0 {
+		return sfsMessage{}, fmt.Errorf("not an SFS2X binary packet (header 0x%02x)", header[0])
+	}
+	if header[0]&sfsEncryptedFlag != 0 {
+		return sfsMessage{}, fmt.Errorf("encrypted packets are not supported")
+	}
+
+	var size uint32
+	if header[0]&sfsBigSizeFlag != 0 {
+		if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+			return sfsMessage{}, err
+		}
+	} else {
+		var small uint16
+		if err := binary.Read(r, binary.BigEndian, &small); err != nil {
+			return sfsMessage{}, err
+		}
+		size = uint32(small)
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return sfsMessage{}, err
+	}
+	if header[0]&sfsCompressedFlag != 0 {
+		zr, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return sfsMessage{}, err
+		}
+		data, err = io.ReadAll(zr)
+		if err != nil {
+			return sfsMessage{}, err
+		}
+	}
+
+	obj, err := decodeSFSObject(data)
+	if err != nil {
+		return sfsMessage{}, err
+	}
+	msg := sfsMessage{Params: map[string]any{}}
+	if c, ok := obj["c"].(float64); ok {
+		msg.Controller = byte(c)
+	}
+	if a, ok := obj["a"].(float64); ok {
+		msg.Action = int16(a)
+	}
+	if p, ok := obj["p"].(map[string]any); ok {
+		msg.Params = p
+	}
+	return msg, nil
+}
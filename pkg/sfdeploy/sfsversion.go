@@ -0,0 +1,243 @@
+package sfdeploy
+
+import (
+	"archive/zip"
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// serverInfo is what could be found out about the SmartFox of a target.
+// Empty fields are unknown.
+type serverInfo struct {
+	Version     string
+	JavaVersion string
+	JavaMajor   int
+}
+
+func (s serverInfo) String() string {
+	version := s.Version
+	if version == "" {
+		version = "version unknown"
+	}
+	if s.JavaVersion == "" {
+		return version
+	}
+	return version + " on Java " + s.JavaVersion
+}
+
+// manifestVersionKeys are the manifest attributes tried for the version of
+// sfs2x.jar and sfs2x-core.jar.
+var manifestVersionKeys = []string{"Implementation-Version", "Bundle-Version", "Specification-Version"}
+
+// jarManifest returns the main attributes of the manifest of a JAR.
+func jarManifest(file string) (map[string]string, error) {
+	r, err := zip.OpenReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	f, err := r.Open("META-INF/MANIFEST.MF")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	attrs := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		// The main section ends at the first blank line
+		if line == "" {
+			break
+		}
+		if key, value, ok := strings.Cut(line, ":"); ok {
+			attrs[key] = strings.TrimSpace(value)
+		}
+	}
+	return attrs, scanner.Err()
+}
+
+// parseReleaseFile reads JAVA_VERSION from the release file of a JRE.
+func parseReleaseFile(data string) string {
+	for _, line := range strings.Split(data, "\n") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(line), "JAVA_VERSION="); ok {
+			return strings.Trim(value, `"`)
+		}
+	}
+	return ""
+}
+
+// javaVersionMajor maps "11.0.21" to 11 and the old "1.8.0_392" to 8.
+func javaVersionMajor(version string) int {
+	parts := strings.FieldsFunc(version, func(r rune) bool { return r == '.' || r == '_' || r == '+' || r == '-' })
+	if len(parts) == 0 {
+		return 0
+	}
+	major, _ := strconv.Atoi(parts[0])
+	if major == 1 && len(parts) > 1 {
+		major, _ = strconv.Atoi(parts[1])
+	}
+	return major
+}
+
+// detectServer reads the SFS2X version from the manifests in SFS2X/lib and
+// the Java version from the release file of the JRE bundled with SmartFox.
+func detectServer(config *Config) serverInfo {
+	var info serverInfo
+	libDir := serverLibDir(config)
+	for _, jar := range []string{"sfs2x.jar", "sfs2x-core.jar"} {
+		attrs, err := jarManifest(filepath.Join(libDir, jar))
+		if err != nil {
+			continue
+		}
+		for _, key := range manifestVersionKeys {
+			if attrs[key] != "" {
+				info.Version = attrs[key]
+				break
+			}
+		}
+		if info.Version != "" {
+			break
+		}
+	}
+
+	if data, err := readTargetFile(config, "jre/release"); err == nil {
+		info.JavaVersion = parseReleaseFile(string(data))
+		info.JavaMajor = javaVersionMajor(info.JavaVersion)
+	}
+	return info
+}
+
+// compileRelease is the Java version the class files are compiled for:
+// java_release, or else the version of the JDK. It is 0 when unknown.
+func compileRelease(config *Config) int {
+	if config.JavaRelease != "" {
+		return javaVersionMajor(config.JavaRelease)
+	}
+	if config.JavaPath == "" || !hasJavac(config.JavaPath) {
+		return 0
+	}
+	major, _ := javacMajor(javaTool(config, "javac"))
+	return major
+}
+
+var smartFoxImport = regexp.MustCompile(`(?m)^\s*import\s+(static\s+)?(com\.smartfoxserver\.[\w.]+?)(\.\*)?\s*;`)
+
+// sourceServerAPIs lists the SmartFox classes and packages the sources
+// import, as JAR entry names: "com/smartfoxserver/v2/entities/User.class" or
+// "com/smartfoxserver/v2/entities/" for a wildcard import.
+func sourceServerAPIs(config *Config) map[string][]string {
+	apis := map[string][]string{}
+	for _, file := range projectJavaFiles(config) {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		for _, m := range smartFoxImport.FindAllStringSubmatch(string(data), -1) {
+			name := m[2]
+			// A static import names a member of the class
+			if m[1] != "" && m[3] == "" {
+				name = name[:strings.LastIndex(name, ".")]
+			}
+			entry := strings.ReplaceAll(name, ".", "/")
+			if m[3] != "" && m[1] == "" {
+				entry += "/"
+			} else {
+				entry += ".class"
+			}
+			apis[entry] = append(apis[entry], file)
+		}
+	}
+	return apis
+}
+
+// serverEntries lists the entries of every JAR in dir, with the folders
+// each entry is in.
+func serverEntries(dir string) map[string]bool {
+	entries := map[string]bool{}
+	jars, _ := filepath.Glob(filepath.Join(dir, "*.jar"))
+	for _, jar := range jars {
+		r, err := zip.OpenReader(jar)
+		if err != nil {
+			continue
+		}
+		for _, f := range r.File {
+			entries[f.Name] = true
+			if i := strings.LastIndex(f.Name, "/"); i > 0 {
+				entries[f.Name[:i+1]] = true
+			}
+		}
+		r.Close()
+	}
+	return entries
+}
+
+// hasEntry looks entry up, also as a nested class: the import of
+// a/B/C.class may be the class a/B$C.class.
+func hasEntry(entries map[string]bool, entry string) bool {
+	for {
+		if entries[entry] {
+			return true
+		}
+		i := strings.LastIndex(entry, "/")
+		if i < 0 || strings.HasSuffix(entry, "/") {
+			return false
+		}
+		entry = entry[:i] + "$" + entry[i+1:]
+	}
+}
+
+// compatWarnings compares the build with the server it is deployed to: a
+// class file version the server's JVM cannot load, and imported SmartFox
+// APIs that the server's libraries do not have, as when compiling against
+// a newer sfs2x.jar than the server runs.
+func compatWarnings(config *Config, info serverInfo) []string {
+	var warnings []string
+	if release := compileRelease(config); release > 0 && info.JavaMajor > 0 && release > info.JavaMajor {
+		warnings = append(warnings, fmt.Sprintf(
+			"Compiling for Java %d, but the server runs Java %s and cannot load the classes; set java_release to %d",
+			release, info.JavaVersion, info.JavaMajor))
+	}
+
+	entries := serverEntries(serverLibDir(config))
+	if len(entries) == 0 {
+		return warnings
+	}
+	apis := sourceServerAPIs(config)
+	var missing []string
+	for entry := range apis {
+		if !hasEntry(entries, entry) {
+			missing = append(missing, entry)
+		}
+	}
+	sort.Strings(missing)
+	for _, entry := range missing {
+		name := strings.ReplaceAll(strings.TrimSuffix(strings.TrimSuffix(entry, ".class"), "/"), "/", ".")
+		rel, err := filepath.Rel(config.SourceDir, apis[entry][0])
+		if err != nil {
+			rel = apis[entry][0]
+		}
+		warnings = append(warnings, fmt.Sprintf("%s, imported by %s, is not in this server's libraries (SFS2X %s)", name, rel, info))
+	}
+	return warnings
+}
+
+// checkServerCompatibility prints the version of the target server and
+// warns about builds that would crash it after the restart. It never fails
+// the build, as the detection can be wrong for unusual installs.
+func checkServerCompatibility(config *Config) {
+	info := detectServer(config)
+	if info.Version != "" || info.JavaVersion != "" {
+		tprintf("🔎 SmartFox %s\n", info)
+	}
+	for _, warning := range compatWarnings(config, info) {
+		tprintf("⚠️ Warning: %s\n", warning)
+	}
+}
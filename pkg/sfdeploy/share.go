@@ -0,0 +1,290 @@
+package sfdeploy
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultNetworkRetry = 60
+	networkRetryInitial = time.Second
+	networkRetryMax     = 10 * time.Second
+)
+
+// Windows error codes for a share that dropped or is briefly unreachable.
+const (
+	errorBadNetpath         syscall.Errno = 53
+	errorNetworkBusy        syscall.Errno = 54
+	errorUnexpNetErr        syscall.Errno = 59
+	errorNetnameDeleted     syscall.Errno = 64
+	errorBadNetName         syscall.Errno = 67
+	errorSemTimeout         syscall.Errno = 121
+	errorNetworkUnreachable syscall.Errno = 1231
+	errorHostUnreachable    syscall.Errno = 1232
+	errorConnectionAborted  syscall.Errno = 1236
+)
+
+// networkFilesystems are the mount types of network shares outside Windows.
+var networkFilesystems = map[string]bool{"cifs": true, "smb3": true, "smbfs": true, "nfs": true, "nfs4": true}
+
+// networkShare is a target_dir on a network share: a UNC path such as
+// \\gameserver\SFS2X_share, a mapped network drive or, outside Windows, an
+// SMB or NFS mount. Host is the file server, which is assumed to be the
+// machine SmartFox runs on.
+type networkShare struct {
+	Host string
+	Path string
+}
+
+func (s networkShare) String() string {
+	return s.Path
+}
+
+// parseUNC splits a UNC path, also written with forward slashes or as
+// \\?\UNC\host\share, into its server and share.
+func parseUNC(dir string) (networkShare, bool) {
+	p := strings.ReplaceAll(dir, "/", `\`)
+	if rest, ok := strings.CutPrefix(p, `\\?\UNC\`); ok {
+		p = `\\` + rest
+	}
+	if !strings.HasPrefix(p, `\\`) || strings.HasPrefix(p, `\\?\`) || strings.HasPrefix(p, `\\.\`) {
+		return networkShare{}, false
+	}
+	host, rest, _ := strings.Cut(p[2:], `\`)
+	share, _, _ := strings.Cut(rest, `\`)
+	if host == "" || share == "" {
+		return networkShare{}, false
+	}
+	return networkShare{Host: host, Path: `\\` + host + `\` + share}, true
+}
+
+var netUseLine = regexp.MustCompile(`(?m)\b([A-Za-z]:)\s+(\\\\\S+)`)
+
+var (
+	networkMountsOnce sync.Once
+	networkMounts     map[string]networkShare
+
+	shareMu    sync.Mutex
+	shareCache = map[string]*networkShare{}
+)
+
+// loadNetworkMounts maps the mapped drives ("Z:") on Windows, or the mount
+// points of network filesystems elsewhere, to their shares.
+func loadNetworkMounts() map[string]networkShare {
+	mounts := map[string]networkShare{}
+	if runtime.GOOS == "windows" {
+		output, _ := newCommand("net", "use").Output()
+		for _, m := range netUseLine.FindAllStringSubmatch(string(output), -1) {
+			if share, ok := parseUNC(m[2]); ok {
+				mounts[strings.ToUpper(m[1])] = share
+			}
+		}
+		return mounts
+	}
+
+	// Linux lists the mounts in /proc/mounts, macOS and the BSDs only
+	// through mount: "//user@gameserver/share on /Volumes/share (smbfs, ...)"
+	var lines [][3]string
+	if file, err := os.Open("/proc/mounts"); err == nil {
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			if fields := strings.Fields(scanner.Text()); len(fields) >= 3 {
+				lines = append(lines, [3]string{fields[0], fields[1], fields[2]})
+			}
+		}
+		file.Close()
+	} else if output, err := newCommand("mount").Output(); err == nil {
+		mountLine := regexp.MustCompile(`^(\S+) on (.+) \((\w+)`)
+		for _, line := range strings.Split(string(output), "\n") {
+			if m := mountLine.FindStringSubmatch(line); m != nil {
+				lines = append(lines, [3]string{m[1], m[2], m[3]})
+			}
+		}
+	}
+	for _, line := range lines {
+		source, point, fstype := line[0], strings.ReplaceAll(line[1], `\040`, " "), line[2]
+		if !networkFilesystems[fstype] {
+			continue
+		}
+		var host string
+		if share, ok := parseUNC(source); ok {
+			host = share.Host
+		} else {
+			host, _, _ = strings.Cut(source, ":")
+		}
+		if at := strings.LastIndex(host, "@"); at >= 0 {
+			host = host[at+1:]
+		}
+		mounts[point] = networkShare{Host: host, Path: source}
+	}
+	return mounts
+}
+
+// findNetworkShare returns the network share dir is on, if any. Outside
+// Windows a path starting with // is local.
+func findNetworkShare(dir string) (networkShare, bool) {
+	if share, ok := parseUNC(dir); ok && runtime.GOOS == "windows" {
+		return share, true
+	}
+	if _, ok := parseShellTarget(dir); ok || dir == "" {
+		return networkShare{}, false
+	}
+
+	shareMu.Lock()
+	defer shareMu.Unlock()
+	if cached, ok := shareCache[dir]; ok {
+		if cached == nil {
+			return networkShare{}, false
+		}
+		return *cached, true
+	}
+	networkMountsOnce.Do(func() { networkMounts = loadNetworkMounts() })
+
+	abs := absPath(dir)
+	var found *networkShare
+	if runtime.GOOS == "windows" {
+		if share, ok := networkMounts[strings.ToUpper(filepath.VolumeName(abs))]; ok {
+			found = &share
+		}
+	} else {
+		// The deepest mount point holding dir
+		best := ""
+		for point, share := range networkMounts {
+			if (abs == point || strings.HasPrefix(abs, strings.TrimSuffix(point, "/")+"/")) && len(point) > len(best) {
+				best, found = point, &share
+			}
+		}
+	}
+	shareCache[dir] = found
+	if found == nil {
+		return networkShare{}, false
+	}
+	return *found, true
+}
+
+// targetShare returns the network share of a local target_dir.
+func targetShare(config *Config) (networkShare, bool) {
+	return findNetworkShare(config.TargetDir)
+}
+
+// isNetworkError reports whether err is a network share dropping or timing
+// out, which usually recovers once the client reconnects.
+func isNetworkError(err error) bool {
+	var errno syscall.Errno
+	if !errors.As(err, &errno) {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		switch errno {
+		case errorBadNetpath, errorNetworkBusy, errorUnexpNetErr, errorNetnameDeleted, errorBadNetName,
+			errorSemTimeout, errorNetworkUnreachable, errorHostUnreachable, errorConnectionAborted:
+			return true
+		}
+		return false
+	}
+	switch errno {
+	case syscall.ETIMEDOUT, syscall.ECONNRESET, syscall.ECONNABORTED, syscall.EHOSTDOWN,
+		syscall.EHOSTUNREACH, syscall.ENETUNREACH, syscall.ENETDOWN, syscall.ESTALE:
+		return true
+	}
+	return false
+}
+
+// networkRetry is how long to keep retrying after a network error. A
+// negative network_retry fails right away.
+func networkRetry(config *Config) time.Duration {
+	if config.NetworkRetry == 0 {
+		return defaultNetworkRetry * time.Second
+	}
+	return time.Duration(config.NetworkRetry) * time.Second
+}
+
+// retryNetwork retries op, which failed with err, with exponential backoff
+// for as long as it fails with network errors.
+func retryNetwork(config *Config, path string, op func() error, err error) error {
+	limit := networkRetry(config)
+	if limit < 0 {
+		return err
+	}
+
+	tprintf("📡 %s is unreachable: %v\n", path, err)
+	tprintf("⏳ Retrying for up to %s...\n", limit)
+
+	deadline := time.Now().Add(limit)
+	delay := networkRetryInitial
+	for time.Now().Add(delay).Before(deadline) {
+		time.Sleep(delay)
+		if err = op(); err == nil {
+			tprintf("📡 %s is reachable again\n", path)
+			return nil
+		}
+		if !isNetworkError(err) {
+			return err
+		}
+		delay = min(delay*2, networkRetryMax)
+	}
+	return fmt.Errorf("%w (still unreachable after %s)", err, limit)
+}
+
+// checkShare makes sure the share of target_dir can be reached, waiting
+// out a dropped connection, before the target is validated.
+func checkShare(config *Config, share networkShare) bool {
+	tprintf("📡 Target is on network share %s (server %s)\n", share, share.Host)
+	op := func() error {
+		_, err := os.Stat(config.TargetDir)
+		return err
+	}
+	err := op()
+	if err != nil && isNetworkError(err) {
+		err = retryNetwork(config, config.TargetDir, op, err)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		tprintf("❌ Network share %s is not reachable: %v\n", share, err)
+		return false
+	}
+	return true
+}
+
+// shareRestartHint explains how a server behind a share can be restarted.
+func shareRestartHint(share networkShare) string {
+	hint := "set restart_command or admin_port"
+	if runtime.GOOS == "windows" {
+		hint += ", or install SmartFox as a Windows service on " + share.Host
+	}
+	return hint
+}
+
+// stopShareServer stops SmartFox on the server of a share through its
+// Windows service. Processes on the share's server cannot be killed from
+// here, so without a service the server is left running for the restart.
+func stopShareServer(config *Config, share networkShare) {
+	if service := findWindowsService(config); service != "" {
+		tprintf("🔍 Stopping Windows service %s on %s...\n", service, share.Host)
+		if err := stopWindowsService(config, service); err != nil {
+			tprintf("⚠️ Warning: %v\n", err)
+		}
+		return
+	}
+	tprintf("⚠️ Warning: Cannot stop SmartFox on %s from here; %s\n", share.Host, shareRestartHint(share))
+}
+
+// restartShare restarts SmartFox on the server of a share through its
+// Windows service, the only mechanism that reaches it besides
+// restart_command and the Admin API.
+func restartShare(config *Config, share networkShare) bool {
+	if service := findWindowsService(config); service != "" {
+		return restartWindowsService(config, service)
+	}
+	tprintf("❌ %s is on network share %s, so SmartFox on %s cannot be restarted from here; %s\n",
+		config.TargetDir, share, share.Host, shareRestartHint(share))
+	return false
+}
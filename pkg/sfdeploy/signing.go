@@ -0,0 +1,98 @@
+package sfdeploy
+
+import (
+	"os"
+	"strings"
+)
+
+// signConfig is the keystore the built JARs are signed with for servers
+// that only load signed code. Passwords are usually secret: references.
+type signConfig struct {
+	Keystore  string `json:"keystore"`
+	Alias     string `json:"alias"`
+	StorePass string `json:"storepass"`
+	KeyPass   string `json:"keypass"`
+	StoreType string `json:"storetype"`
+	TSA       string `json:"tsa"`
+}
+
+// The passwords reach jarsigner through the environment instead of its
+// command line, where other users could read them
+const (
+	storePassEnv = "SFDEPLOY_STOREPASS"
+	keyPassEnv   = "SFDEPLOY_KEYPASS"
+)
+
+func signingEnabled(config *Config) bool {
+	return config.Signing.Keystore != ""
+}
+
+// jarsignerArgs are the options of jarsigner for jarFile; the JAR and alias
+// come last.
+func jarsignerArgs(config *Config, jarFile string) []string {
+	sign := config.Signing
+	args := []string{"-keystore", sign.Keystore}
+	if sign.StoreType != "" {
+		args = append(args, "-storetype", sign.StoreType)
+	}
+	if sign.StorePass != "" {
+		args = append(args, "-storepass:env", storePassEnv)
+	}
+	if sign.KeyPass != "" {
+		args = append(args, "-keypass:env", keyPassEnv)
+	}
+	if sign.TSA != "" {
+		args = append(args, "-tsa", sign.TSA)
+	}
+	return append(args, jarFile, sign.Alias)
+}
+
+// signJar signs a JAR the build created when signing is configured. JARs
+// ProGuard rewrites are signed after obfuscation instead.
+func signJar(config *Config, jarFile, name string) bool {
+	if proguardEnabled(config) {
+		return true
+	}
+	return runJarsigner(config, jarFile, name)
+}
+
+func runJarsigner(config *Config, jarFile, name string) bool {
+	if !signingEnabled(config) {
+		return true
+	}
+	sign := config.Signing
+	if sign.Alias == "" {
+		tprintln("❌ signing.alias is not set")
+		return false
+	}
+
+	cmd := newCommand(javaTool(config, "jarsigner"), jarsignerArgs(config, absPath(jarFile))...)
+	cmd.Env = append(os.Environ(), storePassEnv+"="+sign.StorePass, keyPassEnv+"="+sign.KeyPass)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		tprintf("❌ Signing %s failed: %s\n", name, strings.TrimSpace(string(output)))
+		return false
+	}
+	verbosef("%s", output)
+	tprintf("🔏 Signed %s as %s\n", name, sign.Alias)
+	return true
+}
+
+// validateSigning checks the keystore and the jarsigner of the JDK.
+func validateSigning(v *validation, config *Config) {
+	if !signingEnabled(config) {
+		return
+	}
+	sign := config.Signing
+	switch {
+	case sign.Alias == "":
+		v.fail("signing.alias is not set")
+	case !fileExists(sign.Keystore):
+		v.fail("Keystore not found: %s", sign.Keystore)
+	default:
+		v.pass("Signing with %s from %s", sign.Alias, sign.Keystore)
+	}
+	if config.JavaPath != "" && !fileExists(javaTool(config, "jarsigner")) {
+		v.fail("No jarsigner in %s", config.JavaPath)
+	}
+}
@@ -0,0 +1,203 @@
+package sfdeploy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const defaultSmokeTimeout = 15
+
+// SFS2X controller and action IDs used by the smoke test.
+const (
+	sfsSystemController    byte  = 0
+	sfsExtensionController byte  = 1
+	sfsHandshake           int16 = 0
+	sfsLogin               int16 = 1
+	sfsCallExtension       int16 = 13
+)
+
+// smokeConfig is the smoke_test block. When Zone is set, sfdeploy logs into
+// it as an SFS2X client after each restart and, when Command is set, sends
+// that extension request and compares the response with Expect.
+type smokeConfig struct {
+	Zone     string         `json:"zone"`
+	User     string         `json:"user"`
+	Password string         `json:"password"`
+	Command  string         `json:"command"`
+	Params   map[string]any `json:"params"`
+	Expect   map[string]any `json:"expect"`
+	Timeout  int            `json:"timeout"`
+}
+
+func smokeTimeout(config *Config) time.Duration {
+	if config.SmokeTest.Timeout > 0 {
+		return time.Duration(config.SmokeTest.Timeout) * time.Second
+	}
+	return defaultSmokeTimeout * time.Second
+}
+
+// smokeTest is the phase that catches a server that boots with a broken
+// extension: it connects like a game client and exercises the extension.
+func smokeTest(config *Config) bool {
+	smoke := config.SmokeTest
+	if smoke.Zone == "" {
+		return true
+	}
+
+	addr := net.JoinHostPort(healthHost(config), strconv.Itoa(healthPort(config)))
+	user := smoke.User
+	if user == "" {
+		user = "a guest"
+	}
+
+	tprintf("💨 Smoke test: logging into zone %s on %s as %s\n", smoke.Zone, addr, user)
+	if *flagDryRun {
+		if smoke.Command != "" {
+			tprintf("[dry-run] Would send extension request '%s' and check the response\n", smoke.Command)
+		}
+		fmt.Println()
+		return true
+	}
+
+	conn, err := net.DialTimeout("tcp", addr, smokeTimeout(config))
+	if err != nil {
+		tprintf("❌ Smoke test failed: %v\n", err)
+		return false
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(smokeTimeout(config)))
+
+	if err := smokeLogin(conn, smoke); err != nil {
+		tprintf("❌ Smoke test failed: %v\n", err)
+		return false
+	}
+	tprintf("✅ Logged into zone %s\n", smoke.Zone)
+
+	if smoke.Command != "" {
+		response, err := smokeRequest(conn, smoke)
+		if err != nil {
+			tprintf("❌ Smoke test failed: %v\n", err)
+			return false
+		}
+		if err := checkSmokeResponse(response, smoke.Expect); err != nil {
+			tprintf("❌ Smoke test failed: '%s' %v\n", smoke.Command, err)
+			return false
+		}
+		tprintf("✅ Extension answered '%s' as expected\n", smoke.Command)
+	}
+	fmt.Println()
+
+	return true
+}
+
+// smokeLogin performs the binary protocol handshake and the zone login.
+func smokeLogin(conn net.Conn, smoke smokeConfig) error {
+	err := writeSFSMessage(conn, sfsMessage{sfsSystemController, sfsHandshake, map[string]any{
+		"api": "1.7.0",
+		"cl":  "sfdeploy",
+		"bin": true,
+	}})
+	if err != nil {
+		return err
+	}
+	handshake, err := awaitSFSMessage(conn, sfsSystemController, sfsHandshake)
+	if err != nil {
+		return fmt.Errorf("handshake: %w", err)
+	}
+	if _, ok := handshake.Params["tk"]; !ok {
+		return fmt.Errorf("handshake: server sent no session token")
+	}
+
+	err = writeSFSMessage(conn, sfsMessage{sfsSystemController, sfsLogin, map[string]any{
+		"zn": smoke.Zone,
+		"un": smoke.User,
+		"pw": smoke.Password,
+	}})
+	if err != nil {
+		return err
+	}
+	login, err := awaitSFSMessage(conn, sfsSystemController, sfsLogin)
+	if err != nil {
+		return fmt.Errorf("login: %w", err)
+	}
+	if code, ok := login.Params["ec"]; ok {
+		return fmt.Errorf("login to zone %s refused with error code %v %v", smoke.Zone, code, login.Params["ep"])
+	}
+	return nil
+}
+
+func smokeRequest(conn net.Conn, smoke smokeConfig) (map[string]any, error) {
+	params, _ := sfsParam(smoke.Params).(map[string]any)
+	if params == nil {
+		params = map[string]any{}
+	}
+
+	err := writeSFSMessage(conn, sfsMessage{sfsExtensionController, sfsCallExtension, map[string]any{
+		"c": smoke.Command,
+		"r": int32(-1),
+		"p": params,
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		msg, err := awaitSFSMessage(conn, sfsExtensionController, sfsCallExtension)
+		if err != nil {
+			return nil, fmt.Errorf("extension request '%s': %w", smoke.Command, err)
+		}
+		if msg.Params["c"] != smoke.Command {
+			continue
+		}
+		response, _ := msg.Params["p"].(map[string]any)
+		return response, nil
+	}
+}
+
+// awaitSFSMessage reads messages until one for the given controller and
+// action arrives, skipping the events the server pushes in between.
+func awaitSFSMessage(conn net.Conn, controller byte, action int16) (sfsMessage, error) {
+	for {
+		msg, err := readSFSMessage(conn)
+		if err != nil {
+			return sfsMessage{}, err
+		}
+		if msg.Controller == controller && msg.Action == action {
+			return msg, nil
+		}
+		debugf("🐛 smoke test: skipping message %d/%d\n", msg.Controller, msg.Action)
+	}
+}
+
+// checkSmokeResponse compares each expected field with the response.
+func checkSmokeResponse(response, expect map[string]any) error {
+	keys := make([]string, 0, len(expect))
+	for key := range expect {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		got, ok := response[key]
+		if !ok {
+			return fmt.Errorf("response has no field '%s'", key)
+		}
+		if !reflect.DeepEqual(got, expect[key]) {
+			return fmt.Errorf("response field '%s' is %s, expected %s", key, smokeJSON(got), smokeJSON(expect[key]))
+		}
+	}
+	return nil
+}
+
+func smokeJSON(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -0,0 +1,99 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Deploys are written into <folder>.staging, a copy of the live extension
+// folder, and then swapped in with two renames so the server never sees a
+// half-copied mix of old and new files.
+
+func stagingFolder(config *Config) string {
+	return config.ExtensionFolder + ".staging"
+}
+
+func oldFolder(config *Config) string {
+	return config.ExtensionFolder + ".old"
+}
+
+// stagedTarget maps an item target inside the extension folder to the same
+// file in the staging folder. Targets outside it, like the common JAR in the
+// shared __lib__, are returned unchanged.
+func stagedTarget(config *Config, target string) string {
+	if rest, ok := strings.CutPrefix(target, config.ExtensionFolder+"/"); ok {
+		return stagingFolder(config) + "/" + rest
+	}
+	return target
+}
+
+// stagedSubdirs lists the folders in the staging folder that items go to,
+// parents first, for transfers that do not create them.
+func stagedSubdirs(config *Config, items []deployItem) []string {
+	seen := map[string]bool{}
+	var dirs []string
+	for _, item := range items {
+		rest, ok := strings.CutPrefix(item.Target, config.ExtensionFolder+"/")
+		if !ok {
+			continue
+		}
+		var parents []string
+		for dir := path.Dir(rest); dir != "." && !seen[dir]; dir = path.Dir(dir) {
+			seen[dir] = true
+			parents = append(parents, dir)
+		}
+		for i := len(parents) - 1; i >= 0; i-- {
+			dirs = append(dirs, stagingFolder(config)+"/"+parents[i])
+		}
+	}
+	return dirs
+}
+
+// stageLocal creates a fresh staging folder holding a copy of the live
+// extension and returns its path.
+func stageLocal(config *Config) (string, error) {
+	staging := filepath.Join(extensionsDir(config), stagingFolder(config))
+	if err := os.RemoveAll(staging); err != nil {
+		return "", err
+	}
+	return staging, copyDir(extensionDir(config), staging)
+}
+
+// swapLocal moves the live folder aside, renames staging into its place and
+// removes the old folder. The old folder is put back if the second rename
+// fails.
+func swapLocal(config *Config) error {
+	live := extensionDir(config)
+	staging := filepath.Join(extensionsDir(config), stagingFolder(config))
+	old := filepath.Join(extensionsDir(config), oldFolder(config))
+
+	if err := retryLocked(config, old, func() error { return os.RemoveAll(old) }); err != nil {
+		return err
+	}
+	err := retryLocked(config, live, func() error { return os.Rename(live, old) })
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := retryLocked(config, live, func() error { return os.Rename(staging, live) }); err != nil {
+		os.Rename(old, live)
+		return err
+	}
+	if err := os.RemoveAll(old); err != nil {
+		tprintf("⚠️ Warning: Could not remove %s: %v\n", old, err)
+	}
+	return nil
+}
+
+// stageScript and swapScript do the same for remote and container targets.
+func stageScript(extDir string) string {
+	live, staging := shellQuote(extDir), shellQuote(extDir+".staging")
+	return fmt.Sprintf("rm -rf %[2]s && if [ -d %[1]s ]; then cp -a %[1]s %[2]s; else mkdir -p %[2]s; fi", live, staging)
+}
+
+func swapScript(extDir string) string {
+	live, staging, old := shellQuote(extDir), shellQuote(extDir+".staging"), shellQuote(extDir+".old")
+	return fmt.Sprintf("rm -rf %[3]s && if [ -d %[1]s ]; then mv %[1]s %[3]s; fi && { mv %[2]s %[1]s || { mv %[3]s %[1]s; exit 1; }; } && rm -rf %[3]s", live, staging, old)
+}
@@ -0,0 +1,183 @@
+package sfdeploy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// serverProcess is the running SmartFox as found by its listening port.
+type serverProcess struct {
+	PID    string
+	Uptime time.Duration
+}
+
+// unixProcessScript prints the PID in pidFile, or else the PID listening on
+// port, and the elapsed time of that process as ps reports it.
+func unixProcessScript(port int, pidFile string) string {
+	return unixPidScript(shellQuote(pidFile)) + fmt.Sprintf(`[ -z "$pid" ] && pid=$(lsof -t -iTCP:%[1]d -sTCP:LISTEN 2>/dev/null | head -n 1); `+
+		`[ -z "$pid" ] && pid=$(fuser %[1]d/tcp 2>/dev/null | awk '{print $1}'); `+
+		`[ -n "$pid" ] && echo "$pid $(ps -o etime= -p $pid)"; true`, port)
+}
+
+// parseElapsed parses the [[dd-]hh:]mm:ss elapsed time of ps.
+func parseElapsed(s string) time.Duration {
+	var days int
+	if d, rest, ok := strings.Cut(s, "-"); ok {
+		days, _ = strconv.Atoi(d)
+		s = rest
+	}
+	var seconds int
+	for _, part := range strings.Split(s, ":") {
+		n, _ := strconv.Atoi(part)
+		seconds = seconds*60 + n
+	}
+	return time.Duration(days)*24*time.Hour + time.Duration(seconds)*time.Second
+}
+
+func parseProcessLine(line string) (serverProcess, bool) {
+	pid, elapsed, _ := strings.Cut(strings.TrimSpace(line), " ")
+	if pid == "" {
+		return serverProcess{}, false
+	}
+	return serverProcess{PID: pid, Uptime: parseElapsed(strings.TrimSpace(elapsed))}, true
+}
+
+// findServerProcess looks for the process listening on the SmartFox port of
+// the target. For a Docker target it reports the container.
+func findServerProcess(config *Config) (serverProcess, bool) {
+	port := healthPort(config)
+
+	if d, ok := parseDockerTarget(config.TargetDir); ok {
+		output, err := newCommand("docker", "inspect", "-f", "{{.State.Running}} {{.State.Pid}} {{.State.StartedAt}}", d.Container).Output()
+		fields := strings.Fields(string(output))
+		if err != nil || len(fields) < 3 || fields[0] != "true" {
+			return serverProcess{}, false
+		}
+		started, _ := time.Parse(time.RFC3339Nano, fields[2])
+		return serverProcess{PID: fields[1], Uptime: time.Since(started)}, true
+	}
+
+	if remote, ok := parseRemoteTarget(config.TargetDir); ok {
+		output, _ := remote.run(config, unixProcessScript(port, remote.path("SFS2X", serverPidFile)))
+		return parseProcessLine(string(output))
+	}
+
+	if runtime.GOOS != "windows" {
+		output, _ := newCommand("sh", "-c", unixProcessScript(port, filepath.Join(config.TargetDir, "SFS2X", serverPidFile))).Output()
+		return parseProcessLine(string(output))
+	}
+
+	output, err := newCommand("netstat", "-ano").Output()
+	if err != nil {
+		return serverProcess{}, false
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		parts := strings.Fields(line)
+		if len(parts) >= 5 && strings.HasSuffix(parts[1], ":"+strconv.Itoa(port)) && strings.Contains(line, "LISTENING") {
+			process := serverProcess{PID: parts[len(parts)-1]}
+			script := fmt.Sprintf("(New-TimeSpan -Start (Get-Process -Id %s).StartTime).TotalSeconds", process.PID)
+			if out, err := newCommand("powershell", "-NoProfile", "-Command", script).Output(); err == nil {
+				seconds, _ := strconv.ParseFloat(strings.TrimSpace(string(out)), 64)
+				process.Uptime = time.Duration(seconds) * time.Second
+			}
+			return process, true
+		}
+	}
+	return serverProcess{}, false
+}
+
+// statusPorts are the ports a healthy server listens on.
+func statusPorts(config *Config) []string {
+	host := healthHost(config)
+	ports := []string{net.JoinHostPort(host, strconv.Itoa(healthPort(config)))}
+	if config.HealthHTTPPort > 0 {
+		ports = append(ports, net.JoinHostPort(host, strconv.Itoa(config.HealthHTTPPort)))
+	}
+	if u, err := url.Parse(adminEndpoint(config)); err == nil && u.Port() != "" {
+		ports = append(ports, u.Host)
+	}
+	return ports
+}
+
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh%02dm ago", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%d days ago", int(d.Hours())/24)
+}
+
+// statusCommand reports whether the server runs, which of its ports answer
+// and what is deployed, without changing anything.
+func statusCommand(config *Config) bool {
+	tprintf("📊 Status of %s\n", config.TargetDir)
+
+	// The processes of a share's server cannot be listed from here
+	if share, ok := targetShare(config); ok {
+		tprintf("   Server:   📡 %s, on network share %s\n", share.Host, share)
+	} else if process, ok := findServerProcess(config); ok {
+		tprintf("   Server:   ✅ running (PID %s, up %s)\n", process.PID, process.Uptime.Round(time.Second))
+	} else {
+		tprintln("   Server:   ❌ not running")
+	}
+
+	for _, addr := range statusPorts(config) {
+		if tcpReachable(addr) {
+			tprintf("   Port:     ✅ %s open\n", addr)
+		} else {
+			tprintf("   Port:     ❌ %s closed\n", addr)
+		}
+	}
+
+	if data, err := readTargetFile(config, "SFS2X/"+deployLockFile); err == nil {
+		var lock deployLock
+		if json.Unmarshal(data, &lock) == nil {
+			tprintf("   Lock:     🔒 %s\n", lock)
+		}
+	}
+
+	for _, ext := range extensionConfigs(config) {
+		data, err := readTargetFile(&ext, "SFS2X/extensions/"+ext.ExtensionFolder+"/"+manifestFile)
+		var m deployManifest
+		if err != nil || json.Unmarshal(data, &m) != nil {
+			tprintf("   %s: no deploy manifest, not deployed by sfdeploy yet\n", ext.ExtensionFolder)
+			continue
+		}
+
+		deployed := m.DeployedAt
+		if t, err := time.Parse(time.RFC3339, m.DeployedAt); err == nil {
+			deployed = t.Local().Format("2006-01-02 15:04:05") + " (" + formatAge(time.Since(t)) + ")"
+		}
+		if m.Profile != "" {
+			tprintf("   %s: deployed %s by %s, profile %s\n", ext.ExtensionFolder, deployed, m.DeployedBy, m.Profile)
+		} else {
+			tprintf("   %s: deployed %s by %s\n", ext.ExtensionFolder, deployed, m.DeployedBy)
+		}
+		if m.Commit != "" {
+			commit := m.Commit
+			if len(commit) > 8 {
+				commit = commit[:8]
+			}
+			if m.Dirty {
+				tprintf("      commit %s on %s, uncommitted changes, %d files, sfdeploy %s\n", commit, m.Branch, len(m.Files), m.ToolVersion)
+			} else {
+				tprintf("      commit %s on %s, %d files, sfdeploy %s\n", commit, m.Branch, len(m.Files), m.ToolVersion)
+			}
+		} else {
+			tprintf("      %d files, sfdeploy %s\n", len(m.Files), m.ToolVersion)
+		}
+	}
+	fmt.Println()
+	return true
+}
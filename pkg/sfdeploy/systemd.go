@@ -0,0 +1,90 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// useSystemd reports whether SmartFox is managed by the configured systemd
+// unit. Remote targets are assumed to be Linux; local ones must be.
+func useSystemd(config *Config) bool {
+	if config.SystemdUnit == "" {
+		return false
+	}
+	if _, ok := parseRemoteTarget(config.TargetDir); ok {
+		return true
+	}
+	return runtime.GOOS == "linux"
+}
+
+func systemctlArgs(config *Config, args ...string) []string {
+	args = append([]string{"systemctl"}, args...)
+	if config.SystemdSudo {
+		args = append([]string{"sudo", "-n"}, args...)
+	}
+	return args
+}
+
+// systemctl runs systemctl locally or, for remote targets, over SSH.
+func systemctl(config *Config, args ...string) (string, error) {
+	argv := systemctlArgs(config, args...)
+
+	if remote, ok := parseRemoteTarget(config.TargetDir); ok {
+		quoted := make([]string, len(argv))
+		for i, arg := range argv {
+			quoted[i] = shellQuote(arg)
+		}
+		output, err := remote.run(config, strings.Join(quoted, " "))
+		return strings.TrimSpace(string(output)), err
+	}
+
+	output, err := newCommand(argv[0], argv[1:]...).CombinedOutput()
+	return strings.TrimSpace(string(output)), err
+}
+
+func stopSystemd(config *Config) {
+	tprintf("🔍 Stopping systemd unit %s...\n", config.SystemdUnit)
+	if output, err := systemctl(config, "stop", config.SystemdUnit); err != nil {
+		tprintf("⚠️ Warning: Could not stop %s: %s\n", config.SystemdUnit, output)
+	}
+}
+
+// waitForUnitActive polls systemctl is-active until the unit is active,
+// failing early when systemd reports it as failed.
+func waitForUnitActive(config *Config) error {
+	state := ""
+	deadline := time.Now().Add(restartTimeout(config))
+	waitUntil(deadline, func() bool {
+		state, _ = systemctl(config, "is-active", config.SystemdUnit)
+		return state == "active" || state == "failed"
+	})
+
+	switch state {
+	case "active":
+		return nil
+	case "failed":
+		status, _ := systemctl(config, "status", "--no-pager", "--lines=20", config.SystemdUnit)
+		return fmt.Errorf("unit %s failed to start:\n%s", config.SystemdUnit, status)
+	}
+	return fmt.Errorf("unit %s is %q after %s", config.SystemdUnit, state, restartTimeout(config))
+}
+
+func restartSystemd(config *Config) bool {
+	tprintf("▶️ Restarting systemd unit %s...\n", config.SystemdUnit)
+	if output, err := systemctl(config, "restart", config.SystemdUnit); err != nil {
+		tprintf("❌ systemctl restart %s failed: %s\n", config.SystemdUnit, output)
+		return false
+	}
+
+	tprintf("⏳ Waiting for %s to become active...\n", config.SystemdUnit)
+	if err := waitForUnitActive(config); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return false
+	}
+
+	tprintf("✅ %s is active\n", config.SystemdUnit)
+	fmt.Println()
+	return true
+}
@@ -0,0 +1,79 @@
+package sfdeploy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// templateData is what JSON templates can reference, e.g. {{.Env.DB_HOST}}
+// or {{.Vars.maxPlayers}}.
+type templateData struct {
+	Env       map[string]string
+	Vars      map[string]string
+	Profile   string
+	Extension string
+}
+
+func newTemplateData(config *Config) templateData {
+	env := map[string]string{}
+	for _, kv := range environ(config) {
+		if key, value, ok := strings.Cut(kv, "="); ok {
+			env[key] = value
+		}
+	}
+
+	vars := config.TemplateVars
+	if vars == nil {
+		vars = map[string]string{}
+	}
+
+	return templateData{Env: env, Vars: vars, Profile: *flagProfile, Extension: config.ExtensionFolder}
+}
+
+var templateFuncs = template.FuncMap{
+	// default returns value, or fallback when value is empty: {{default "x" .Vars.y}}
+	"default": func(fallback, value string) string {
+		if value == "" {
+			return fallback
+		}
+		return value
+	},
+}
+
+func renderDir(config *Config) string {
+	return filepath.Join(stateDir, "render", config.ExtensionFolder)
+}
+
+// renderJSONTemplate executes src as a Go template and writes the result to
+// name in the render directory, returning its path. Missing keys are errors
+// so a typo can never deploy an empty value.
+func renderJSONTemplate(config *Config, src, name string, data templateData) (string, error) {
+	content, err := os.ReadFile(src)
+	if err != nil {
+		return "", err
+	}
+
+	tmpl, err := template.New(filepath.Base(src)).Funcs(templateFuncs).Option("missingkey=error").Parse(string(content))
+	if err != nil {
+		return "", err
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", err
+	}
+	if !json.Valid(out.Bytes()) {
+		return "", fmt.Errorf("%s is not valid JSON after rendering", filepath.Base(src))
+	}
+
+	dst := filepath.Join(renderDir(config), filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return "", err
+	}
+	return dst, os.WriteFile(dst, out.Bytes(), 0644)
+}
@@ -0,0 +1,348 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+const tuiLogLimit = 2000
+
+// tuiPhases are the phase headers shown in the status pane, numbered as
+// the phases print them.
+var tuiPhases = []string{"Directory Setup", "Building", "Running Tests", "Deploying", "Restarting", "Checking Health", "Cleaning Up"}
+
+// tuiActions are the pipelines the TUI runs on a key press.
+var tuiActions = []struct {
+	key    string
+	name   string
+	phases []phase
+}{
+	{"d", "deploy", watchPhases},
+	{"b", "build", []phase{buildProject, customPhases("build")}},
+	{"t", "test", []phase{buildProject, customPhases("build"), runTests, customPhases("test")}},
+	{"r", "restart", []phase{lockedTarget(restartServer, checkServerHealth, smokeTest, customPhases("restart"))}},
+	{"u", "rollback", []phase{lockedTarget(eachExtension(rollbackDeployment), restartServer, checkServerHealth, smokeTest)}},
+}
+
+var phaseHeaderPattern = regexp.MustCompile(`Phase (\d+): `)
+
+type phaseState int
+
+const (
+	phaseIdle phaseState = iota
+	phaseRunning
+	phaseDone
+	phaseFailed
+)
+
+type tuiLogMsg string
+
+type tuiDoneMsg struct {
+	action string
+	ok     bool
+	busy   bool
+	took   time.Duration
+}
+
+type tuiWatchStoppedMsg struct{}
+
+type tuiModel struct {
+	config    *Config
+	phases    []phaseState
+	log       []string
+	scroll    int // lines scrolled up from the newest
+	running   string
+	watching  bool
+	stopWatch chan struct{}
+	result    string
+	width     int
+	height    int
+	quitArmed bool
+}
+
+var (
+	tuiTitle  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	tuiPane   = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("8")).Padding(0, 1)
+	tuiFooter = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+)
+
+// tuiCommand runs the full-screen UI. Phase output goes to the log pane
+// instead of the terminal, and still reaches the log file.
+func tuiCommand(config *Config) bool {
+	if !isTerminal(consoleFile) {
+		tprintln("❌ The TUI needs an interactive terminal")
+		return false
+	}
+	// Questions cannot be answered while the UI owns the terminal
+	*flagNoPrompt = true
+	*flagNoPause = true
+	// The status pane follows the English phase headlines
+	activeCatalog.Store(nil)
+
+	model := tuiModel{config: config, phases: make([]phaseState, len(tuiPhases))}
+	program := tea.NewProgram(model, tea.WithAltScreen(), tea.WithOutput(consoleFile))
+
+	// Output already routed has reached the terminal once the switch is made
+	console := consoleOut.set(&tuiLogWriter{program: program})
+	final, err := program.Run()
+	consoleOut.set(console)
+
+	if m, ok := final.(tuiModel); ok && m.stopWatch != nil {
+		close(m.stopWatch)
+	}
+	if err != nil {
+		fmt.Printf("❌ TUI failed: %v\n", err)
+		return false
+	}
+	return true
+}
+
+// tuiLogWriter turns captured output into log pane lines.
+type tuiLogWriter struct {
+	program *tea.Program
+	partial string
+}
+
+func (t *tuiLogWriter) Write(p []byte) (int, error) {
+	t.partial += string(p)
+	for {
+		i := strings.IndexByte(t.partial, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		line := strings.TrimRight(t.partial[:i], "\r")
+		t.partial = t.partial[i+1:]
+		t.program.Send(tuiLogMsg(line))
+	}
+}
+
+func (m tuiModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+
+	case tuiLogMsg:
+		m.addLog(string(msg))
+
+	case tuiDoneMsg:
+		if msg.busy {
+			m.running = ""
+			m.result = "⏳ A deploy is already running"
+			break
+		}
+		m.running = ""
+		m.finishPhases(msg.ok)
+		if msg.ok {
+			m.result = fmt.Sprintf("✅ %s succeeded in %s", msg.action, msg.took.Round(time.Second))
+		} else {
+			m.result = fmt.Sprintf("❌ %s failed after %s", msg.action, msg.took.Round(time.Second))
+		}
+
+	case tuiWatchStoppedMsg:
+		m.watching, m.stopWatch = false, nil
+
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+	return m, nil
+}
+
+func (m tuiModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	if key != "q" && key != "ctrl+c" {
+		m.quitArmed = false
+	}
+
+	switch key {
+	case "q", "ctrl+c":
+		if m.running != "" && !m.quitArmed {
+			m.quitArmed = true
+			m.result = fmt.Sprintf("⚠️ %s is still running, press q again to quit anyway", m.running)
+			return m, nil
+		}
+		return m, tea.Quit
+	case "w":
+		return m.toggleWatch()
+	case "c":
+		m.log, m.scroll = nil, 0
+	case "up", "k":
+		m.scroll = min(m.scroll+1, max(len(m.log)-m.logHeight(), 0))
+	case "down", "j":
+		m.scroll = max(m.scroll-1, 0)
+	case "pgup":
+		m.scroll = min(m.scroll+m.logHeight(), max(len(m.log)-m.logHeight(), 0))
+	case "pgdown":
+		m.scroll = max(m.scroll-m.logHeight(), 0)
+	case "end", "G":
+		m.scroll = 0
+	default:
+		for _, action := range tuiActions {
+			if key == action.key {
+				return m.start(action.name, action.phases)
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m tuiModel) start(name string, phases []phase) (tea.Model, tea.Cmd) {
+	if m.running != "" {
+		m.result = fmt.Sprintf("⏳ %s is still running", m.running)
+		return m, nil
+	}
+	m.running = name
+	m.result = ""
+	m.scroll = 0
+	for i := range m.phases {
+		m.phases[i] = phaseIdle
+	}
+
+	config := m.config
+	return m, func() tea.Msg {
+		if !deployMu.TryLock() {
+			return tuiDoneMsg{action: name, busy: true}
+		}
+		defer deployMu.Unlock()
+
+		started := time.Now()
+		fmt.Printf("▶️ %s started at %s\n", name, started.Format("15:04:05"))
+		ok := runPhases(config, phases)
+		return tuiDoneMsg{action: name, ok: ok, took: time.Since(started)}
+	}
+}
+
+func (m tuiModel) toggleWatch() (tea.Model, tea.Cmd) {
+	if m.watching {
+		close(m.stopWatch)
+		m.stopWatch = nil
+		return m, nil
+	}
+
+	m.watching = true
+	m.stopWatch = make(chan struct{})
+	config, stop := m.config, m.stopWatch
+	return m, func() tea.Msg {
+		watchSources(config, stop)
+		return tuiWatchStoppedMsg{}
+	}
+}
+
+// addLog appends a line and follows phase headers and watch cycle results
+// to update the status pane.
+func (m *tuiModel) addLog(line string) {
+	m.log = append(m.log, line)
+	if len(m.log) > tuiLogLimit {
+		m.log = m.log[len(m.log)-tuiLogLimit:]
+	}
+	if m.scroll > 0 {
+		m.scroll++
+	}
+
+	switch {
+	case strings.HasPrefix(line, "🔁 Change detected"):
+		for i := range m.phases {
+			m.phases[i] = phaseIdle
+		}
+	case strings.HasPrefix(line, "Hot deploy completed successfully"):
+		m.finishPhases(true)
+	case strings.HasPrefix(line, "❌ Hot deploy failed"):
+		m.finishPhases(false)
+	}
+
+	if match := phaseHeaderPattern.FindStringSubmatch(line); match != nil {
+		n, _ := strconv.Atoi(match[1])
+		if n < 1 || n > len(m.phases) {
+			return
+		}
+		for i, state := range m.phases {
+			if state == phaseRunning {
+				m.phases[i] = phaseDone
+			}
+		}
+		m.phases[n-1] = phaseRunning
+	}
+}
+
+func (m *tuiModel) finishPhases(ok bool) {
+	for i, state := range m.phases {
+		if state == phaseRunning {
+			if ok {
+				m.phases[i] = phaseDone
+			} else {
+				m.phases[i] = phaseFailed
+			}
+		}
+	}
+}
+
+// logHeight is the number of log lines that fit below the status pane.
+func (m tuiModel) logHeight() int {
+	// Title, the borders of both panes, the phase rows, status and keys
+	return max(m.height-len(tuiPhases)-7, 3)
+}
+
+func (m tuiModel) View() string {
+	if m.width == 0 {
+		return "Starting..."
+	}
+	inner := max(m.width-4, 10)
+
+	title := fmt.Sprintf("SpookyZone Hot Deploy — %s → %s", m.config.ExtensionFolder, m.config.TargetDir)
+	if m.watching {
+		title += "  👀 watching"
+	}
+
+	var phases []string
+	for i, name := range tuiPhases {
+		icon := map[phaseState]string{phaseIdle: "·", phaseRunning: "⏳", phaseDone: "✅", phaseFailed: "❌"}[m.phases[i]]
+		phases = append(phases, fmt.Sprintf("%s %d %s", icon, i+1, name))
+	}
+
+	height := m.logHeight()
+	end := len(m.log) - m.scroll
+	begin := max(end-height, 0)
+	lines := make([]string, 0, height)
+	for _, line := range m.log[begin:end] {
+		lines = append(lines, runewidth.Truncate(strings.ReplaceAll(line, "\t", "    "), inner, "…"))
+	}
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+
+	status := m.result
+	if m.running != "" {
+		status = fmt.Sprintf("⏳ %s running...", m.running)
+	}
+	if m.scroll > 0 {
+		status += fmt.Sprintf("  (scrolled %d lines, End to follow)", m.scroll)
+	}
+
+	keys := []string{}
+	for _, action := range tuiActions {
+		keys = append(keys, action.key+" "+action.name)
+	}
+	watch := "w watch"
+	if m.watching {
+		watch = "w stop watching"
+	}
+	keys = append(keys, watch, "↑/↓ scroll", "c clear", "q quit")
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		tuiTitle.Render(runewidth.Truncate(title, m.width, "…")),
+		tuiPane.Width(m.width-2).Render(strings.Join(phases, "\n")),
+		tuiPane.Width(m.width-2).Render(strings.Join(lines, "\n")),
+		runewidth.Truncate(status, m.width, "…"),
+		tuiFooter.Render(runewidth.Truncate(strings.Join(keys, " · "), m.width, "…")),
+	)
+}
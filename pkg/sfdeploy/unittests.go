@@ -0,0 +1,200 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const defaultTestDir = "test"
+
+func testDir(config *Config) string {
+	dir := config.TestDir
+	if dir == "" {
+		dir = defaultTestDir
+	}
+	if filepath.IsAbs(dir) {
+		return dir
+	}
+	return filepath.Join(config.SourceDir, dir)
+}
+
+func testClassesDir(config *Config) string {
+	return filepath.Join(stateDir, "build", config.ExtensionFolder, "test-classes")
+}
+
+// testClasspath expands test_classpath the same way as extra_libs.
+func testClasspath(config *Config) []string {
+	var jars []string
+	for _, entry := range config.TestClasspath {
+		if !filepath.IsAbs(entry) {
+			entry = filepath.Join(config.SourceDir, entry)
+		}
+		for _, jar := range expandClasspathEntry(entry) {
+			jars = append(jars, absPath(jar))
+		}
+	}
+	return jars
+}
+
+// junitConsole returns the JUnit Platform console launcher among the test
+// JARs, or "" to fall back to the JUnit 4 runner.
+func junitConsole(jars []string) string {
+	for _, jar := range jars {
+		if strings.HasPrefix(filepath.Base(jar), "junit-platform-console-standalone") {
+			return jar
+		}
+	}
+	return ""
+}
+
+func javaTool(config *Config, name string) string {
+	path := filepath.Join(config.JavaPath, name)
+	if runtime.GOOS == "windows" {
+		path += ".exe"
+	}
+	return path
+}
+
+// runTests is the phase that runs the project's unit tests before anything
+// is deployed. Maven runs them during its package build; Gradle projects
+// run the test task; plain javac projects run the JUnit tests in test_dir
+// when test_classpath names the JUnit JARs.
+func runTests(config *Config) bool {
+	tprintln("🧪 Phase 3: Running Tests")
+
+	if *flagSkipTests {
+		tprintln("⏭️ Skipping tests (--skip-tests)")
+		fmt.Println()
+		return true
+	}
+
+	switch {
+	case isMavenProject(config.SourceDir):
+		tprintln("✅ Tests ran as part of the Maven build")
+		fmt.Println()
+		return true
+	case isGradleProject(config.SourceDir):
+		return runGradleTests(config)
+	}
+
+	testFiles := findJavaFiles(config, testDir(config))
+	if len(testFiles) == 0 {
+		tprintln("No tests found")
+		fmt.Println()
+		return true
+	}
+	if len(config.TestClasspath) == 0 {
+		tprintf("⚠️ Warning: %d test files in %s but test_classpath is not set, skipping tests\n", len(testFiles), testDir(config))
+		fmt.Println()
+		return true
+	}
+
+	if *flagDryRun {
+		tprintf("[dry-run] Would compile and run %d test files from %s\n", len(testFiles), testDir(config))
+		fmt.Println()
+		return true
+	}
+
+	return runJUnitTests(config, testFiles)
+}
+
+func runGradleTests(config *Config) bool {
+	gradle := gradleCommand(config.SourceDir)
+	if *flagDryRun {
+		tprintf("[dry-run] Would run: %s --console=plain test\n", filepath.Base(gradle))
+		fmt.Println()
+		return true
+	}
+
+	tprintf("Running %s test...\n", filepath.Base(gradle))
+	cmd := newCommand(gradle, "--console=plain", "test")
+	cmd.Dir = config.SourceDir
+	cmd.Env = javaHomeEnv(config)
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		tprintf("❌ Tests failed: %v\n", err)
+		fmt.Printf("%s\n", output)
+		return false
+	}
+
+	tprintln("✅ Tests passed")
+	fmt.Println()
+	return true
+}
+
+func runJUnitTests(config *Config, testFiles []string) bool {
+	jars := testClasspath(config)
+	outDir := testClassesDir(config)
+	os.RemoveAll(outDir)
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		tprintf("❌ Failed to create %s: %v\n", outDir, err)
+		return false
+	}
+	absOutDir := absPath(outDir)
+
+	// Tests compile and run against the freshly compiled extension classes
+	cp := append([]string{absPath(classCacheDir(config)), absPath(kotlinClassesDir(config)), buildClasspath(config)}, jars...)
+	classpath := strings.Join(cp, string(os.PathListSeparator))
+
+	tprintf("Compiling %d test files...\n", len(testFiles))
+	args := append([]string{"-cp", classpath, "-d", absOutDir}, javacOptions(config)...)
+	for _, file := range testFiles {
+		args = append(args, absPath(file))
+	}
+	if output, err := newCommand(javaTool(config, "javac"), args...).CombinedOutput(); err != nil {
+		tprintf("❌ Test compilation failed: %s\n", string(output))
+		return false
+	}
+
+	classpath += string(os.PathListSeparator) + absOutDir
+	var cmdArgs []string
+	if console := junitConsole(jars); console != "" {
+		cmdArgs = []string{"-jar", console, "--disable-banner", "--class-path", classpath, "--scan-class-path", absOutDir}
+	} else {
+		classes := testClassNames(outDir)
+		if len(classes) == 0 {
+			tprintln("No test classes (*Test, *Tests) found")
+			fmt.Println()
+			return true
+		}
+		cmdArgs = append([]string{"-cp", classpath, "org.junit.runner.JUnitCore"}, classes...)
+	}
+
+	tprintln("Running tests...")
+	output, err := newCommand(javaTool(config, "java"), cmdArgs...).CombinedOutput()
+	if err != nil {
+		tprintln("❌ Tests failed:")
+		fmt.Println(strings.TrimSpace(string(output)))
+		tprintln("   Use --skip-tests to deploy anyway")
+		return false
+	}
+	verbosef("%s\n", strings.TrimSpace(string(output)))
+
+	tprintln("✅ Tests passed")
+	fmt.Println()
+	return true
+}
+
+// testClassNames lists the compiled top-level classes named *Test or
+// *Tests, for the JUnit 4 runner.
+func testClassNames(dir string) []string {
+	var names []string
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(path, ".class") || strings.Contains(info.Name(), "$") {
+			return nil
+		}
+		base := strings.TrimSuffix(info.Name(), ".class")
+		if !strings.HasSuffix(base, "Test") && !strings.HasSuffix(base, "Tests") {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err == nil {
+			names = append(names, strings.ReplaceAll(filepath.ToSlash(strings.TrimSuffix(rel, ".class")), "/", "."))
+		}
+		return nil
+	})
+	return names
+}
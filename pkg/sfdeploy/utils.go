@@ -0,0 +1,90 @@
+package sfdeploy
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+func askYesNo(prompt string) bool {
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Print(prompt)
+		response, _ := reader.ReadString('\n')
+		response = strings.ToLower(strings.TrimSpace(response))
+
+		if response == "y" || response == "yes" {
+			return true
+		} else if response == "n" || response == "no" {
+			return false
+		} else {
+			tprintln("Please enter 'y' or 'n'")
+		}
+	}
+}
+
+func hasJavac(binDir string) bool {
+	javacPath := filepath.Join(binDir, "javac")
+	if runtime.GOOS == "windows" {
+		javacPath += ".exe"
+	}
+	_, err := os.Stat(javacPath)
+	return err == nil
+}
+
+func findSmartFoxServer() string {
+	var searchPaths []string
+
+	if userHome, err := os.UserHomeDir(); err == nil {
+		searchPaths = append(searchPaths,
+			filepath.Join(userHome, "SmartFoxServer_2X"),
+			filepath.Join(userHome, "SmartFoxServer"),
+			filepath.Join(userHome, "Desktop", "SmartFoxServer_2X"),
+			filepath.Join(userHome, "Downloads", "SmartFoxServer_2X"),
+		)
+	}
+
+	for _, path := range searchPaths {
+		if validateTargetDir(path) {
+			return path
+		}
+	}
+
+	if runtime.GOOS == "windows" {
+		drives := []string{"C:", "D:", "E:", "F:"}
+		patterns := []string{
+			"SmartFoxServer_2X",
+			"SmartFoxServer",
+			"SFS2X",
+		}
+
+		for _, drive := range drives {
+			for _, pattern := range patterns {
+				searchPath := filepath.Join(drive+"\\", pattern)
+				if validateTargetDir(searchPath) {
+					return searchPath
+				}
+
+				programFilesPath := filepath.Join(drive+"\\Program Files", pattern)
+				if validateTargetDir(programFilesPath) {
+					return programFilesPath
+				}
+
+				programFilesx86Path := filepath.Join(drive+"\\Program Files (x86)", pattern)
+				if validateTargetDir(programFilesx86Path) {
+					return programFilesx86Path
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+func isWantedJava(config *Config, javacPath string) bool {
+	major, err := javacMajor(javacPath)
+	return err == nil && javaVersionMatches(config, major)
+}
@@ -0,0 +1,354 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// validation counts the results of "config validate" as they are printed.
+type validation struct {
+	passed int
+	warned int
+	failed int
+}
+
+func (v *validation) pass(format string, args ...any) {
+	v.passed++
+	printCheck("✅", format, args...)
+}
+
+func (v *validation) warn(format string, args ...any) {
+	v.warned++
+	printCheck("⚠️", format, args...)
+}
+
+func (v *validation) fail(format string, args ...any) {
+	v.failed++
+	printCheck("❌", format, args...)
+}
+
+// printCheck prints a result after its mark, translated like tprintf.
+func printCheck(mark, format string, args ...any) {
+	translation := tr(format)
+	if translation == format {
+		fmt.Printf(mark+" "+format+"\n", args...)
+		return
+	}
+	printTranslated(mark+" "+fmt.Sprintf(format, args...), mark+" "+fmt.Sprintf(translation, args...), true)
+}
+
+// configCommand implements "config validate", "config set", "config edit",
+// "config export" and "config import".
+func configCommand(config *Config) bool {
+	switch commandArg(0) {
+	case "validate":
+		return validateConfig(config)
+	case "set":
+		return configSet()
+	case "edit":
+		return configEdit()
+	case "export":
+		return configExport()
+	case "import":
+		return configImport()
+	default:
+		tprintf("Unknown config command: %s (expected validate, set, edit, export or import)\n", commandArg(0))
+		return false
+	}
+}
+
+// validateConfig runs every check a deploy depends on and reports them all,
+// instead of stopping at the first problem as the phases do. Nothing is
+// built, copied or restarted.
+func validateConfig(config *Config) bool {
+	tprintln("🔍 Validating configuration")
+	v := &validation{}
+
+	issues, _ := checkConfigFile()
+	invalid := false
+	for _, issue := range issues {
+		if issue.Warning {
+			v.warn("%s: %s", findConfigFile(), issue)
+		} else {
+			v.fail("%s: %s", findConfigFile(), issue)
+			invalid = true
+		}
+	}
+
+	saved, err := readConfigFile()
+	switch {
+	case err == nil:
+		v.pass("Config file: %s", findConfigFile())
+	case invalid:
+		return finishValidation(v)
+	case hasFlagOverrides() || hasEnvOverrides():
+		v.warn("Config file not loaded (%v), using flags and environment only", err)
+	default:
+		v.fail("Config file %s: %v", findConfigFile(), err)
+		return finishValidation(v)
+	}
+
+	*config = saved
+	if !applyOverrides(config) {
+		v.fail("The project config, profile, overrides or secrets could not be applied")
+		return finishValidation(v)
+	}
+	if !selectExtensions(config) {
+		v.fail("The extensions list is invalid")
+		return finishValidation(v)
+	}
+
+	for _, key := range missingRequired(config) {
+		v.fail("Required setting missing: %s", key)
+	}
+	if config.ExtensionFile == "" {
+		config.ExtensionFile = config.ExtensionFolder + ".jar"
+	}
+
+	if err := checkCustomPhases(config); err != nil {
+		v.fail("%v", err)
+	}
+	validateSourceFiles(v, config)
+	validateJava(v, config)
+	validateSigning(v, config)
+	validateProGuard(v, config)
+	validateEmail(v, config)
+	validateLanguage(v, config)
+
+	if err := parallelTargetsError(config); err != nil {
+		v.fail("%v", err)
+	}
+	targets := config.Targets
+	if len(targets) == 0 {
+		targets = []string{config.TargetDir}
+	}
+	for _, target := range targets {
+		targetConfig := *config
+		targetConfig.TargetDir = target
+		validateTargetFiles(v, &targetConfig)
+		validateServerVersion(v, &targetConfig)
+	}
+
+	return finishValidation(v)
+}
+
+func finishValidation(v *validation) bool {
+	fmt.Println()
+	tprintf("%d passed, %d warnings, %d failed\n", v.passed, v.warned, v.failed)
+	return v.failed == 0
+}
+
+func validateSourceFiles(v *validation, config *Config) {
+	roots := sourceRoots(config)
+	_, modulesErr := projectModules(config)
+	switch {
+	case config.SourceDir == "":
+		v.fail("source_dir is not set")
+	case !fileExists(config.SourceDir):
+		v.fail("Source directory not found: %s", config.SourceDir)
+	case modulesErr != nil:
+		v.fail("Modules: %v", modulesErr)
+	case len(roots) == 1 && !fileExists(roots[0]):
+		v.fail("Source directory has no src folder: %s", roots[0])
+	case len(projectJavaFiles(config)) == 0 && len(projectKotlinFiles(config)) == 0:
+		v.fail("No .java or .kt files under %s", strings.Join(roots, ", "))
+	case len(roots) > 1:
+		v.pass("Source: %s, %d modules", config.SourceDir, len(roots))
+	default:
+		v.pass("Source: %s", config.SourceDir)
+	}
+	if len(roots) > 1 {
+		for _, root := range roots {
+			if !fileExists(root) {
+				v.fail("Module has no src folder: %s", root)
+			}
+		}
+	}
+
+	for _, ext := range extensionConfigs(config) {
+		if ext.ExtensionFolder != "" {
+			v.pass("Extension: %s", ext.ExtensionFolder)
+		}
+		for _, pkg := range ext.packages {
+			found := false
+			for _, root := range roots {
+				found = found || fileExists(filepath.Join(root, packagePath(pkg)))
+			}
+			if !found {
+				v.fail("Package %s of %s not found in %s", pkg, ext.ExtensionFolder, strings.Join(roots, ", "))
+			}
+		}
+		for _, name := range ext.DeployJsonFiles {
+			path := filepath.Join(ext.JsonSourceDir, name+".json")
+			if fileExists(path) {
+				v.pass("JSON file: %s", path)
+			} else {
+				v.fail("JSON file not found: %s", path)
+			}
+		}
+		if len(ext.DeployFiles) > 0 {
+			files, err := globFiles(resourceDir(&ext), ext.DeployFiles)
+			switch {
+			case err != nil:
+				v.fail("deploy_files of %s: %v", ext.ExtensionFolder, err)
+			case len(files) == 0:
+				v.warn("deploy_files of %s matched no files in %s", ext.ExtensionFolder, resourceDir(&ext))
+			default:
+				v.pass("deploy_files: %d files from %s", len(files), resourceDir(&ext))
+			}
+		}
+		for _, root := range ext.ResourceDirs {
+			if _, err := root.targetFolder(); err != nil {
+				v.fail("%v", err)
+				continue
+			}
+			files, err := globFiles(root.Source, root.Files)
+			switch {
+			case !fileExists(root.Source):
+				v.fail("Resource folder not found: %s", root.Source)
+			case err != nil:
+				v.fail("resource_dirs %s: %v", root.Source, err)
+			case len(files) == 0:
+				v.warn("resource_dirs %s has no matching files", root.Source)
+			default:
+				v.pass("Resources: %d files from %s", len(files), root.Source)
+			}
+		}
+	}
+
+	if _, err := parseGlobs(config.JarFiles); err != nil {
+		v.fail("jar_files: %v", err)
+	}
+
+	for field, entries := range map[string][]string{"lib_jars": config.LibJars, "extra_libs": config.ExtraLibs} {
+		for _, entry := range entries {
+			if !filepath.IsAbs(entry) {
+				entry = filepath.Join(config.SourceDir, entry)
+			}
+			if len(expandClasspathEntry(entry)) == 0 {
+				v.warn("%s entry matched no JAR files: %s", field, entry)
+			}
+		}
+	}
+}
+
+// validateJava checks for a JDK the way setupJava picks one, without
+// prompting or downloading.
+func validateJava(v *validation, config *Config) {
+	if _, err := parseJavaVersion(javaVersionSpec(config)); err != nil {
+		v.fail("%v", err)
+		return
+	}
+
+	javaConfig := *config
+	if *flagJava != "" {
+		javaConfig.JavaPath = *flagJava
+	} else if javaConfig.JavaPath == "" || !hasJavac(javaConfig.JavaPath) {
+		candidates := findJDKs(config)
+		if len(candidates) == 0 {
+			v.fail("Java %s not found", javaVersionSpec(config))
+			return
+		}
+		javaConfig.JavaPath = candidates[0].BinDir
+	}
+	if !hasJavac(javaConfig.JavaPath) {
+		v.fail("No javac in %s", javaConfig.JavaPath)
+		return
+	}
+
+	major, err := javacMajor(javaTool(&javaConfig, "javac"))
+	switch {
+	case err != nil:
+		v.warn("Could not determine the JDK version of %s: %v", javaConfig.JavaPath, err)
+	case !javaVersionMatches(config, major):
+		v.warn("Java %d at %s does not match java_version %s", major, javaConfig.JavaPath, javaVersionSpec(config))
+	default:
+		v.pass("Java %d: %s", major, javaConfig.JavaPath)
+	}
+}
+
+func validateTargetFiles(v *validation, config *Config) {
+	if config.TargetDir == "" {
+		v.fail("target_dir is not set")
+		return
+	}
+	if remote, ok := parseRemoteTarget(config.TargetDir); ok {
+		if validateRemoteTargetDir(config, remote) {
+			v.pass("Remote target: %s", remote)
+		} else {
+			v.fail("Remote target directory is invalid or unreachable: %s", remote)
+		}
+		return
+	}
+	if d, ok := parseDockerTarget(config.TargetDir); ok {
+		if validateDockerTarget(config, d) {
+			v.pass("Docker target: %s", d)
+		} else {
+			v.fail("Docker target is invalid or the container is not running: %s", d)
+		}
+		return
+	}
+
+	sfsDir := filepath.Join(config.TargetDir, "SFS2X")
+	if !fileExists(sfsDir) {
+		v.fail("No SFS2X folder in %s", config.TargetDir)
+		return
+	}
+	if launcher := findLauncher(sfsDir); launcher == "" {
+		v.fail("No SmartFox launcher in %s", sfsDir)
+	} else {
+		v.pass("Target: %s (%s)", config.TargetDir, launcher)
+	}
+	for _, jar := range []string{"sfs2x.jar", "sfs2x-core.jar"} {
+		if path := filepath.Join(sfsDir, "lib", jar); !fileExists(path) {
+			v.warn("%s not found at %s", jar, path)
+		}
+	}
+
+	// The extensions folder is created on the first deploy if it is missing
+	dir := extensionsDir(config)
+	if !fileExists(dir) {
+		dir = sfsDir
+	}
+	if err := checkWritable(dir); err != nil {
+		v.fail("Cannot write to %s: %v", dir, err)
+	} else {
+		v.pass("Writable: %s", dir)
+	}
+
+	if config.ZoneName != "" || config.ZoneFile != "" {
+		zone := filepath.Join(sfsDir, "zones", zoneFileName(config))
+		if fileExists(zone) {
+			v.pass("Zone file: %s", zone)
+		} else {
+			v.fail("Zone file not found: %s", zone)
+		}
+	}
+}
+
+func validateServerVersion(v *validation, config *Config) {
+	info := detectServer(config)
+	if info.Version != "" || info.JavaVersion != "" {
+		v.pass("SmartFox %s", info)
+	}
+	javaConfig := *config
+	if *flagJava != "" {
+		javaConfig.JavaPath = *flagJava
+	}
+	for _, warning := range compatWarnings(&javaConfig, info) {
+		v.warn("%s", warning)
+	}
+}
+
+// checkWritable creates and removes a file in dir, which catches read-only
+// mounts and ACLs that permission bits alone do not show.
+func checkWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".sfdeploy-write-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
@@ -0,0 +1,131 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// verbosity is the amount of console output chosen with --quiet, --verbose
+// or --debug. The log file always receives every level.
+type verbosity int
+
+const (
+	levelQuiet verbosity = iota
+	levelNormal
+	levelVerbose
+	levelDebug
+)
+
+// levelMark starts a line that is only shown at a raised verbosity; the
+// byte after it names the level.
+const levelMark = '\x1e'
+
+func outputLevel() (verbosity, error) {
+	level, set := levelNormal, 0
+	for _, f := range []struct {
+		on    bool
+		level verbosity
+	}{{*flagQuiet, levelQuiet}, {*flagVerbose, levelVerbose}, {*flagDebug, levelDebug}} {
+		if f.on {
+			level = f.level
+			set++
+		}
+	}
+	if set > 1 {
+		return levelNormal, fmt.Errorf("--quiet, --verbose and --debug cannot be combined")
+	}
+	return level, nil
+}
+
+// routed is set while stdout goes through the levelRouter. Outside of it,
+// leveled lines are filtered here instead of being marked.
+var (
+	routedMu    sync.Mutex
+	routed      bool
+	routedLevel = levelNormal
+)
+
+func setRouted(level verbosity, on bool) {
+	routedMu.Lock()
+	defer routedMu.Unlock()
+	routed, routedLevel = on, level
+}
+
+func printLevel(level verbosity, mark string, format string, args ...any) {
+	routedMu.Lock()
+	on, current := routed, routedLevel
+	routedMu.Unlock()
+
+	switch {
+	case on:
+		fmt.Printf(string(levelMark)+mark+format, args...)
+	case current >= level:
+		fmt.Printf(format, args...)
+	}
+}
+
+// verbosef prints a line shown only with --verbose or --debug, like each
+// copied file.
+func verbosef(format string, args ...any) {
+	printLevel(levelVerbose, "v", format, args...)
+}
+
+// debugf prints a line shown only with --debug.
+func debugf(format string, args ...any) {
+	printLevel(levelDebug, "d", format, args...)
+}
+
+func markLevel(b byte) verbosity {
+	if b == 'd' {
+		return levelDebug
+	}
+	return levelVerbose
+}
+
+// resultLine matches the final line of a run, the only output besides
+// errors that --quiet keeps.
+var resultLine = regexp.MustCompile(`completed successfully!$|^Command '.*' failed$`)
+
+func isQuietLine(line string) bool {
+	line = strings.TrimSpace(line)
+	return strings.HasPrefix(line, "❌") || resultLine.MatchString(line)
+}
+
+// newCommand is exec.Command that prints the command line with --debug.
+func newCommand(name string, args ...string) *exec.Cmd {
+	debugf("🐛 exec: %s\n", strings.Join(append([]string{name}, args...), " "))
+	return exec.Command(name, args...)
+}
+
+// secretEnvParts mark the environment variables --debug does not print.
+var secretEnvParts = []string{"PASSWORD", "PASSPHRASE", "TOKEN", "SECRET", "KEY"}
+
+func isSecretEnv(key string) bool {
+	for _, part := range secretEnvParts {
+		if strings.Contains(strings.ToUpper(key), part) {
+			return true
+		}
+	}
+	return false
+}
+
+// printDebugEnv prints what the run depends on from its environment.
+func printDebugEnv() {
+	wd, _ := os.Getwd()
+	debugf("🐛 sfdeploy on %s/%s, working directory %s\n", runtime.GOOS, runtime.GOARCH, wd)
+	debugf("🐛 args: %s\n", strings.Join(commandLine, " "))
+	for _, kv := range os.Environ() {
+		key, _, _ := strings.Cut(kv, "=")
+		if strings.HasPrefix(key, envPrefix) || key == "JAVA_HOME" || key == "PATH" {
+			if isSecretEnv(key) {
+				kv = key + "=***"
+			}
+			debugf("🐛 env: %s\n", kv)
+		}
+	}
+}
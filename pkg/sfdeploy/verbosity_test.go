@@ -0,0 +1,24 @@
+package sfdeploy
+
+import "testing"
+
+func TestIsSecretEnv(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"SFDEPLOY_ADMIN_PASSWORD", true},
+		{"SFDEPLOY_PASSPHRASE", true},
+		{"SFDEPLOY_SERVE_TOKEN", true},
+		{"SFDEPLOY_WEBHOOK_SECRET", true},
+		{"SFDEPLOY_SIGNING_KEY", true},
+		{"SFDEPLOY_TARGET_DIR", false},
+		{"JAVA_HOME", false},
+		{"PATH", false},
+	}
+	for _, tt := range tests {
+		if got := isSecretEnv(tt.key); got != tt.want {
+			t.Errorf("isSecretEnv(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
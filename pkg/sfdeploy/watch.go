@@ -0,0 +1,158 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const watchDebounce = 500 * time.Millisecond
+
+// watchPhases is the pipeline run for each change in watch mode and each
+// push in listen mode.
+var watchPhases = []phase{buildProject, customPhases("build"), runTests, customPhases("test"),
+	lockedTarget(deployProject, customPhases("deploy"), restartServer, checkServerHealth, smokeTest, customPhases("restart"), postDeployHooks), cleanupProject}
+
+// noRestartWatchPhases is the watch cycle of --skip-restart.
+var noRestartWatchPhases = []phase{buildProject, customPhases("build"), runTests, customPhases("test"),
+	lockedTarget(deployProject, customPhases("deploy"), postDeployHooks), cleanupProject}
+
+// deployMu keeps pipelines from overlapping when the TUI starts one while
+// watch mode is running.
+var deployMu sync.Mutex
+
+func watchProject(config *Config) bool {
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-interrupt:
+			close(stop)
+		case <-done:
+		}
+	}()
+	return watchSources(config, stop)
+}
+
+// watchSources runs a deploy cycle after every change until stop is closed.
+func watchSources(config *Config, stop <-chan struct{}) bool {
+	tprintln("👀 Watch Mode")
+
+	roots := sourceRoots(config)
+	watched := strings.Join(roots, ", ")
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		tprintf("❌ Failed to start file watcher: %v\n", err)
+		return false
+	}
+	defer watcher.Close()
+
+	ignore := sourceIgnore(config)
+	for _, srcDir := range roots {
+		if err := addWatchDirs(watcher, srcDir, ignore); err != nil {
+			tprintf("❌ Failed to watch %s: %v\n", srcDir, err)
+			return false
+		}
+	}
+
+	tprintf("Watching %s for .java changes (Ctrl+C to stop)\n", watched)
+	fmt.Println()
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return true
+			}
+
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					addWatchDirs(watcher, event.Name, ignore)
+				}
+			}
+
+			if event.Op == fsnotify.Chmod || !strings.HasSuffix(strings.ToLower(event.Name), ".java") || ignore.skip(event.Name, false) {
+				continue
+			}
+
+			// Restart the timer on every event so a burst of saves triggers a single deploy
+			debounce = time.After(watchDebounce)
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return true
+			}
+			tprintf("⚠️ Watcher error: %v\n", err)
+
+		case <-debounce:
+			debounce = nil
+			runWatchCycle(config)
+			tprintf("Watching %s for .java changes (Ctrl+C to stop)\n", watched)
+			fmt.Println()
+
+		case <-stop:
+			tprintln("Stopping watch mode")
+			return true
+		}
+	}
+}
+
+// runWatchCycle runs synchronously inside the watch loop, so changes made
+// while a deploy is in progress queue up and trigger the next cycle instead
+// of overlapping with the current one.
+func runWatchCycle(config *Config) {
+	deployMu.Lock()
+	defer deployMu.Unlock()
+
+	started := time.Now()
+	tprintf("🔁 Change detected at %s\n", started.Format("15:04:05"))
+	fmt.Println()
+
+	phases := watchPhases
+	switch {
+	case *flagHot:
+		phases = hotWatchPhases
+	case *flagSkipRestart:
+		phases = noRestartWatchPhases
+	}
+	for _, run := range phases {
+		if !run(config) {
+			tprintln("❌ Hot deploy failed, waiting for the next change")
+			fmt.Println()
+			recordMetrics(config, "watch", false, started)
+			return
+		}
+	}
+	recordMetrics(config, "watch", true, started)
+
+	tprintln("Hot deploy completed successfully!")
+	fmt.Println()
+}
+
+func addWatchDirs(watcher *fsnotify.Watcher, root string, ignore *ignoreRules) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() && ignore.skip(path, true) {
+			return filepath.SkipDir
+		}
+		if info.IsDir() {
+			return watcher.Add(path)
+		}
+		return nil
+	})
+}
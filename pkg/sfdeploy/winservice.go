@@ -0,0 +1,104 @@
+package sfdeploy
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// windowsServiceCandidates are the service names probed when
+// windows_service is not set.
+var windowsServiceCandidates = []string{"sfs2x", "SmartFoxServer2X", "SmartFoxServer 2X"}
+
+var serviceStatePattern = regexp.MustCompile(`STATE\s*:\s*\d+\s+(\w+)`)
+
+// findWindowsService returns the name of the installed SmartFox Windows
+// service, or "" when SmartFox is not installed as a service.
+func findWindowsService(config *Config) string {
+	if runtime.GOOS != "windows" {
+		return ""
+	}
+
+	candidates := windowsServiceCandidates
+	if config.WindowsService != "" {
+		candidates = []string{config.WindowsService}
+	}
+
+	for _, name := range candidates {
+		if serviceState(config, name) != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// scCommand runs sc against this machine or, for a target on a network
+// share, the share's server.
+func scCommand(config *Config, args ...string) *exec.Cmd {
+	if share, ok := targetShare(config); ok {
+		args = append([]string{`\\` + share.Host}, args...)
+	}
+	return newCommand("sc", args...)
+}
+
+// serviceState returns the sc query state, e.g. RUNNING or STOPPED, or ""
+// when the service does not exist.
+func serviceState(config *Config, name string) string {
+	output, err := scCommand(config, "query", name).Output()
+	if err != nil {
+		return ""
+	}
+	if match := serviceStatePattern.FindSubmatch(output); match != nil {
+		return string(match[1])
+	}
+	return ""
+}
+
+func waitForServiceState(config *Config, name, state string) bool {
+	deadline := time.Now().Add(restartTimeout(config))
+	return waitUntil(deadline, func() bool { return serviceState(config, name) == state })
+}
+
+func stopWindowsService(config *Config, name string) error {
+	if serviceState(config, name) == "STOPPED" {
+		return nil
+	}
+	if output, err := scCommand(config, "stop", name).CombinedOutput(); err != nil {
+		return fmt.Errorf("sc stop %s: %s", name, strings.TrimSpace(string(output)))
+	}
+	if !waitForServiceState(config, name, "STOPPED") {
+		return fmt.Errorf("service %s did not stop within %s", name, restartTimeout(config))
+	}
+	return nil
+}
+
+func startWindowsService(config *Config, name string) error {
+	if output, err := scCommand(config, "start", name).CombinedOutput(); err != nil {
+		return fmt.Errorf("sc start %s: %s", name, strings.TrimSpace(string(output)))
+	}
+	if !waitForServiceState(config, name, "RUNNING") {
+		return fmt.Errorf("service %s did not start within %s", name, restartTimeout(config))
+	}
+	return nil
+}
+
+func restartWindowsService(config *Config, name string) bool {
+	tprintf("🔍 Stopping Windows service %s...\n", name)
+	if err := stopWindowsService(config, name); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return false
+	}
+
+	tprintf("▶️ Starting Windows service %s...\n", name)
+	if err := startWindowsService(config, name); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return false
+	}
+
+	tprintln("✅ Service restarted")
+	fmt.Println()
+	return true
+}
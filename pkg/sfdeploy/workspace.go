@@ -0,0 +1,237 @@
+package sfdeploy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// workspaceEntry is one project of the workspace list: a source directory
+// built and deployed by --all after the projects it depends on. config holds
+// extra keys for this project only, e.g. its extension_folder.
+type workspaceEntry struct {
+	Name      string          `json:"name"`
+	SourceDir string          `json:"source_dir"`
+	DependsOn []string        `json:"depends_on"`
+	Config    json.RawMessage `json:"config"`
+}
+
+// workspaceProject is a workspace entry with its resolved config.
+type workspaceProject struct {
+	name   string
+	config Config
+}
+
+// workspacePhases is the --all pipeline of a command. Build and deploy run
+// per project in dependency order; the server restarts and the post-deploy
+// hooks of the config file run once at the end.
+func workspacePhases(name string) ([]phase, bool) {
+	switch name {
+	case "all":
+		// Every project is built and tested before the first one is deployed
+		return []phase{setupWorkspace, setupJava, eachProject(buildProject, customPhases("build"), runTests, customPhases("test")),
+			eachProject(lockedTarget(deployProject, customPhases("deploy"))),
+			lockedTarget(restartServer, checkServerHealth, smokeTest, customPhases("restart"), postDeployHooks), eachProject(cleanupProject)}, true
+	case "build":
+		return []phase{setupWorkspace, setupJava, eachProject(buildProject, customPhases("build"))}, true
+	case "test":
+		return []phase{setupWorkspace, setupJava, eachProject(buildProject, customPhases("build"), runTests, customPhases("test"))}, true
+	case "deploy":
+		return []phase{setupWorkspace, setupJava, eachProject(buildProject, customPhases("build")), eachProject(lockedTarget(deployProject, customPhases("deploy"))),
+			lockedTarget(restartServer, checkServerHealth, customPhases("restart"), postDeployHooks)}, true
+	}
+	return nil, false
+}
+
+// setupWorkspace replaces setupDirectories for --all. The targets are
+// checked once and every project gets the config setupDirectories would give
+// it with source_dir pointing at the project.
+func setupWorkspace(config *Config) bool {
+	tprintln("Phase 1: Workspace Setup")
+
+	if !reportConfigFile() {
+		return false
+	}
+	savedConfig, exists := loadConfig()
+	if !exists {
+		tprintf("Config file not found: %s\n", findConfigFile())
+		return false
+	}
+
+	*config = savedConfig
+	if !applyOverrides(config) {
+		return false
+	}
+	openLogFile(config)
+	useLanguage(config)
+
+	if config.CopyWorkers > 0 {
+		copyWorkers = config.CopyWorkers
+	}
+
+	if len(config.Workspace) == 0 {
+		tprintf("--all needs a workspace list in %s\n", findConfigFile())
+		return false
+	}
+	order, err := workspaceOrder(config.Workspace)
+	if err != nil {
+		tprintf("Invalid workspace: %v\n", err)
+		return false
+	}
+
+	if !setupTargets(config) {
+		return false
+	}
+
+	var names []string
+	for _, entry := range order {
+		project, ok := workspaceProjectConfig(savedConfig, entry)
+		if !ok {
+			return false
+		}
+		project.Targets, project.TargetDir = config.Targets, config.TargetDir
+		config.projects = append(config.projects, workspaceProject{entry.Name, project})
+		names = append(names, entry.Name)
+	}
+
+	// The single restart names the last project's extension to the admin API
+	if config.ExtensionFolder == "" {
+		last := config.projects[len(config.projects)-1].config
+		config.ExtensionFolder, config.ExtensionFile = last.ExtensionFolder, last.ExtensionFile
+	}
+
+	tprintf("Workspace: %s\n", strings.Join(names, " → "))
+	printTargets(config)
+	fmt.Println()
+	return true
+}
+
+// workspaceProjectConfig layers the project's .sfdeploy.json, the config file,
+// profile, environment and flags, then the entry's own config.
+func workspaceProjectConfig(saved Config, entry workspaceEntry) (Config, bool) {
+	project := saved
+	project.SourceDir = entry.SourceDir
+	if !applyOverrides(&project) {
+		return project, false
+	}
+	// A source_dir in the config file belongs to the workspace, not to this project
+	project.SourceDir = entry.SourceDir
+	project.Workspace = nil
+
+	if len(entry.Config) > 0 {
+		if err := json.Unmarshal(entry.Config, &project); err != nil {
+			tprintf("Invalid config of workspace project %s: %v\n", entry.Name, err)
+			return project, false
+		}
+		if err := resolveSecrets(&project); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return project, false
+		}
+	}
+
+	if len(project.Modules) == 0 && !validateSourceDir(project.SourceDir) {
+		tprintf("Source directory of %s is invalid: %s\n", entry.Name, project.SourceDir)
+		return project, false
+	}
+	if !selectExtensions(&project) {
+		return project, false
+	}
+	if project.ExtensionFolder == "" {
+		tprintf("Workspace project %s has no extension_folder\n", entry.Name)
+		return project, false
+	}
+	if project.ExtensionFile == "" {
+		project.ExtensionFile = project.ExtensionFolder + ".jar"
+	}
+	if err := checkCustomPhases(&project); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return project, false
+	}
+	return project, true
+}
+
+// workspaceOrder sorts the entries so that every project comes after the
+// projects it depends on, keeping the listed order otherwise. An entry
+// without a name is named after its source directory.
+func workspaceOrder(entries []workspaceEntry) ([]workspaceEntry, error) {
+	entries = append([]workspaceEntry(nil), entries...)
+	index := map[string]int{}
+	for i := range entries {
+		if entries[i].SourceDir == "" {
+			return nil, errors.New("every workspace project needs a source_dir")
+		}
+		if entries[i].Name == "" {
+			entries[i].Name = filepath.Base(filepath.Clean(entries[i].SourceDir))
+		}
+		if _, ok := index[entries[i].Name]; ok {
+			return nil, fmt.Errorf("project %s is listed twice", entries[i].Name)
+		}
+		index[entries[i].Name] = i
+	}
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make([]int, len(entries))
+	var order []workspaceEntry
+	var path []string
+
+	var visit func(i int) error
+	visit = func(i int) error {
+		name := entries[i].Name
+		switch state[i] {
+		case done:
+			return nil
+		case visiting:
+			for j, n := range path {
+				if n == name {
+					return fmt.Errorf("dependency cycle: %s", strings.Join(append(path[j:], name), " → "))
+				}
+			}
+		}
+
+		state[i] = visiting
+		path = append(path, name)
+		for _, dep := range entries[i].DependsOn {
+			j, ok := index[dep]
+			if !ok {
+				return fmt.Errorf("%s depends on %s, which is not in the workspace", name, dep)
+			}
+			if err := visit(j); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = done
+		order = append(order, entries[i])
+		return nil
+	}
+
+	for i := range entries {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// eachProject runs phases for every workspace project in build order.
+func eachProject(phases ...phase) phase {
+	return func(config *Config) bool {
+		for i, p := range config.projects {
+			project := p.config
+			// setupJava ran once, on the workspace config
+			project.JavaPath = config.JavaPath
+
+			tprintf("📦 Project %d/%d: %s (%s)\n", i+1, len(config.projects), p.name, project.SourceDir)
+			fmt.Println()
+			if !runPhases(&project, phases) {
+				return false
+			}
+		}
+		return true
+	}
+}
@@ -0,0 +1,51 @@
+package sfdeploy
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestWorkspaceOrder(t *testing.T) {
+	entry := func(dir string, deps ...string) workspaceEntry {
+		return workspaceEntry{SourceDir: dir, DependsOn: deps}
+	}
+
+	tests := []struct {
+		name    string
+		entries []workspaceEntry
+		want    []string
+		wantErr string
+	}{
+		{"listed order without dependencies", []workspaceEntry{entry("../game"), entry("../chat")}, []string{"game", "chat"}, ""},
+		{"dependencies first", []workspaceEntry{entry("../game", "common"), entry("../chat", "common"), entry("../common/")},
+			[]string{"common", "game", "chat"}, ""},
+		{"transitive dependencies", []workspaceEntry{entry("a", "b"), entry("b", "c"), entry("c")}, []string{"c", "b", "a"}, ""},
+		{"explicit names", []workspaceEntry{{Name: "core", SourceDir: "../lib"}, entry("../game", "core")}, []string{"core", "game"}, ""},
+		{"missing source_dir", []workspaceEntry{{Name: "x"}}, nil, "needs a source_dir"},
+		{"listed twice", []workspaceEntry{entry("a"), entry("../other/a")}, nil, "project a is listed twice"},
+		{"unknown dependency", []workspaceEntry{entry("a", "b")}, nil, "a depends on b, which is not in the workspace"},
+		{"cycle", []workspaceEntry{entry("a", "b"), entry("b", "c"), entry("c", "b")}, nil, "dependency cycle: b → c → b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, err := workspaceOrder(tt.entries)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("workspaceOrder() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, e := range order {
+				names = append(names, e.Name)
+			}
+			if !slices.Equal(names, tt.want) {
+				t.Errorf("workspaceOrder() = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package sfdeploy
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// xmlChange is one leaf element whose text patchXML replaced.
+type xmlChange struct {
+	Path string
+	Old  string
+	New  string
+}
+
+// zoneSettings collects the zone XML values to enforce, as element paths
+// relative to <zone>.
+func zoneSettings(config *Config) map[string]string {
+	settings := map[string]string{}
+	for key, value := range config.ZoneSettings {
+		settings[strings.Trim(key, "/")] = value
+	}
+	if config.ZoneMainClass != "" {
+		settings["extension/name"] = config.ExtensionFolder
+		settings["extension/file"] = config.ZoneMainClass
+	}
+	if config.ZoneReloadMode != "" {
+		settings["extension/reloadMode"] = config.ZoneReloadMode
+	}
+	return settings
+}
+
+func zoneFileName(config *Config) string {
+	if config.ZoneFile != "" {
+		return config.ZoneFile
+	}
+	return config.ZoneName + ".zone.xml"
+}
+
+// patchXML sets the text of the leaf elements at the given paths, leaving
+// every other byte of the document untouched so comments and formatting
+// survive. Only the first element matching a path is changed.
+func patchXML(data []byte, values map[string]string) ([]byte, []xmlChange, []string, error) {
+	type element struct {
+		start      int64 // offset of '<'
+		innerStart int64 // offset just after the start tag
+		leaf       bool
+	}
+	type edit struct {
+		from, to int64
+		text     string
+	}
+
+	var stack []string
+	var elements []element
+	var edits []edit
+	var changes []xmlChange
+	done := map[string]bool{}
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		before := decoder.InputOffset()
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if len(elements) > 0 {
+				elements[len(elements)-1].leaf = false
+			}
+			stack = append(stack, t.Name.Local)
+			elements = append(elements, element{start: before, innerStart: decoder.InputOffset(), leaf: true})
+
+		case xml.EndElement:
+			el := elements[len(elements)-1]
+			key := strings.Join(stack[1:], "/")
+			end := decoder.InputOffset()
+
+			if value, ok := values[key]; ok && el.leaf && !done[key] {
+				done[key] = true
+				name := stack[len(stack)-1]
+				escaped := xmlEscape(value)
+
+				if end == el.innerStart {
+					// Self-closing <name/>
+					if value != "" {
+						edits = append(edits, edit{el.start, end, "<" + name + ">" + escaped + "</" + name + ">"})
+						changes = append(changes, xmlChange{Path: key, New: value})
+					}
+				} else {
+					innerEnd := int64(bytes.LastIndex(data[:end], []byte("</")))
+					old := strings.TrimSpace(xmlUnescape(string(data[el.innerStart:innerEnd])))
+					if old != value {
+						edits = append(edits, edit{el.innerStart, innerEnd, escaped})
+						changes = append(changes, xmlChange{Path: key, Old: old, New: value})
+					}
+				}
+			}
+
+			stack = stack[:len(stack)-1]
+			elements = elements[:len(elements)-1]
+		}
+	}
+
+	var missing []string
+	for key := range values {
+		if !done[key] {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+
+	var out bytes.Buffer
+	var pos int64
+	for _, e := range edits {
+		out.Write(data[pos:e.from])
+		out.WriteString(e.text)
+		pos = e.to
+	}
+	out.Write(data[pos:])
+
+	return out.Bytes(), changes, missing, nil
+}
+
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+func xmlUnescape(s string) string {
+	var text string
+	if xml.Unmarshal([]byte("<x>"+s+"</x>"), &text) != nil {
+		return s
+	}
+	return text
+}
+
+// readTargetFile and writeTargetFile access a file below target_dir,
+// whichever kind of target it is.
+func readTargetFile(config *Config, rel string) ([]byte, error) {
+	if target, ok := parseShellTarget(config.TargetDir); ok {
+		output, err := target.run(config, "cat "+shellQuote(target.path(rel)))
+		if err != nil {
+			return nil, fmt.Errorf("%s", strings.TrimSpace(string(output)))
+		}
+		return output, nil
+	}
+	return os.ReadFile(filepath.Join(config.TargetDir, filepath.FromSlash(rel)))
+}
+
+func writeTargetFile(config *Config, rel string, data []byte) error {
+	remote, isRemote := parseRemoteTarget(config.TargetDir)
+	d, isDocker := parseDockerTarget(config.TargetDir)
+	if !isRemote && !isDocker {
+		return os.WriteFile(filepath.Join(config.TargetDir, filepath.FromSlash(rel)), data, 0644)
+	}
+
+	tmp, err := os.CreateTemp("", "sfdeploy-*"+filepath.Ext(rel))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	tmp.Close()
+
+	if isDocker {
+		return dockerCopy(tmp.Name(), d.Container+":"+d.path(rel))
+	}
+	if output, err := remote.sftp(config, []string{fmt.Sprintf("put %s %s", sftpQuote(tmp.Name()), sftpQuote(remote.path(rel)))}); err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// patchZone brings the zone definition in SFS2X/zones in line with the
+// zone settings from the config.
+func patchZone(config *Config) bool {
+	settings := zoneSettings(config)
+	if config.ZoneName == "" && config.ZoneFile == "" {
+		if len(settings) > 0 {
+			tprintln("⚠️ Warning: zone settings are configured but zone_name is empty")
+		}
+		return true
+	}
+	if len(settings) == 0 {
+		return true
+	}
+
+	rel := "SFS2X/zones/" + zoneFileName(config)
+	tprintf("🧩 Checking zone definition %s...\n", rel)
+
+	data, err := readTargetFile(config, rel)
+	if err != nil {
+		tprintf("❌ Failed to read %s: %v\n", rel, err)
+		return false
+	}
+
+	patched, changes, missing, err := patchXML(data, settings)
+	if err != nil {
+		tprintf("❌ Failed to parse %s: %v\n", rel, err)
+		return false
+	}
+	for _, key := range missing {
+		tprintf("⚠️ Warning: <%s> not found in %s\n", key, zoneFileName(config))
+	}
+
+	if len(changes) == 0 {
+		tprintln("   Zone definition is up to date")
+		fmt.Println()
+		return true
+	}
+
+	for _, change := range changes {
+		prefix := "   ✏️"
+		if *flagDryRun {
+			prefix = "[dry-run] Would set"
+		}
+		fmt.Printf("%s %s: %q -> %q\n", prefix, change.Path, change.Old, change.New)
+	}
+	if *flagDryRun {
+		fmt.Println()
+		return true
+	}
+
+	if err := writeTargetFile(config, rel, patched); err != nil {
+		tprintf("❌ Failed to write %s: %v\n", rel, err)
+		return false
+	}
+	tprintf("✅ Updated %s\n", zoneFileName(config))
+	fmt.Println()
+	return true
+}
@@ -0,0 +1,109 @@
+package sfdeploy
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPatchXML(t *testing.T) {
+	const zone = `<zone>
+  <!-- keep me -->
+  <name>Game</name>
+  <maxUsers>100</maxUsers>
+  <roomSettings>
+    <room><name>Lobby</name></room>
+    <room><name>Arena</name></room>
+  </roomSettings>
+  <customLogin/>
+</zone>
+`
+
+	tests := []struct {
+		name    string
+		values  map[string]string
+		want    string
+		changes []xmlChange
+		missing []string
+	}{
+		{
+			name:   "replaces the text and keeps the rest",
+			values: map[string]string{"maxUsers": "500"},
+			want: `<zone>
+  <!-- keep me -->
+  <name>Game</name>
+  <maxUsers>500</maxUsers>
+  <roomSettings>
+    <room><name>Lobby</name></room>
+    <room><name>Arena</name></room>
+  </roomSettings>
+  <customLogin/>
+</zone>
+`,
+			changes: []xmlChange{{Path: "maxUsers", Old: "100", New: "500"}},
+		},
+		{
+			name:   "escapes the value and fills a self-closing element",
+			values: map[string]string{"name": "A&B <1>", "customLogin": "true"},
+			want: `<zone>
+  <!-- keep me -->
+  <name>A&amp;B &lt;1&gt;</name>
+  <maxUsers>100</maxUsers>
+  <roomSettings>
+    <room><name>Lobby</name></room>
+    <room><name>Arena</name></room>
+  </roomSettings>
+  <customLogin>true</customLogin>
+</zone>
+`,
+			changes: []xmlChange{{Path: "name", Old: "Game", New: "A&B <1>"}, {Path: "customLogin", New: "true"}},
+		},
+		{
+			name:   "only the first match changes",
+			values: map[string]string{"roomSettings/room/name": "Hall"},
+			want: `<zone>
+  <!-- keep me -->
+  <name>Game</name>
+  <maxUsers>100</maxUsers>
+  <roomSettings>
+    <room><name>Hall</name></room>
+    <room><name>Arena</name></room>
+  </roomSettings>
+  <customLogin/>
+</zone>
+`,
+			changes: []xmlChange{{Path: "roomSettings/room/name", Old: "Lobby", New: "Hall"}},
+		},
+		{
+			name:   "same value, empty self-closing value",
+			values: map[string]string{"maxUsers": "100", "customLogin": ""},
+			want:   zone,
+		},
+		{
+			name:    "unknown and non-leaf paths are missing",
+			values:  map[string]string{"roomSettings": "x", "maxRooms": "3"},
+			want:    zone,
+			missing: []string{"maxRooms", "roomSettings"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changes, missing, err := patchXML([]byte(zone), tt.values)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("patchXML() =\n%s\nwant\n%s", got, tt.want)
+			}
+			if !slices.Equal(changes, tt.changes) {
+				t.Errorf("changes = %+v, want %+v", changes, tt.changes)
+			}
+			if !slices.Equal(missing, tt.missing) {
+				t.Errorf("missing = %v, want %v", missing, tt.missing)
+			}
+		})
+	}
+
+	if _, _, _, err := patchXML([]byte("<zone><name>Game</na"), map[string]string{"name": "x"}); err == nil {
+		t.Error("patchXML() of truncated XML did not fail")
+	}
+}